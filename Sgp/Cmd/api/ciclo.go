@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// ciclo guarda como encerrar cada subsistema, na ordem em que foram
+// iniciados. O encerramento percorre a lista ao contrário: o servidor HTTP
+// para antes dos agendadores, estes antes das tarefas em segundo plano, e o
+// Firestore fecha por último, quando ninguém mais o usa.
+type ciclo struct {
+	etapas []etapa
+}
+
+type etapa struct {
+	nome     string
+	prazo    time.Duration
+	encerrar func(ctx context.Context) error
+}
+
+func (c *ciclo) registrar(nome string, prazo time.Duration, encerrar func(ctx context.Context) error) {
+	c.etapas = append(c.etapas, etapa{nome: nome, prazo: prazo, encerrar: encerrar})
+}
+
+// encerrar dá a cada etapa o seu próprio prazo, para que uma etapa lenta não
+// consuma o tempo das seguintes. Uma etapa que falha ou estoura o prazo não
+// impede as seguintes; se ela ignorar o contexto, segue rodando enquanto as
+// outras encerram.
+func (c *ciclo) encerrar() {
+	for i := len(c.etapas) - 1; i >= 0; i-- {
+		e := c.etapas[i]
+		inicio := time.Now()
+		ctx, cancelar := context.WithTimeout(context.Background(), e.prazo)
+		resultado := make(chan error, 1)
+		go func() { resultado <- e.encerrar(ctx) }()
+
+		var err error
+		select {
+		case err = <-resultado:
+		case <-ctx.Done():
+			err = fmt.Errorf("prazo de %s esgotado", e.prazo)
+		}
+		cancelar()
+		if err != nil {
+			log.Printf("ERRO ao encerrar %s: %v", e.nome, err)
+			continue
+		}
+		log.Printf("%s: encerrado em %s", e.nome, time.Since(inicio).Round(time.Millisecond))
+	}
+}
+
+// prontidao responde GET /saude. Durante a drenagem devolve 503, para que o
+// balanceador tire a instância de rotação antes de o servidor parar de aceitar conexões.
+type prontidao struct {
+	drenando atomic.Bool
+}
+
+func (p *prontidao) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if p.drenando.Load() {
+		http.Error(w, "encerrando", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+func segundosEnv(nome string, padrao time.Duration) time.Duration {
+	if v, err := strconv.Atoi(os.Getenv(nome)); err == nil && v >= 0 {
+		return time.Duration(v) * time.Second
+	}
+	return padrao
+}
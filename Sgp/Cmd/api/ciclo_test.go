@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCicloEncerraNaOrdemInversa(t *testing.T) {
+	var mu sync.Mutex
+	var ordem []string
+	anotar := func(nome string) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			ordem = append(ordem, nome)
+			return nil
+		}
+	}
+
+	vida := &ciclo{}
+	vida.registrar("firestore", time.Second, anotar("firestore"))
+	vida.registrar("tarefas", time.Second, func(context.Context) error {
+		anotar("tarefas")(context.Background())
+		return errors.New("falhou")
+	})
+	vida.registrar("servidor HTTP", time.Second, anotar("servidor HTTP"))
+	vida.encerrar()
+
+	if esperado := []string{"servidor HTTP", "tarefas", "firestore"}; !reflect.DeepEqual(ordem, esperado) {
+		t.Errorf("ordem de encerramento = %v, esperado %v", ordem, esperado)
+	}
+}
+
+func TestCicloPrazoPorEtapa(t *testing.T) {
+	var restante time.Duration
+	vida := &ciclo{}
+	vida.registrar("firestore", 200*time.Millisecond, func(ctx context.Context) error {
+		prazo, _ := ctx.Deadline()
+		restante = time.Until(prazo)
+		return nil
+	})
+	// Uma etapa que ignora o contexto não segura as seguintes além do prazo dela.
+	liberar := make(chan struct{})
+	defer close(liberar)
+	vida.registrar("tarefas", 30*time.Millisecond, func(context.Context) error {
+		<-liberar
+		return nil
+	})
+	vida.registrar("servidor HTTP", 30*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	inicio := time.Now()
+	vida.encerrar()
+	if duracao := time.Since(inicio); duracao > 150*time.Millisecond {
+		t.Errorf("as etapas lentas deveriam parar no próprio prazo; o encerramento levou %s", duracao)
+	}
+	if restante < 150*time.Millisecond {
+		t.Errorf("a última etapa deveria ter o prazo inteiro, restavam %s", restante)
+	}
+}
+
+func TestProntidaoDuranteDrenagem(t *testing.T) {
+	pronto := &prontidao{}
+	for _, tc := range []struct {
+		drenando bool
+		status   int
+	}{{false, http.StatusOK}, {true, http.StatusServiceUnavailable}} {
+		pronto.drenando.Store(tc.drenando)
+		rr := httptest.NewRecorder()
+		pronto.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/saude", nil))
+		if rr.Code != tc.status {
+			t.Errorf("drenando=%v: status %d, esperado %d", tc.drenando, rr.Code, tc.status)
+		}
+	}
+}
+
+func TestSegundosEnv(t *testing.T) {
+	t.Setenv("ENCERRAMENTO_TESTE", "3")
+	if d := segundosEnv("ENCERRAMENTO_TESTE", time.Second); d != 3*time.Second {
+		t.Errorf("valor definido: %s", d)
+	}
+	t.Setenv("ENCERRAMENTO_TESTE", "-1")
+	if d := segundosEnv("ENCERRAMENTO_TESTE", time.Second); d != time.Second {
+		t.Errorf("valor inválido deveria usar o padrão: %s", d)
+	}
+}
@@ -6,10 +6,15 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"sgp/Internal/handler"
 	"sgp/Internal/middleware"
 	"sgp/Internal/repository"
+	"sgp/Internal/reqctx"
 	"sgp/Internal/service"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	firebase "firebase.google.com/go/v4"
@@ -47,21 +52,149 @@ func main() {
 	if err != nil {
 		log.Fatalf("erro ao conectar ao firestore: %v", err)
 	}
-	defer client.Close()
+
+	// Cada subsistema registra seu encerramento logo depois de iniciado, com
+	// o próprio prazo; no SIGTERM eles param na ordem inversa (veja ciclo.go).
+	vida := &ciclo{}
+	vida.registrar("firestore", 5*time.Second, func(context.Context) error { return client.Close() })
 
 	resendApiKey := os.Getenv("RESEND_API_KEY")
 
 	emailService := service.NewEmailService(resendApiKey)
+	// Os envios que as tarefas não concluírem no prazo ficam gravados como
+	// falha, para reenvio, antes de o Firestore fechar.
+	vida.registrar("e-mails interrompidos", 5*time.Second, emailService.RegistrarInterrompidos)
+
+	// E-mails e alertas disparados pelos handlers e pelo plantão.
+	tarefas := &service.Tarefas{}
+	vida.registrar("tarefas em segundo plano", segundosEnv("ENCERRAMENTO_TAREFAS_SEGUNDOS", 10*time.Second), tarefas.Aguardar)
+
+	// Sem a chave mestra as notas de sessão ficam indisponíveis (503).
+	cifraNotas, err := service.NewCifraEnvelope(os.Getenv("NOTAS_CHAVE_MESTRA"))
+	if err != nil {
+		log.Printf("AVISO: notas de sessão desativadas: %v", err)
+	}
+
+	// Toda escrita nos quatro repositórios principais passa pela auditoria.
+	auditoriaRepo := repository.NewAuditoriaRepository(client)
+	alunoRepo := repository.NewAlunoRepositoryAuditado(repository.NewAlunoRepository(client), auditoriaRepo)
+	psicologoRepo := repository.NewPsicologoRepositoryAuditado(repository.NewPsicologoRepository(client), auditoriaRepo)
+	consultaFirestore := repository.NewConsultaRepository(client)
+	consultaRepo := repository.NewConsultaRepositoryAuditado(consultaFirestore, repository.NewHorarioDisponivelRepository(client), auditoriaRepo)
+	horarioRepo := repository.NewHorarioDisponivelRepositoryAuditado(repository.NewHorarioDisponivelRepository(client), auditoriaRepo)
+	notaRepo := repository.NewSessaoNotaRepository(client)
+	notificacaoRepo := repository.NewNotificacaoRepository(client)
+	emailService.Registro = notificacaoRepo
+	consentimentoRepo := repository.NewConsentimentoRepository(client)
+	formularioRepo := repository.NewFormularioRepository(client)
+	salaRepo := repository.NewSalaRepository(client)
+	calendarioRepo := repository.NewCalendarioRepository(client)
+	instrumentoRepo := repository.NewInstrumentoRepository(client)
+	plantaoRepo := repository.NewPlantaoRepository(client)
+	episodioRepo := repository.NewEpisodioRepository(client)
+	encaminhamentoRepo := repository.NewEncaminhamentoRepository(client)
+
+	// Os agendadores param quando ctxAgendadores é cancelado; a rodada em
+	// curso de cada um termina antes.
+	ctxAgendadores, pararAgendadores := context.WithCancel(ctx)
+	agendadores := &service.Tarefas{}
+	vida.registrar("agendadores", segundosEnv("ENCERRAMENTO_AGENDADORES_SEGUNDOS", 10*time.Second), func(ctx context.Context) error {
+		pararAgendadores()
+		return agendadores.Aguardar(ctx)
+	})
+
+	plantaoService := service.NewPlantaoService(plantaoRepo, psicologoRepo, alunoRepo, emailService)
+	plantaoService.Tarefas = tarefas
+	if emails := os.Getenv("PLANTAO_EMAILS"); emails != "" {
+		plantaoService.EmailsReserva = strings.Split(emails, ",")
+	}
+	if minutos, err := strconv.Atoi(os.Getenv("PLANTAO_PRAZO_MINUTOS")); err == nil && minutos > 0 {
+		plantaoService.Prazo = time.Duration(minutos) * time.Minute
+	}
+	agendadores.Disparar(func() { plantaoService.IniciarEscalonamento(ctxAgendadores, time.Minute) })
+
+	calendarioService := service.NewCalendarioService(calendarioRepo, consultaRepo, horarioRepo, alunoRepo, emailService)
+	calendarioService.ExigirPeriodoLetivo = os.Getenv("CALENDARIO_EXIGIR_PERIODO_LETIVO") == "true"
+
+	presencaService := service.NewPresencaService(consultaRepo)
+	if minutos, err := strconv.Atoi(os.Getenv("FALTA_TOLERANCIA_MINUTOS")); err == nil && minutos > 0 {
+		presencaService.Tolerancia = time.Duration(minutos) * time.Minute
+	}
+	agendadores.Disparar(func() { presencaService.IniciarMarcacaoFaltas(ctxAgendadores, 5*time.Minute) })
 
-	alunoRepo := repository.NewAlunoRepository(client)
-	psicologoRepo := repository.NewPsicologoRepository(client)
-	consultaRepo := repository.NewConsultaRepository(client)
-	horarioRepo := repository.NewHorarioDisponivelRepository(client)
+	// Horários reservados por encaminhamentos sem resposta voltam à agenda.
+	reservaService := service.NewReservaService(horarioRepo)
+	agendadores.Disparar(func() { reservaService.IniciarLiberacaoReservas(ctxAgendadores, 15*time.Minute) })
+
+	// Sem RECONCILIACAO_APLICAR as inconsistências só são registradas no log.
+	reconciliador := service.NewReconciliador(alunoRepo, psicologoRepo, horarioRepo, consultaRepo)
+	intervaloReconciliacao := time.Hour
+	if minutos, err := strconv.Atoi(os.Getenv("RECONCILIACAO_INTERVALO_MINUTOS")); err == nil {
+		intervaloReconciliacao = time.Duration(minutos) * time.Minute
+	}
+	if intervaloReconciliacao > 0 {
+		aplicar := os.Getenv("RECONCILIACAO_APLICAR") == "true"
+		agendadores.Disparar(func() { reconciliador.IniciarReconciliacao(ctxAgendadores, intervaloReconciliacao, aplicar) })
+	}
 
 	alunoHandler := handler.NewAlunoHandler(alunoRepo)
+	alunoHandler.ConsultaRepo = consultaRepo
 	psicologoHandler := handler.NewPsicologoHandler(psicologoRepo)
+	psicologoHandler.HorarioRepo = horarioRepo
 	consultaHandler := handler.NewConsultaHandler(consultaRepo, alunoRepo, psicologoRepo, emailService)
+	consultaHandler.ConsentimentoRepo = consentimentoRepo
+	consultaHandler.HorarioRepo = horarioRepo
+	consultaHandler.FormularioRepo = formularioRepo
+	consultaHandler.Plantao = plantaoService
+	politica := carregarPolitica()
+	consultaHandler.Politica = &politica
+	consultaHandler.EmailsPlantao = plantaoService.EmailsReserva
+	consultaHandler.Calendario = calendarioService
+	consultaHandler.EpisodioRepo = episodioRepo
+	consultaHandler.Tarefas = tarefas
+	// Quem sai da lista de espera de um grupo é avisado por e-mail, e a
+	// promoção entra na auditoria.
+	consultaFirestore.AoPromover = consultaRepo.AuditarPromocao(consultaHandler.NotificarPromocao)
+	// Sem o segredo, consultas online são confirmadas sem link gerado.
+	if reunioes, err := service.NewJitsiProvider(os.Getenv("REUNIAO_URL_BASE"), os.Getenv("REUNIAO_SEGREDO")); err == nil {
+		consultaHandler.Reunioes = reunioes
+	} else {
+		log.Printf("AVISO: links de reunião desativados: %v", err)
+	}
 	horarioHandler := handler.NewHorarioDisponivelHandler(horarioRepo)
+	horarioHandler.SalaRepo = salaRepo
+	horarioHandler.Calendario = calendarioService
+	horarioHandler.PsicologoRepo = psicologoRepo
+	calendarioHandler := handler.NewCalendarioHandler(calendarioService)
+	calendarioHandler.Tarefas = tarefas
+	salaHandler := handler.NewSalaHandler(salaRepo, horarioRepo)
+	auditoriaHandler := handler.NewAuditoriaHandler(auditoriaRepo)
+	relatorioHandler := handler.NewRelatorioHandler(consultaRepo, horarioRepo, psicologoRepo)
+	importacaoHandler := handler.NewImportacaoHandler(alunoRepo, psicologoRepo)
+	notaHandler := handler.NewSessaoNotaHandler(notaRepo, consultaRepo, cifraNotas)
+	privacidadeHandler := handler.NewPrivacidadeHandler(alunoRepo, consultaRepo, notificacaoRepo, auditoriaRepo)
+	privacidadeHandler.ConsentimentoRepo = consentimentoRepo
+	privacidadeHandler.FormularioRepo = formularioRepo
+	privacidadeHandler.InstrumentoRepo = instrumentoRepo
+	privacidadeHandler.EpisodioRepo = episodioRepo
+	consentimentoHandler := handler.NewConsentimentoHandler(consentimentoRepo)
+	// Atrás de um proxy reverso, o IP do aceite vem do X-Forwarded-For que ele escreve.
+	proxies, err := handler.LerRedes(os.Getenv("PROXIES_CONFIAVEIS"))
+	if err != nil {
+		log.Fatalf("PROXIES_CONFIAVEIS inválida: %v", err)
+	}
+	consentimentoHandler.ProxiesConfiaveis = proxies
+	formularioHandler := handler.NewFormularioHandler(formularioRepo, consultaRepo)
+	instrumentoHandler := handler.NewInstrumentoHandler(instrumentoRepo, alunoRepo, psicologoRepo, emailService)
+	instrumentoHandler.Plantao = plantaoService
+	instrumentoHandler.Tarefas = tarefas
+	instrumentoHandler.ConsultaRepo = consultaRepo
+	plantaoHandler := handler.NewPlantaoHandler(plantaoService)
+	presencaHandler := handler.NewPresencaHandler(consultaRepo)
+	episodioHandler := handler.NewEpisodioHandler(episodioRepo, consultaRepo, psicologoRepo)
+	encaminhamentoHandler := handler.NewEncaminhamentoHandler(encaminhamentoRepo, consultaRepo, horarioRepo, episodioRepo, psicologoRepo)
+	encaminhamentoHandler.FormularioRepo = formularioRepo
+	encaminhamentoHandler.InstrumentoRepo = instrumentoRepo
 	
 	// [!code ++] NOVO HANDLER DE USUÁRIO
 	userHandler := handler.NewUserHandler(alunoRepo, psicologoRepo)
@@ -75,6 +208,9 @@ func main() {
 	
 	// [!code ++] NOVA ROTA
 	mux.HandleFunc("GET /users/{id}/role", userHandler.HandlerGetUserRole)
+	mux.HandleFunc("GET /recursos-crise", alunoHandler.HandlerRecursosCrise)
+	pronto := &prontidao{}
+	mux.Handle("GET /saude", pronto)
 
 	if is_middleware_on {
 		mux.Handle("POST /alunos", authMiddleware.Verify(http.HandlerFunc(alunoHandler.HandlerCriarAluno)))
@@ -88,17 +224,84 @@ func main() {
 		mux.Handle("GET /psicologos", authMiddleware.Verify(http.HandlerFunc(psicologoHandler.HandlerListarPsicologos)))
 		mux.Handle("GET /psicologos/{id}", authMiddleware.Verify(http.HandlerFunc(psicologoHandler.HandlerBuscarPsicologoPorID)))
 		mux.Handle("GET /psicologos/nome", authMiddleware.Verify(http.HandlerFunc(psicologoHandler.HandlerBuscarPsicologoPorNome)))
+		mux.Handle("GET /psicologos/recomendacoes", authMiddleware.Verify(http.HandlerFunc(psicologoHandler.HandlerRecomendarPsicologos)))
 		mux.Handle("PUT /psicologos/{id}", authMiddleware.Verify(http.HandlerFunc(psicologoHandler.HandlerAtualizarPsicologo)))
 		mux.Handle("DELETE /psicologos/{id}", authMiddleware.Verify(http.HandlerFunc(psicologoHandler.HandlerDeletarPsicologo)))
 
 		mux.HandleFunc("POST /consultas", consultaHandler.HandlerAgendarConsulta)
+		mux.HandleFunc("GET /triagem/perguntas", consultaHandler.HandlerPerguntasTriagem)
 		mux.Handle("GET /consultas/psicologo", authMiddleware.Verify(http.HandlerFunc(consultaHandler.HandlerListarConsultasPorPsicologo)))
 		mux.Handle("PATCH /consultas/{id}/status", authMiddleware.Verify(http.HandlerFunc(consultaHandler.HandlerAtualizarStatusConsulta)))
 		mux.Handle("DELETE /consultas/{id}", authMiddleware.Verify(http.HandlerFunc(consultaHandler.HandlerDeletarConsulta)))
+		mux.Handle("GET /consultas/{id}/convite.ics", authMiddleware.Verify(http.HandlerFunc(consultaHandler.HandlerConviteConsulta)))
+		mux.Handle("PUT /consultas/{id}/nota", authMiddleware.Verify(http.HandlerFunc(notaHandler.HandlerSalvarNota)))
+		mux.Handle("GET /consultas/{id}/nota", authMiddleware.Verify(http.HandlerFunc(notaHandler.HandlerBuscarNota)))
+		mux.Handle("GET /consultas/{id}/nota/versoes", authMiddleware.Verify(http.HandlerFunc(notaHandler.HandlerListarVersoesNota)))
 
 		mux.HandleFunc("POST /horarios", horarioHandler.HandlerCriarHorario)
+		mux.HandleFunc("POST /horarios/grade", horarioHandler.HandlerGerarHorarios)
 		mux.HandleFunc("GET /horarios", horarioHandler.HandlerListarHorarios)
 		mux.HandleFunc("DELETE /horarios/{id}", horarioHandler.HandlerDeletarHorario)
+
+		somenteAdmin := middleware.ExigirPapel(reqctx.PapelAdmin)
+		mux.Handle("GET /auditoria", authMiddleware.Verify(somenteAdmin(http.HandlerFunc(auditoriaHandler.HandlerListarAuditoria))))
+		mux.Handle("GET /relatorios/atendimento", authMiddleware.Verify(somenteAdmin(http.HandlerFunc(relatorioHandler.HandlerRelatorioAtendimento))))
+		mux.Handle("POST /importacoes/{tipo}", authMiddleware.Verify(somenteAdmin(http.HandlerFunc(importacaoHandler.HandlerImportar))))
+		mux.Handle("GET /alunos/{id}/dados", authMiddleware.Verify(http.HandlerFunc(privacidadeHandler.HandlerExportarDadosAluno)))
+		mux.Handle("POST /alunos/{id}/anonimizar", authMiddleware.Verify(somenteAdmin(http.HandlerFunc(privacidadeHandler.HandlerAnonimizarAluno))))
+
+		mux.Handle("POST /consentimentos", authMiddleware.Verify(somenteAdmin(http.HandlerFunc(consentimentoHandler.HandlerPublicarConsentimento))))
+		mux.HandleFunc("GET /consentimentos/vigente", consentimentoHandler.HandlerBuscarConsentimentoVigente)
+		mux.Handle("POST /alunos/{id}/consentimentos", authMiddleware.Verify(http.HandlerFunc(consentimentoHandler.HandlerAceitarConsentimento)))
+		mux.Handle("GET /alunos/{id}/consentimentos", authMiddleware.Verify(http.HandlerFunc(consentimentoHandler.HandlerListarConsentimentosAluno)))
+
+		mux.Handle("PUT /psicologos/{id}/formulario", authMiddleware.Verify(http.HandlerFunc(formularioHandler.HandlerSalvarFormulario)))
+		mux.HandleFunc("GET /psicologos/{id}/formulario", formularioHandler.HandlerBuscarFormulario)
+		mux.Handle("GET /consultas/{id}/formulario", authMiddleware.Verify(http.HandlerFunc(formularioHandler.HandlerBuscarRespostaFormulario)))
+
+		somentePsicologo := middleware.ExigirPapel(reqctx.PapelPsicologo)
+		mux.HandleFunc("GET /instrumentos", instrumentoHandler.HandlerListarInstrumentos)
+		mux.Handle("POST /instrumentos/aplicacoes", authMiddleware.Verify(somentePsicologo(http.HandlerFunc(instrumentoHandler.HandlerAtribuirInstrumento))))
+		mux.Handle("POST /instrumentos/aplicacoes/{id}/respostas", authMiddleware.Verify(http.HandlerFunc(instrumentoHandler.HandlerResponderInstrumento)))
+		mux.Handle("GET /alunos/{id}/instrumentos/serie", authMiddleware.Verify(http.HandlerFunc(instrumentoHandler.HandlerSerieInstrumento)))
+
+		mux.Handle("POST /plantao/turnos", authMiddleware.Verify(somenteAdmin(http.HandlerFunc(plantaoHandler.HandlerCriarTurno))))
+		mux.Handle("GET /plantao/turnos", authMiddleware.Verify(http.HandlerFunc(plantaoHandler.HandlerListarTurnos)))
+		mux.Handle("DELETE /plantao/turnos/{id}", authMiddleware.Verify(somenteAdmin(http.HandlerFunc(plantaoHandler.HandlerDeletarTurno))))
+		mux.Handle("GET /plantao/agora", authMiddleware.Verify(http.HandlerFunc(plantaoHandler.HandlerPlantaoAgora)))
+		mux.Handle("GET /plantao/alertas", authMiddleware.Verify(somentePsicologo(http.HandlerFunc(plantaoHandler.HandlerListarAlertas))))
+		mux.Handle("POST /plantao/alertas/{id}/reconhecer", authMiddleware.Verify(somentePsicologo(http.HandlerFunc(plantaoHandler.HandlerReconhecerAlerta))))
+
+		mux.Handle("GET /consultas/{id}/checkin/codigo", authMiddleware.Verify(http.HandlerFunc(presencaHandler.HandlerCodigoCheckin)))
+		mux.Handle("POST /consultas/{id}/checkin", authMiddleware.Verify(http.HandlerFunc(presencaHandler.HandlerCheckin)))
+		mux.Handle("POST /consultas/{id}/concluir", authMiddleware.Verify(http.HandlerFunc(presencaHandler.HandlerConcluirConsulta)))
+		mux.Handle("GET /alunos/{id}/presenca", authMiddleware.Verify(http.HandlerFunc(presencaHandler.HandlerPresencaAluno)))
+		mux.Handle("GET /psicologos/{id}/presenca", authMiddleware.Verify(http.HandlerFunc(presencaHandler.HandlerPresencaPsicologo)))
+
+		mux.Handle("POST /salas", authMiddleware.Verify(somenteAdmin(http.HandlerFunc(salaHandler.HandlerCriarSala))))
+		mux.Handle("GET /salas", authMiddleware.Verify(http.HandlerFunc(salaHandler.HandlerListarSalas)))
+		mux.Handle("GET /salas/ocupacao", authMiddleware.Verify(http.HandlerFunc(salaHandler.HandlerOcupacaoSalas)))
+		mux.Handle("PUT /salas/{id}", authMiddleware.Verify(somenteAdmin(http.HandlerFunc(salaHandler.HandlerAtualizarSala))))
+		mux.Handle("DELETE /salas/{id}", authMiddleware.Verify(somenteAdmin(http.HandlerFunc(salaHandler.HandlerDeletarSala))))
+
+		mux.Handle("POST /calendario", authMiddleware.Verify(somenteAdmin(http.HandlerFunc(calendarioHandler.HandlerCriarEvento))))
+		mux.Handle("GET /calendario", authMiddleware.Verify(http.HandlerFunc(calendarioHandler.HandlerListarEventos)))
+		mux.Handle("DELETE /calendario/{id}", authMiddleware.Verify(somenteAdmin(http.HandlerFunc(calendarioHandler.HandlerDeletarEvento))))
+
+		mux.Handle("POST /episodios", authMiddleware.Verify(http.HandlerFunc(episodioHandler.HandlerAbrirEpisodio)))
+		mux.Handle("GET /episodios/{id}", authMiddleware.Verify(http.HandlerFunc(episodioHandler.HandlerBuscarEpisodio)))
+		mux.Handle("POST /episodios/{id}/encerrar", authMiddleware.Verify(http.HandlerFunc(episodioHandler.HandlerEncerrarEpisodio)))
+		mux.Handle("POST /episodios/{id}/transferir", authMiddleware.Verify(http.HandlerFunc(episodioHandler.HandlerTransferirEpisodio)))
+		mux.Handle("GET /alunos/{id}/episodios", authMiddleware.Verify(http.HandlerFunc(episodioHandler.HandlerListarEpisodiosAluno)))
+		mux.Handle("POST /encaminhamentos", authMiddleware.Verify(http.HandlerFunc(encaminhamentoHandler.HandlerCriarEncaminhamento)))
+		mux.Handle("GET /encaminhamentos/{id}", authMiddleware.Verify(http.HandlerFunc(encaminhamentoHandler.HandlerBuscarEncaminhamento)))
+		mux.Handle("POST /encaminhamentos/{id}/aceitar", authMiddleware.Verify(http.HandlerFunc(encaminhamentoHandler.HandlerAceitarEncaminhamento)))
+		mux.Handle("POST /encaminhamentos/{id}/recusar", authMiddleware.Verify(http.HandlerFunc(encaminhamentoHandler.HandlerRecusarEncaminhamento)))
+		mux.Handle("POST /encaminhamentos/{id}/concluir", authMiddleware.Verify(http.HandlerFunc(encaminhamentoHandler.HandlerConcluirEncaminhamento)))
+		mux.Handle("POST /encaminhamentos/{id}/consentimento", authMiddleware.Verify(http.HandlerFunc(encaminhamentoHandler.HandlerConsentimentoEncaminhamento)))
+		mux.Handle("GET /encaminhamentos/{id}/dados-iniciais", authMiddleware.Verify(somentePsicologo(http.HandlerFunc(encaminhamentoHandler.HandlerDadosIniciaisEncaminhamento))))
+		mux.Handle("GET /psicologos/{id}/encaminhamentos", authMiddleware.Verify(http.HandlerFunc(encaminhamentoHandler.HandlerListarEncaminhamentosPsicologo)))
+		mux.Handle("GET /alunos/{id}/encaminhamentos", authMiddleware.Verify(http.HandlerFunc(encaminhamentoHandler.HandlerListarEncaminhamentosAluno)))
 	} else {
 		// Modo Dev (sem Auth)
 		mux.HandleFunc("POST /alunos", alunoHandler.HandlerCriarAluno)
@@ -114,27 +317,98 @@ func main() {
 		mux.HandleFunc("GET /psicologos", psicologoHandler.HandlerListarPsicologos)
 		mux.HandleFunc("GET /psicologos/{id}", psicologoHandler.HandlerBuscarPsicologoPorID)
 		mux.HandleFunc("GET /psicologos/nome", psicologoHandler.HandlerBuscarPsicologoPorNome)
+		mux.HandleFunc("GET /psicologos/recomendacoes", psicologoHandler.HandlerRecomendarPsicologos)
 		mux.HandleFunc("PUT /psicologos/{id}", psicologoHandler.HandlerAtualizarPsicologo)
 		mux.HandleFunc("DELETE /psicologos/{id}", psicologoHandler.HandlerDeletarPsicologo)
 
 		mux.HandleFunc("POST /consultas", consultaHandler.HandlerAgendarConsulta)
+		mux.HandleFunc("GET /triagem/perguntas", consultaHandler.HandlerPerguntasTriagem)
 		mux.HandleFunc("GET /consultas/psicologo", consultaHandler.HandlerListarConsultasPorPsicologo)
 		mux.HandleFunc("PATCH /consultas/{id}/status", consultaHandler.HandlerAtualizarStatusConsulta)
 		mux.HandleFunc("DELETE /consultas/{id}", consultaHandler.HandlerDeletarConsulta)
+		mux.HandleFunc("GET /consultas/{id}/convite.ics", consultaHandler.HandlerConviteConsulta)
+		mux.HandleFunc("PUT /consultas/{id}/nota", notaHandler.HandlerSalvarNota)
+		mux.HandleFunc("GET /consultas/{id}/nota", notaHandler.HandlerBuscarNota)
+		mux.HandleFunc("GET /consultas/{id}/nota/versoes", notaHandler.HandlerListarVersoesNota)
 
 		mux.HandleFunc("POST /horarios", horarioHandler.HandlerCriarHorario)
+		mux.HandleFunc("POST /horarios/grade", horarioHandler.HandlerGerarHorarios)
 		mux.HandleFunc("GET /horarios", horarioHandler.HandlerListarHorarios)
 		mux.HandleFunc("DELETE /horarios/{id}", horarioHandler.HandlerDeletarHorario)
+
+		mux.HandleFunc("GET /auditoria", auditoriaHandler.HandlerListarAuditoria)
+		mux.HandleFunc("GET /relatorios/atendimento", relatorioHandler.HandlerRelatorioAtendimento)
+		mux.HandleFunc("POST /importacoes/{tipo}", importacaoHandler.HandlerImportar)
+		mux.HandleFunc("GET /alunos/{id}/dados", privacidadeHandler.HandlerExportarDadosAluno)
+		mux.HandleFunc("POST /alunos/{id}/anonimizar", privacidadeHandler.HandlerAnonimizarAluno)
+
+		mux.HandleFunc("POST /consentimentos", consentimentoHandler.HandlerPublicarConsentimento)
+		mux.HandleFunc("GET /consentimentos/vigente", consentimentoHandler.HandlerBuscarConsentimentoVigente)
+		mux.HandleFunc("POST /alunos/{id}/consentimentos", consentimentoHandler.HandlerAceitarConsentimento)
+		mux.HandleFunc("GET /alunos/{id}/consentimentos", consentimentoHandler.HandlerListarConsentimentosAluno)
+
+		mux.HandleFunc("PUT /psicologos/{id}/formulario", formularioHandler.HandlerSalvarFormulario)
+		mux.HandleFunc("GET /psicologos/{id}/formulario", formularioHandler.HandlerBuscarFormulario)
+		mux.HandleFunc("GET /consultas/{id}/formulario", formularioHandler.HandlerBuscarRespostaFormulario)
+
+		mux.HandleFunc("GET /instrumentos", instrumentoHandler.HandlerListarInstrumentos)
+		mux.HandleFunc("POST /instrumentos/aplicacoes", instrumentoHandler.HandlerAtribuirInstrumento)
+		mux.HandleFunc("POST /instrumentos/aplicacoes/{id}/respostas", instrumentoHandler.HandlerResponderInstrumento)
+		mux.HandleFunc("GET /alunos/{id}/instrumentos/serie", instrumentoHandler.HandlerSerieInstrumento)
+
+		mux.HandleFunc("POST /plantao/turnos", plantaoHandler.HandlerCriarTurno)
+		mux.HandleFunc("GET /plantao/turnos", plantaoHandler.HandlerListarTurnos)
+		mux.HandleFunc("DELETE /plantao/turnos/{id}", plantaoHandler.HandlerDeletarTurno)
+		mux.HandleFunc("GET /plantao/agora", plantaoHandler.HandlerPlantaoAgora)
+		mux.HandleFunc("GET /plantao/alertas", plantaoHandler.HandlerListarAlertas)
+		mux.HandleFunc("POST /plantao/alertas/{id}/reconhecer", plantaoHandler.HandlerReconhecerAlerta)
+
+		mux.HandleFunc("GET /consultas/{id}/checkin/codigo", presencaHandler.HandlerCodigoCheckin)
+		mux.HandleFunc("POST /consultas/{id}/checkin", presencaHandler.HandlerCheckin)
+		mux.HandleFunc("POST /consultas/{id}/concluir", presencaHandler.HandlerConcluirConsulta)
+		mux.HandleFunc("GET /alunos/{id}/presenca", presencaHandler.HandlerPresencaAluno)
+		mux.HandleFunc("GET /psicologos/{id}/presenca", presencaHandler.HandlerPresencaPsicologo)
+
+		mux.HandleFunc("POST /salas", salaHandler.HandlerCriarSala)
+		mux.HandleFunc("GET /salas", salaHandler.HandlerListarSalas)
+		mux.HandleFunc("GET /salas/ocupacao", salaHandler.HandlerOcupacaoSalas)
+		mux.HandleFunc("PUT /salas/{id}", salaHandler.HandlerAtualizarSala)
+		mux.HandleFunc("DELETE /salas/{id}", salaHandler.HandlerDeletarSala)
+
+		mux.HandleFunc("POST /calendario", calendarioHandler.HandlerCriarEvento)
+		mux.HandleFunc("GET /calendario", calendarioHandler.HandlerListarEventos)
+		mux.HandleFunc("DELETE /calendario/{id}", calendarioHandler.HandlerDeletarEvento)
+
+		mux.HandleFunc("POST /episodios", episodioHandler.HandlerAbrirEpisodio)
+		mux.HandleFunc("GET /episodios/{id}", episodioHandler.HandlerBuscarEpisodio)
+		mux.HandleFunc("POST /episodios/{id}/encerrar", episodioHandler.HandlerEncerrarEpisodio)
+		mux.HandleFunc("POST /episodios/{id}/transferir", episodioHandler.HandlerTransferirEpisodio)
+		mux.HandleFunc("GET /alunos/{id}/episodios", episodioHandler.HandlerListarEpisodiosAluno)
+		mux.HandleFunc("POST /encaminhamentos", encaminhamentoHandler.HandlerCriarEncaminhamento)
+		mux.HandleFunc("GET /encaminhamentos/{id}", encaminhamentoHandler.HandlerBuscarEncaminhamento)
+		mux.HandleFunc("POST /encaminhamentos/{id}/aceitar", encaminhamentoHandler.HandlerAceitarEncaminhamento)
+		mux.HandleFunc("POST /encaminhamentos/{id}/recusar", encaminhamentoHandler.HandlerRecusarEncaminhamento)
+		mux.HandleFunc("POST /encaminhamentos/{id}/concluir", encaminhamentoHandler.HandlerConcluirEncaminhamento)
+		mux.HandleFunc("POST /encaminhamentos/{id}/consentimento", encaminhamentoHandler.HandlerConsentimentoEncaminhamento)
+		mux.HandleFunc("GET /encaminhamentos/{id}/dados-iniciais", encaminhamentoHandler.HandlerDadosIniciaisEncaminhamento)
+		mux.HandleFunc("GET /psicologos/{id}/encaminhamentos", encaminhamentoHandler.HandlerListarEncaminhamentosPsicologo)
+		mux.HandleFunc("GET /alunos/{id}/encaminhamentos", encaminhamentoHandler.HandlerListarEncaminhamentosAluno)
 	}
 
 	c := cors.New(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:5173"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type", middleware.HeaderRequestID},
+		ExposedHeaders:   []string{middleware.HeaderRequestID},
 		AllowCredentials: true,
 	})
 
-	handler := c.Handler(mux)
+	var rotas http.Handler = mux
+	if !is_middleware_on {
+		rotas = middleware.IdentidadeDev(rotas)
+	}
+
+	handler := c.Handler(middleware.RequestID(rotas))
 
 	port := ":8080"
 	server := &http.Server{
@@ -145,6 +419,62 @@ func main() {
 		IdleTimeout:  120 * time.Second,
 	}
 
+	// Drenagem: por quanto tempo /saude responde 503 antes de o servidor
+	// parar de aceitar conexões.
+	drenagem := segundosEnv("ENCERRAMENTO_DRENAGEM_SEGUNDOS", 0)
+
+	sinais, pararSinais := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer pararSinais()
+
+	falhaServidor := make(chan error, 1)
+	go func() { falhaServidor <- server.ListenAndServe() }()
+	vida.registrar("servidor HTTP", segundosEnv("ENCERRAMENTO_HTTP_SEGUNDOS", 10*time.Second), server.Shutdown)
 	fmt.Printf("🐄 bovino na porta %s\n", port)
-	log.Fatal(server.ListenAndServe())
-}
\ No newline at end of file
+
+	codigoSaida := 0
+	select {
+	case <-sinais.Done():
+		log.Printf("sinal recebido, encerrando")
+	case err := <-falhaServidor:
+		log.Printf("ERRO no servidor HTTP: %v", err)
+		codigoSaida = 1
+	}
+	// A partir daqui um segundo sinal encerra o processo na hora.
+	pararSinais()
+
+	if drenagem > 0 && codigoSaida == 0 {
+		pronto.drenando.Store(true)
+		log.Printf("drenando por %s", drenagem)
+		time.Sleep(drenagem)
+	}
+
+	vida.encerrar()
+	log.Printf("encerrado")
+	os.Exit(codigoSaida)
+}
+
+// carregarPolitica parte da política padrão e aplica o que estiver no .env.
+// Um valor 0 desliga a regra.
+func carregarPolitica() service.PoliticaAgendamento {
+	p := service.PoliticaPadrao
+
+	inteiro := func(nome string, destino *int) {
+		if v, err := strconv.Atoi(os.Getenv(nome)); err == nil && v >= 0 {
+			*destino = v
+		}
+	}
+	duracao := func(nome string, unidade time.Duration, destino *time.Duration) {
+		if v, err := strconv.Atoi(os.Getenv(nome)); err == nil && v >= 0 {
+			*destino = time.Duration(v) * unidade
+		}
+	}
+
+	inteiro("POLITICA_MAX_PEDIDOS_ATIVOS", &p.MaxPedidosAtivos)
+	inteiro("POLITICA_MAX_SESSOES_SEMANA", &p.MaxSessoesSemana)
+	duracao("POLITICA_ANTECEDENCIA_HORAS", time.Hour, &p.AntecedenciaMinima)
+	duracao("POLITICA_CANCELAMENTO_HORAS", time.Hour, &p.PrazoCancelamento)
+	inteiro("POLITICA_FALTAS_SUSPENSAO", &p.FaltasParaSuspensao)
+	duracao("POLITICA_JANELA_FALTAS_DIAS", 24*time.Hour, &p.JanelaFaltas)
+	duracao("POLITICA_SUSPENSAO_DIAS", 24*time.Hour, &p.DuracaoSuspensao)
+	return p
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sgp/Internal/handler"
+	"sgp/Internal/repository"
+	"sgp/Internal/repository/memoria"
+	"sgp/Internal/service"
+
+	firebase "firebase.google.com/go/v4"
+	"firebase.google.com/go/v4/auth"
+	"google.golang.org/api/option"
+)
+
+const (
+	BackendMemoria  = "memoria"
+	BackendEmulador = "emulador"
+	BackendProducao = "producao"
+)
+
+// contas são as operações de usuário do Firebase Auth usadas pela CLI.
+type contas interface {
+	CriarConta(ctx context.Context, email, nome, senha string) (string, error)
+	DesativarConta(ctx context.Context, uid string) error
+	DefinirPapel(ctx context.Context, uid, papel string) error
+}
+
+type configuracao struct {
+	backend string
+	estado  string // arquivo do backend em memória
+	projeto string // projeto do emulador
+	creds   string // credenciais de produção
+}
+
+// ambiente reúne os repositórios do backend escolhido, já com auditoria,
+// como no Cmd/api.
+type ambiente struct {
+	backend      string
+	alunos       repository.AlunoRepository
+	psicologos   repository.PsicologoRepository
+	horarios     repository.HorarioDisponivelRepository
+	consultas    repository.ConsultaRepository
+	notificacoes repository.NotificacaoRepository
+	auditoria    repository.AuditoriaRepository
+	privacidade  *handler.PrivacidadeHandler
+	contas       contas
+	email        *service.EmailService // nil sem RESEND_API_KEY
+
+	// salvar grava o estado do backend em memória e só é chamado quando o
+	// comando termina sem erro; nos demais backends é nil.
+	salvar func() error
+	fechar func() error
+}
+
+func abrirAmbiente(ctx context.Context, cfg configuracao) (*ambiente, error) {
+	switch cfg.backend {
+	case BackendMemoria:
+		return abrirMemoria(cfg)
+	case BackendEmulador:
+		if cfg.projeto == "" {
+			return nil, fmt.Errorf("o backend emulador precisa de -projeto ou GOOGLE_CLOUD_PROJECT")
+		}
+		// Com estas variáveis os clientes do Firestore e do Auth falam com o emulador.
+		definirSeVazia("FIRESTORE_EMULATOR_HOST", "localhost:8080")
+		definirSeVazia("FIREBASE_AUTH_EMULATOR_HOST", "localhost:9099")
+		return abrirFirebase(ctx, &firebase.Config{ProjectID: cfg.projeto}, cfg.backend)
+	case BackendProducao:
+		if cfg.creds == "" {
+			return nil, fmt.Errorf("o backend producao precisa de -creds ou CREDS")
+		}
+		return abrirFirebase(ctx, nil, cfg.backend, option.WithCredentialsFile(cfg.creds))
+	}
+	return nil, fmt.Errorf("backend desconhecido '%s': use %s, %s ou %s", cfg.backend, BackendMemoria, BackendEmulador, BackendProducao)
+}
+
+func abrirMemoria(cfg configuracao) (*ambiente, error) {
+	banco, err := memoria.CarregarBanco(cfg.estado)
+	if err != nil {
+		return nil, err
+	}
+
+	auditoria := memoria.NewAuditoriaRepository(banco)
+	consultas := memoria.NewConsultaRepository(banco)
+	consultasAuditado := repository.NewConsultaRepositoryAuditado(consultas, memoria.NewHorarioDisponivelRepository(banco), auditoria)
+	consultas.AoPromover = consultasAuditado.AuditarPromocao(nil)
+	amb := &ambiente{
+		backend:      cfg.backend,
+		alunos:       repository.NewAlunoRepositoryAuditado(memoria.NewAlunoRepository(banco), auditoria),
+		psicologos:   repository.NewPsicologoRepositoryAuditado(memoria.NewPsicologoRepository(banco), auditoria),
+		horarios:     repository.NewHorarioDisponivelRepositoryAuditado(memoria.NewHorarioDisponivelRepository(banco), auditoria),
+		consultas:    consultasAuditado,
+		notificacoes: memoria.NewNotificacaoRepository(banco),
+		auditoria:    auditoria,
+		contas:       memoria.NewContas(banco),
+		salvar:       func() error { return banco.Salvar(cfg.estado) },
+		fechar:       func() error { return nil },
+	}
+	amb.completar()
+	return amb, nil
+}
+
+func abrirFirebase(ctx context.Context, config *firebase.Config, backend string, opts ...option.ClientOption) (*ambiente, error) {
+	app, err := firebase.NewApp(ctx, config, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao inicializar firebase: %w", err)
+	}
+	authClient, err := app.Auth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao inicializar cliente de autenticação: %w", err)
+	}
+	client, err := app.Firestore(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar ao firestore: %w", err)
+	}
+
+	auditoria := repository.NewAuditoriaRepository(client)
+	consultas := repository.NewConsultaRepository(client)
+	consultasAuditado := repository.NewConsultaRepositoryAuditado(consultas, repository.NewHorarioDisponivelRepository(client), auditoria)
+	consultas.AoPromover = consultasAuditado.AuditarPromocao(nil)
+	amb := &ambiente{
+		backend:      backend,
+		alunos:       repository.NewAlunoRepositoryAuditado(repository.NewAlunoRepository(client), auditoria),
+		psicologos:   repository.NewPsicologoRepositoryAuditado(repository.NewPsicologoRepository(client), auditoria),
+		horarios:     repository.NewHorarioDisponivelRepositoryAuditado(repository.NewHorarioDisponivelRepository(client), auditoria),
+		consultas:    consultasAuditado,
+		notificacoes: repository.NewNotificacaoRepository(client),
+		auditoria:    auditoria,
+		contas:       contasFirebase{client: authClient},
+		fechar:       client.Close,
+	}
+	amb.completar()
+	amb.privacidade.ConsentimentoRepo = repository.NewConsentimentoRepository(client)
+	amb.privacidade.FormularioRepo = repository.NewFormularioRepository(client)
+	amb.privacidade.InstrumentoRepo = repository.NewInstrumentoRepository(client)
+	amb.privacidade.EpisodioRepo = repository.NewEpisodioRepository(client)
+	return amb, nil
+}
+
+// completar monta o que é igual nos backends.
+func (a *ambiente) completar() {
+	a.privacidade = handler.NewPrivacidadeHandler(a.alunos, a.consultas, a.notificacoes, a.auditoria)
+	if chave := os.Getenv("RESEND_API_KEY"); chave != "" {
+		a.email = service.NewEmailService(chave)
+		a.email.Registro = a.notificacoes
+	}
+}
+
+func definirSeVazia(variavel, valor string) {
+	if os.Getenv(variavel) == "" {
+		os.Setenv(variavel, valor)
+	}
+}
+
+type contasFirebase struct {
+	client *auth.Client
+}
+
+func (c contasFirebase) CriarConta(ctx context.Context, email, nome, senha string) (string, error) {
+	params := (&auth.UserToCreate{}).Email(email).DisplayName(nome)
+	if senha != "" {
+		params = params.Password(senha)
+	}
+	usuario, err := c.client.CreateUser(ctx, params)
+	if err != nil {
+		return "", fmt.Errorf("erro ao criar conta de %s: %w", email, err)
+	}
+	return usuario.UID, nil
+}
+
+// DesativarConta também revoga as sessões abertas, para que o token atual
+// deixe de valer na próxima renovação.
+func (c contasFirebase) DesativarConta(ctx context.Context, uid string) error {
+	if _, err := c.client.UpdateUser(ctx, uid, (&auth.UserToUpdate{}).Disabled(true)); err != nil {
+		return fmt.Errorf("erro ao desativar conta %s: %w", uid, err)
+	}
+	return c.client.RevokeRefreshTokens(ctx, uid)
+}
+
+func (c contasFirebase) DefinirPapel(ctx context.Context, uid, papel string) error {
+	if err := c.client.SetCustomUserClaims(ctx, uid, map[string]interface{}{"role": papel}); err != nil {
+		return fmt.Errorf("erro ao definir papel de %s: %w", uid, err)
+	}
+	return nil
+}
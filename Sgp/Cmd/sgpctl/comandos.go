@@ -0,0 +1,408 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/reqctx"
+	"sgp/Internal/service"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// papeis traduz o tipo usado nas flags para o valor da custom claim "role".
+var papeis = map[string]string{
+	"aluno":     reqctx.PapelAluno,
+	"psicologo": reqctx.PapelPsicologo,
+	"admin":     reqctx.PapelAdmin,
+}
+
+func novasFlags(nome string) *flag.FlagSet {
+	flags := flag.NewFlagSet("sgpctl "+nome, flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	return flags
+}
+
+func usuariosCriar(ctx context.Context, amb *ambiente, args []string) error {
+	flags := novasFlags("usuarios criar")
+	tipo := flags.String("tipo", "", "aluno, psicologo ou admin")
+	nome := flags.String("nome", "", "nome completo")
+	email := flags.String("email", "", "e-mail de acesso")
+	crp := flags.String("crp", "", "registro no conselho (psicologo)")
+	senha := flags.String("senha", "", "senha inicial; vazia exige redefinição pelo e-mail")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	papel, ok := papeis[*tipo]
+	if !ok {
+		return fmt.Errorf("-tipo deve ser aluno, psicologo ou admin")
+	}
+	if *nome == "" || *email == "" {
+		return fmt.Errorf("-nome e -email são obrigatórios")
+	}
+	if *tipo == "psicologo" && *crp == "" {
+		return fmt.Errorf("-crp é obrigatório para psicólogos")
+	}
+
+	uid, err := criarUsuario(ctx, amb, *tipo, papel, *nome, *email, *crp, *senha)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s criado: %s\n", *tipo, uid)
+	return nil
+}
+
+// criarUsuario cria a conta no Auth e o perfil com o mesmo ID, já que o
+// middleware usa o UID do token como ID do aluno ou do psicólogo.
+func criarUsuario(ctx context.Context, amb *ambiente, tipo, papel, nome, email, crp, senha string) (string, error) {
+	uid, err := amb.contas.CriarConta(ctx, email, nome, senha)
+	if err != nil {
+		return "", err
+	}
+	if err := amb.contas.DefinirPapel(ctx, uid, papel); err != nil {
+		return "", err
+	}
+
+	switch tipo {
+	case "aluno":
+		err = amb.alunos.AtualizarAluno(ctx, uid, model.Aluno{Nome: nome, Email: email})
+	case "psicologo":
+		err = amb.psicologos.AtualizarPsicologo(ctx, uid, model.Psicologo{Nome: nome, Email: email, CRP: crp})
+	}
+	if err != nil {
+		return "", fmt.Errorf("conta %s criada, mas o perfil falhou: %w", uid, err)
+	}
+	return uid, nil
+}
+
+func usuariosListar(ctx context.Context, amb *ambiente, args []string) error {
+	flags := novasFlags("usuarios listar")
+	tipo := flags.String("tipo", "", "aluno ou psicologo; vazio lista os dois")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *tipo != "" && *tipo != "aluno" && *tipo != "psicologo" {
+		return fmt.Errorf("-tipo deve ser aluno ou psicologo")
+	}
+
+	tabela := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tabela, "TIPO\tID\tNOME\tEMAIL\tSITUACAO")
+	if *tipo == "" || *tipo == "aluno" {
+		alunos, err := amb.alunos.ListarAlunos(ctx)
+		if err != nil {
+			return err
+		}
+		for _, a := range alunos {
+			situacao := "ativo"
+			if a.Anonimizado {
+				situacao = "anonimizado"
+			}
+			fmt.Fprintf(tabela, "aluno\t%s\t%s\t%s\t%s\n", a.ID, a.Nome, a.Email, situacao)
+		}
+	}
+	if *tipo == "" || *tipo == "psicologo" {
+		psicologos, err := amb.psicologos.ListarPsicologos(ctx)
+		if err != nil {
+			return err
+		}
+		for _, p := range psicologos {
+			situacao := "ativo"
+			if p.Ativo != nil && !*p.Ativo {
+				situacao = "inativo"
+			}
+			fmt.Fprintf(tabela, "psicologo\t%s\t%s\t%s\t%s\n", p.ID, p.Nome, p.Email, situacao)
+		}
+	}
+	return tabela.Flush()
+}
+
+func usuariosDesativar(ctx context.Context, amb *ambiente, args []string) error {
+	flags := novasFlags("usuarios desativar")
+	id := flags.String("id", "", "UID da conta")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *id == "" {
+		return fmt.Errorf("-id é obrigatório")
+	}
+
+	if err := amb.contas.DesativarConta(ctx, *id); err != nil {
+		return err
+	}
+
+	// Psicólogo desativado sai da busca e deixa de receber agendamentos.
+	psicologo, err := amb.psicologos.BuscarPsicologoPorID(ctx, *id)
+	if err != nil && status.Code(err) != codes.NotFound {
+		return err
+	}
+	if err == nil {
+		inativo := false
+		psicologo.Ativo = &inativo
+		if err := amb.psicologos.AtualizarPsicologo(ctx, *id, *psicologo); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("conta %s desativada\n", *id)
+	return nil
+}
+
+func usuariosPapel(ctx context.Context, amb *ambiente, args []string) error {
+	flags := novasFlags("usuarios papel")
+	id := flags.String("id", "", "UID da conta")
+	papel := flags.String("papel", "", "aluno, psicologo ou admin")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	claim, ok := papeis[*papel]
+	if *id == "" || !ok {
+		return fmt.Errorf("-id e -papel (aluno, psicologo ou admin) são obrigatórios")
+	}
+
+	if err := amb.contas.DefinirPapel(ctx, *id, claim); err != nil {
+		return err
+	}
+	fmt.Printf("papel de %s definido como %s\n", *id, claim)
+	return nil
+}
+
+// seed cria um admin, um psicólogo com uma semana de horários e um aluno com
+// uma consulta pendente. Rodar de novo não duplica nada: os usuários são
+// reconhecidos pelo e-mail e os horários só são criados se não houver nenhum.
+func seed(ctx context.Context, amb *ambiente, args []string) error {
+	flags := novasFlags("seed")
+	senha := flags.String("senha", "sgp-demo-123", "senha das contas de demonstração")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if amb.backend == BackendProducao {
+		return fmt.Errorf("seed não pode ser usado em produção")
+	}
+
+	alunos, err := amb.alunos.ListarAlunos(ctx)
+	if err != nil {
+		return err
+	}
+	psicologos, err := amb.psicologos.ListarPsicologos(ctx)
+	if err != nil {
+		return err
+	}
+
+	psicologoID := ""
+	for _, p := range psicologos {
+		if p.Email == "psicologa@demo.sgp" {
+			psicologoID = p.ID
+		}
+	}
+	if psicologoID == "" {
+		// O admin não tem perfil; criá-lo só na primeira execução evita
+		// depender do erro de e-mail repetido do Auth.
+		if _, err := criarUsuario(ctx, amb, "admin", reqctx.PapelAdmin, "Admin Demo", "admin@demo.sgp", "", *senha); err != nil {
+			return err
+		}
+		if psicologoID, err = criarUsuario(ctx, amb, "psicologo", reqctx.PapelPsicologo, "Psicóloga Demo", "psicologa@demo.sgp", "06/000000", *senha); err != nil {
+			return err
+		}
+	}
+
+	alunoID := ""
+	for _, a := range alunos {
+		if a.Email == "aluno@demo.sgp" {
+			alunoID = a.ID
+		}
+	}
+	if alunoID == "" {
+		if alunoID, err = criarUsuario(ctx, amb, "aluno", reqctx.PapelAluno, "Aluno Demo", "aluno@demo.sgp", "", *senha); err != nil {
+			return err
+		}
+	}
+
+	horarios, err := amb.horarios.ListarHorariosPorPsicologo(ctx, psicologoID, "")
+	if err != nil {
+		return err
+	}
+	if len(horarios) == 0 {
+		dia := time.Now().Truncate(24*time.Hour).AddDate(0, 0, 1)
+		var primeiro *model.HorarioDisponivel
+		for criados := 0; criados < 5; dia = dia.AddDate(0, 0, 1) {
+			if dia.Weekday() == time.Saturday || dia.Weekday() == time.Sunday {
+				continue
+			}
+			inicio := dia.Add(13 * time.Hour) // 10h em Brasília
+			horario, err := amb.horarios.CriarHorario(ctx, model.HorarioDisponivel{
+				PsicologoID: psicologoID,
+				Inicio:      inicio,
+				Fim:         inicio.Add(50 * time.Minute),
+				Status:      "disponivel",
+			})
+			if err != nil {
+				return err
+			}
+			if primeiro == nil {
+				primeiro = horario
+			}
+			criados++
+		}
+		if _, err := amb.consultas.AgendarConsulta(ctx, model.Consulta{AlunoID: alunoID, HorarioID: primeiro.ID}); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("dados de demonstração prontos (psicólogo %s, aluno %s, senha %q)\n", psicologoID, alunoID, *senha)
+	return nil
+}
+
+func notificacoesReenviar(ctx context.Context, amb *ambiente, args []string) error {
+	flags := novasFlags("notificacoes reenviar")
+	limite := flags.Int("limite", 50, "máximo de notificações reenviadas")
+	aplicar := flags.Bool("aplicar", false, "reenvia as notificações encontradas")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *aplicar && amb.email == nil {
+		return fmt.Errorf("RESEND_API_KEY não configurada")
+	}
+
+	falhas, err := amb.notificacoes.ListarNotificacoesPorStatus(ctx, "falhou")
+	if err != nil {
+		return err
+	}
+	if len(falhas) > *limite {
+		falhas = falhas[:*limite]
+	}
+
+	enviadas := 0
+	for _, n := range falhas {
+		if !*aplicar {
+			fmt.Printf("notificação %s para %s (%d tentativa(s)): %s\n", n.ID, n.Destinatario, n.Tentativas, n.Erro)
+			continue
+		}
+		reenviada, err := amb.email.Reenviar(ctx, *n)
+		if err != nil {
+			fmt.Printf("notificação %s: falhou de novo: %v\n", n.ID, err)
+			continue
+		}
+		enviadas++
+		fmt.Printf("notificação %s: %s\n", n.ID, reenviada.Status)
+	}
+
+	if *aplicar {
+		fmt.Printf("%d de %d notificação(ões) reenviada(s)\n", enviadas, len(falhas))
+	} else {
+		fmt.Printf("%d notificação(ões) com falha; use -aplicar para reenviar\n", len(falhas))
+	}
+	return nil
+}
+
+func exportar(ctx context.Context, amb *ambiente, args []string) error {
+	flags := novasFlags("exportar")
+	alunoID := flags.String("aluno", "", "ID do aluno")
+	saida := flags.String("saida", "", "arquivo JSON de saída; vazio escreve na saída padrão")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *alunoID == "" {
+		return fmt.Errorf("-aluno é obrigatório")
+	}
+
+	exportacao, err := amb.privacidade.ExportarDadosAluno(ctx, *alunoID)
+	if err != nil {
+		return err
+	}
+	repository.RegistrarAuditoria(ctx, amb.auditoria, repository.AcaoExportar, "aluno", *alunoID, nil, nil)
+
+	dados, err := json.MarshalIndent(exportacao, "", "  ")
+	if err != nil {
+		return err
+	}
+	if *saida == "" {
+		_, err = fmt.Println(string(dados))
+		return err
+	}
+	if err := os.WriteFile(*saida, dados, 0o600); err != nil {
+		return err
+	}
+	fmt.Printf("dados do aluno %s exportados para %s\n", *alunoID, *saida)
+	return nil
+}
+
+// reconciliar roda o service.Reconciliador, o mesmo da execução periódica
+// da API; "horarios liberar" e "consultas reconciliar" são atalhos para
+// alguns tipos de inconsistência. Sem -aplicar só lista.
+func reconciliar(ctx context.Context, amb *ambiente, args []string) error {
+	return executarReconciliacao(ctx, amb, "reconciliar", nil, args)
+}
+
+func horariosLiberar(ctx context.Context, amb *ambiente, args []string) error {
+	return executarReconciliacao(ctx, amb, "horarios liberar", []string{model.InconsistenciaHorarioAgendadoLivre}, args)
+}
+
+func consultasReconciliar(ctx context.Context, amb *ambiente, args []string) error {
+	tipos := []string{
+		model.InconsistenciaConsultaSemHorario,
+		model.InconsistenciaConsultaSemAluno,
+		model.InconsistenciaConsultaSemPsicologo,
+	}
+	return executarReconciliacao(ctx, amb, "consultas reconciliar", tipos, args)
+}
+
+func executarReconciliacao(ctx context.Context, amb *ambiente, nome string, tipos []string, args []string) error {
+	flags := novasFlags(nome)
+	aplicar := flags.Bool("aplicar", false, "corrige as inconsistências encontradas")
+	comoJSON := flags.Bool("json", false, "escreve o relatório em JSON")
+	filtro := ""
+	if tipos == nil {
+		flags.StringVar(&filtro, "tipos", "", "tipos de inconsistência separados por vírgula; vazio trata todos")
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	reconciliador := service.NewReconciliador(amb.alunos, amb.psicologos, amb.horarios, amb.consultas)
+	reconciliador.Tipos = tipos
+	if filtro != "" {
+		reconciliador.Tipos = strings.Split(filtro, ",")
+	}
+	relatorio, err := reconciliador.Reconciliar(ctx, time.Now(), *aplicar)
+	if err != nil {
+		return err
+	}
+
+	if *comoJSON {
+		dados, err := json.MarshalIndent(relatorio, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(dados))
+	} else {
+		for _, inc := range relatorio.Inconsistencias {
+			situacao := ""
+			if inc.Corrigida {
+				situacao = " (feito)"
+			} else if inc.Descartada {
+				situacao = " (mudou antes da correção; nada feito)"
+			} else if inc.Erro != "" {
+				situacao = " (falhou: " + inc.Erro + ")"
+			}
+			fmt.Printf("%s %s [%s] %s: %s%s\n", inc.Entidade, inc.EntidadeID, inc.Tipo, inc.Detalhe, inc.Correcao, situacao)
+		}
+		if *aplicar {
+			fmt.Printf("%d inconsistência(s), %d corrigida(s), %d descartada(s)\n", relatorio.Total, relatorio.Corrigidas, relatorio.Descartadas)
+		} else {
+			fmt.Printf("%d inconsistência(s) encontrada(s); use -aplicar para corrigir\n", relatorio.Total)
+		}
+	}
+
+	if relatorio.Falhas > 0 {
+		return fmt.Errorf("%d correção(ões) falharam", relatorio.Falhas)
+	}
+	return nil
+}
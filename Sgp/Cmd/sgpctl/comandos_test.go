@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"sgp/Internal/model"
+	"sgp/Internal/repository/memoria"
+	"sgp/Internal/reqctx"
+	"testing"
+	"time"
+)
+
+func novoAmbienteMemoria(t *testing.T) (context.Context, *ambiente) {
+	t.Helper()
+	ctx := reqctx.ComAtor(context.Background(), AtorCLI)
+	amb, err := abrirAmbiente(ctx, configuracao{backend: BackendMemoria, estado: filepath.Join(t.TempDir(), "estado.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx, amb
+}
+
+func TestEncontrarComando(t *testing.T) {
+	casos := []struct {
+		args  []string
+		nome  string
+		resto int
+		ok    bool
+	}{
+		{[]string{"usuarios", "criar", "-tipo", "aluno"}, "usuarios criar", 2, true},
+		{[]string{"seed"}, "seed", 0, true},
+		{[]string{"reconciliar", "-aplicar"}, "reconciliar", 1, true},
+		{[]string{"usuarios"}, "", 0, false},
+		{[]string{"apagar", "tudo"}, "", 0, false},
+		{nil, "", 0, false},
+	}
+	for _, tc := range casos {
+		nome, _, args, ok := encontrarComando(tc.args)
+		if nome != tc.nome || len(args) != tc.resto || ok != tc.ok {
+			t.Errorf("encontrarComando(%q) = %q, %q, %v", tc.args, nome, args, ok)
+		}
+	}
+}
+
+func TestSeedNaoDuplica(t *testing.T) {
+	ctx, amb := novoAmbienteMemoria(t)
+
+	for i := 0; i < 2; i++ {
+		if err := seed(ctx, amb, nil); err != nil {
+			t.Fatalf("execução %d: %v", i+1, err)
+		}
+	}
+
+	alunos, _ := amb.alunos.ListarAlunos(ctx)
+	psicologos, _ := amb.psicologos.ListarPsicologos(ctx)
+	if len(alunos) != 1 || len(psicologos) != 1 {
+		t.Fatalf("seed duplicou usuários: %d aluno(s), %d psicólogo(s)", len(alunos), len(psicologos))
+	}
+	horarios, _ := amb.horarios.ListarHorariosPorPsicologo(ctx, psicologos[0].ID, "")
+	consultas, _ := amb.consultas.ListarConsultasPorAluno(ctx, alunos[0].ID)
+	if len(horarios) != 5 || len(consultas) != 1 {
+		t.Errorf("seed deveria criar 5 horários e 1 consulta, criou %d e %d", len(horarios), len(consultas))
+	}
+
+	if err := seed(ctx, &ambiente{backend: BackendProducao}, nil); err == nil {
+		t.Error("seed não deveria rodar em produção")
+	}
+}
+
+func TestUsuariosCriarEDesativar(t *testing.T) {
+	ctx, amb := novoAmbienteMemoria(t)
+
+	if err := usuariosCriar(ctx, amb, []string{"-tipo", "psicologo", "-nome", "Helena", "-email", "helena@uni.br"}); err == nil {
+		t.Error("psicólogo sem -crp deveria falhar")
+	}
+	if err := usuariosCriar(ctx, amb, []string{"-tipo", "psicologo", "-nome", "Helena", "-email", "helena@uni.br", "-crp", "06/1234"}); err != nil {
+		t.Fatal(err)
+	}
+	psicologos, _ := amb.psicologos.ListarPsicologos(ctx)
+	if len(psicologos) != 1 {
+		t.Fatalf("perfil não criado: %+v", psicologos)
+	}
+	uid := psicologos[0].ID
+	banco := amb.contas.(*memoria.Contas).Banco
+	if conta := banco.Contas[uid]; conta == nil || conta.Papel != reqctx.PapelPsicologo {
+		t.Fatalf("a conta deveria ter o mesmo ID do perfil e o papel de psicólogo: %+v", conta)
+	}
+
+	if err := usuariosDesativar(ctx, amb, []string{"-id", uid}); err != nil {
+		t.Fatal(err)
+	}
+	psicologo, _ := amb.psicologos.BuscarPsicologoPorID(ctx, uid)
+	if !banco.Contas[uid].Desativada || psicologo.Ativo == nil || *psicologo.Ativo {
+		t.Errorf("conta e perfil deveriam ficar inativos: %+v, %+v", banco.Contas[uid], psicologo)
+	}
+}
+
+func TestHorariosLiberarSoComAplicar(t *testing.T) {
+	ctx, amb := novoAmbienteMemoria(t)
+	psicologo, err := amb.psicologos.CriarPsicologo(ctx, model.Psicologo{Nome: "Helena", CRP: "06/1234"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inicio := time.Now().Add(48 * time.Hour)
+	h, err := amb.horarios.CriarHorario(ctx, model.HorarioDisponivel{PsicologoID: psicologo.ID, Inicio: inicio, Fim: inicio.Add(time.Hour), Status: "agendado"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := horariosLiberar(ctx, amb, nil); err != nil {
+		t.Fatal(err)
+	}
+	if atual, _ := amb.horarios.BuscarHorarioPorID(ctx, h.ID); atual.Status != "agendado" {
+		t.Fatalf("sem -aplicar nada deveria mudar: %s", atual.Status)
+	}
+
+	if err := horariosLiberar(ctx, amb, []string{"-aplicar"}); err != nil {
+		t.Fatal(err)
+	}
+	if atual, _ := amb.horarios.BuscarHorarioPorID(ctx, h.ID); atual.Status != "disponivel" {
+		t.Errorf("horário agendado sem consulta deveria ser liberado: %s", atual.Status)
+	}
+}
+
+func TestMemoriaSalvaEntreExecucoes(t *testing.T) {
+	ctx := reqctx.ComAtor(context.Background(), AtorCLI)
+	cfg := configuracao{backend: BackendMemoria, estado: filepath.Join(t.TempDir(), "estado.json")}
+
+	amb, err := abrirAmbiente(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := usuariosCriar(ctx, amb, []string{"-tipo", "aluno", "-nome", "Ana", "-email", "ana@uni.br"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := amb.salvar(); err != nil {
+		t.Fatal(err)
+	}
+
+	reaberto, err := abrirAmbiente(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alunos, _ := reaberto.alunos.ListarAlunos(ctx); len(alunos) != 1 || alunos[0].Email != "ana@uni.br" {
+		t.Errorf("o estado salvo não foi recarregado: %+v", alunos)
+	}
+}
+
+func TestBackendDesconhecido(t *testing.T) {
+	if _, err := abrirAmbiente(context.Background(), configuracao{backend: "nuvem"}); err == nil {
+		t.Error("backend desconhecido deveria falhar")
+	}
+	if _, err := abrirAmbiente(context.Background(), configuracao{backend: BackendProducao}); err == nil {
+		t.Error("produção sem credenciais deveria falhar")
+	}
+}
@@ -0,0 +1,120 @@
+// sgpctl reúne as tarefas operacionais do SGP (contas, dados de demonstração,
+// correções de dados e exportações) sobre a mesma camada de repositórios da
+// API. O backend é escolhido por flag: em memória (padrão, salvo num arquivo
+// JSON), emulador do Firebase ou produção.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sgp/Internal/reqctx"
+	"sort"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// AtorCLI aparece na auditoria como autor das alterações feitas pela CLI.
+var AtorCLI = reqctx.Ator{ID: "sgpctl", Papel: reqctx.PapelAdmin}
+
+type comando struct {
+	descricao string
+	executar  func(ctx context.Context, amb *ambiente, args []string) error
+}
+
+var comandos = map[string]comando{
+	"usuarios criar":        {"cria a conta, define o papel e cadastra o perfil", usuariosCriar},
+	"usuarios listar":       {"lista alunos e psicólogos", usuariosListar},
+	"usuarios desativar":    {"desativa a conta e encerra as sessões abertas", usuariosDesativar},
+	"usuarios papel":        {"define o papel (custom claim \"role\") de uma conta", usuariosPapel},
+	"seed":                  {"cria dados de demonstração (fora de produção)", seed},
+	"horarios liberar":      {"libera horários \"agendado\" sem consulta ativa", horariosLiberar},
+	"notificacoes reenviar": {"reenvia as notificações que falharam", notificacoesReenviar},
+	"exportar":              {"exporta os dados de um aluno (LGPD)", exportar},
+	"consultas reconciliar": {"cancela consultas com aluno, psicólogo ou horário inexistente", consultasReconciliar},
+	"reconciliar":           {"verifica e corrige todas as inconsistências entre consultas e horários", reconciliar},
+}
+
+func main() {
+	// O .env é opcional aqui: no backend em memória nada dele é necessário.
+	_ = godotenv.Load(".env")
+
+	cfg := configuracao{}
+	flags := flag.NewFlagSet("sgpctl", flag.ExitOnError)
+	flags.StringVar(&cfg.backend, "backend", valorOuPadrao(os.Getenv("SGPCTL_BACKEND"), BackendMemoria), "memoria, emulador ou producao")
+	flags.StringVar(&cfg.estado, "estado", "sgpctl-memoria.json", "arquivo do backend em memória")
+	flags.StringVar(&cfg.projeto, "projeto", valorOuPadrao(os.Getenv("GOOGLE_CLOUD_PROJECT"), "demo-sgp"), "projeto do Firebase no emulador")
+	flags.StringVar(&cfg.creds, "creds", os.Getenv("CREDS"), "credenciais da conta de serviço (producao)")
+	flags.Usage = func() { uso(flags) }
+	flags.Parse(os.Args[1:])
+
+	nome, cmd, args, ok := encontrarComando(flags.Args())
+	if !ok {
+		uso(flags)
+		os.Exit(2)
+	}
+
+	ctx := reqctx.ComAtor(context.Background(), AtorCLI)
+	amb, err := abrirAmbiente(ctx, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sgpctl: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = cmd.executar(ctx, amb, args)
+	if err == nil && amb.salvar != nil {
+		err = amb.salvar()
+	}
+	if errFechar := amb.fechar(); errFechar != nil && err == nil {
+		err = errFechar
+	}
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "sgpctl %s: %v\n", nome, err)
+		}
+		os.Exit(1)
+	}
+}
+
+// encontrarComando aceita comandos de uma ou duas palavras ("seed",
+// "usuarios criar") e devolve o restante dos argumentos como flags do comando.
+func encontrarComando(args []string) (string, comando, []string, bool) {
+	if len(args) >= 2 {
+		nome := args[0] + " " + args[1]
+		if cmd, ok := comandos[nome]; ok {
+			return nome, cmd, args[2:], true
+		}
+	}
+	if len(args) >= 1 {
+		if cmd, ok := comandos[args[0]]; ok {
+			return args[0], cmd, args[1:], true
+		}
+	}
+	return "", comando{}, nil, false
+}
+
+func uso(flags *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "uso: sgpctl [flags] <comando> [flags do comando]")
+	fmt.Fprintln(os.Stderr, "\ncomandos:")
+	nomes := make([]string, 0, len(comandos))
+	for nome := range comandos {
+		nomes = append(nomes, nome)
+	}
+	sort.Strings(nomes)
+	for _, nome := range nomes {
+		fmt.Fprintf(os.Stderr, "  %-22s %s\n", nome, comandos[nome].descricao)
+	}
+	fmt.Fprintln(os.Stderr, "\nflags:")
+	flags.PrintDefaults()
+	fmt.Fprintln(os.Stderr, "\nUse \"sgpctl <comando> -h\" para ver as flags de cada comando.")
+}
+
+func valorOuPadrao(valor, padrao string) string {
+	if strings.TrimSpace(valor) == "" {
+		return padrao
+	}
+	return valor
+}
@@ -3,10 +3,15 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log" // MODIFICADO: Garantir que o pacote de log está sendo usado
 	"net/http"
 	"sgp/Internal/model"
 	"sgp/Internal/repository"
+	"sgp/Internal/reqctx"
+	"sgp/Internal/service"
+	"strings"
 	"time"
 
 	"google.golang.org/grpc/codes"
@@ -17,6 +22,10 @@ const TimeoutAluno = 5 * time.Second
 
 type AlunoHandler struct {
 	Repo repository.AlunoRepository
+
+	// ConsultaRepo é opcional; sem ele, só o próprio aluno e os admins veem
+	// os dados sensíveis do perfil.
+	ConsultaRepo repository.ConsultaRepository
 }
 
 func NewAlunoHandler(repo repository.AlunoRepository) *AlunoHandler {
@@ -33,11 +42,18 @@ func (h *AlunoHandler) HandlerCriarAluno(
 	}
 	defer r.Body.Close()
 
+	aluno.Anonimizado = false
+	aluno.AnonimizadoEm = time.Time{}
+
 	if aluno.Nome == "" || aluno.Email == "" {
 		httpError(w, "Campos 'nome' e 'email' são obrigatórios",
 			http.StatusBadRequest)
 		return
 	}
+	if err := validarPerfilAluno(aluno); err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), TimeoutAluno)
 	defer cancel()
@@ -70,6 +86,21 @@ func (h *AlunoHandler) HandlerListarAlunos(
 		return
 	}
 
+	ator := reqctx.AtorDe(ctx)
+	if ator.Papel != reqctx.PapelAdmin {
+		pacientes, err := pacientesDoPsicologo(ctx, h.ConsultaRepo, ator)
+		if err != nil {
+			log.Printf("ERRO ao buscar pacientes do psicólogo %s: %v", ator.ID, err)
+			httpError(w, "Erro ao listar alunos", http.StatusInternalServerError)
+			return
+		}
+		for _, aluno := range alunos {
+			if aluno.ID != ator.ID && !pacientes[aluno.ID] {
+				ocultarDadosSensiveis(aluno)
+			}
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(alunos)
@@ -99,6 +130,16 @@ func (h *AlunoHandler) HandlerBuscarAlunoPorID(
 		return
 	}
 
+	podeVer, err := podeVerDadosSensiveis(ctx, h.ConsultaRepo, id)
+	if err != nil {
+		log.Printf("ERRO ao verificar acesso ao perfil do aluno (%s): %v", id, err)
+		httpError(w, "Erro ao buscar aluno", http.StatusInternalServerError)
+		return
+	}
+	if !podeVer {
+		ocultarDadosSensiveis(aluno)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(aluno)
@@ -120,13 +161,26 @@ func (h *AlunoHandler) HandlerAtualizarAluno(
 	}
 	defer r.Body.Close()
 
+	if err := validarPerfilAluno(aluno); err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), TimeoutAluno)
 	defer cancel()
 
+	// Os campos de anonimização não vêm do cliente: só o fluxo de
+	// anonimização os escreve.
+	aluno.Anonimizado = false
+	aluno.AnonimizadoEm = time.Time{}
+
 	if err := h.Repo.AtualizarAluno(ctx, id, aluno); err != nil {
 		// MODIFICADO: Adicionado log de erro
 		log.Printf("ERRO ao atualizar aluno por ID (%s): %v", id, err)
-		if status.Code(err) == codes.NotFound {
+		if errors.Is(err, repository.ErrAlunoAnonimizado) {
+			httpError(w, "O aluno foi anonimizado e não pode mais ser alterado.",
+				http.StatusConflict)
+		} else if status.Code(err) == codes.NotFound {
 			httpError(w, "Não é possível atualizar um aluno que não existe.",
 				http.StatusNotFound)
 		} else {
@@ -192,7 +246,71 @@ func (h *AlunoHandler) HandlerBuscarAlunoPorNome(
 }
 
 
+// HandlerRecursosCrise responde à rota GET /recursos-crise com os canais de
+// ajuda imediata, que não dependem de agendamento.
+func (h *AlunoHandler) HandlerRecursosCrise(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(service.RecursosCrise)
+}
+
+// podeVerDadosSensiveis libera os dados do aluno ao próprio aluno, aos admins
+// e aos psicólogos com consulta (não cancelada) com ele. Sem consultaRepo,
+// nenhum psicólogo é liberado.
+func podeVerDadosSensiveis(ctx context.Context, consultaRepo repository.ConsultaRepository, alunoID string) (bool, error) {
+	ator := reqctx.AtorDe(ctx)
+	if ator.ID == alunoID || ator.Papel == reqctx.PapelAdmin {
+		return true, nil
+	}
+	pacientes, err := pacientesDoPsicologo(ctx, consultaRepo, ator)
+	if err != nil {
+		return false, err
+	}
+	return pacientes[alunoID], nil
+}
+
+func pacientesDoPsicologo(ctx context.Context, consultaRepo repository.ConsultaRepository, ator reqctx.Ator) (map[string]bool, error) {
+	pacientes := map[string]bool{}
+	if ator.ID == "" || ator.Papel != reqctx.PapelPsicologo || consultaRepo == nil {
+		return pacientes, nil
+	}
 
+	consultas, err := consultaRepo.ListarConsultasPorPsicologo(ctx, ator.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range consultas {
+		if !strings.HasPrefix(c.Status, "cancelada") && c.Status != "recusada" {
+			pacientes[c.AlunoID] = true
+		}
+	}
+	return pacientes, nil
+}
+
+func ocultarDadosSensiveis(aluno *model.Aluno) {
+	aluno.Matricula = ""
+	aluno.Telefone = ""
+	aluno.DataNascimento = ""
+	aluno.ContatosEmergencia = nil
+}
+
+func validarPerfilAluno(aluno model.Aluno) error {
+	if aluno.DataNascimento != "" {
+		nascimento, err := time.Parse("2006-01-02", aluno.DataNascimento)
+		if err != nil {
+			return fmt.Errorf("Campo 'dataNascimento' inválido, use AAAA-MM-DD")
+		}
+		if nascimento.After(time.Now()) {
+			return fmt.Errorf("Campo 'dataNascimento' não pode estar no futuro")
+		}
+	}
+	for _, c := range aluno.ContatosEmergencia {
+		if c.Nome == "" || c.Telefone == "" {
+			return fmt.Errorf("Todo contato de emergência precisa de 'nome' e 'telefone'")
+		}
+	}
+	return service.ValidarFuso(aluno.FusoHorario)
+}
 
 // A função httpError não foi fornecida, mas estou assumindo que ela existe
 // em algum lugar do seu pacote 'handler' para que o código compile.
@@ -8,7 +8,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"sgp/Internal/model"
+	"sgp/Internal/repository"
 	"sgp/Internal/repository/mocks"
+	"sgp/Internal/reqctx"
 	"testing"
 
 	"google.golang.org/grpc/codes"
@@ -168,6 +170,48 @@ func TestHandlerAtualizarAluno(t *testing.T) {
 	})
 }
 
+func TestHandlerAtualizarAlunoAnonimizado(t *testing.T) {
+	t.Run("aluno anonimizado não pode ser atualizado", func(t *testing.T) {
+		req, _ := http.NewRequest("PUT", "/alunos/123", bytes.NewBufferString(`{"nome":"Maria Souza","email":"maria@test.com"}`))
+		req.SetPathValue("id", "123")
+		rr := httptest.NewRecorder()
+
+		mockRepo := &mocks.AlunoRepositoryMock{
+			AtualizarAlunosFunc: func(ctx context.Context, id string, a model.Aluno) error {
+				return repository.ErrAlunoAnonimizado
+			},
+		}
+
+		h := NewAlunoHandler(mockRepo)
+		h.HandlerAtualizarAluno(rr, req)
+
+		if status := rr.Code; status != http.StatusConflict {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusConflict)
+		}
+	})
+
+	t.Run("cliente não define os campos de anonimização", func(t *testing.T) {
+		req, _ := http.NewRequest("PUT", "/alunos/123", bytes.NewBufferString(`{"nome":"Maria","anonimizado":true,"anonimizadoEm":"2025-01-01T00:00:00Z"}`))
+		req.SetPathValue("id", "123")
+		rr := httptest.NewRecorder()
+
+		var gravado model.Aluno
+		mockRepo := &mocks.AlunoRepositoryMock{
+			AtualizarAlunosFunc: func(ctx context.Context, id string, a model.Aluno) error {
+				gravado = a
+				return nil
+			},
+		}
+
+		h := NewAlunoHandler(mockRepo)
+		h.HandlerAtualizarAluno(rr, req)
+
+		if rr.Code != http.StatusOK || gravado.Anonimizado || !gravado.AnonimizadoEm.IsZero() {
+			t.Errorf("campos de anonimização deveriam ser descartados: status %d, gravado %+v", rr.Code, gravado)
+		}
+	})
+}
+
 func TestHandlerDeletarAluno(t *testing.T) {
 	t.Run("sucesso ao deletar aluno", func(t *testing.T) {
 		req, _ := http.NewRequest("DELETE", "/alunos/123", nil)
@@ -227,4 +271,76 @@ func TestHandlerBuscarAlunoPorNome(t *testing.T) {
 			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusNotFound)
 		}
 	})
-}
\ No newline at end of file
+}
+func TestHandlerBuscarAlunoDadosSensiveis(t *testing.T) {
+	mockRepo := &mocks.AlunoRepositoryMock{
+		BuscarAlunoPorIDFunc: func(ctx context.Context, id string) (*model.Aluno, error) {
+			return &model.Aluno{
+				ID:                 id,
+				Nome:               "Maria",
+				NomeSocial:         "Mar",
+				Telefone:           "85999990000",
+				DataNascimento:     "2003-05-10",
+				ContatosEmergencia: []model.ContatoEmergencia{{Nome: "Ana", Telefone: "85988887777"}},
+			}, nil
+		},
+	}
+	mockConsultaRepo := &mocks.ConsultaRepositoryMock{
+		ListarConsultasPorPsicologoFunc: func(ctx context.Context, psicologoID string, statusFiltro string) ([]*model.Consulta, error) {
+			if psicologoID == "psico-responsavel" {
+				return []*model.Consulta{{AlunoID: "aluno-1", Status: "confirmada"}}, nil
+			}
+			return []*model.Consulta{{AlunoID: "aluno-1", Status: "cancelada pelo aluno"}}, nil
+		},
+	}
+	h := NewAlunoHandler(mockRepo)
+	h.ConsultaRepo = mockConsultaRepo
+
+	casos := []struct {
+		nome    string
+		ator    reqctx.Ator
+		podeVer bool
+	}{
+		{"próprio aluno", reqctx.Ator{ID: "aluno-1", Papel: reqctx.PapelAluno}, true},
+		{"admin", reqctx.Ator{ID: "admin-1", Papel: reqctx.PapelAdmin}, true},
+		{"psicólogo responsável", reqctx.Ator{ID: "psico-responsavel", Papel: reqctx.PapelPsicologo}, true},
+		{"psicólogo com consulta cancelada", reqctx.Ator{ID: "psico-outro", Papel: reqctx.PapelPsicologo}, false},
+		{"outro aluno", reqctx.Ator{ID: "aluno-2", Papel: reqctx.PapelAluno}, false},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/alunos/aluno-1", nil)
+			req.SetPathValue("id", "aluno-1")
+			req = req.WithContext(reqctx.ComAtor(req.Context(), c.ator))
+			rr := httptest.NewRecorder()
+			h.HandlerBuscarAlunoPorID(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("status code incorreto: obteve %v, esperava %v", rr.Code, http.StatusOK)
+			}
+			var aluno model.Aluno
+			json.NewDecoder(rr.Body).Decode(&aluno)
+
+			if aluno.NomeSocial != "Mar" {
+				t.Errorf("nome social deveria ser sempre visível, obteve %q", aluno.NomeSocial)
+			}
+			if viu := aluno.Telefone != "" && len(aluno.ContatosEmergencia) == 1; viu != c.podeVer {
+				t.Errorf("acesso aos dados sensíveis incorreto: viu=%v, esperava %v", viu, c.podeVer)
+			}
+		})
+	}
+}
+
+func TestHandlerCriarAlunoDataNascimentoInvalida(t *testing.T) {
+	body, _ := json.Marshal(model.Aluno{Nome: "Maria", Email: "maria@exemplo.com", DataNascimento: "10/05/2003"})
+	req, _ := http.NewRequest("POST", "/alunos", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+
+	h := NewAlunoHandler(&mocks.AlunoRepositoryMock{})
+	h.HandlerCriarAluno(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("status code incorreto: obteve %v, esperava %v", rr.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"strconv"
+)
+
+const LimiteAuditoriaMaximo = 200
+
+type AuditoriaHandler struct {
+	Repo repository.AuditoriaRepository
+}
+
+func NewAuditoriaHandler(repo repository.AuditoriaRepository) *AuditoriaHandler {
+	return &AuditoriaHandler{Repo: repo}
+}
+
+// HandlerListarAuditoria responde à rota GET /auditoria, filtrando por
+// entidade/entidadeId ou por atorId, com paginação via cursor.
+func (h *AuditoriaHandler) HandlerListarAuditoria(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filtro := model.FiltroAuditoria{
+		Entidade:   q.Get("entidade"),
+		EntidadeID: q.Get("entidadeId"),
+		AtorID:     q.Get("atorId"),
+		Cursor:     q.Get("cursor"),
+	}
+
+	if filtro.Entidade == "" && filtro.AtorID == "" {
+		httpError(w, "Informe 'entidade' ou 'atorId'", http.StatusBadRequest)
+		return
+	}
+
+	if limite := q.Get("limite"); limite != "" {
+		n, err := strconv.Atoi(limite)
+		if err != nil || n <= 0 || n > LimiteAuditoriaMaximo {
+			httpError(w, "O 'limite' deve estar entre 1 e 200", http.StatusBadRequest)
+			return
+		}
+		filtro.Limite = n
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	registros, proximoCursor, err := h.Repo.ListarAuditoria(ctx, filtro)
+	if err != nil {
+		log.Printf("ERRO ao listar auditoria: %v", err)
+		httpError(w, "Erro ao listar auditoria", http.StatusInternalServerError)
+		return
+	}
+	if registros == nil {
+		registros = []*model.RegistroAuditoria{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"registros":     registros,
+		"proximoCursor": proximoCursor,
+	})
+}
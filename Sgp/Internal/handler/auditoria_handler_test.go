@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sgp/Internal/model"
+	"sgp/Internal/repository/mocks"
+	"testing"
+)
+
+func TestHandlerListarAuditoria(t *testing.T) {
+	t.Run("sucesso ao listar por entidade", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/auditoria?entidade=aluno&entidadeId=a1&limite=2", nil)
+		rr := httptest.NewRecorder()
+
+		mockRepo := &mocks.AuditoriaRepositoryMock{
+			ListarAuditoriaFunc: func(ctx context.Context, f model.FiltroAuditoria) ([]*model.RegistroAuditoria, string, error) {
+				if f.Entidade != "aluno" || f.EntidadeID != "a1" || f.Limite != 2 {
+					t.Errorf("filtro incorreto: %+v", f)
+				}
+				return []*model.RegistroAuditoria{{ID: "r1"}, {ID: "r2"}}, "r2", nil
+			},
+		}
+
+		h := NewAuditoriaHandler(mockRepo)
+		h.HandlerListarAuditoria(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusOK)
+		}
+		var resp struct {
+			Registros     []*model.RegistroAuditoria `json:"registros"`
+			ProximoCursor string                     `json:"proximoCursor"`
+		}
+		json.NewDecoder(rr.Body).Decode(&resp)
+		if len(resp.Registros) != 2 || resp.ProximoCursor != "r2" {
+			t.Errorf("resposta incorreta: %+v", resp)
+		}
+	})
+
+	t.Run("sem filtro", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/auditoria", nil)
+		rr := httptest.NewRecorder()
+
+		h := NewAuditoriaHandler(&mocks.AuditoriaRepositoryMock{})
+		h.HandlerListarAuditoria(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusBadRequest)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sgp/Internal/model"
+	"sgp/Internal/service"
+	"time"
+)
+
+type CalendarioHandler struct {
+	Service *service.CalendarioService
+
+	// Tarefas acompanha os avisos de cancelamento; nil só os dispara.
+	Tarefas *service.Tarefas
+}
+
+func NewCalendarioHandler(s *service.CalendarioService) *CalendarioHandler {
+	return &CalendarioHandler{Service: s}
+}
+
+// HandlerCriarEvento responde à rota POST /calendario.
+// Feriados sem "fim" duram o dia inteiro no fuso da clínica. Feriados e fechamentos cancelam as
+// consultas ativas do intervalo e avisam os alunos.
+func (h *CalendarioHandler) HandlerCriarEvento(w http.ResponseWriter, r *http.Request) {
+	var evento model.EventoCalendario
+	if err := json.NewDecoder(r.Body).Decode(&evento); err != nil {
+		httpError(w, "Requisição inválida", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	switch evento.Tipo {
+	case model.EventoFeriado, model.EventoFechamento, model.EventoPeriodoLetivo:
+	default:
+		httpError(w, "Tipo inválido: use 'feriado', 'fechamento' ou 'periodo letivo'", http.StatusBadRequest)
+		return
+	}
+	if evento.Nome == "" || evento.Inicio.IsZero() {
+		httpError(w, "Campos 'nome' e 'inicio' são obrigatórios", http.StatusBadRequest)
+		return
+	}
+	if evento.Tipo == model.EventoFeriado && evento.Fim.IsZero() {
+		// Vale a data como o cliente a escreveu, da meia-noite à meia-noite locais.
+		ano, mes, dia := evento.Inicio.Date()
+		fuso := service.Fuso("")
+		evento.Inicio = time.Date(ano, mes, dia, 0, 0, 0, 0, fuso).UTC()
+		evento.Fim = time.Date(ano, mes, dia+1, 0, 0, 0, 0, fuso).UTC()
+	}
+	if !evento.Fim.After(evento.Inicio) {
+		httpError(w, "O fim do evento deve ser posterior ao início", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	criado, canceladas, err := h.Service.RegistrarEvento(ctx, evento)
+	if err != nil && criado == nil {
+		log.Printf("ERRO ao criar evento no calendário: %v", err)
+		httpError(w, "Erro ao criar evento no calendário", http.StatusInternalServerError)
+		return
+	}
+	if len(canceladas) > 0 {
+		h.Tarefas.Disparar(func() { h.Service.NotificarCancelamentos(criado, canceladas) })
+	}
+	if err != nil {
+		log.Printf("ERRO ao cancelar consultas do evento %s: %v", criado.ID, err)
+		httpError(w, "Evento criado, mas nem todas as consultas do período foram canceladas", http.StatusInternalServerError)
+		return
+	}
+
+	if canceladas == nil {
+		canceladas = []*model.Consulta{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"evento":              criado,
+		"consultasCanceladas": canceladas,
+	})
+}
+
+// HandlerListarEventos responde à rota GET /calendario?de=...&ate=...
+// Sem parâmetros, retorna os próximos 90 dias.
+func (h *CalendarioHandler) HandlerListarEventos(w http.ResponseWriter, r *http.Request) {
+	de := time.Now().UTC()
+	ate := de.AddDate(0, 0, 90)
+
+	var err error
+	if v := r.URL.Query().Get("de"); v != "" {
+		if de, err = time.Parse(time.RFC3339, v); err != nil {
+			httpError(w, "Parâmetro 'de' inválido, use RFC3339", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("ate"); v != "" {
+		if ate, err = time.Parse(time.RFC3339, v); err != nil {
+			httpError(w, "Parâmetro 'ate' inválido, use RFC3339", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	eventos, err := h.Service.Repo.ListarEventos(ctx, de, ate)
+	if err != nil {
+		log.Printf("ERRO ao listar eventos do calendário: %v", err)
+		httpError(w, "Erro ao listar eventos do calendário", http.StatusInternalServerError)
+		return
+	}
+	if eventos == nil {
+		eventos = []*model.EventoCalendario{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(eventos)
+}
+
+// HandlerDeletarEvento responde à rota DELETE /calendario/{id}.
+// Consultas já canceladas pelo evento não são restauradas.
+func (h *CalendarioHandler) HandlerDeletarEvento(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	if err := h.Service.Repo.DeletarEvento(ctx, id); err != nil {
+		log.Printf("ERRO ao deletar evento %s: %v", id, err)
+		httpError(w, "Erro ao deletar evento", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sgp/Internal/model"
+	"sgp/Internal/repository/mocks"
+	"sgp/Internal/service"
+	"testing"
+	"time"
+)
+
+// 2025-06-19 é feriado (Corpus Christi) nos testes abaixo.
+var feriadoTeste = &model.EventoCalendario{
+	ID:     "corpus-christi",
+	Tipo:   model.EventoFeriado,
+	Nome:   "Corpus Christi",
+	Inicio: time.Date(2025, 6, 19, 0, 0, 0, 0, time.UTC),
+	Fim:    time.Date(2025, 6, 20, 0, 0, 0, 0, time.UTC),
+}
+
+func calendarioComEventos(eventos ...*model.EventoCalendario) *mocks.CalendarioRepositoryMock {
+	return &mocks.CalendarioRepositoryMock{
+		ListarEventosFunc: func(ctx context.Context, de, ate time.Time) ([]*model.EventoCalendario, error) {
+			var sobrepostos []*model.EventoCalendario
+			for _, e := range eventos {
+				if e.Inicio.Before(ate) && de.Before(e.Fim) {
+					sobrepostos = append(sobrepostos, e)
+				}
+			}
+			return sobrepostos, nil
+		},
+	}
+}
+
+func TestHandlerCriarEventoCancelaConsultas(t *testing.T) {
+	inicio := time.Date(2025, 7, 14, 0, 0, 0, 0, time.UTC)
+	canceladas := map[string]string{}
+	bloqueados := map[string]string{}
+
+	consultaRepo := &mocks.ConsultaRepositoryMock{
+		ListarConsultasNoPeriodoFunc: func(ctx context.Context, status string, de, ate time.Time) ([]*model.Consulta, error) {
+			if status != "confirmada" {
+				return nil, nil
+			}
+			var sobrepostas []*model.Consulta
+			for _, c := range []*model.Consulta{
+				{ID: "dentro", HorarioID: "h1", Status: status, Inicio: inicio.Add(50 * time.Hour), Fim: inicio.Add(51 * time.Hour)},
+				{ID: "fora", HorarioID: "h2", Status: status, Inicio: inicio.AddDate(0, 0, 20), Fim: inicio.AddDate(0, 0, 20).Add(time.Hour)},
+			} {
+				if c.Inicio.Before(ate) && de.Before(c.Fim) {
+					sobrepostas = append(sobrepostas, c)
+				}
+			}
+			return sobrepostas, nil
+		},
+		AtualizaStatusConsultaFunc: func(ctx context.Context, id string, novoStatus string) error {
+			canceladas[id] = novoStatus
+			return nil
+		},
+	}
+	horarioRepo := &mocks.HorarioDisponivelRepositoryMock{
+		AtualizarStatusHorarioFunc: func(ctx context.Context, id string, novoStatus string) error {
+			bloqueados[id] = novoStatus
+			return nil
+		},
+	}
+	calendarioRepo := &mocks.CalendarioRepositoryMock{
+		CriarEventoFunc: func(ctx context.Context, evento model.EventoCalendario) (*model.EventoCalendario, error) {
+			evento.ID = "recesso"
+			return &evento, nil
+		},
+	}
+
+	h := NewCalendarioHandler(service.NewCalendarioService(calendarioRepo, consultaRepo, horarioRepo, &mocks.AlunoRepositoryMock{}, nil))
+
+	body, _ := json.Marshal(model.EventoCalendario{
+		Tipo: model.EventoFechamento, Nome: "Recesso de julho", Inicio: inicio, Fim: inicio.AddDate(0, 0, 14),
+	})
+	req, _ := http.NewRequest("POST", "/calendario", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+	h.HandlerCriarEvento(rr, req)
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v (%s)", status, http.StatusCreated, rr.Body.String())
+	}
+	if len(canceladas) != 1 || canceladas["dentro"] != "cancelada pela clinica" {
+		t.Errorf("consultas canceladas incorretamente: %v", canceladas)
+	}
+	if len(bloqueados) != 1 || bloqueados["h1"] != "bloqueado" {
+		t.Errorf("horários bloqueados incorretamente: %v", bloqueados)
+	}
+}
+
+func TestHandlerCriarFeriadoOcupaODiaLocal(t *testing.T) {
+	var periodo [2]time.Time
+	consultaRepo := &mocks.ConsultaRepositoryMock{
+		ListarConsultasNoPeriodoFunc: func(ctx context.Context, status string, de, ate time.Time) ([]*model.Consulta, error) {
+			periodo = [2]time.Time{de, ate}
+			return nil, nil
+		},
+	}
+	calendarioRepo := &mocks.CalendarioRepositoryMock{
+		CriarEventoFunc: func(ctx context.Context, evento model.EventoCalendario) (*model.EventoCalendario, error) {
+			evento.ID = "corpus-christi"
+			return &evento, nil
+		},
+	}
+	h := NewCalendarioHandler(service.NewCalendarioService(calendarioRepo, consultaRepo, &mocks.HorarioDisponivelRepositoryMock{}, &mocks.AlunoRepositoryMock{}, nil))
+
+	body, _ := json.Marshal(model.EventoCalendario{
+		Tipo: model.EventoFeriado, Nome: "Corpus Christi", Inicio: time.Date(2025, 6, 19, 0, 0, 0, 0, time.UTC),
+	})
+	req, _ := http.NewRequest("POST", "/calendario", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+	h.HandlerCriarEvento(rr, req)
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v (%s)", status, http.StatusCreated, rr.Body.String())
+	}
+	// Meia-noite em São Paulo (UTC-3) do dia 19 até a do dia 20.
+	inicio := time.Date(2025, 6, 19, 3, 0, 0, 0, time.UTC)
+	if !periodo[0].Equal(inicio) || !periodo[1].Equal(inicio.AddDate(0, 0, 1)) {
+		t.Errorf("período do feriado incorreto: %v a %v", periodo[0], periodo[1])
+	}
+}
+
+func TestHandlerCriarHorarioNoFeriado(t *testing.T) {
+	h := NewHorarioDisponivelHandler(&mocks.HorarioDisponivelRepositoryMock{
+		CriarHorarioFunc: func(ctx context.Context, horario model.HorarioDisponivel) (*model.HorarioDisponivel, error) {
+			horario.ID = "h1"
+			return &horario, nil
+		},
+	})
+	h.Calendario = service.NewCalendarioService(calendarioComEventos(feriadoTeste), nil, nil, nil, nil)
+
+	casos := []struct {
+		nome     string
+		inicio   time.Time
+		esperado int
+	}{
+		{"dia util", time.Date(2025, 6, 18, 14, 0, 0, 0, time.UTC), http.StatusCreated},
+		{"feriado", time.Date(2025, 6, 19, 14, 0, 0, 0, time.UTC), http.StatusUnprocessableEntity},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			body, _ := json.Marshal(model.HorarioDisponivel{PsicologoID: "psico-1", Inicio: tc.inicio, Fim: tc.inicio.Add(time.Hour)})
+			req, _ := http.NewRequest("POST", "/horarios", bytes.NewBuffer(body))
+			rr := httptest.NewRecorder()
+			h.HandlerCriarHorario(rr, req)
+
+			if status := rr.Code; status != tc.esperado {
+				t.Errorf("status code incorreto: obteve %v, esperava %v", status, tc.esperado)
+			}
+		})
+	}
+}
+
+func TestHandlerGerarHorarios(t *testing.T) {
+	var criados []model.HorarioDisponivel
+	h := NewHorarioDisponivelHandler(&mocks.HorarioDisponivelRepositoryMock{
+		CriarHorarioFunc: func(ctx context.Context, horario model.HorarioDisponivel) (*model.HorarioDisponivel, error) {
+			criados = append(criados, horario)
+			return &horario, nil
+		},
+	})
+	periodo := &model.EventoCalendario{
+		Tipo: model.EventoPeriodoLetivo, Nome: "2025.1",
+		Inicio: time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC), Fim: time.Date(2025, 6, 28, 0, 0, 0, 0, time.UTC),
+	}
+	h.Calendario = service.NewCalendarioService(calendarioComEventos(feriadoTeste, periodo), nil, nil, nil, nil)
+	h.Calendario.ExigirPeriodoLetivo = true
+
+	// Quintas de 12/06 a 03/07: 19/06 é feriado e 03/07 está fora do período letivo.
+	body, _ := json.Marshal(service.GradeHorarios{
+		PsicologoID: "psico-1", De: "2025-06-12", Ate: "2025-07-03",
+		DiasSemana: []int{4}, Horarios: []string{"14:00"}, DuracaoMinutos: 50,
+	})
+	req, _ := http.NewRequest("POST", "/horarios/grade", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+	h.HandlerGerarHorarios(rr, req)
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v (%s)", status, http.StatusCreated, rr.Body.String())
+	}
+	if len(criados) != 2 || criados[0].Inicio.Day() != 12 || criados[1].Inicio.Day() != 26 {
+		t.Errorf("horários criados incorretamente: %+v", criados)
+	}
+	if criados[0].Fim.Sub(criados[0].Inicio) != 50*time.Minute {
+		t.Errorf("duração incorreta: %v", criados[0].Fim.Sub(criados[0].Inicio))
+	}
+
+	var resposta struct {
+		Ignorados []struct {
+			Motivo string `json:"motivo"`
+		} `json:"ignorados"`
+	}
+	json.NewDecoder(rr.Body).Decode(&resposta)
+	if len(resposta.Ignorados) != 2 {
+		t.Errorf("esperava 2 horários ignorados, obteve %+v", resposta.Ignorados)
+	}
+}
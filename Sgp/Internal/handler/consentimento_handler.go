@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/reqctx"
+	"strings"
+	"time"
+)
+
+type ConsentimentoHandler struct {
+	Repo repository.ConsentimentoRepository
+	// ProxiesConfiaveis são as redes dos proxies reversos cujo X-Forwarded-For
+	// vale como origem do aceite. Vazia, vale só o endereço da conexão.
+	ProxiesConfiaveis []*net.IPNet
+}
+
+func NewConsentimentoHandler(repo repository.ConsentimentoRepository) *ConsentimentoHandler {
+	return &ConsentimentoHandler{Repo: repo}
+}
+
+// HandlerPublicarConsentimento responde à rota POST /consentimentos.
+// Cada publicação vira a nova versão vigente e exige novo aceite de todos.
+func (h *ConsentimentoHandler) HandlerPublicarConsentimento(w http.ResponseWriter, r *http.Request) {
+	var documento model.DocumentoConsentimento
+	if err := json.NewDecoder(r.Body).Decode(&documento); err != nil {
+		httpError(w, "Requisição inválida", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if documento.Titulo == "" || documento.Conteudo == "" {
+		httpError(w, "Campos 'titulo' e 'conteudo' são obrigatórios", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	publicado, err := h.Repo.PublicarDocumento(ctx, documento)
+	if err != nil {
+		log.Printf("ERRO ao publicar termo de consentimento: %v", err)
+		httpError(w, "Erro ao publicar termo de consentimento", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(publicado)
+}
+
+// HandlerBuscarConsentimentoVigente responde à rota GET /consentimentos/vigente
+func (h *ConsentimentoHandler) HandlerBuscarConsentimentoVigente(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	documento, err := h.Repo.BuscarDocumentoVigente(ctx)
+	if err != nil {
+		log.Printf("ERRO ao buscar termo de consentimento vigente: %v", err)
+		httpError(w, "Erro ao buscar termo de consentimento", http.StatusInternalServerError)
+		return
+	}
+	if documento == nil {
+		httpError(w, "Nenhum termo de consentimento publicado", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(documento)
+}
+
+// HandlerAceitarConsentimento responde à rota POST /alunos/{id}/consentimentos.
+// Só o próprio aluno pode aceitar, e apenas a versão vigente.
+func (h *ConsentimentoHandler) HandlerAceitarConsentimento(w http.ResponseWriter, r *http.Request) {
+	alunoID := r.PathValue("id")
+	if alunoID == "" {
+		httpError(w, "O ID do aluno é obrigatório", http.StatusBadRequest)
+		return
+	}
+
+	if reqctx.AtorDe(r.Context()).ID != alunoID {
+		httpError(w, "O aceite deve ser feito pelo próprio aluno", http.StatusForbidden)
+		return
+	}
+
+	var payload struct {
+		Versao int `json:"versao"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		httpError(w, "Requisição inválida", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	vigente, err := h.Repo.BuscarDocumentoVigente(ctx)
+	if err != nil {
+		log.Printf("ERRO ao buscar termo de consentimento vigente: %v", err)
+		httpError(w, "Erro ao registrar aceite", http.StatusInternalServerError)
+		return
+	}
+	if vigente == nil || payload.Versao != vigente.Versao {
+		httpError(w, "Só é possível aceitar a versão vigente do termo", http.StatusConflict)
+		return
+	}
+
+	aceite, err := h.Repo.RegistrarAceite(ctx, model.AceiteConsentimento{
+		AlunoID:  alunoID,
+		Versao:   vigente.Versao,
+		AceitoEm: time.Now().UTC(),
+		IP:       h.ipDaRequisicao(r),
+	})
+	if err != nil {
+		log.Printf("ERRO ao registrar aceite do aluno %s: %v", alunoID, err)
+		httpError(w, "Erro ao registrar aceite", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(aceite)
+}
+
+// HandlerListarConsentimentosAluno responde à rota GET /alunos/{id}/consentimentos
+func (h *ConsentimentoHandler) HandlerListarConsentimentosAluno(w http.ResponseWriter, r *http.Request) {
+	alunoID := r.PathValue("id")
+	if alunoID == "" {
+		httpError(w, "O ID do aluno é obrigatório", http.StatusBadRequest)
+		return
+	}
+
+	ator := reqctx.AtorDe(r.Context())
+	if ator.ID != alunoID && ator.Papel != reqctx.PapelAdmin {
+		httpError(w, "Apenas o titular ou um admin pode ver estes aceites", http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	aceites, err := h.Repo.ListarAceitesPorAluno(ctx, alunoID)
+	if err != nil {
+		log.Printf("ERRO ao listar aceites do aluno %s: %v", alunoID, err)
+		httpError(w, "Erro ao listar aceites", http.StatusInternalServerError)
+		return
+	}
+
+	pendente, err := consentimentoPendente(ctx, h.Repo, alunoID)
+	if err != nil {
+		log.Printf("ERRO ao verificar consentimento do aluno %s: %v", alunoID, err)
+		httpError(w, "Erro ao listar aceites", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"aceites":  aceites,
+		"pendente": pendente,
+	})
+}
+
+// consentimentoPendente indica se existe termo vigente ainda não aceito pelo aluno.
+func consentimentoPendente(ctx context.Context, repo repository.ConsentimentoRepository, alunoID string) (bool, error) {
+	vigente, err := repo.BuscarDocumentoVigente(ctx)
+	if err != nil || vigente == nil {
+		return false, err
+	}
+	aceite, err := repo.BuscarAceite(ctx, alunoID, vigente.Versao)
+	if err != nil {
+		return false, err
+	}
+	return aceite == nil, nil
+}
+
+// ipDaRequisicao devolve o endereço guardado como evidência do aceite. O
+// X-Forwarded-For só é lido quando a conexão vem de um proxy confiável, e
+// dele vale o primeiro endereço, da direita para a esquerda, que não é de um
+// desses proxies: o que vem antes pode ter sido escrito pelo próprio cliente.
+func (h *ConsentimentoHandler) ipDaRequisicao(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	if !h.proxyConfiavel(ip) {
+		return ip
+	}
+	saltos := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(saltos) - 1; i >= 0; i-- {
+		salto := strings.TrimSpace(saltos[i])
+		if net.ParseIP(salto) == nil {
+			break
+		}
+		ip = salto
+		if !h.proxyConfiavel(salto) {
+			break
+		}
+	}
+	return ip
+}
+
+func (h *ConsentimentoHandler) proxyConfiavel(endereco string) bool {
+	ip := net.ParseIP(endereco)
+	if ip == nil {
+		return false
+	}
+	for _, rede := range h.ProxiesConfiaveis {
+		if rede.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// LerRedes interpreta uma lista de redes (CIDR) ou IPs separados por vírgula,
+// como a de PROXIES_CONFIAVEIS.
+func LerRedes(lista string) ([]*net.IPNet, error) {
+	var redes []*net.IPNet
+	for _, item := range strings.Split(lista, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("endereço inválido: %q", item)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			redes = append(redes, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, rede, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("rede inválida: %q", item)
+		}
+		redes = append(redes, rede)
+	}
+	return redes, nil
+}
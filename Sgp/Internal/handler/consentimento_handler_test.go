@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sgp/Internal/model"
+	"sgp/Internal/repository/mocks"
+	"sgp/Internal/reqctx"
+	"testing"
+)
+
+func novoConsentimentoRepoTeste(aceitas map[int]bool) *mocks.ConsentimentoRepositoryMock {
+	return &mocks.ConsentimentoRepositoryMock{
+		BuscarDocumentoVigenteFunc: func(ctx context.Context) (*model.DocumentoConsentimento, error) {
+			return &model.DocumentoConsentimento{ID: "v2", Versao: 2}, nil
+		},
+		BuscarAceiteFunc: func(ctx context.Context, alunoID string, versao int) (*model.AceiteConsentimento, error) {
+			if aceitas[versao] {
+				return &model.AceiteConsentimento{AlunoID: alunoID, Versao: versao}, nil
+			}
+			return nil, nil
+		},
+		RegistrarAceiteFunc: func(ctx context.Context, a model.AceiteConsentimento) (*model.AceiteConsentimento, error) {
+			return &a, nil
+		},
+	}
+}
+
+func TestHandlerAceitarConsentimento(t *testing.T) {
+	t.Run("sucesso ao aceitar versao vigente", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]int{"versao": 2})
+		req, _ := http.NewRequest("POST", "/alunos/aluno-1/consentimentos", bytes.NewBuffer(body))
+		req.SetPathValue("id", "aluno-1")
+		req.RemoteAddr = "10.0.0.5:51234"
+		req = req.WithContext(reqctx.ComAtor(req.Context(), reqctx.Ator{ID: "aluno-1"}))
+		rr := httptest.NewRecorder()
+
+		h := NewConsentimentoHandler(novoConsentimentoRepoTeste(nil))
+		h.HandlerAceitarConsentimento(rr, req)
+
+		if status := rr.Code; status != http.StatusCreated {
+			t.Fatalf("status code incorreto: obteve %v, esperava %v", status, http.StatusCreated)
+		}
+		var aceite model.AceiteConsentimento
+		json.NewDecoder(rr.Body).Decode(&aceite)
+		if aceite.IP != "10.0.0.5" || aceite.Versao != 2 || aceite.AceitoEm.IsZero() {
+			t.Errorf("aceite incorreto: %+v", aceite)
+		}
+	})
+
+	t.Run("versao antiga", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]int{"versao": 1})
+		req, _ := http.NewRequest("POST", "/alunos/aluno-1/consentimentos", bytes.NewBuffer(body))
+		req.SetPathValue("id", "aluno-1")
+		req = req.WithContext(reqctx.ComAtor(req.Context(), reqctx.Ator{ID: "aluno-1"}))
+		rr := httptest.NewRecorder()
+
+		h := NewConsentimentoHandler(novoConsentimentoRepoTeste(nil))
+		h.HandlerAceitarConsentimento(rr, req)
+
+		if status := rr.Code; status != http.StatusConflict {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusConflict)
+		}
+	})
+}
+
+func TestIPDoAceiteSoConfiaEmProxiesConfigurados(t *testing.T) {
+	proxies, err := LerRedes("10.0.0.0/8, 192.168.1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	casos := []struct {
+		nome        string
+		proxies     bool
+		remoto      string
+		encaminhado string
+		esperado    string
+	}{
+		{"sem proxy configurado ignora o cabeçalho", false, "203.0.113.9:4000", "198.51.100.1", "203.0.113.9"},
+		{"conexão direta não pode forjar o cabeçalho", true, "203.0.113.9:4000", "198.51.100.1", "203.0.113.9"},
+		{"atrás do proxy vale o cliente", true, "10.0.0.2:4000", "198.51.100.1", "198.51.100.1"},
+		{"endereço forjado antes do cliente é ignorado", true, "10.0.0.2:4000", "1.2.3.4, 198.51.100.1, 192.168.1.1", "198.51.100.1"},
+		{"cabeçalho inválido fica no proxy", true, "10.0.0.2:4000", "nao-e-ip", "10.0.0.2"},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/alunos/aluno-1/consentimentos", nil)
+			req.RemoteAddr = tc.remoto
+			req.Header.Set("X-Forwarded-For", tc.encaminhado)
+			h := NewConsentimentoHandler(nil)
+			if tc.proxies {
+				h.ProxiesConfiaveis = proxies
+			}
+			if ip := h.ipDaRequisicao(req); ip != tc.esperado {
+				t.Errorf("IP = %q, esperado %q", ip, tc.esperado)
+			}
+		})
+	}
+
+	if _, err := LerRedes("10.0.0.0/33"); err == nil {
+		t.Error("rede inválida deveria falhar")
+	}
+}
+
+func TestHandlerListarConsentimentosAluno(t *testing.T) {
+	casos := []struct {
+		nome   string
+		ator   reqctx.Ator
+		status int
+	}{
+		{"o próprio aluno", reqctx.Ator{ID: "aluno-1", Papel: reqctx.PapelAluno}, http.StatusOK},
+		{"admin", reqctx.Ator{ID: "adm", Papel: reqctx.PapelAdmin}, http.StatusOK},
+		{"outro aluno", reqctx.Ator{ID: "aluno-2", Papel: reqctx.PapelAluno}, http.StatusForbidden},
+		{"psicólogo", reqctx.Ator{ID: "p1", Papel: reqctx.PapelPsicologo}, http.StatusForbidden},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/alunos/aluno-1/consentimentos", nil)
+			req.SetPathValue("id", "aluno-1")
+			req = req.WithContext(reqctx.ComAtor(req.Context(), tc.ator))
+			rr := httptest.NewRecorder()
+
+			repo := novoConsentimentoRepoTeste(map[int]bool{2: true})
+			listou := false
+			repo.ListarAceitesPorAlunoFunc = func(ctx context.Context, alunoID string) ([]*model.AceiteConsentimento, error) {
+				listou = true
+				return []*model.AceiteConsentimento{{AlunoID: alunoID, Versao: 2}}, nil
+			}
+			NewConsentimentoHandler(repo).HandlerListarConsentimentosAluno(rr, req)
+
+			if rr.Code != tc.status {
+				t.Errorf("status code incorreto: obteve %v, esperava %v", rr.Code, tc.status)
+			}
+			if listou != (tc.status == http.StatusOK) {
+				t.Errorf("aceites lidos = %v com status %d", listou, rr.Code)
+			}
+		})
+	}
+}
+
+func TestHandlerAgendarConsultaSemConsentimento(t *testing.T) {
+	payloadJSON, _ := json.Marshal(map[string]string{"alunoId": "aluno-1", "horarioId": "h1"})
+
+	t.Run("termo novo ainda nao aceito", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/consultas", bytes.NewBuffer(payloadJSON))
+		rr := httptest.NewRecorder()
+
+		h := NewConsultaHandler(&mocks.ConsultaRepositoryMock{}, &mocks.AlunoRepositoryMock{}, &mocks.PsicologoRepositoryMock{}, nil)
+		// O aluno aceitou apenas a versão 1; a vigente é a 2.
+		h.ConsentimentoRepo = novoConsentimentoRepoTeste(map[int]bool{1: true})
+		h.HandlerAgendarConsulta(rr, req)
+
+		if status := rr.Code; status != http.StatusUnprocessableEntity {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusUnprocessableEntity)
+		}
+	})
+}
@@ -3,11 +3,15 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"sgp/Internal/model"
 	"sgp/Internal/repository"
+	"sgp/Internal/reqctx"
 	"sgp/Internal/service" // Novo import para o serviço de e-mail
+	"sort"
 	"time"
 )
 
@@ -18,6 +22,33 @@ type ConsultaHandler struct {
 	AlunoRepo     repository.AlunoRepository     // Dependência adicionada
 	PsicologoRepo repository.PsicologoRepository // Dependência adicionada
 	EmailService  *service.EmailService          // Dependência adicionada
+
+	// Dependências opcionais: quando nil, a regra correspondente não é aplicada.
+	ConsentimentoRepo repository.ConsentimentoRepository
+	HorarioRepo       repository.HorarioDisponivelRepository
+	FormularioRepo    repository.FormularioRepository // exige HorarioRepo
+
+	// Pedidos urgentes vão para a escala de plantão; sem ela, EmailsPlantao
+	// recebe o alerta diretamente.
+	Plantao       *service.PlantaoService
+	EmailsPlantao []string
+
+	// Politica é opcional; as regras de agendamento por aluno exigem HorarioRepo.
+	Politica *service.PoliticaAgendamento
+
+	// Reunioes gera o link das consultas online quando são confirmadas.
+	Reunioes service.MeetingProvider
+
+	// Calendario é opcional; quando presente, bloqueia pedidos em feriados e
+	// fechamentos (exige HorarioRepo).
+	Calendario *service.CalendarioService
+
+	// EpisodioRepo é opcional; quando presente, o agendamento com o psicólogo
+	// do episódio aberto do aluno entra nesse episódio (exige HorarioRepo).
+	EpisodioRepo repository.EpisodioRepository
+
+	// Tarefas acompanha os e-mails e alertas assíncronos; nil só os dispara.
+	Tarefas *service.Tarefas
 }
 
 // NewConsultaHandler atualizado com as novas dependências
@@ -37,8 +68,14 @@ func NewConsultaHandler(
 
 func (h *ConsultaHandler) HandlerAgendarConsulta(w http.ResponseWriter, r *http.Request) {
 	var payload struct {
-		AlunoID   string `json:"alunoId"`
-		HorarioID string `json:"horarioId"`
+		AlunoID             string            `json:"alunoId"`
+		HorarioID           string            `json:"horarioId"`
+		RespostasFormulario map[string]string `json:"respostasFormulario"`
+		Urgencia            string            `json:"urgencia"`
+		Triagem             map[string]bool   `json:"triagem"`
+		// QualquerPsicologo dispensa horarioId: o sistema escolhe o psicólogo
+		// elegível de menor carga e o primeiro horário livre dele.
+		QualquerPsicologo bool `json:"qualquerPsicologo"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
@@ -47,28 +84,193 @@ func (h *ConsultaHandler) HandlerAgendarConsulta(w http.ResponseWriter, r *http.
 	}
 	defer r.Body.Close()
 
-	if payload.AlunoID == "" || payload.HorarioID == "" {
+	if payload.AlunoID == "" || (payload.HorarioID == "" && !payload.QualquerPsicologo) {
 		http.Error(w, "campos alunoId e horarioId sao obrigatorios", http.StatusBadRequest)
 		return
 	}
 
+	urgencia, err := service.ClassificarUrgencia(payload.Urgencia, payload.Triagem)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
 	defer cancel()
 
+	if h.ConsentimentoRepo != nil {
+		pendente, err := consentimentoPendente(ctx, h.ConsentimentoRepo, payload.AlunoID)
+		if err != nil {
+			log.Printf("ERRO ao verificar consentimento do aluno: %v", err)
+			http.Error(w, "erro ao verificar termo de consentimento", http.StatusInternalServerError)
+			return
+		}
+		if pendente {
+			http.Error(w, "o aluno precisa aceitar a versao vigente do termo de consentimento", http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	if payload.HorarioID == "" {
+		if h.HorarioRepo == nil {
+			http.Error(w, "atribuicao automatica indisponivel", http.StatusServiceUnavailable)
+			return
+		}
+		aPartirDe := time.Now()
+		if h.Politica != nil {
+			aPartirDe = aPartirDe.Add(h.Politica.AntecedenciaMinima)
+		}
+		candidato, err := h.escolherHorario(ctx, payload.AlunoID, aPartirDe)
+		if err != nil {
+			log.Printf("ERRO ao escolher psicólogo para o aluno %s: %v", payload.AlunoID, err)
+			http.Error(w, "erro ao escolher psicologo", http.StatusInternalServerError)
+			return
+		}
+		if candidato == nil {
+			http.Error(w, "nenhum psicologo com horario livre e capacidade disponivel", http.StatusUnprocessableEntity)
+			return
+		}
+		payload.HorarioID = candidato.Horario.ID
+	}
+
+	// Regras que dependem do psicólogo dono do horário.
+	var formulario *model.FormularioInicial
+	var episodioID string
+	if h.HorarioRepo != nil {
+		horario, err := h.HorarioRepo.BuscarHorarioPorID(ctx, payload.HorarioID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		psicologo, err := h.PsicologoRepo.BuscarPsicologoPorID(ctx, horario.PsicologoID)
+		if err != nil {
+			log.Printf("ERRO ao buscar psicólogo do horário %s: %v", horario.ID, err)
+			http.Error(w, "psicologo do horario nao encontrado", http.StatusNotFound)
+			return
+		}
+		if !service.PsicologoAtivo(psicologo) {
+			http.Error(w, "o psicologo deste horario nao esta atendendo no momento", http.StatusUnprocessableEntity)
+			return
+		}
+
+		liberado, err := h.horarioLiberado(ctx, horario)
+		if err != nil {
+			log.Printf("ERRO ao consultar calendário: %v", err)
+			http.Error(w, "erro ao consultar calendario", http.StatusInternalServerError)
+			return
+		}
+		if !liberado {
+			http.Error(w, "o horario cai em um feriado ou fechamento da clinica", http.StatusUnprocessableEntity)
+			return
+		}
+
+		if h.Politica != nil {
+			consultasAluno, err := h.Repo.ListarConsultasPorAluno(ctx, payload.AlunoID)
+			if err != nil {
+				log.Printf("ERRO ao listar consultas do aluno %s: %v", payload.AlunoID, err)
+				http.Error(w, "erro ao verificar politica de agendamento", http.StatusInternalServerError)
+				return
+			}
+			if err := h.Politica.VerificarAgendamento(consultasAluno, horario.Inicio, time.Now()); err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+		}
+
+		// A checagem não é transacional: dois pedidos simultâneos podem passar
+		// juntos do limite por uma sessão, o que é aceitável aqui. Horários de
+		// grupo já têm a capacidade como limite.
+		if horario.Tipo != model.HorarioGrupo && (psicologo.LimiteSessoesSemana > 0 || psicologo.LimitePacientesAtivos > 0) {
+			consultas, err := h.Repo.ListarConsultasPorPsicologo(ctx, psicologo.ID, "")
+			if err != nil {
+				log.Printf("ERRO ao calcular carga do psicólogo %s: %v", psicologo.ID, err)
+				http.Error(w, "erro ao verificar carga do psicologo", http.StatusInternalServerError)
+				return
+			}
+			carga := service.CalcularCarga(consultas, time.Now())
+			if err := service.VerificarLimites(psicologo, carga, payload.AlunoID, horario.Inicio); err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+		}
+
+		// Formulário inicial: opcional ou obrigatório conforme o psicólogo.
+		if h.FormularioRepo != nil {
+			formulario, err = formularioDoPrimeiroAgendamento(ctx, h.FormularioRepo, h.Repo, payload.AlunoID, horario.PsicologoID)
+			if err != nil {
+				log.Printf("ERRO ao buscar formulário inicial: %v", err)
+				http.Error(w, "erro ao verificar formulario inicial", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		// Falhar aqui não impede o agendamento: a consulta só fica fora do episódio.
+		if h.EpisodioRepo != nil {
+			episodio, err := h.EpisodioRepo.BuscarEpisodioAberto(ctx, payload.AlunoID)
+			if err != nil {
+				log.Printf("ERRO ao buscar episódio aberto do aluno %s: %v", payload.AlunoID, err)
+			} else if episodio != nil && episodio.PsicologoID == horario.PsicologoID {
+				episodioID = episodio.ID
+			}
+		}
+
+		if formulario != nil {
+			if len(payload.RespostasFormulario) == 0 {
+				if formulario.Obrigatorio {
+					http.Error(w, "o psicologo exige o formulario inicial no primeiro agendamento", http.StatusUnprocessableEntity)
+					return
+				}
+				formulario = nil
+			} else if err := validarRespostas(formulario, payload.RespostasFormulario); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
 	consulta := model.Consulta{
-		AlunoID:   payload.AlunoID,
-		HorarioID: payload.HorarioID,
+		AlunoID:    payload.AlunoID,
+		HorarioID:  payload.HorarioID,
+		Urgencia:   urgencia,
+		EpisodioID: episodioID,
 	}
 
 	novaConsulta, err := h.Repo.AgendarConsulta(ctx, consulta)
+	if errors.Is(err, repository.ErrJaInscrito) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		log.Printf("ERRO ao agendar consulta: %v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	if formulario != nil {
+		_, err := h.FormularioRepo.RegistrarResposta(ctx, model.RespostaFormulario{
+			PsicologoID: novaConsulta.PsicologoID,
+			AlunoID:     novaConsulta.AlunoID,
+			ConsultaID:  novaConsulta.ID,
+			Respostas:   payload.RespostasFormulario,
+			EnviadoEm:   time.Now().UTC(),
+		})
+		if err != nil {
+			log.Printf("ERRO ao registrar formulário inicial da consulta %s: %v", novaConsulta.ID, err)
+			// O psicólogo exige o formulário: sem ele o agendamento é desfeito,
+			// e apagar a consulta devolve o horário.
+			if formulario.Obrigatorio {
+				if errDel := h.Repo.DeletarConsulta(ctx, novaConsulta.ID); errDel != nil {
+					log.Printf("ERRO ao desfazer a consulta %s sem formulário inicial: %v", novaConsulta.ID, errDel)
+				}
+				http.Error(w, "erro ao registrar formulario inicial; o agendamento nao foi feito", http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
 	// --- ENVIO DE EMAIL (ASSÍNCRONO) ---
-	go func() {
+	h.Tarefas.Disparar(func() {
 		// Contexto independente da requisição HTTP
 		bgCtx := context.Background()
 
@@ -76,13 +278,24 @@ func (h *ConsultaHandler) HandlerAgendarConsulta(w http.ResponseWriter, r *http.
 		aluno, errA := h.AlunoRepo.BuscarAlunoPorID(bgCtx, novaConsulta.AlunoID)
 		psico, errP := h.PsicologoRepo.BuscarPsicologoPorID(bgCtx, novaConsulta.PsicologoID)
 
-		if errA == nil && errP == nil {
-			dataFormatada := novaConsulta.Inicio.Format("02/01/2006 às 15:04")
+		if errA == nil && errP == nil && novaConsulta.Status == "lista de espera" {
+			errEmail := h.EmailService.EnviarNotificacaoAtualizacaoStatus(
+				aluno.ID,
+				aluno.Email,
+				service.NomeDeTratamento(aluno),
+				novaConsulta.Status,
+			)
+			if errEmail != nil {
+				log.Printf("ERRO ao enviar email (Resend): %v", errEmail)
+			}
+		} else if errA == nil && errP == nil {
+			dataFormatada := service.FormatarDataHora(novaConsulta.Inicio, service.Fuso(aluno.FusoHorario))
 
 			// Dispara o e-mail
 			errEmail := h.EmailService.EnviarNotificacaoAgendamento(
+				aluno.ID,
 				aluno.Email,
-				aluno.Nome,
+				service.NomeDeTratamento(aluno),
 				psico.Nome,
 				dataFormatada,
 			)
@@ -92,14 +305,134 @@ func (h *ConsultaHandler) HandlerAgendarConsulta(w http.ResponseWriter, r *http.
 		} else {
 			log.Printf("ERRO ao buscar dados para email de agendamento: AlunoErr: %v, PsicoErr: %v", errA, errP)
 		}
-	}()
+	})
 	// -----------------------------------
 
+	if novaConsulta.Urgencia == model.UrgenciaUrgente {
+		urgente := *novaConsulta
+		h.Tarefas.Disparar(func() { h.alertarPlantao(urgente) })
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(novaConsulta)
 }
 
+// escolherHorario monta os candidatos para o modo "qualquer psicólogo": para
+// cada psicólogo ativo, o primeiro horário após aPartirDe que caiba nos seus limites.
+func (h *ConsultaHandler) escolherHorario(ctx context.Context, alunoID string, aPartirDe time.Time) (*service.CandidatoAtribuicao, error) {
+	psicologos, err := h.PsicologoRepo.ListarPsicologos(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	agora := time.Now()
+	var candidatos []service.CandidatoAtribuicao
+	for _, p := range psicologos {
+		if !service.PsicologoAtivo(p) {
+			continue
+		}
+
+		horarios, err := h.HorarioRepo.ListarHorariosPorPsicologo(ctx, p.ID, "disponivel")
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(horarios, func(i, j int) bool { return horarios[i].Inicio.Before(horarios[j].Inicio) })
+
+		consultas, err := h.Repo.ListarConsultasPorPsicologo(ctx, p.ID, "")
+		if err != nil {
+			return nil, err
+		}
+		carga := service.CalcularCarga(consultas, agora)
+
+		for _, horario := range horarios {
+			if horario.Tipo == model.HorarioGrupo || !horario.Inicio.After(aPartirDe) || service.VerificarLimites(p, carga, alunoID, horario.Inicio) != nil {
+				continue
+			}
+			liberado, err := h.horarioLiberado(ctx, horario)
+			if err != nil {
+				return nil, err
+			}
+			if liberado {
+				candidatos = append(candidatos, service.CandidatoAtribuicao{Psicologo: p, Horario: horario, Carga: carga})
+				break
+			}
+		}
+	}
+	return service.EscolherCandidato(candidatos), nil
+}
+
+// horarioLiberado diz se o calendário institucional permite atender no horário.
+func (h *ConsultaHandler) horarioLiberado(ctx context.Context, horario *model.HorarioDisponivel) (bool, error) {
+	if h.Calendario == nil {
+		return true, nil
+	}
+	err := h.Calendario.VerificarHorario(ctx, horario.Inicio, horario.Fim)
+	if errors.Is(err, service.ErrDataBloqueada) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// HandlerPerguntasTriagem responde à rota GET /triagem/perguntas
+func (h *ConsultaHandler) HandlerPerguntasTriagem(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(service.PerguntasTriagem)
+}
+
+func (h *ConsultaHandler) alertarPlantao(consulta model.Consulta) {
+	if h.Plantao != nil {
+		_, err := h.Plantao.AbrirAlerta(context.Background(), model.AlertaPlantao{
+			Origem:       model.OrigemTriagem,
+			AlunoID:      consulta.AlunoID,
+			ReferenciaID: consulta.ID,
+			Motivo:       fmt.Sprintf("triagem com urgência %s no agendamento de %s", consulta.Urgencia, service.FormatarDataHora(consulta.Inicio, service.Fuso(""))),
+		})
+		if err != nil {
+			log.Printf("ERRO ao abrir alerta de plantão para consulta %s: %v", consulta.ID, err)
+		}
+		return
+	}
+
+	if len(h.EmailsPlantao) == 0 {
+		log.Printf("AVISO: consulta urgente %s sem equipe de plantão configurada", consulta.ID)
+		return
+	}
+
+	aluno, err := h.AlunoRepo.BuscarAlunoPorID(context.Background(), consulta.AlunoID)
+	if err != nil {
+		log.Printf("ERRO ao buscar aluno para alerta de urgência: %v", err)
+		return
+	}
+
+	dataFormatada := service.FormatarDataHora(consulta.Inicio, service.Fuso(""))
+	if err := h.EmailService.EnviarAlertaUrgencia(aluno.ID, h.EmailsPlantao, service.NomeDeTratamento(aluno), dataFormatada, consulta.Urgencia); err != nil {
+		log.Printf("ERRO ao enviar alerta de urgência (Resend): %v", err)
+	}
+}
+
+// NotificarPromocao avisa o aluno cujo pedido saiu da lista de espera de um
+// grupo. Em main, é ligada ao AoPromover do repositório de consultas.
+func (h *ConsultaHandler) NotificarPromocao(ctx context.Context, promovida model.Consulta) {
+	h.Tarefas.Disparar(func() {
+		aluno, err := h.AlunoRepo.BuscarAlunoPorID(context.Background(), promovida.AlunoID)
+		if err != nil {
+			log.Printf("ERRO ao buscar aluno promovido da lista de espera: %v", err)
+			return
+		}
+		errEmail := h.EmailService.EnviarNotificacaoAtualizacaoStatus(
+			aluno.ID,
+			aluno.Email,
+			service.NomeDeTratamento(aluno),
+			promovida.Status,
+		)
+		if errEmail != nil {
+			log.Printf("ERRO ao enviar email de promoção da lista de espera (Resend): %v", errEmail)
+		}
+	})
+}
+
 func (h *ConsultaHandler) HandlerListarConsultasPorPsicologo(w http.ResponseWriter, r *http.Request) {
 	log.Println("--- INÍCIO: HandlerListarConsultasPorPsicologo foi chamado ---")
 
@@ -112,6 +445,8 @@ func (h *ConsultaHandler) HandlerListarConsultasPorPsicologo(w http.ResponseWrit
 	log.Printf("Buscando consultas para o psicologoId: %s", psicologoId)
 
 	statusFiltro := r.URL.Query().Get("status")
+	urgenciaFiltro := r.URL.Query().Get("urgencia")
+	ordenar := r.URL.Query().Get("ordenar")
 
 	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
 	defer cancel()
@@ -124,6 +459,27 @@ func (h *ConsultaHandler) HandlerListarConsultasPorPsicologo(w http.ResponseWrit
 		return
 	}
 
+	if urgenciaFiltro != "" {
+		filtradas := []*model.Consulta{}
+		for _, c := range consultas {
+			if c.Urgencia == urgenciaFiltro || (c.Urgencia == "" && urgenciaFiltro == model.UrgenciaRotina) {
+				filtradas = append(filtradas, c)
+			}
+		}
+		consultas = filtradas
+	}
+
+	// Mais urgentes primeiro; dentro do mesmo nível, quem pediu antes.
+	if ordenar == "urgencia" {
+		sort.SliceStable(consultas, func(i, j int) bool {
+			pi, pj := service.PesoUrgencia(consultas[i].Urgencia), service.PesoUrgencia(consultas[j].Urgencia)
+			if pi != pj {
+				return pi > pj
+			}
+			return consultas[i].DataAgendamento.Before(consultas[j].DataAgendamento)
+		})
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(consultas)
@@ -184,15 +540,38 @@ func (h *ConsultaHandler) HandlerAtualizarStatusConsulta(w http.ResponseWriter,
 	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
 	defer cancel()
 
+	if h.Politica != nil && payload.Status == "cancelada pelo aluno" {
+		consulta, err := h.Repo.BuscarConsultaPorID(ctx, id)
+		if err != nil {
+			http.Error(w, "consulta nao encontrada", http.StatusNotFound)
+			return
+		}
+		// Sair da lista de espera não tem prazo.
+		if consulta.Status != "lista de espera" {
+			if err := h.Politica.VerificarCancelamento(consulta, time.Now()); err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+		}
+	}
+
 	// 1. Atualiza no banco
 	if err := h.Repo.AtualizaStatusConsulta(ctx, id, payload.Status); err != nil {
 		log.Printf("ERRO ao atualizar status da consulta: %v", err)
+		if errors.Is(err, repository.ErrStatusNaoPermitido) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
 		http.Error(w, "erro ao atualizar o status da consulta", http.StatusInternalServerError)
 		return
 	}
 
+	if payload.Status == "confirmada" && h.Reunioes != nil {
+		h.prepararReuniao(ctx, id)
+	}
+
 	// --- ENVIO DE EMAIL (ASSÍNCRONO) ---
-	go func() {
+	h.Tarefas.Disparar(func() {
 		bgCtx := context.Background()
 
 		// Busca a consulta para saber quem é o aluno
@@ -200,10 +579,13 @@ func (h *ConsultaHandler) HandlerAtualizarStatusConsulta(w http.ResponseWriter,
 		if err == nil {
 			// Busca os dados do aluno para pegar o e-mail
 			aluno, errA := h.AlunoRepo.BuscarAlunoPorID(bgCtx, consulta.AlunoID)
-			if errA == nil {
+			if errA == nil && payload.Status == "confirmada" {
+				h.enviarConfirmacao(bgCtx, consulta, aluno)
+			} else if errA == nil {
 				errEmail := h.EmailService.EnviarNotificacaoAtualizacaoStatus(
+					aluno.ID,
 					aluno.Email,
-					aluno.Nome,
+					service.NomeDeTratamento(aluno),
 					payload.Status,
 				)
 				if errEmail != nil {
@@ -215,13 +597,87 @@ func (h *ConsultaHandler) HandlerAtualizarStatusConsulta(w http.ResponseWriter,
 		} else {
 			log.Printf("ERRO ao buscar consulta para notificação de status: %v", err)
 		}
-	}()
+	})
 	// -----------------------------------
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"message": "status da consulta atualizado com sucesso"})
 }
 
+// prepararReuniao gera e grava o link de uma consulta online que ainda não tem
+// um. Falhas só são registradas: a confirmação já foi feita e o link pode ser
+// gerado de novo numa próxima confirmação.
+func (h *ConsultaHandler) prepararReuniao(ctx context.Context, id string) {
+	consulta, err := h.Repo.BuscarConsultaPorID(ctx, id)
+	if err != nil {
+		log.Printf("ERRO ao buscar consulta %s para gerar link de reunião: %v", id, err)
+		return
+	}
+	if consulta.Modalidade != model.ModalidadeOnline || consulta.LinkReuniao != "" {
+		return
+	}
+
+	link, err := h.Reunioes.GerarLink(*consulta)
+	if err != nil {
+		log.Printf("ERRO ao gerar link de reunião da consulta %s: %v", id, err)
+		return
+	}
+	if err := h.Repo.DefinirLinkReuniao(ctx, id, link); err != nil {
+		log.Printf("ERRO ao salvar link de reunião da consulta %s: %v", id, err)
+	}
+}
+
+func (h *ConsultaHandler) enviarConfirmacao(ctx context.Context, consulta *model.Consulta, aluno *model.Aluno) {
+	psico, err := h.PsicologoRepo.BuscarPsicologoPorID(ctx, consulta.PsicologoID)
+	if err != nil {
+		log.Printf("ERRO ao buscar psicólogo para confirmação da consulta %s: %v", consulta.ID, err)
+		return
+	}
+
+	errEmail := h.EmailService.EnviarConfirmacaoConsulta(
+		aluno.ID,
+		aluno.Email,
+		service.NomeDeTratamento(aluno),
+		psico.Nome,
+		service.FormatarDataHora(consulta.Inicio, service.Fuso(aluno.FusoHorario)),
+		consulta.LinkReuniao,
+		service.GerarICS(*consulta, psico.Nome),
+	)
+	if errEmail != nil {
+		log.Printf("ERRO ao enviar confirmação da consulta (Resend): %v", errEmail)
+	}
+}
+
+// HandlerConviteConsulta responde à rota GET /consultas/{id}/convite.ics
+func (h *ConsultaHandler) HandlerConviteConsulta(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	consulta, err := h.Repo.BuscarConsultaPorID(ctx, id)
+	if err != nil {
+		http.Error(w, "consulta nao encontrada", http.StatusNotFound)
+		return
+	}
+
+	ator := reqctx.AtorDe(ctx)
+	if ator.ID != consulta.AlunoID && ator.ID != consulta.PsicologoID && ator.Papel != reqctx.PapelAdmin {
+		http.Error(w, "acesso negado", http.StatusForbidden)
+		return
+	}
+
+	nomePsicologo := "psicólogo"
+	if psico, err := h.PsicologoRepo.BuscarPsicologoPorID(ctx, consulta.PsicologoID); err == nil {
+		nomePsicologo = psico.Nome
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="consulta-%s.ics"`, id))
+	w.WriteHeader(http.StatusOK)
+	w.Write(service.GerarICS(*consulta, nomePsicologo))
+}
+
 func (h *ConsultaHandler) HandlerDeletarConsulta(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	if id == "" {
@@ -232,6 +688,21 @@ func (h *ConsultaHandler) HandlerDeletarConsulta(w http.ResponseWriter, r *http.
 	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
 	defer cancel()
 
+	// Para o aluno, apagar a própria consulta é cancelar: vale o mesmo prazo.
+	if h.Politica != nil && reqctx.AtorDe(ctx).Papel == reqctx.PapelAluno {
+		consulta, err := h.Repo.BuscarConsultaPorID(ctx, id)
+		if err != nil {
+			http.Error(w, "consulta nao encontrada", http.StatusNotFound)
+			return
+		}
+		if service.ConsultaAtiva(consulta) {
+			if err := h.Politica.VerificarCancelamento(consulta, time.Now()); err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+		}
+	}
+
 	if err := h.Repo.DeletarConsulta(ctx, id); err != nil {
 		log.Printf("ERRO ao deletar consulta: %v", err)
 		http.Error(w, "erro ao deletar consulta", http.StatusInternalServerError)
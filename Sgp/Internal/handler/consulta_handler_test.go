@@ -5,10 +5,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"sgp/Internal/model"
+	"sgp/Internal/repository"
 	"sgp/Internal/repository/mocks"
+	"sgp/Internal/reqctx"
+	"sgp/Internal/service"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -123,6 +129,27 @@ func TestHandlerAtualizarStatusConsulta(t *testing.T) {
 			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusOK)
 		}
 	})
+
+	t.Run("transicao nao permitida", func(t *testing.T) {
+		req, _ := http.NewRequest("PATCH", "/consultas/consulta-1/status", bytes.NewBuffer(jsonBody))
+		req.SetPathValue("id", "consulta-1")
+		rr := httptest.NewRecorder()
+
+		mockRepo := &mocks.ConsultaRepositoryMock{
+			AtualizaStatusConsultaFunc: func(ctx context.Context, id string, novoStatus string) error {
+				return fmt.Errorf("erro ao atualizar status da consulta com ID '%s': %w", id, repository.ErrStatusNaoPermitido)
+			},
+		}
+
+		h := NewConsultaHandler(mockRepo, &mocks.AlunoRepositoryMock{}, &mocks.PsicologoRepositoryMock{}, nil)
+		h.Tarefas = &service.Tarefas{}
+		h.HandlerAtualizarStatusConsulta(rr, req)
+		h.Tarefas.Aguardar(context.Background())
+
+		if status := rr.Code; status != http.StatusConflict {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusConflict)
+		}
+	})
 }
 
 func TestHandlerDeletarConsulta(t *testing.T) {
@@ -144,4 +171,465 @@ func TestHandlerDeletarConsulta(t *testing.T) {
 			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusNoContent)
 		}
 	})
-}
\ No newline at end of file
+}
+func novoConsultaHandlerComCarga(psicologos []*model.Psicologo, horarios map[string][]*model.HorarioDisponivel, consultas map[string][]*model.Consulta, agendada *model.Consulta) *ConsultaHandler {
+	consultaRepo := &mocks.ConsultaRepositoryMock{
+		ListarConsultasPorPsicologoFunc: func(ctx context.Context, psicologoID string, statusFiltro string) ([]*model.Consulta, error) {
+			return consultas[psicologoID], nil
+		},
+		AgendarConsultaFunc: func(ctx context.Context, c model.Consulta) (*model.Consulta, error) {
+			*agendada = c
+			c.ID = "consulta-nova"
+			return &c, nil
+		},
+	}
+	// Erro na busca do aluno evita o uso do EmailService nil na goroutine
+	alunoRepo := &mocks.AlunoRepositoryMock{
+		BuscarAlunoPorIDFunc: func(ctx context.Context, id string) (*model.Aluno, error) {
+			return nil, errors.New("ignorar email no teste")
+		},
+	}
+	psicologoRepo := &mocks.PsicologoRepositoryMock{
+		ListarPsicologosFunc: func(ctx context.Context) ([]*model.Psicologo, error) {
+			return psicologos, nil
+		},
+		BuscarPsicologoPorIDFunc: func(ctx context.Context, id string) (*model.Psicologo, error) {
+			for _, p := range psicologos {
+				if p.ID == id {
+					return p, nil
+				}
+			}
+			return nil, errors.New("psicologo nao encontrado")
+		},
+	}
+
+	h := NewConsultaHandler(consultaRepo, alunoRepo, psicologoRepo, nil)
+	h.HorarioRepo = &mocks.HorarioDisponivelRepositoryMock{
+		ListarHorariosPorPsicologoFunc: func(ctx context.Context, psicologoID string, status string) ([]*model.HorarioDisponivel, error) {
+			return horarios[psicologoID], nil
+		},
+		BuscarHorarioPorIDFunc: func(ctx context.Context, id string) (*model.HorarioDisponivel, error) {
+			for _, lista := range horarios {
+				for _, h := range lista {
+					if h.ID == id {
+						return h, nil
+					}
+				}
+			}
+			return nil, errors.New("horario nao encontrado")
+		},
+	}
+	return h
+}
+
+func TestHandlerAgendarConsultaLimitesCarga(t *testing.T) {
+	inicio := time.Now().Add(48 * time.Hour)
+	psicologos := []*model.Psicologo{{ID: "psico-1", LimiteSessoesSemana: 2, LimitePacientesAtivos: 2}}
+	horarios := map[string][]*model.HorarioDisponivel{
+		"psico-1": {{ID: "h1", PsicologoID: "psico-1", Inicio: inicio, Status: "disponivel"}},
+	}
+
+	agendar := func(consultas []*model.Consulta) int {
+		var agendada model.Consulta
+		h := novoConsultaHandlerComCarga(psicologos, horarios, map[string][]*model.Consulta{"psico-1": consultas}, &agendada)
+		body, _ := json.Marshal(map[string]string{"alunoId": "aluno-1", "horarioId": "h1"})
+		req, _ := http.NewRequest("POST", "/consultas", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+		h.HandlerAgendarConsulta(rr, req)
+		return rr.Code
+	}
+
+	t.Run("limite semanal atingido", func(t *testing.T) {
+		cheia := []*model.Consulta{
+			{AlunoID: "aluno-1", Status: "confirmada", Inicio: inicio, Fim: inicio.Add(time.Hour)},
+			{AlunoID: "aluno-1", Status: "aguardando aprovacao", Inicio: inicio, Fim: inicio.Add(time.Hour)},
+		}
+		if code := agendar(cheia); code != http.StatusUnprocessableEntity {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", code, http.StatusUnprocessableEntity)
+		}
+	})
+
+	t.Run("limite de pacientes atingido", func(t *testing.T) {
+		outros := []*model.Consulta{
+			{AlunoID: "aluno-2", Status: "confirmada", Inicio: inicio.AddDate(0, 0, 14), Fim: inicio.AddDate(0, 0, 14)},
+			{AlunoID: "aluno-3", Status: "confirmada", Inicio: inicio.AddDate(0, 0, 21), Fim: inicio.AddDate(0, 0, 21)},
+		}
+		if code := agendar(outros); code != http.StatusUnprocessableEntity {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", code, http.StatusUnprocessableEntity)
+		}
+	})
+
+	t.Run("consultas canceladas não contam", func(t *testing.T) {
+		canceladas := []*model.Consulta{
+			{AlunoID: "aluno-2", Status: "cancelada pelo aluno", Inicio: inicio, Fim: inicio.Add(time.Hour)},
+			{AlunoID: "aluno-3", Status: "cancelada pelo aluno", Inicio: inicio, Fim: inicio.Add(time.Hour)},
+		}
+		if code := agendar(canceladas); code != http.StatusCreated {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", code, http.StatusCreated)
+		}
+	})
+}
+
+func TestHandlerAgendarConsultaQualquerPsicologo(t *testing.T) {
+	agora := time.Now()
+	psicologos := []*model.Psicologo{
+		{ID: "ocupado"},
+		{ID: "livre"},
+		{ID: "lotado", LimitePacientesAtivos: 1},
+	}
+	horarios := map[string][]*model.HorarioDisponivel{
+		"ocupado": {{ID: "h-ocupado", PsicologoID: "ocupado", Inicio: agora.Add(24 * time.Hour), Status: "disponivel"}},
+		"livre": {
+			{ID: "h-livre-tarde", PsicologoID: "livre", Inicio: agora.Add(72 * time.Hour), Status: "disponivel"},
+			{ID: "h-livre-passado", PsicologoID: "livre", Inicio: agora.Add(-time.Hour), Status: "disponivel"},
+			{ID: "h-livre-cedo", PsicologoID: "livre", Inicio: agora.Add(48 * time.Hour), Status: "disponivel"},
+		},
+		"lotado": {{ID: "h-lotado", PsicologoID: "lotado", Inicio: agora.Add(time.Hour), Status: "disponivel"}},
+	}
+	futuro := agora.Add(10 * 24 * time.Hour)
+	consultas := map[string][]*model.Consulta{
+		"ocupado": {{AlunoID: "aluno-2", Status: "confirmada", Inicio: futuro, Fim: futuro.Add(time.Hour)}},
+		"lotado":  {{AlunoID: "aluno-3", Status: "confirmada", Inicio: futuro, Fim: futuro.Add(time.Hour)}},
+	}
+
+	var agendada model.Consulta
+	h := novoConsultaHandlerComCarga(psicologos, horarios, consultas, &agendada)
+
+	body, _ := json.Marshal(map[string]interface{}{"alunoId": "aluno-1", "qualquerPsicologo": true})
+	req, _ := http.NewRequest("POST", "/consultas", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+	h.HandlerAgendarConsulta(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v (%s)", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	if agendada.HorarioID != "h-livre-cedo" {
+		t.Errorf("horário escolhido incorreto: obteve %s, esperava h-livre-cedo", agendada.HorarioID)
+	}
+}
+
+func TestHandlerAgendarConsultaPolitica(t *testing.T) {
+	agora := time.Now()
+	inicio := agora.Add(72 * time.Hour)
+	psicologos := []*model.Psicologo{{ID: "psico-1"}}
+
+	agendar := func(t *testing.T, horarioInicio time.Time, consultasAluno []*model.Consulta) *httptest.ResponseRecorder {
+		horarios := map[string][]*model.HorarioDisponivel{
+			"psico-1": {{ID: "h1", PsicologoID: "psico-1", Inicio: horarioInicio, Status: "disponivel"}},
+		}
+		var agendada model.Consulta
+		h := novoConsultaHandlerComCarga(psicologos, horarios, nil, &agendada)
+		h.Repo.(*mocks.ConsultaRepositoryMock).ListarConsultasPorAlunoFunc = func(ctx context.Context, alunoID string) ([]*model.Consulta, error) {
+			return consultasAluno, nil
+		}
+		politica := politicaTeste
+		h.Politica = &politica
+
+		body, _ := json.Marshal(map[string]string{"alunoId": "aluno-1", "horarioId": "h1"})
+		req, _ := http.NewRequest("POST", "/consultas", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+		h.HandlerAgendarConsulta(rr, req)
+		return rr
+	}
+
+	casos := []struct {
+		nome      string
+		inicio    time.Time
+		consultas []*model.Consulta
+		esperado  int
+	}{
+		{"dentro da política", inicio, nil, http.StatusCreated},
+		{"antecedência insuficiente", agora.Add(time.Hour), nil, http.StatusUnprocessableEntity},
+		{"pedidos ativos demais", inicio, []*model.Consulta{
+			{Status: "aguardando aprovacao", Inicio: inicio.AddDate(0, 0, 14), Fim: inicio.AddDate(0, 0, 14)},
+			{Status: "confirmada", Inicio: inicio.AddDate(0, 0, 21), Fim: inicio.AddDate(0, 0, 21)},
+		}, http.StatusUnprocessableEntity},
+		{"sessão na mesma semana", inicio, []*model.Consulta{
+			{Status: "confirmada", Inicio: inicio, Fim: inicio.Add(time.Hour)},
+		}, http.StatusUnprocessableEntity},
+		{"suspenso por faltas", inicio, []*model.Consulta{
+			{Status: "falta", Inicio: agora.AddDate(0, 0, -3)},
+			{Status: "falta", Inicio: agora.AddDate(0, 0, -10)},
+			{Status: "falta", Inicio: agora.AddDate(0, 0, -20)},
+		}, http.StatusUnprocessableEntity},
+		{"faltas antigas não suspendem", inicio, []*model.Consulta{
+			{Status: "falta", Inicio: agora.AddDate(0, 0, -100)},
+			{Status: "falta", Inicio: agora.AddDate(0, 0, -110)},
+			{Status: "falta", Inicio: agora.AddDate(0, 0, -120)},
+		}, http.StatusCreated},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if rr := agendar(t, c.inicio, c.consultas); rr.Code != c.esperado {
+				t.Errorf("status code incorreto: obteve %v, esperava %v (%s)", rr.Code, c.esperado, rr.Body.String())
+			}
+		})
+	}
+}
+
+// politicaTeste liga todas as regras; a PoliticaPadrao não limita nada.
+var politicaTeste = service.PoliticaAgendamento{
+	MaxPedidosAtivos:    2,
+	MaxSessoesSemana:    1,
+	AntecedenciaMinima:  12 * time.Hour,
+	PrazoCancelamento:   24 * time.Hour,
+	FaltasParaSuspensao: 3,
+	JanelaFaltas:        60 * 24 * time.Hour,
+	DuracaoSuspensao:    30 * 24 * time.Hour,
+}
+
+func TestHandlerCancelarConsultaForaDoPrazo(t *testing.T) {
+	body, _ := json.Marshal(map[string]string{"status": "cancelada pelo aluno"})
+	req, _ := http.NewRequest("PATCH", "/consultas/consulta-1/status", bytes.NewBuffer(body))
+	req.SetPathValue("id", "consulta-1")
+	rr := httptest.NewRecorder()
+
+	mockRepo := &mocks.ConsultaRepositoryMock{
+		BuscarConsultaPorIDFunc: func(ctx context.Context, id string) (*model.Consulta, error) {
+			return &model.Consulta{ID: id, Status: "confirmada", Inicio: time.Now().Add(2 * time.Hour)}, nil
+		},
+		AtualizaStatusConsultaFunc: func(ctx context.Context, id string, novoStatus string) error {
+			t.Error("o status não deveria ser atualizado")
+			return nil
+		},
+	}
+	h := NewConsultaHandler(mockRepo, &mocks.AlunoRepositoryMock{}, &mocks.PsicologoRepositoryMock{}, nil)
+	politica := politicaTeste
+	h.Politica = &politica
+	h.HandlerAtualizarStatusConsulta(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status code incorreto: obteve %v, esperava %v", rr.Code, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestHandlerDeletarConsultaForaDoPrazo(t *testing.T) {
+	casos := []struct {
+		nome     string
+		papel    string
+		politica service.PoliticaAgendamento
+		esperado int
+	}{
+		{"aluno fora do prazo", reqctx.PapelAluno, politicaTeste, http.StatusUnprocessableEntity},
+		{"admin nao tem prazo", reqctx.PapelAdmin, politicaTeste, http.StatusNoContent},
+		{"politica padrao nao limita", reqctx.PapelAluno, service.PoliticaPadrao, http.StatusNoContent},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			req, _ := http.NewRequest("DELETE", "/consultas/consulta-1", nil)
+			req.SetPathValue("id", "consulta-1")
+			req = req.WithContext(reqctx.ComAtor(req.Context(), reqctx.Ator{ID: "aluno-1", Papel: tc.papel}))
+			rr := httptest.NewRecorder()
+
+			apagada := false
+			mockRepo := &mocks.ConsultaRepositoryMock{
+				BuscarConsultaPorIDFunc: func(ctx context.Context, id string) (*model.Consulta, error) {
+					return &model.Consulta{ID: id, AlunoID: "aluno-1", Status: "confirmada", Inicio: time.Now().Add(2 * time.Hour)}, nil
+				},
+				DeletarConsultaFunc: func(ctx context.Context, id string) error {
+					apagada = true
+					return nil
+				},
+			}
+			h := NewConsultaHandler(mockRepo, &mocks.AlunoRepositoryMock{}, &mocks.PsicologoRepositoryMock{}, nil)
+			politica := tc.politica
+			h.Politica = &politica
+			h.HandlerDeletarConsulta(rr, req)
+
+			if rr.Code != tc.esperado {
+				t.Errorf("status code incorreto: obteve %v, esperava %v", rr.Code, tc.esperado)
+			}
+			if apagada != (tc.esperado == http.StatusNoContent) {
+				t.Errorf("consulta apagada = %v com status %v", apagada, rr.Code)
+			}
+		})
+	}
+}
+
+// confirmarComReunioes confirma a consulta c1 num handler novo e devolve os
+// links gravados, depois de esperar as tarefas em segundo plano.
+func confirmarComReunioes(t *testing.T, modalidade string) []string {
+	t.Helper()
+	reunioes, _ := service.NewJitsiProvider("", "segredo-de-teste")
+	var mu sync.Mutex
+	var links []string
+
+	mockRepo := &mocks.ConsultaRepositoryMock{
+		AtualizaStatusConsultaFunc: func(ctx context.Context, id string, novoStatus string) error {
+			return nil
+		},
+		BuscarConsultaPorIDFunc: func(ctx context.Context, id string) (*model.Consulta, error) {
+			return &model.Consulta{ID: "c1", AlunoID: "aluno-1", PsicologoID: "psico-1", Modalidade: modalidade}, nil
+		},
+		DefinirLinkReuniaoFunc: func(ctx context.Context, id string, link string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			links = append(links, link)
+			return nil
+		},
+	}
+	alunoRepo := &mocks.AlunoRepositoryMock{
+		BuscarAlunoPorIDFunc: func(ctx context.Context, id string) (*model.Aluno, error) {
+			return nil, errors.New("ignorar email no teste")
+		},
+	}
+	h := NewConsultaHandler(mockRepo, alunoRepo, &mocks.PsicologoRepositoryMock{}, nil)
+	h.Reunioes = reunioes
+	h.Tarefas = &service.Tarefas{}
+
+	body, _ := json.Marshal(map[string]string{"status": "confirmada"})
+	req, _ := http.NewRequest("PATCH", "/consultas/c1/status", bytes.NewBuffer(body))
+	req.SetPathValue("id", "c1")
+	rr := httptest.NewRecorder()
+	h.HandlerAtualizarStatusConsulta(rr, req)
+	if err := h.Tarefas.Aguardar(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v", status, http.StatusOK)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return links
+}
+
+func TestConfirmarConsultaOnlineGeraLink(t *testing.T) {
+	t.Run("online gera link deterministico", func(t *testing.T) {
+		primeiro, segundo := confirmarComReunioes(t, model.ModalidadeOnline), confirmarComReunioes(t, model.ModalidadeOnline)
+		if len(primeiro) != 1 || len(segundo) != 1 {
+			t.Fatalf("cada confirmação deveria gravar um link: %v e %v", primeiro, segundo)
+		}
+		if !strings.HasPrefix(primeiro[0], service.JitsiPadrao+"/sgp-") || primeiro[0] != segundo[0] {
+			t.Errorf("link deveria ser determinístico: %q e %q", primeiro[0], segundo[0])
+		}
+	})
+
+	t.Run("presencial nao gera link", func(t *testing.T) {
+		if links := confirmarComReunioes(t, model.ModalidadePresencial); len(links) != 0 {
+			t.Errorf("consulta presencial não deveria ganhar link: %v", links)
+		}
+	})
+}
+
+func TestHandlerConviteConsulta(t *testing.T) {
+	h := NewConsultaHandler(
+		&mocks.ConsultaRepositoryMock{
+			BuscarConsultaPorIDFunc: func(ctx context.Context, id string) (*model.Consulta, error) {
+				inicio := time.Date(2025, 6, 2, 17, 0, 0, 0, time.UTC)
+				return &model.Consulta{
+					ID: id, AlunoID: "aluno-1", PsicologoID: "psico-1", Inicio: inicio, Fim: inicio.Add(time.Hour),
+					Modalidade: model.ModalidadeOnline, LinkReuniao: "https://meet.jit.si/sgp-abc",
+				}, nil
+			},
+		},
+		&mocks.AlunoRepositoryMock{},
+		&mocks.PsicologoRepositoryMock{
+			BuscarPsicologoPorIDFunc: func(ctx context.Context, id string) (*model.Psicologo, error) {
+				return &model.Psicologo{ID: id, Nome: "Ana"}, nil
+			},
+		},
+		nil,
+	)
+
+	t.Run("aluno recebe convite com link", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/consultas/c1/convite.ics", nil)
+		req.SetPathValue("id", "c1")
+		req = req.WithContext(reqctx.ComAtor(req.Context(), reqctx.Ator{ID: "aluno-1"}))
+		rr := httptest.NewRecorder()
+		h.HandlerConviteConsulta(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Fatalf("status code incorreto: obteve %v, esperava %v", status, http.StatusOK)
+		}
+		ics := rr.Body.String()
+		for _, trecho := range []string{"DTSTART:20250602T170000Z", "LOCATION:https://meet.jit.si/sgp-abc", "SUMMARY:Consulta com Ana"} {
+			if !strings.Contains(ics, trecho) {
+				t.Errorf("convite sem %q:\n%s", trecho, ics)
+			}
+		}
+	})
+
+	t.Run("terceiro nao acessa", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/consultas/c1/convite.ics", nil)
+		req.SetPathValue("id", "c1")
+		req = req.WithContext(reqctx.ComAtor(req.Context(), reqctx.Ator{ID: "outro"}))
+		rr := httptest.NewRecorder()
+		h.HandlerConviteConsulta(rr, req)
+
+		if status := rr.Code; status != http.StatusForbidden {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusForbidden)
+		}
+	})
+}
+
+func TestHandlerAgendarConsultaEmGrupo(t *testing.T) {
+	inicio := time.Now().Add(48 * time.Hour)
+	// O limite semanal já estaria estourado se cada inscrito contasse como sessão.
+	psicologos := []*model.Psicologo{{ID: "psico-1", LimiteSessoesSemana: 1, LimitePacientesAtivos: 1}}
+	grupo := &model.HorarioDisponivel{
+		ID: "grupo-1", PsicologoID: "psico-1", Inicio: inicio, Fim: inicio.Add(90 * time.Minute),
+		Status: "disponivel", Tipo: model.HorarioGrupo, Capacidade: 8, Inscritos: 2,
+	}
+	horarios := map[string][]*model.HorarioDisponivel{"psico-1": {grupo}}
+	inscritos := []*model.Consulta{
+		{AlunoID: "aluno-2", HorarioID: "grupo-1", Grupo: true, Status: "confirmada", Inicio: inicio, Fim: grupo.Fim},
+		{AlunoID: "aluno-3", HorarioID: "grupo-1", Grupo: true, Status: "confirmada", Inicio: inicio, Fim: grupo.Fim},
+	}
+
+	t.Run("inscricao aceita", func(t *testing.T) {
+		var agendada model.Consulta
+		h := novoConsultaHandlerComCarga(psicologos, horarios, map[string][]*model.Consulta{"psico-1": inscritos}, &agendada)
+		body, _ := json.Marshal(map[string]string{"alunoId": "aluno-1", "horarioId": "grupo-1"})
+		req, _ := http.NewRequest("POST", "/consultas", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+		h.HandlerAgendarConsulta(rr, req)
+
+		if rr.Code != http.StatusCreated {
+			t.Errorf("status code incorreto: obteve %v, esperava %v (%s)", rr.Code, http.StatusCreated, rr.Body.String())
+		}
+	})
+
+	t.Run("aluno ja inscrito", func(t *testing.T) {
+		var agendada model.Consulta
+		h := novoConsultaHandlerComCarga(psicologos, horarios, nil, &agendada)
+		h.Repo.(*mocks.ConsultaRepositoryMock).AgendarConsultaFunc = func(ctx context.Context, c model.Consulta) (*model.Consulta, error) {
+			return nil, repository.ErrJaInscrito
+		}
+		body, _ := json.Marshal(map[string]string{"alunoId": "aluno-2", "horarioId": "grupo-1"})
+		req, _ := http.NewRequest("POST", "/consultas", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+		h.HandlerAgendarConsulta(rr, req)
+
+		if rr.Code != http.StatusConflict {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", rr.Code, http.StatusConflict)
+		}
+	})
+
+	t.Run("atribuicao automatica ignora grupos", func(t *testing.T) {
+		var agendada model.Consulta
+		h := novoConsultaHandlerComCarga(psicologos, horarios, nil, &agendada)
+		body, _ := json.Marshal(map[string]interface{}{"alunoId": "aluno-1", "qualquerPsicologo": true})
+		req, _ := http.NewRequest("POST", "/consultas", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+		h.HandlerAgendarConsulta(rr, req)
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", rr.Code, http.StatusUnprocessableEntity)
+		}
+	})
+}
+
+func TestLinkReuniaoCompartilhadoNoGrupo(t *testing.T) {
+	provider, _ := service.NewJitsiProvider("", "segredo")
+	a, _ := provider.GerarLink(model.Consulta{ID: "c1", HorarioID: "grupo-1", Grupo: true})
+	b, _ := provider.GerarLink(model.Consulta{ID: "c2", HorarioID: "grupo-1", Grupo: true})
+	individual, _ := provider.GerarLink(model.Consulta{ID: "c3", HorarioID: "h-1"})
+	if a != b {
+		t.Errorf("inscritos do mesmo grupo deveriam ter o mesmo link: %s != %s", a, b)
+	}
+	if individual == a {
+		t.Errorf("consulta individual não deveria cair na sala do grupo")
+	}
+}
@@ -0,0 +1,555 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/reqctx"
+	"sgp/Internal/service"
+	"time"
+)
+
+// PrazoReservaEncaminhamento é quanto tempo o horário fica reservado ao
+// aluno esperando a resposta do destino; depois disso volta à agenda.
+const PrazoReservaEncaminhamento = 72 * time.Hour
+
+// EncaminhamentoHandler conduz os encaminhamentos entre psicólogos:
+// criado -> aceito ou recusado, e aceito -> concluido.
+type EncaminhamentoHandler struct {
+	Repo          repository.EncaminhamentoRepository
+	ConsultaRepo  repository.ConsultaRepository
+	HorarioRepo   repository.HorarioDisponivelRepository
+	EpisodioRepo  repository.EpisodioRepository
+	PsicologoRepo repository.PsicologoRepository
+
+	// Opcionais: fontes dos dados iniciais compartilhados com o destino.
+	FormularioRepo  repository.FormularioRepository
+	InstrumentoRepo repository.InstrumentoRepository
+}
+
+func NewEncaminhamentoHandler(
+	repo repository.EncaminhamentoRepository,
+	consultaRepo repository.ConsultaRepository,
+	horarioRepo repository.HorarioDisponivelRepository,
+	episodioRepo repository.EpisodioRepository,
+	psicologoRepo repository.PsicologoRepository,
+) *EncaminhamentoHandler {
+	return &EncaminhamentoHandler{
+		Repo:          repo,
+		ConsultaRepo:  consultaRepo,
+		HorarioRepo:   horarioRepo,
+		EpisodioRepo:  episodioRepo,
+		PsicologoRepo: psicologoRepo,
+	}
+}
+
+// HandlerCriarEncaminhamento responde à rota POST /encaminhamentos. O destino
+// é outro psicólogo (paraPsicologoId) ou um serviço externo (servicoExterno);
+// no primeiro caso, 'horarioId' reserva um primeiro horário dele para o aluno.
+func (h *EncaminhamentoHandler) HandlerCriarEncaminhamento(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		AlunoID         string `json:"alunoId"`
+		DePsicologoID   string `json:"dePsicologoId"`
+		ParaPsicologoID string `json:"paraPsicologoId"`
+		ServicoExterno  string `json:"servicoExterno"`
+		Motivo          string `json:"motivo"`
+		HorarioID       string `json:"horarioId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		httpError(w, "Requisição inválida", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	ator := reqctx.AtorDe(r.Context())
+	if payload.DePsicologoID == "" && ator.Papel == reqctx.PapelPsicologo {
+		payload.DePsicologoID = ator.ID
+	}
+	if payload.AlunoID == "" || payload.DePsicologoID == "" || payload.Motivo == "" {
+		httpError(w, "Os campos 'alunoId', 'dePsicologoId' e 'motivo' são obrigatórios", http.StatusBadRequest)
+		return
+	}
+	if (payload.ParaPsicologoID == "") == (payload.ServicoExterno == "") {
+		httpError(w, "Informe 'paraPsicologoId' ou 'servicoExterno', não os dois", http.StatusBadRequest)
+		return
+	}
+	if payload.ParaPsicologoID == payload.DePsicologoID {
+		httpError(w, "O psicólogo de destino deve ser outro", http.StatusBadRequest)
+		return
+	}
+	if payload.HorarioID != "" && payload.ParaPsicologoID == "" {
+		httpError(w, "Só encaminhamentos internos reservam horário", http.StatusBadRequest)
+		return
+	}
+	if ator.ID != payload.DePsicologoID && ator.Papel != reqctx.PapelAdmin {
+		httpError(w, "Apenas o psicólogo de origem ou um administrador pode encaminhar", http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	if payload.ParaPsicologoID != "" {
+		destino, err := h.PsicologoRepo.BuscarPsicologoPorID(ctx, payload.ParaPsicologoID)
+		if err != nil {
+			httpError(w, "Psicólogo de destino não encontrado", http.StatusNotFound)
+			return
+		}
+		if !service.PsicologoAtivo(destino) {
+			httpError(w, "O psicólogo de destino não está atendendo no momento", http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	if payload.HorarioID != "" {
+		horario, err := h.HorarioRepo.BuscarHorarioPorID(ctx, payload.HorarioID)
+		if err != nil {
+			httpError(w, "Horário não encontrado", http.StatusNotFound)
+			return
+		}
+		if horario.PsicologoID != payload.ParaPsicologoID {
+			httpError(w, "O horário não é do psicólogo de destino", http.StatusBadRequest)
+			return
+		}
+		err = h.HorarioRepo.ReservarHorario(ctx, horario.ID, payload.AlunoID, time.Now().Add(PrazoReservaEncaminhamento).UTC())
+		if errors.Is(err, repository.ErrHorarioIndisponivel) {
+			httpError(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			log.Printf("ERRO ao reservar horário %s: %v", horario.ID, err)
+			httpError(w, "Erro ao reservar horário", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	encaminhamento := model.Encaminhamento{
+		AlunoID:         payload.AlunoID,
+		DePsicologoID:   payload.DePsicologoID,
+		ParaPsicologoID: payload.ParaPsicologoID,
+		ServicoExterno:  payload.ServicoExterno,
+		Motivo:          payload.Motivo,
+		Status:          model.EncaminhamentoCriado,
+		HorarioID:       payload.HorarioID,
+		CriadoEm:        time.Now().UTC(),
+	}
+	episodio, err := h.EpisodioRepo.BuscarEpisodioAberto(ctx, payload.AlunoID)
+	if err != nil {
+		log.Printf("ERRO ao buscar episódio aberto do aluno %s: %v", payload.AlunoID, err)
+	} else if episodio != nil && episodio.PsicologoID == payload.DePsicologoID {
+		encaminhamento.EpisodioID = episodio.ID
+	}
+
+	criado, err := h.Repo.CriarEncaminhamento(ctx, encaminhamento)
+	if err != nil {
+		log.Printf("ERRO ao criar encaminhamento: %v", err)
+		h.liberarReserva(ctx, &encaminhamento)
+		httpError(w, "Erro ao criar encaminhamento", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(criado)
+}
+
+// HandlerBuscarEncaminhamento responde à rota GET /encaminhamentos/{id}.
+func (h *EncaminhamentoHandler) HandlerBuscarEncaminhamento(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	encaminhamento, err := h.Repo.BuscarEncaminhamentoPorID(ctx, r.PathValue("id"))
+	if err != nil {
+		httpError(w, "Encaminhamento não encontrado", http.StatusNotFound)
+		return
+	}
+	ator := reqctx.AtorDe(ctx)
+	if ator.ID != encaminhamento.AlunoID && !participaDoEncaminhamento(ator, encaminhamento) {
+		httpError(w, "Sem permissão para ver este encaminhamento", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(encaminhamento)
+}
+
+// HandlerListarEncaminhamentosPsicologo responde à rota GET /psicologos/{id}/encaminhamentos
+// com os encaminhamentos enviados e recebidos.
+func (h *EncaminhamentoHandler) HandlerListarEncaminhamentosPsicologo(w http.ResponseWriter, r *http.Request) {
+	psicologoID := r.PathValue("id")
+	ator := reqctx.AtorDe(r.Context())
+	if ator.ID != psicologoID && ator.Papel != reqctx.PapelAdmin {
+		httpError(w, "Sem permissão para ver os encaminhamentos deste psicólogo", http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	encaminhamentos, err := h.Repo.ListarEncaminhamentosPorPsicologo(ctx, psicologoID)
+	if err != nil {
+		log.Printf("ERRO ao listar encaminhamentos do psicólogo %s: %v", psicologoID, err)
+		httpError(w, "Erro ao listar encaminhamentos", http.StatusInternalServerError)
+		return
+	}
+	escreverEncaminhamentos(w, encaminhamentos)
+}
+
+// HandlerListarEncaminhamentosAluno responde à rota GET /alunos/{id}/encaminhamentos.
+func (h *EncaminhamentoHandler) HandlerListarEncaminhamentosAluno(w http.ResponseWriter, r *http.Request) {
+	alunoID := r.PathValue("id")
+	ator := reqctx.AtorDe(r.Context())
+	if ator.ID != alunoID && ator.Papel != reqctx.PapelPsicologo && ator.Papel != reqctx.PapelAdmin {
+		httpError(w, "Sem permissão para ver os encaminhamentos deste aluno", http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	encaminhamentos, err := h.Repo.ListarEncaminhamentosPorAluno(ctx, alunoID)
+	if err != nil {
+		log.Printf("ERRO ao listar encaminhamentos do aluno %s: %v", alunoID, err)
+		httpError(w, "Erro ao listar encaminhamentos", http.StatusInternalServerError)
+		return
+	}
+	escreverEncaminhamentos(w, encaminhamentos)
+}
+
+// HandlerAceitarEncaminhamento responde à rota POST /encaminhamentos/{id}/aceitar.
+// Em encaminhamentos internos, o horário reservado vira uma consulta antes do
+// aceite: se não der, a reserva é liberada, o horário sai do encaminhamento e
+// a resposta é 409, para o destino aceitar de novo sem ele. Depois do aceite
+// o episódio aberto do aluno passa ao destino (ou um novo é aberto); uma
+// falha nessa etapa é registrada e não desfaz o aceite.
+func (h *EncaminhamentoHandler) HandlerAceitarEncaminhamento(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	encaminhamento, ok := h.encaminhamentoParaResposta(ctx, w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	var consulta *model.Consulta
+	if encaminhamento.ParaPsicologoID != "" && encaminhamento.HorarioID != "" {
+		var err error
+		consulta, err = h.ConsultaRepo.AgendarConsulta(ctx, model.Consulta{
+			AlunoID:    encaminhamento.AlunoID,
+			HorarioID:  encaminhamento.HorarioID,
+			EpisodioID: encaminhamento.EpisodioID,
+		})
+		if err != nil {
+			log.Printf("ERRO ao agendar o horário reservado do encaminhamento %s: %v", encaminhamento.ID, err)
+			h.liberarReserva(ctx, encaminhamento)
+			encaminhamento.HorarioID = ""
+			if err := h.Repo.AtualizarEncaminhamento(ctx, encaminhamento.ID, model.EncaminhamentoCriado, *encaminhamento); err != nil {
+				log.Printf("ERRO ao retirar o horário do encaminhamento %s: %v", encaminhamento.ID, err)
+			}
+			httpError(w, "O horário reservado não pôde ser agendado e foi liberado; aceite novamente sem horário", http.StatusConflict)
+			return
+		}
+		encaminhamento.ConsultaID = consulta.ID
+	}
+
+	encaminhamento.Status = model.EncaminhamentoAceito
+	encaminhamento.RespondidoEm = time.Now().UTC()
+	if !h.salvar(ctx, w, encaminhamento, model.EncaminhamentoCriado) {
+		if consulta != nil {
+			if err := h.ConsultaRepo.DeletarConsulta(ctx, consulta.ID); err != nil {
+				log.Printf("ERRO ao desfazer a consulta %s do encaminhamento %s: %v", consulta.ID, encaminhamento.ID, err)
+			}
+		}
+		return
+	}
+
+	if encaminhamento.ParaPsicologoID != "" {
+		if err := h.moverCaso(ctx, encaminhamento); err != nil {
+			log.Printf("ERRO ao passar o episódio do encaminhamento %s: %v", encaminhamento.ID, err)
+		} else if consulta != nil && consulta.EpisodioID != encaminhamento.EpisodioID {
+			if err := h.ConsultaRepo.DefinirEpisodio(ctx, consulta.ID, encaminhamento.EpisodioID); err != nil {
+				log.Printf("ERRO ao vincular a consulta %s ao episódio %s: %v", consulta.ID, encaminhamento.EpisodioID, err)
+			}
+		}
+		if err := h.Repo.AtualizarEncaminhamento(ctx, encaminhamento.ID, model.EncaminhamentoAceito, *encaminhamento); err != nil {
+			log.Printf("ERRO ao registrar o episódio do encaminhamento %s: %v", encaminhamento.ID, err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(encaminhamento)
+}
+
+// HandlerRecusarEncaminhamento responde à rota POST /encaminhamentos/{id}/recusar
+// e devolve o horário reservado à agenda do destino.
+func (h *EncaminhamentoHandler) HandlerRecusarEncaminhamento(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Motivo string `json:"motivo"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			httpError(w, "Requisição inválida", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	encaminhamento, ok := h.encaminhamentoParaResposta(ctx, w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	encaminhamento.Status = model.EncaminhamentoRecusado
+	encaminhamento.RespondidoEm = time.Now().UTC()
+	encaminhamento.MotivoRecusa = payload.Motivo
+	if !h.salvar(ctx, w, encaminhamento, model.EncaminhamentoCriado) {
+		return
+	}
+	h.liberarReserva(ctx, encaminhamento)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(encaminhamento)
+}
+
+// HandlerConcluirEncaminhamento responde à rota POST /encaminhamentos/{id}/concluir.
+func (h *EncaminhamentoHandler) HandlerConcluirEncaminhamento(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	encaminhamento, err := h.Repo.BuscarEncaminhamentoPorID(ctx, r.PathValue("id"))
+	if err != nil {
+		httpError(w, "Encaminhamento não encontrado", http.StatusNotFound)
+		return
+	}
+	if !participaDoEncaminhamento(reqctx.AtorDe(ctx), encaminhamento) {
+		httpError(w, "Apenas os psicólogos do encaminhamento ou um administrador podem concluí-lo", http.StatusForbidden)
+		return
+	}
+	if encaminhamento.Status != model.EncaminhamentoAceito {
+		httpError(w, "Só encaminhamentos aceitos podem ser concluídos", http.StatusConflict)
+		return
+	}
+
+	encaminhamento.Status = model.EncaminhamentoConcluido
+	encaminhamento.ConcluidoEm = time.Now().UTC()
+	if !h.salvar(ctx, w, encaminhamento, model.EncaminhamentoAceito) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(encaminhamento)
+}
+
+// HandlerConsentimentoEncaminhamento responde à rota POST /encaminhamentos/{id}/consentimento.
+// Só o aluno autoriza (ou revoga) o compartilhamento dos dados iniciais.
+func (h *EncaminhamentoHandler) HandlerConsentimentoEncaminhamento(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Autorizado *bool `json:"autorizado"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.Autorizado == nil {
+		httpError(w, "O campo 'autorizado' é obrigatório", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	encaminhamento, err := h.Repo.BuscarEncaminhamentoPorID(ctx, r.PathValue("id"))
+	if err != nil {
+		httpError(w, "Encaminhamento não encontrado", http.StatusNotFound)
+		return
+	}
+	if reqctx.AtorDe(ctx).ID != encaminhamento.AlunoID {
+		httpError(w, "Apenas o aluno pode autorizar o compartilhamento", http.StatusForbidden)
+		return
+	}
+
+	encaminhamento.CompartilharDados = *payload.Autorizado
+	encaminhamento.ConsentimentoEm = time.Now().UTC()
+	if !h.salvar(ctx, w, encaminhamento, encaminhamento.Status) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(encaminhamento)
+}
+
+// HandlerDadosIniciaisEncaminhamento responde à rota GET /encaminhamentos/{id}/dados-iniciais.
+// Exige o consentimento do aluno e só atende o psicólogo de destino. Só vai o
+// acolhimento feito com a origem: a resposta ao formulário inicial dela e a
+// primeira aplicação respondida de cada instrumento que ela atribuiu.
+func (h *EncaminhamentoHandler) HandlerDadosIniciaisEncaminhamento(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	encaminhamento, err := h.Repo.BuscarEncaminhamentoPorID(ctx, r.PathValue("id"))
+	if err != nil {
+		httpError(w, "Encaminhamento não encontrado", http.StatusNotFound)
+		return
+	}
+	ator := reqctx.AtorDe(ctx)
+	if encaminhamento.ParaPsicologoID == "" || ator.ID != encaminhamento.ParaPsicologoID {
+		httpError(w, "Apenas o psicólogo de destino pode ver os dados iniciais", http.StatusForbidden)
+		return
+	}
+	if encaminhamento.Status == model.EncaminhamentoRecusado {
+		httpError(w, "O encaminhamento foi recusado", http.StatusConflict)
+		return
+	}
+	if !encaminhamento.CompartilharDados {
+		httpError(w, "O aluno não autorizou o compartilhamento dos dados iniciais", http.StatusForbidden)
+		return
+	}
+
+	dados := model.DadosIniciaisEncaminhamento{
+		Encaminhamento: encaminhamento,
+		Formularios:    []*model.RespostaFormulario{},
+		Instrumentos:   []*model.AplicacaoInstrumento{},
+	}
+	if h.FormularioRepo != nil {
+		formularios, err := h.FormularioRepo.ListarRespostasPorAluno(ctx, encaminhamento.AlunoID)
+		if err != nil {
+			log.Printf("ERRO ao buscar formulários do aluno %s: %v", encaminhamento.AlunoID, err)
+			httpError(w, "Erro ao buscar dados iniciais", http.StatusInternalServerError)
+			return
+		}
+		for _, f := range formularios {
+			if f.PsicologoID == encaminhamento.DePsicologoID {
+				dados.Formularios = append(dados.Formularios, f)
+			}
+		}
+	}
+	if h.InstrumentoRepo != nil {
+		instrumentos, err := h.InstrumentoRepo.ListarAplicacoesPorAluno(ctx, encaminhamento.AlunoID, "")
+		if err != nil {
+			log.Printf("ERRO ao buscar instrumentos do aluno %s: %v", encaminhamento.AlunoID, err)
+			httpError(w, "Erro ao buscar dados iniciais", http.StatusInternalServerError)
+			return
+		}
+		dados.Instrumentos = append(dados.Instrumentos, aplicacoesIniciais(instrumentos, encaminhamento.DePsicologoID)...)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dados)
+}
+
+// encaminhamentoParaResposta busca um encaminhamento ainda sem resposta e
+// confere quem responde: o destino, nos internos; a origem, nos externos, que
+// registra a resposta do serviço. Em caso de falha já escreve a resposta.
+func (h *EncaminhamentoHandler) encaminhamentoParaResposta(ctx context.Context, w http.ResponseWriter, id string) (*model.Encaminhamento, bool) {
+	encaminhamento, err := h.Repo.BuscarEncaminhamentoPorID(ctx, id)
+	if err != nil {
+		httpError(w, "Encaminhamento não encontrado", http.StatusNotFound)
+		return nil, false
+	}
+
+	responsavel := encaminhamento.ParaPsicologoID
+	if responsavel == "" {
+		responsavel = encaminhamento.DePsicologoID
+	}
+	ator := reqctx.AtorDe(ctx)
+	if ator.ID != responsavel && ator.Papel != reqctx.PapelAdmin {
+		httpError(w, "Apenas o psicólogo de destino ou um administrador pode responder", http.StatusForbidden)
+		return nil, false
+	}
+	if encaminhamento.Status != model.EncaminhamentoCriado {
+		httpError(w, "O encaminhamento já foi respondido", http.StatusConflict)
+		return nil, false
+	}
+	return encaminhamento, true
+}
+
+// salvar grava o encaminhamento se o status ainda for esperado. Em caso de
+// falha já escreve a resposta.
+func (h *EncaminhamentoHandler) salvar(ctx context.Context, w http.ResponseWriter, encaminhamento *model.Encaminhamento, esperado string) bool {
+	err := h.Repo.AtualizarEncaminhamento(ctx, encaminhamento.ID, esperado, *encaminhamento)
+	if errors.Is(err, repository.ErrTransicaoInvalida) {
+		httpError(w, "O encaminhamento mudou de status; tente novamente", http.StatusConflict)
+		return false
+	}
+	if err != nil {
+		log.Printf("ERRO ao atualizar encaminhamento %s: %v", encaminhamento.ID, err)
+		httpError(w, "Erro ao atualizar encaminhamento", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// moverCaso passa o episódio aberto do aluno ao psicólogo de destino, ou abre
+// um novo com ele, e guarda o ID em encaminhamento.EpisodioID.
+func (h *EncaminhamentoHandler) moverCaso(ctx context.Context, encaminhamento *model.Encaminhamento) error {
+	episodio, err := h.EpisodioRepo.AssumirEpisodio(ctx, encaminhamento.AlunoID, model.TransferenciaEpisodio{
+		ParaPsicologoID: encaminhamento.ParaPsicologoID,
+		Motivo:          "encaminhamento: " + encaminhamento.Motivo,
+		AtorID:          reqctx.AtorDe(ctx).ID,
+		Em:              time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	encaminhamento.EpisodioID = episodio.ID
+	return nil
+}
+
+func (h *EncaminhamentoHandler) liberarReserva(ctx context.Context, encaminhamento *model.Encaminhamento) {
+	if encaminhamento.HorarioID == "" {
+		return
+	}
+	if err := h.HorarioRepo.LiberarReserva(ctx, encaminhamento.HorarioID, encaminhamento.AlunoID); err != nil {
+		log.Printf("ERRO ao liberar horário %s reservado pelo encaminhamento: %v", encaminhamento.HorarioID, err)
+	}
+}
+
+// aplicacoesIniciais escolhe, para cada instrumento, a primeira aplicação
+// respondida entre as atribuídas pelo psicólogo.
+func aplicacoesIniciais(aplicacoes []*model.AplicacaoInstrumento, psicologoID string) []*model.AplicacaoInstrumento {
+	primeiras := map[string]*model.AplicacaoInstrumento{}
+	var ordem []string
+	for _, a := range aplicacoes {
+		if a.PsicologoID != psicologoID || a.Status != "respondido" {
+			continue
+		}
+		atual, ok := primeiras[a.InstrumentoID]
+		if !ok {
+			ordem = append(ordem, a.InstrumentoID)
+		}
+		if !ok || a.RespondidoEm.Before(atual.RespondidoEm) {
+			primeiras[a.InstrumentoID] = a
+		}
+	}
+	iniciais := make([]*model.AplicacaoInstrumento, 0, len(ordem))
+	for _, id := range ordem {
+		iniciais = append(iniciais, primeiras[id])
+	}
+	return iniciais
+}
+
+func participaDoEncaminhamento(ator reqctx.Ator, encaminhamento *model.Encaminhamento) bool {
+	if ator.Papel == reqctx.PapelAdmin {
+		return true
+	}
+	return ator.ID != "" && (ator.ID == encaminhamento.DePsicologoID || ator.ID == encaminhamento.ParaPsicologoID)
+}
+
+func escreverEncaminhamentos(w http.ResponseWriter, encaminhamentos []*model.Encaminhamento) {
+	if encaminhamentos == nil {
+		encaminhamentos = []*model.Encaminhamento{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(encaminhamentos)
+}
@@ -0,0 +1,326 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/repository/mocks"
+	"sgp/Internal/reqctx"
+	"sgp/Internal/service"
+	"testing"
+	"time"
+)
+
+// cenarioEncaminhamento guarda o estado que os mocks alteram.
+type cenarioEncaminhamento struct {
+	encaminhamento *model.Encaminhamento
+	episodio       *model.Episodio
+	reservas       map[string]string
+	agendada       *model.Consulta
+}
+
+func novoEncaminhamentoHandler(c *cenarioEncaminhamento) *EncaminhamentoHandler {
+	return NewEncaminhamentoHandler(
+		&mocks.EncaminhamentoRepositoryMock{
+			CriarEncaminhamentoFunc: func(ctx context.Context, e model.Encaminhamento) (*model.Encaminhamento, error) {
+				e.ID = "enc-1"
+				c.encaminhamento = &e
+				return &e, nil
+			},
+			BuscarEncaminhamentoPorIDFunc: func(ctx context.Context, id string) (*model.Encaminhamento, error) {
+				if c.encaminhamento == nil || c.encaminhamento.ID != id {
+					return nil, errors.New("encaminhamento não encontrado")
+				}
+				copia := *c.encaminhamento
+				return &copia, nil
+			},
+			AtualizarEncaminhamentoFunc: func(ctx context.Context, id string, esperado string, e model.Encaminhamento) error {
+				if c.encaminhamento.Status != esperado {
+					return repository.ErrTransicaoInvalida
+				}
+				c.encaminhamento = &e
+				return nil
+			},
+		},
+		&mocks.ConsultaRepositoryMock{
+			AgendarConsultaFunc: func(ctx context.Context, consulta model.Consulta) (*model.Consulta, error) {
+				if c.reservas[consulta.HorarioID] != consulta.AlunoID {
+					return nil, repository.ErrHorarioIndisponivel
+				}
+				delete(c.reservas, consulta.HorarioID)
+				consulta.ID = "consulta-1"
+				c.agendada = &consulta
+				return &consulta, nil
+			},
+			DeletarConsultaFunc: func(ctx context.Context, id string) error {
+				c.agendada = nil
+				return nil
+			},
+			DefinirEpisodioFunc: func(ctx context.Context, id string, episodioID string) error {
+				c.agendada.EpisodioID = episodioID
+				return nil
+			},
+		},
+		&mocks.HorarioDisponivelRepositoryMock{
+			BuscarHorarioPorIDFunc: func(ctx context.Context, id string) (*model.HorarioDisponivel, error) {
+				return &model.HorarioDisponivel{ID: id, PsicologoID: "psico-2", Status: "disponivel"}, nil
+			},
+			ReservarHorarioFunc: func(ctx context.Context, id string, alunoID string, ate time.Time) error {
+				if _, ok := c.reservas[id]; ok {
+					return repository.ErrHorarioIndisponivel
+				}
+				c.reservas[id] = alunoID
+				return nil
+			},
+			LiberarReservaFunc: func(ctx context.Context, id string, alunoID string) error {
+				if c.reservas[id] == alunoID {
+					delete(c.reservas, id)
+				}
+				return nil
+			},
+		},
+		&mocks.EpisodioRepositoryMock{
+			BuscarEpisodioAbertoFunc: func(ctx context.Context, alunoID string) (*model.Episodio, error) {
+				if c.episodio == nil {
+					return nil, nil
+				}
+				copia := *c.episodio
+				return &copia, nil
+			},
+			AssumirEpisodioFunc: func(ctx context.Context, alunoID string, t model.TransferenciaEpisodio) (*model.Episodio, error) {
+				if c.episodio == nil {
+					c.episodio = &model.Episodio{ID: "ep-novo", AlunoID: alunoID, PsicologoID: t.ParaPsicologoID, Status: model.EpisodioAberto}
+				} else if c.episodio.PsicologoID != t.ParaPsicologoID {
+					t.DePsicologoID = c.episodio.PsicologoID
+					c.episodio.Transferencias = append(c.episodio.Transferencias, t)
+					c.episodio.PsicologoID = t.ParaPsicologoID
+				}
+				copia := *c.episodio
+				return &copia, nil
+			},
+		},
+		&mocks.PsicologoRepositoryMock{
+			BuscarPsicologoPorIDFunc: func(ctx context.Context, id string) (*model.Psicologo, error) {
+				return &model.Psicologo{ID: id}, nil
+			},
+		},
+	)
+}
+
+func enviarEncaminhamento(handlerFunc http.HandlerFunc, ator reqctx.Ator, corpo interface{}) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(corpo)
+	req, _ := http.NewRequest("POST", "/encaminhamentos", bytes.NewBuffer(body))
+	req.SetPathValue("id", "enc-1")
+	req = req.WithContext(reqctx.ComAtor(req.Context(), ator))
+	rr := httptest.NewRecorder()
+	handlerFunc(rr, req)
+	return rr
+}
+
+func TestFluxoEncaminhamentoInterno(t *testing.T) {
+	origem := reqctx.Ator{ID: "psico-1", Papel: reqctx.PapelPsicologo}
+	destino := reqctx.Ator{ID: "psico-2", Papel: reqctx.PapelPsicologo}
+	c := &cenarioEncaminhamento{
+		episodio: &model.Episodio{ID: "ep-1", AlunoID: "aluno-1", PsicologoID: "psico-1", Status: model.EpisodioAberto},
+		reservas: map[string]string{},
+	}
+	h := novoEncaminhamentoHandler(c)
+
+	rr := enviarEncaminhamento(h.HandlerCriarEncaminhamento, origem, map[string]string{
+		"alunoId": "aluno-1", "paraPsicologoId": "psico-2", "motivo": "demanda de neuropsicologia", "horarioId": "h1",
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v (%s)", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	if c.reservas["h1"] != "aluno-1" || c.encaminhamento.EpisodioID != "ep-1" || c.encaminhamento.DePsicologoID != "psico-1" {
+		t.Fatalf("encaminhamento criado incorretamente: %+v, reservas %v", c.encaminhamento, c.reservas)
+	}
+
+	if rr := enviarEncaminhamento(h.HandlerDadosIniciaisEncaminhamento, destino, nil); rr.Code != http.StatusForbidden {
+		t.Errorf("dados iniciais sem consentimento: obteve %v, esperava %v", rr.Code, http.StatusForbidden)
+	}
+	aluno := reqctx.Ator{ID: "aluno-1", Papel: reqctx.PapelAluno}
+	if rr := enviarEncaminhamento(h.HandlerConsentimentoEncaminhamento, aluno, map[string]bool{"autorizado": true}); rr.Code != http.StatusOK {
+		t.Fatalf("consentimento: obteve %v, esperava %v", rr.Code, http.StatusOK)
+	}
+	if rr := enviarEncaminhamento(h.HandlerDadosIniciaisEncaminhamento, destino, nil); rr.Code != http.StatusOK {
+		t.Errorf("dados iniciais com consentimento: obteve %v, esperava %v", rr.Code, http.StatusOK)
+	}
+
+	if rr := enviarEncaminhamento(h.HandlerAceitarEncaminhamento, origem, nil); rr.Code != http.StatusForbidden {
+		t.Errorf("origem aceitando: obteve %v, esperava %v", rr.Code, http.StatusForbidden)
+	}
+	if rr := enviarEncaminhamento(h.HandlerAceitarEncaminhamento, destino, nil); rr.Code != http.StatusOK {
+		t.Fatalf("aceite: obteve %v, esperava %v (%s)", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if c.episodio.PsicologoID != "psico-2" || len(c.episodio.Transferencias) != 1 {
+		t.Errorf("episódio não transferido: %+v", c.episodio)
+	}
+	if c.agendada == nil || c.agendada.EpisodioID != "ep-1" || c.encaminhamento.ConsultaID != "consulta-1" {
+		t.Errorf("horário reservado não virou consulta: %+v / %+v", c.agendada, c.encaminhamento)
+	}
+	if c.encaminhamento.Status != model.EncaminhamentoAceito {
+		t.Errorf("status incorreto: %q", c.encaminhamento.Status)
+	}
+
+	if rr := enviarEncaminhamento(h.HandlerAceitarEncaminhamento, destino, nil); rr.Code != http.StatusConflict {
+		t.Errorf("segundo aceite: obteve %v, esperava %v", rr.Code, http.StatusConflict)
+	}
+	if rr := enviarEncaminhamento(h.HandlerConcluirEncaminhamento, origem, nil); rr.Code != http.StatusOK {
+		t.Errorf("conclusão: obteve %v, esperava %v", rr.Code, http.StatusOK)
+	}
+}
+
+func TestHandlerRecusarEncaminhamento(t *testing.T) {
+	c := &cenarioEncaminhamento{reservas: map[string]string{}}
+	h := novoEncaminhamentoHandler(c)
+	origem := reqctx.Ator{ID: "psico-1", Papel: reqctx.PapelPsicologo}
+	destino := reqctx.Ator{ID: "psico-2", Papel: reqctx.PapelPsicologo}
+
+	enviarEncaminhamento(h.HandlerCriarEncaminhamento, origem, map[string]string{
+		"alunoId": "aluno-1", "paraPsicologoId": "psico-2", "motivo": "supervisão", "horarioId": "h1",
+	})
+	rr := enviarEncaminhamento(h.HandlerRecusarEncaminhamento, destino, map[string]string{"motivo": "agenda cheia"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v", rr.Code, http.StatusOK)
+	}
+	if len(c.reservas) != 0 || c.encaminhamento.Status != model.EncaminhamentoRecusado || c.encaminhamento.MotivoRecusa != "agenda cheia" {
+		t.Errorf("recusa registrada incorretamente: %+v, reservas %v", c.encaminhamento, c.reservas)
+	}
+}
+
+func TestHandlerCriarEncaminhamentoValidacao(t *testing.T) {
+	origem := reqctx.Ator{ID: "psico-1", Papel: reqctx.PapelPsicologo}
+	casos := []struct {
+		nome     string
+		ator     reqctx.Ator
+		corpo    map[string]string
+		esperado int
+	}{
+		{"destino e servico externo", origem, map[string]string{"alunoId": "aluno-1", "paraPsicologoId": "psico-2", "servicoExterno": "CAPS", "motivo": "x"}, http.StatusBadRequest},
+		{"para si mesmo", origem, map[string]string{"alunoId": "aluno-1", "paraPsicologoId": "psico-1", "motivo": "x"}, http.StatusBadRequest},
+		{"reserva em servico externo", origem, map[string]string{"alunoId": "aluno-1", "servicoExterno": "CAPS", "motivo": "x", "horarioId": "h1"}, http.StatusBadRequest},
+		{"aluno encaminhando", reqctx.Ator{ID: "aluno-1", Papel: reqctx.PapelAluno}, map[string]string{"alunoId": "aluno-1", "dePsicologoId": "psico-1", "servicoExterno": "CAPS", "motivo": "x"}, http.StatusForbidden},
+		{"servico externo", origem, map[string]string{"alunoId": "aluno-1", "servicoExterno": "CAPS", "motivo": "acompanhamento psiquiátrico"}, http.StatusCreated},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			h := novoEncaminhamentoHandler(&cenarioEncaminhamento{reservas: map[string]string{}})
+			if rr := enviarEncaminhamento(h.HandlerCriarEncaminhamento, tc.ator, tc.corpo); rr.Code != tc.esperado {
+				t.Errorf("status code incorreto: obteve %v, esperava %v (%s)", rr.Code, tc.esperado, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlerAceitarEncaminhamentoSemHorario(t *testing.T) {
+	origem := reqctx.Ator{ID: "psico-1", Papel: reqctx.PapelPsicologo}
+	destino := reqctx.Ator{ID: "psico-2", Papel: reqctx.PapelPsicologo}
+	c := &cenarioEncaminhamento{reservas: map[string]string{}}
+	h := novoEncaminhamentoHandler(c)
+
+	enviarEncaminhamento(h.HandlerCriarEncaminhamento, origem, map[string]string{
+		"alunoId": "aluno-1", "paraPsicologoId": "psico-2", "motivo": "supervisão", "horarioId": "h1",
+	})
+	// A reserva venceu e o horário foi pego por outro aluno.
+	c.reservas["h1"] = "aluno-2"
+
+	rr := enviarEncaminhamento(h.HandlerAceitarEncaminhamento, destino, nil)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v", rr.Code, http.StatusConflict)
+	}
+	if c.encaminhamento.Status != model.EncaminhamentoCriado || c.encaminhamento.HorarioID != "" || c.episodio != nil {
+		t.Errorf("aceite sem horário deveria ser desfeito: %+v, episódio %+v", c.encaminhamento, c.episodio)
+	}
+	if c.reservas["h1"] != "aluno-2" {
+		t.Errorf("reserva de outro aluno liberada: %v", c.reservas)
+	}
+
+	// Sem o horário, o destino aceita e o caso passa a ele.
+	if rr := enviarEncaminhamento(h.HandlerAceitarEncaminhamento, destino, nil); rr.Code != http.StatusOK {
+		t.Fatalf("segundo aceite: obteve %v, esperava %v (%s)", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if c.agendada != nil || c.episodio == nil || c.episodio.PsicologoID != "psico-2" || c.encaminhamento.EpisodioID != "ep-novo" {
+		t.Errorf("aceite incorreto: encaminhamento %+v, episódio %+v, consulta %+v", c.encaminhamento, c.episodio, c.agendada)
+	}
+}
+
+func TestHandlerDadosIniciaisSoAcolhimento(t *testing.T) {
+	c := &cenarioEncaminhamento{
+		encaminhamento: &model.Encaminhamento{
+			ID: "enc-1", AlunoID: "aluno-1", DePsicologoID: "psico-1", ParaPsicologoID: "psico-2",
+			Status: model.EncaminhamentoCriado, CompartilharDados: true,
+		},
+		reservas: map[string]string{},
+	}
+	h := novoEncaminhamentoHandler(c)
+	inicio := time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC)
+	h.FormularioRepo = &mocks.FormularioRepositoryMock{
+		ListarRespostasPorAlunoFunc: func(ctx context.Context, alunoID string) ([]*model.RespostaFormulario, error) {
+			return []*model.RespostaFormulario{
+				{ID: "origem", PsicologoID: "psico-1", AlunoID: alunoID},
+				{ID: "outro", PsicologoID: "psico-3", AlunoID: alunoID},
+			}, nil
+		},
+	}
+	h.InstrumentoRepo = &mocks.InstrumentoRepositoryMock{
+		ListarAplicacoesPorAlunoFunc: func(ctx context.Context, alunoID string, instrumentoID string) ([]*model.AplicacaoInstrumento, error) {
+			return []*model.AplicacaoInstrumento{
+				{ID: "phq9-depois", InstrumentoID: "phq9", PsicologoID: "psico-1", Status: "respondido", RespondidoEm: inicio.AddDate(0, 1, 0)},
+				{ID: "phq9-inicial", InstrumentoID: "phq9", PsicologoID: "psico-1", Status: "respondido", RespondidoEm: inicio},
+				{ID: "gad7-pendente", InstrumentoID: "gad7", PsicologoID: "psico-1", Status: "pendente"},
+				{ID: "gad7-outro", InstrumentoID: "gad7", PsicologoID: "psico-3", Status: "respondido", RespondidoEm: inicio},
+			}, nil
+		},
+	}
+
+	rr := enviarEncaminhamento(h.HandlerDadosIniciaisEncaminhamento, reqctx.Ator{ID: "psico-2", Papel: reqctx.PapelPsicologo}, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v", rr.Code, http.StatusOK)
+	}
+	var dados model.DadosIniciaisEncaminhamento
+	json.NewDecoder(rr.Body).Decode(&dados)
+	if len(dados.Formularios) != 1 || dados.Formularios[0].ID != "origem" {
+		t.Errorf("formulários incorretos: %+v", dados.Formularios)
+	}
+	if len(dados.Instrumentos) != 1 || dados.Instrumentos[0].ID != "phq9-inicial" {
+		t.Errorf("instrumentos incorretos: %+v", dados.Instrumentos)
+	}
+}
+
+func TestLiberarReservasVencidas(t *testing.T) {
+	agora := time.Now()
+	var liberados []string
+	s := service.NewReservaService(&mocks.HorarioDisponivelRepositoryMock{
+		ListarHorariosPorStatusFunc: func(ctx context.Context, status string) ([]*model.HorarioDisponivel, error) {
+			return []*model.HorarioDisponivel{
+				{ID: "vencida", Status: status, ReservadoAte: agora.Add(-time.Minute)},
+				{ID: "no-prazo", Status: status, ReservadoAte: agora.Add(time.Hour)},
+				{ID: "sem-prazo", Status: status},
+				{ID: "agendada-no-meio", Status: status, ReservadoAte: agora.Add(-time.Hour)},
+				{ID: "falha", Status: status, ReservadoAte: agora.Add(-time.Hour)},
+				{ID: "vencida-2", Status: status, ReservadoAte: agora.Add(-time.Hour)},
+			}, nil
+		},
+		ExpirarReservaFunc: func(ctx context.Context, id string, em time.Time) error {
+			switch id {
+			case "agendada-no-meio":
+				return repository.ErrTransicaoInvalida
+			case "falha":
+				return errors.New("falha de rede")
+			}
+			liberados = append(liberados, id)
+			return nil
+		},
+	})
+
+	n, err := s.LiberarReservasVencidas(context.Background(), agora)
+	if err != nil || n != 2 || len(liberados) != 2 || liberados[0] != "vencida" || liberados[1] != "vencida-2" {
+		t.Errorf("reservas liberadas incorretamente: n=%d err=%v liberados=%v", n, err, liberados)
+	}
+}
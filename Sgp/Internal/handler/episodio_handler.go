@@ -0,0 +1,359 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/reqctx"
+	"sgp/Internal/service"
+	"time"
+)
+
+// EpisodioHandler abre, encerra e transfere episódios de cuidado. Os
+// agendamentos seguintes do aluno com o psicólogo responsável entram no
+// episódio aberto automaticamente (veja ConsultaHandler.EpisodioRepo).
+type EpisodioHandler struct {
+	Repo          repository.EpisodioRepository
+	ConsultaRepo  repository.ConsultaRepository
+	PsicologoRepo repository.PsicologoRepository
+}
+
+func NewEpisodioHandler(
+	repo repository.EpisodioRepository,
+	consultaRepo repository.ConsultaRepository,
+	psicologoRepo repository.PsicologoRepository,
+) *EpisodioHandler {
+	return &EpisodioHandler{Repo: repo, ConsultaRepo: consultaRepo, PsicologoRepo: psicologoRepo}
+}
+
+// HandlerAbrirEpisodio responde à rota POST /episodios. As consultas ativas do
+// aluno com o psicólogo entram no episódio; consultas anteriores podem ser
+// incluídas em 'consultaIds'.
+func (h *EpisodioHandler) HandlerAbrirEpisodio(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		AlunoID           string   `json:"alunoId"`
+		PsicologoID       string   `json:"psicologoId"`
+		Objetivos         []string `json:"objetivos"`
+		SessoesPlanejadas int      `json:"sessoesPlanejadas"`
+		ConsultaIDs       []string `json:"consultaIds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		httpError(w, "Requisição inválida", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	ator := reqctx.AtorDe(r.Context())
+	if payload.PsicologoID == "" && ator.Papel == reqctx.PapelPsicologo {
+		payload.PsicologoID = ator.ID
+	}
+	if payload.AlunoID == "" || payload.PsicologoID == "" {
+		httpError(w, "Os campos 'alunoId' e 'psicologoId' são obrigatórios", http.StatusBadRequest)
+		return
+	}
+	if payload.SessoesPlanejadas < 0 {
+		httpError(w, "O campo 'sessoesPlanejadas' não pode ser negativo", http.StatusBadRequest)
+		return
+	}
+	if ator.ID != payload.PsicologoID && ator.Papel != reqctx.PapelAdmin {
+		httpError(w, "Apenas o próprio psicólogo ou um administrador pode abrir o episódio", http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	if _, err := h.PsicologoRepo.BuscarPsicologoPorID(ctx, payload.PsicologoID); err != nil {
+		httpError(w, "Psicólogo não encontrado", http.StatusNotFound)
+		return
+	}
+
+	consultas, err := h.ConsultaRepo.ListarConsultasPorAluno(ctx, payload.AlunoID)
+	if err != nil {
+		log.Printf("ERRO ao listar consultas do aluno %s: %v", payload.AlunoID, err)
+		httpError(w, "Erro ao abrir episódio", http.StatusInternalServerError)
+		return
+	}
+	vincular, err := consultasDoEpisodio(consultas, payload.PsicologoID, payload.ConsultaIDs)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	episodio, err := h.Repo.AbrirEpisodio(ctx, model.Episodio{
+		AlunoID:           payload.AlunoID,
+		PsicologoID:       payload.PsicologoID,
+		Status:            model.EpisodioAberto,
+		Objetivos:         payload.Objetivos,
+		SessoesPlanejadas: payload.SessoesPlanejadas,
+		AbertoEm:          time.Now().UTC(),
+	})
+	if errors.Is(err, repository.ErrEpisodioAberto) {
+		httpError(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("ERRO ao abrir episódio do aluno %s: %v", payload.AlunoID, err)
+		httpError(w, "Erro ao abrir episódio", http.StatusInternalServerError)
+		return
+	}
+
+	progresso := model.ProgressoEpisodio{Episodio: episodio, Consultas: []*model.Consulta{}}
+	for _, c := range vincular {
+		if err := h.ConsultaRepo.DefinirEpisodio(ctx, c.ID, episodio.ID); err != nil {
+			log.Printf("ERRO ao vincular consulta %s ao episódio %s: %v", c.ID, episodio.ID, err)
+			continue
+		}
+		c.EpisodioID = episodio.ID
+		progresso.Consultas = append(progresso.Consultas, c)
+	}
+	progresso.SessoesRealizadas = sessoesRealizadas(progresso.Consultas)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(progresso)
+}
+
+// HandlerBuscarEpisodio responde à rota GET /episodios/{id} com as consultas
+// do episódio e o número de sessões já realizadas.
+func (h *EpisodioHandler) HandlerBuscarEpisodio(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	episodio, err := h.Repo.BuscarEpisodioPorID(ctx, r.PathValue("id"))
+	if err != nil {
+		httpError(w, "Episódio não encontrado", http.StatusNotFound)
+		return
+	}
+	ator := reqctx.AtorDe(ctx)
+	if ator.ID != episodio.AlunoID && !podeGerirEpisodio(ator, episodio) {
+		httpError(w, "Sem permissão para ver este episódio", http.StatusForbidden)
+		return
+	}
+
+	consultas, err := h.ConsultaRepo.ListarConsultasPorAluno(ctx, episodio.AlunoID)
+	if err != nil {
+		log.Printf("ERRO ao listar consultas do episódio %s: %v", episodio.ID, err)
+		httpError(w, "Erro ao buscar episódio", http.StatusInternalServerError)
+		return
+	}
+	progresso := model.ProgressoEpisodio{Episodio: episodio, Consultas: []*model.Consulta{}}
+	for _, c := range consultas {
+		if c.EpisodioID == episodio.ID {
+			progresso.Consultas = append(progresso.Consultas, c)
+		}
+	}
+	progresso.SessoesRealizadas = sessoesRealizadas(progresso.Consultas)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(progresso)
+}
+
+// HandlerListarEpisodiosAluno responde à rota GET /alunos/{id}/episodios.
+func (h *EpisodioHandler) HandlerListarEpisodiosAluno(w http.ResponseWriter, r *http.Request) {
+	alunoID := r.PathValue("id")
+	ator := reqctx.AtorDe(r.Context())
+	if ator.ID != alunoID && ator.Papel != reqctx.PapelPsicologo && ator.Papel != reqctx.PapelAdmin {
+		httpError(w, "Sem permissão para ver os episódios deste aluno", http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	episodios, err := h.Repo.ListarEpisodiosPorAluno(ctx, alunoID)
+	if err != nil {
+		log.Printf("ERRO ao listar episódios do aluno %s: %v", alunoID, err)
+		httpError(w, "Erro ao listar episódios", http.StatusInternalServerError)
+		return
+	}
+	if episodios == nil {
+		episodios = []*model.Episodio{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(episodios)
+}
+
+// HandlerEncerrarEpisodio responde à rota POST /episodios/{id}/encerrar (alta).
+// As consultas já marcadas não são canceladas.
+func (h *EpisodioHandler) HandlerEncerrarEpisodio(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Motivo string `json:"motivo"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			httpError(w, "Requisição inválida", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	episodio, ok := h.episodioAberto(ctx, w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	episodio.Status = model.EpisodioEncerrado
+	episodio.EncerradoEm = time.Now().UTC()
+	episodio.MotivoEncerramento = payload.Motivo
+	if err := h.Repo.AtualizarEpisodio(ctx, episodio.ID, episodio.PsicologoID, *episodio); err != nil {
+		if errors.Is(err, repository.ErrTransicaoInvalida) {
+			httpError(w, "O episódio foi alterado por outra pessoa; tente novamente", http.StatusConflict)
+			return
+		}
+		log.Printf("ERRO ao encerrar episódio %s: %v", episodio.ID, err)
+		httpError(w, "Erro ao encerrar episódio", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(episodio)
+}
+
+// HandlerTransferirEpisodio responde à rota POST /episodios/{id}/transferir.
+// As consultas já marcadas continuam com o psicólogo anterior; os próximos
+// agendamentos com o novo responsável entram no episódio.
+func (h *EpisodioHandler) HandlerTransferirEpisodio(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		PsicologoID string `json:"psicologoId"`
+		Motivo      string `json:"motivo"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		httpError(w, "Requisição inválida", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if payload.PsicologoID == "" {
+		httpError(w, "O campo 'psicologoId' é obrigatório", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	episodio, ok := h.episodioAberto(ctx, w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	if payload.PsicologoID == episodio.PsicologoID {
+		httpError(w, "O episódio já está com este psicólogo", http.StatusBadRequest)
+		return
+	}
+
+	destino, err := h.PsicologoRepo.BuscarPsicologoPorID(ctx, payload.PsicologoID)
+	if err != nil {
+		httpError(w, "Psicólogo não encontrado", http.StatusNotFound)
+		return
+	}
+	if !service.PsicologoAtivo(destino) {
+		httpError(w, "O psicólogo de destino não está atendendo no momento", http.StatusUnprocessableEntity)
+		return
+	}
+
+	anterior := episodio.PsicologoID
+	transferirEpisodio(episodio, destino.ID, payload.Motivo, reqctx.AtorDe(ctx).ID)
+	if err := h.Repo.AtualizarEpisodio(ctx, episodio.ID, anterior, *episodio); err != nil {
+		if errors.Is(err, repository.ErrTransicaoInvalida) {
+			httpError(w, "O episódio foi alterado por outra pessoa; tente novamente", http.StatusConflict)
+			return
+		}
+		log.Printf("ERRO ao transferir episódio %s: %v", episodio.ID, err)
+		httpError(w, "Erro ao transferir episódio", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(episodio)
+}
+
+// episodioAberto busca o episódio e garante que ele está aberto e que o ator
+// pode alterá-lo. Em caso de falha já escreve a resposta.
+func (h *EpisodioHandler) episodioAberto(ctx context.Context, w http.ResponseWriter, id string) (*model.Episodio, bool) {
+	episodio, err := h.Repo.BuscarEpisodioPorID(ctx, id)
+	if err != nil {
+		httpError(w, "Episódio não encontrado", http.StatusNotFound)
+		return nil, false
+	}
+	if !podeGerirEpisodio(reqctx.AtorDe(ctx), episodio) {
+		httpError(w, "Apenas o psicólogo responsável ou um administrador pode alterar o episódio", http.StatusForbidden)
+		return nil, false
+	}
+	if episodio.Status != model.EpisodioAberto {
+		httpError(w, "O episódio já foi encerrado", http.StatusConflict)
+		return nil, false
+	}
+	return episodio, true
+}
+
+// transferirEpisodio troca o responsável e registra a transferência; quem
+// chama grava o episódio.
+func transferirEpisodio(episodio *model.Episodio, paraPsicologoID, motivo, atorID string) {
+	episodio.Transferencias = append(episodio.Transferencias, model.TransferenciaEpisodio{
+		DePsicologoID:   episodio.PsicologoID,
+		ParaPsicologoID: paraPsicologoID,
+		Motivo:          motivo,
+		AtorID:          atorID,
+		Em:              time.Now().UTC(),
+	})
+	episodio.PsicologoID = paraPsicologoID
+}
+
+func podeGerirEpisodio(ator reqctx.Ator, episodio *model.Episodio) bool {
+	return (ator.ID != "" && ator.ID == episodio.PsicologoID) || ator.Papel == reqctx.PapelAdmin
+}
+
+// consultasDoEpisodio escolhe as consultas vinculadas na abertura: as ativas
+// com o psicólogo e as pedidas explicitamente, que precisam ser do mesmo
+// aluno e psicólogo e ainda não pertencer a outro episódio.
+func consultasDoEpisodio(consultas []*model.Consulta, psicologoID string, pedidas []string) ([]*model.Consulta, error) {
+	porID := map[string]*model.Consulta{}
+	for _, c := range consultas {
+		porID[c.ID] = c
+	}
+	incluir := map[string]bool{}
+	for _, id := range pedidas {
+		c, ok := porID[id]
+		if !ok || c.PsicologoID != psicologoID {
+			return nil, fmt.Errorf("A consulta '%s' não é deste aluno com este psicólogo", id)
+		}
+		if c.EpisodioID != "" {
+			return nil, fmt.Errorf("A consulta '%s' já pertence a outro episódio", id)
+		}
+		incluir[id] = true
+	}
+
+	var vincular []*model.Consulta
+	for _, c := range consultas {
+		if c.EpisodioID != "" || c.PsicologoID != psicologoID {
+			continue
+		}
+		if incluir[c.ID] || service.ConsultaAtiva(c) {
+			vincular = append(vincular, c)
+		}
+	}
+	return vincular, nil
+}
+
+func sessoesRealizadas(consultas []*model.Consulta) int {
+	n := 0
+	for _, c := range consultas {
+		if c.Status == "concluida" || c.Status == "em andamento" {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/repository/mocks"
+	"sgp/Internal/reqctx"
+	"testing"
+	"time"
+)
+
+func novoEpisodioHandler(episodio *model.Episodio, consultas []*model.Consulta, vinculadas map[string]string, salvo *model.Episodio) *EpisodioHandler {
+	inativo := false
+	return NewEpisodioHandler(
+		&mocks.EpisodioRepositoryMock{
+			AbrirEpisodioFunc: func(ctx context.Context, e model.Episodio) (*model.Episodio, error) {
+				if episodio != nil && episodio.Status == model.EpisodioAberto {
+					return nil, repository.ErrEpisodioAberto
+				}
+				e.ID = "ep-novo"
+				return &e, nil
+			},
+			BuscarEpisodioPorIDFunc: func(ctx context.Context, id string) (*model.Episodio, error) {
+				if episodio == nil || episodio.ID != id {
+					return nil, errors.New("episódio não encontrado")
+				}
+				copia := *episodio
+				return &copia, nil
+			},
+			AtualizarEpisodioFunc: func(ctx context.Context, id string, psicologoEsperado string, e model.Episodio) error {
+				if episodio.Status != model.EpisodioAberto || episodio.PsicologoID != psicologoEsperado {
+					return repository.ErrTransicaoInvalida
+				}
+				*salvo = e
+				return nil
+			},
+		},
+		&mocks.ConsultaRepositoryMock{
+			ListarConsultasPorAlunoFunc: func(ctx context.Context, alunoID string) ([]*model.Consulta, error) {
+				return consultas, nil
+			},
+			DefinirEpisodioFunc: func(ctx context.Context, id string, episodioID string) error {
+				vinculadas[id] = episodioID
+				return nil
+			},
+		},
+		&mocks.PsicologoRepositoryMock{
+			BuscarPsicologoPorIDFunc: func(ctx context.Context, id string) (*model.Psicologo, error) {
+				switch id {
+				case "psico-1", "psico-2":
+					return &model.Psicologo{ID: id}, nil
+				case "psico-afastado":
+					return &model.Psicologo{ID: id, Ativo: &inativo}, nil
+				}
+				return nil, errors.New("psicólogo não encontrado")
+			},
+		},
+	)
+}
+
+func TestHandlerAbrirEpisodio(t *testing.T) {
+	consultas := []*model.Consulta{
+		{ID: "ativa", AlunoID: "aluno-1", PsicologoID: "psico-1", Status: "confirmada"},
+		{ID: "triagem", AlunoID: "aluno-1", PsicologoID: "psico-1", Status: "concluida"},
+		{ID: "antiga", AlunoID: "aluno-1", PsicologoID: "psico-1", Status: "concluida"},
+		{ID: "outro-psico", AlunoID: "aluno-1", PsicologoID: "psico-2", Status: "confirmada"},
+		{ID: "outro-episodio", AlunoID: "aluno-1", PsicologoID: "psico-1", Status: "confirmada", EpisodioID: "ep-velho"},
+	}
+
+	abrir := func(ator reqctx.Ator, aberto *model.Episodio, corpo map[string]interface{}) (*httptest.ResponseRecorder, map[string]string) {
+		vinculadas := map[string]string{}
+		h := novoEpisodioHandler(aberto, consultas, vinculadas, &model.Episodio{})
+		body, _ := json.Marshal(corpo)
+		req, _ := http.NewRequest("POST", "/episodios", bytes.NewBuffer(body))
+		req = req.WithContext(reqctx.ComAtor(req.Context(), ator))
+		rr := httptest.NewRecorder()
+		h.HandlerAbrirEpisodio(rr, req)
+		return rr, vinculadas
+	}
+	psicologo := reqctx.Ator{ID: "psico-1", Papel: reqctx.PapelPsicologo}
+
+	t.Run("psicologo abre o proprio episodio", func(t *testing.T) {
+		rr, vinculadas := abrir(psicologo, nil, map[string]interface{}{
+			"alunoId": "aluno-1", "objetivos": []string{"reduzir ansiedade"}, "sessoesPlanejadas": 12, "consultaIds": []string{"triagem"},
+		})
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("status code incorreto: obteve %v, esperava %v (%s)", rr.Code, http.StatusCreated, rr.Body.String())
+		}
+		if len(vinculadas) != 2 || vinculadas["ativa"] != "ep-novo" || vinculadas["triagem"] != "ep-novo" {
+			t.Errorf("consultas vinculadas incorretamente: %v", vinculadas)
+		}
+		var progresso model.ProgressoEpisodio
+		json.NewDecoder(rr.Body).Decode(&progresso)
+		if progresso.PsicologoID != "psico-1" || progresso.SessoesPlanejadas != 12 || progresso.SessoesRealizadas != 1 {
+			t.Errorf("episódio aberto incorretamente: %+v", progresso)
+		}
+	})
+
+	t.Run("aluno com episodio aberto", func(t *testing.T) {
+		aberto := &model.Episodio{ID: "ep-1", AlunoID: "aluno-1", Status: model.EpisodioAberto}
+		if rr, _ := abrir(psicologo, aberto, map[string]interface{}{"alunoId": "aluno-1"}); rr.Code != http.StatusConflict {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", rr.Code, http.StatusConflict)
+		}
+	})
+
+	t.Run("consulta de outro episodio", func(t *testing.T) {
+		rr, _ := abrir(psicologo, nil, map[string]interface{}{"alunoId": "aluno-1", "consultaIds": []string{"outro-episodio"}})
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", rr.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("psicologo abrindo para outro", func(t *testing.T) {
+		rr, _ := abrir(psicologo, nil, map[string]interface{}{"alunoId": "aluno-1", "psicologoId": "psico-2"})
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", rr.Code, http.StatusForbidden)
+		}
+	})
+}
+
+func TestHandlerTransferirEEncerrarEpisodio(t *testing.T) {
+	aberto := func() *model.Episodio {
+		return &model.Episodio{ID: "ep-1", AlunoID: "aluno-1", PsicologoID: "psico-1", Status: model.EpisodioAberto}
+	}
+	enviar := func(episodio *model.Episodio, ator reqctx.Ator, rota string, corpo map[string]string) (int, model.Episodio) {
+		var salvo model.Episodio
+		h := novoEpisodioHandler(episodio, nil, map[string]string{}, &salvo)
+		body, _ := json.Marshal(corpo)
+		req, _ := http.NewRequest("POST", "/episodios/ep-1/"+rota, bytes.NewBuffer(body))
+		req.SetPathValue("id", "ep-1")
+		req = req.WithContext(reqctx.ComAtor(req.Context(), ator))
+		rr := httptest.NewRecorder()
+		if rota == "transferir" {
+			h.HandlerTransferirEpisodio(rr, req)
+		} else {
+			h.HandlerEncerrarEpisodio(rr, req)
+		}
+		return rr.Code, salvo
+	}
+	responsavel := reqctx.Ator{ID: "psico-1", Papel: reqctx.PapelPsicologo}
+
+	t.Run("transferencia", func(t *testing.T) {
+		code, salvo := enviar(aberto(), responsavel, "transferir", map[string]string{"psicologoId": "psico-2", "motivo": "mudança de turno"})
+		if code != http.StatusOK {
+			t.Fatalf("status code incorreto: obteve %v, esperava %v", code, http.StatusOK)
+		}
+		if salvo.PsicologoID != "psico-2" || len(salvo.Transferencias) != 1 || salvo.Transferencias[0].DePsicologoID != "psico-1" {
+			t.Errorf("transferência registrada incorretamente: %+v", salvo)
+		}
+	})
+
+	t.Run("destino inativo", func(t *testing.T) {
+		if code, _ := enviar(aberto(), responsavel, "transferir", map[string]string{"psicologoId": "psico-afastado"}); code != http.StatusUnprocessableEntity {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", code, http.StatusUnprocessableEntity)
+		}
+	})
+
+	t.Run("outro psicologo", func(t *testing.T) {
+		outro := reqctx.Ator{ID: "psico-2", Papel: reqctx.PapelPsicologo}
+		if code, _ := enviar(aberto(), outro, "encerrar", map[string]string{}); code != http.StatusForbidden {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", code, http.StatusForbidden)
+		}
+	})
+
+	t.Run("alta", func(t *testing.T) {
+		code, salvo := enviar(aberto(), reqctx.Ator{ID: "admin-1", Papel: reqctx.PapelAdmin}, "encerrar", map[string]string{"motivo": "objetivos atingidos"})
+		if code != http.StatusOK {
+			t.Fatalf("status code incorreto: obteve %v, esperava %v", code, http.StatusOK)
+		}
+		if salvo.Status != model.EpisodioEncerrado || salvo.EncerradoEm.IsZero() || salvo.MotivoEncerramento != "objetivos atingidos" {
+			t.Errorf("encerramento registrado incorretamente: %+v", salvo)
+		}
+	})
+
+	t.Run("alterado entre a leitura e a gravacao", func(t *testing.T) {
+		for _, rota := range []string{"transferir", "encerrar"} {
+			episodio := aberto()
+			h := novoEpisodioHandler(episodio, nil, map[string]string{}, &model.Episodio{})
+			h.Repo.(*mocks.EpisodioRepositoryMock).AtualizarEpisodioFunc = func(ctx context.Context, id string, psicologoEsperado string, e model.Episodio) error {
+				return repository.ErrTransicaoInvalida
+			}
+			body, _ := json.Marshal(map[string]string{"psicologoId": "psico-2"})
+			req, _ := http.NewRequest("POST", "/episodios/ep-1/"+rota, bytes.NewBuffer(body))
+			req.SetPathValue("id", "ep-1")
+			req = req.WithContext(reqctx.ComAtor(req.Context(), responsavel))
+			rr := httptest.NewRecorder()
+			if rota == "transferir" {
+				h.HandlerTransferirEpisodio(rr, req)
+			} else {
+				h.HandlerEncerrarEpisodio(rr, req)
+			}
+			if rr.Code != http.StatusConflict {
+				t.Errorf("%s: status code incorreto: obteve %v, esperava %v", rota, rr.Code, http.StatusConflict)
+			}
+		}
+	})
+
+	t.Run("episodio ja encerrado", func(t *testing.T) {
+		encerrado := aberto()
+		encerrado.Status = model.EpisodioEncerrado
+		if code, _ := enviar(encerrado, responsavel, "transferir", map[string]string{"psicologoId": "psico-2"}); code != http.StatusConflict {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", code, http.StatusConflict)
+		}
+	})
+}
+
+func TestHandlerAgendarConsultaEntraNoEpisodio(t *testing.T) {
+	inicio := time.Now().Add(48 * time.Hour)
+	psicologos := []*model.Psicologo{{ID: "psico-1"}, {ID: "psico-2"}}
+	horarios := map[string][]*model.HorarioDisponivel{
+		"psico-1": {{ID: "h1", PsicologoID: "psico-1", Inicio: inicio, Status: "disponivel"}},
+		"psico-2": {{ID: "h2", PsicologoID: "psico-2", Inicio: inicio, Status: "disponivel"}},
+	}
+
+	casos := []struct {
+		horarioID string
+		esperado  string
+	}{
+		{"h1", "ep-1"},
+		{"h2", ""}, // outro psicólogo: fica fora do episódio
+	}
+	for _, tc := range casos {
+		t.Run(tc.horarioID, func(t *testing.T) {
+			var agendada model.Consulta
+			h := novoConsultaHandlerComCarga(psicologos, horarios, nil, &agendada)
+			h.EpisodioRepo = &mocks.EpisodioRepositoryMock{
+				BuscarEpisodioAbertoFunc: func(ctx context.Context, alunoID string) (*model.Episodio, error) {
+					return &model.Episodio{ID: "ep-1", AlunoID: alunoID, PsicologoID: "psico-1", Status: model.EpisodioAberto}, nil
+				},
+			}
+			body, _ := json.Marshal(map[string]string{"alunoId": "aluno-1", "horarioId": tc.horarioID})
+			req, _ := http.NewRequest("POST", "/consultas", bytes.NewBuffer(body))
+			rr := httptest.NewRecorder()
+			h.HandlerAgendarConsulta(rr, req)
+
+			if rr.Code != http.StatusCreated {
+				t.Fatalf("status code incorreto: obteve %v, esperava %v (%s)", rr.Code, http.StatusCreated, rr.Body.String())
+			}
+			if agendada.EpisodioID != tc.esperado {
+				t.Errorf("episódio incorreto: obteve %q, esperava %q", agendada.EpisodioID, tc.esperado)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/reqctx"
+)
+
+type FormularioHandler struct {
+	Repo         repository.FormularioRepository
+	ConsultaRepo repository.ConsultaRepository
+}
+
+func NewFormularioHandler(repo repository.FormularioRepository, consultaRepo repository.ConsultaRepository) *FormularioHandler {
+	return &FormularioHandler{Repo: repo, ConsultaRepo: consultaRepo}
+}
+
+// HandlerSalvarFormulario responde à rota PUT /psicologos/{id}/formulario
+func (h *FormularioHandler) HandlerSalvarFormulario(w http.ResponseWriter, r *http.Request) {
+	psicologoID := r.PathValue("id")
+	if psicologoID == "" {
+		httpError(w, "O ID do psicólogo é obrigatório", http.StatusBadRequest)
+		return
+	}
+
+	ator := reqctx.AtorDe(r.Context())
+	if ator.ID != psicologoID && ator.Papel != reqctx.PapelAdmin {
+		httpError(w, "Apenas o próprio psicólogo pode alterar seu formulário", http.StatusForbidden)
+		return
+	}
+
+	var formulario model.FormularioInicial
+	if err := json.NewDecoder(r.Body).Decode(&formulario); err != nil {
+		httpError(w, "Requisição inválida", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if err := validarPerguntas(formulario.Perguntas); err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	formulario.PsicologoID = psicologoID
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	salvo, err := h.Repo.SalvarFormulario(ctx, formulario)
+	if err != nil {
+		log.Printf("ERRO ao salvar formulário do psicólogo %s: %v", psicologoID, err)
+		httpError(w, "Erro ao salvar formulário", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(salvo)
+}
+
+// HandlerBuscarFormulario responde à rota GET /psicologos/{id}/formulario.
+// As perguntas são públicas para que o aluno possa respondê-las ao agendar.
+func (h *FormularioHandler) HandlerBuscarFormulario(w http.ResponseWriter, r *http.Request) {
+	psicologoID := r.PathValue("id")
+	if psicologoID == "" {
+		httpError(w, "O ID do psicólogo é obrigatório", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	formulario, err := h.Repo.BuscarFormularioPorPsicologo(ctx, psicologoID)
+	if err != nil {
+		log.Printf("ERRO ao buscar formulário do psicólogo %s: %v", psicologoID, err)
+		httpError(w, "Erro ao buscar formulário", http.StatusInternalServerError)
+		return
+	}
+	if formulario == nil {
+		httpError(w, "Psicólogo não possui formulário inicial", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(formulario)
+}
+
+// HandlerBuscarRespostaFormulario responde à rota GET /consultas/{id}/formulario.
+// Só o psicólogo responsável pela consulta vê as respostas.
+func (h *FormularioHandler) HandlerBuscarRespostaFormulario(w http.ResponseWriter, r *http.Request) {
+	consultaID := r.PathValue("id")
+	if consultaID == "" {
+		httpError(w, "O ID da consulta é obrigatório", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	consulta, err := h.ConsultaRepo.BuscarConsultaPorID(ctx, consultaID)
+	if err != nil {
+		httpError(w, "Consulta não encontrada", http.StatusNotFound)
+		return
+	}
+
+	resposta, err := h.Repo.BuscarRespostaPorConsulta(ctx, consultaID)
+	if err != nil {
+		log.Printf("ERRO ao buscar resposta da consulta %s: %v", consultaID, err)
+		httpError(w, "Erro ao buscar respostas", http.StatusInternalServerError)
+		return
+	}
+
+	if !podeVerResposta(reqctx.AtorDe(ctx), consulta) {
+		httpError(w, "Apenas o psicólogo responsável pode ver estas respostas", http.StatusForbidden)
+		return
+	}
+	if resposta == nil {
+		httpError(w, "Consulta sem formulário respondido", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resposta)
+}
+
+func podeVerResposta(ator reqctx.Ator, consulta *model.Consulta) bool {
+	return ator.ID != "" && ator.ID == consulta.PsicologoID
+}
+
+func validarPerguntas(perguntas []model.PerguntaFormulario) error {
+	if len(perguntas) == 0 {
+		return fmt.Errorf("O formulário precisa ter ao menos uma pergunta")
+	}
+
+	ids := map[string]bool{}
+	for _, p := range perguntas {
+		if p.ID == "" || p.Texto == "" {
+			return fmt.Errorf("Toda pergunta precisa de 'id' e 'texto'")
+		}
+		if ids[p.ID] {
+			return fmt.Errorf("Pergunta '%s' repetida", p.ID)
+		}
+		ids[p.ID] = true
+
+		switch p.Tipo {
+		case "texto":
+		case "escolha":
+			if len(p.Opcoes) == 0 {
+				return fmt.Errorf("Pergunta '%s' do tipo escolha precisa de opções", p.ID)
+			}
+		default:
+			return fmt.Errorf("Tipo inválido na pergunta '%s': use 'texto' ou 'escolha'", p.ID)
+		}
+	}
+	return nil
+}
+
+// validarRespostas confere as respostas do aluno contra o esquema do formulário.
+func validarRespostas(formulario *model.FormularioInicial, respostas map[string]string) error {
+	perguntas := map[string]model.PerguntaFormulario{}
+	for _, p := range formulario.Perguntas {
+		perguntas[p.ID] = p
+		if p.Obrigatoria && respostas[p.ID] == "" {
+			return fmt.Errorf("a pergunta '%s' é obrigatória", p.ID)
+		}
+	}
+
+	for id, valor := range respostas {
+		p, ok := perguntas[id]
+		if !ok {
+			return fmt.Errorf("a pergunta '%s' não existe no formulário", id)
+		}
+		if p.Tipo == "escolha" && valor != "" && !contem(p.Opcoes, valor) {
+			return fmt.Errorf("resposta inválida para a pergunta '%s'", id)
+		}
+	}
+	return nil
+}
+
+// formularioDoPrimeiroAgendamento retorna o formulário do psicólogo se esta
+// for a primeira consulta do aluno com ele; caso contrário retorna nil.
+func formularioDoPrimeiroAgendamento(
+	ctx context.Context,
+	repo repository.FormularioRepository,
+	consultaRepo repository.ConsultaRepository,
+	alunoID, psicologoID string,
+) (*model.FormularioInicial, error) {
+	formulario, err := repo.BuscarFormularioPorPsicologo(ctx, psicologoID)
+	if err != nil || formulario == nil {
+		return nil, err
+	}
+
+	consultas, err := consultaRepo.ListarConsultasPorAluno(ctx, alunoID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range consultas {
+		if c.PsicologoID == psicologoID {
+			return nil, nil
+		}
+	}
+	return formulario, nil
+}
+
+func contem(lista []string, valor string) bool {
+	for _, v := range lista {
+		if v == valor {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sgp/Internal/model"
+	"sgp/Internal/repository/mocks"
+	"sgp/Internal/reqctx"
+	"testing"
+)
+
+func novoConsultaHandlerComFormulario(obrigatorio bool, registradas *[]model.RespostaFormulario) *ConsultaHandler {
+	consultaRepo := &mocks.ConsultaRepositoryMock{
+		ListarConsultasPorAlunoFunc: func(ctx context.Context, alunoID string) ([]*model.Consulta, error) {
+			return nil, nil
+		},
+		AgendarConsultaFunc: func(ctx context.Context, c model.Consulta) (*model.Consulta, error) {
+			c.ID = "consulta-1"
+			c.PsicologoID = "psico-1"
+			return &c, nil
+		},
+	}
+	alunoRepo := &mocks.AlunoRepositoryMock{
+		BuscarAlunoPorIDFunc: func(ctx context.Context, id string) (*model.Aluno, error) {
+			return nil, errors.New("ignorar email no teste")
+		},
+	}
+	// O erro na busca do aluno já evita o envio de e-mail
+	psicologoRepo := &mocks.PsicologoRepositoryMock{
+		BuscarPsicologoPorIDFunc: func(ctx context.Context, id string) (*model.Psicologo, error) {
+			return &model.Psicologo{ID: id}, nil
+		},
+	}
+
+	h := NewConsultaHandler(consultaRepo, alunoRepo, psicologoRepo, nil)
+	h.HorarioRepo = &mocks.HorarioDisponivelRepositoryMock{
+		BuscarHorarioPorIDFunc: func(ctx context.Context, id string) (*model.HorarioDisponivel, error) {
+			return &model.HorarioDisponivel{ID: id, PsicologoID: "psico-1"}, nil
+		},
+	}
+	h.FormularioRepo = &mocks.FormularioRepositoryMock{
+		BuscarFormularioPorPsicologoFunc: func(ctx context.Context, psicologoID string) (*model.FormularioInicial, error) {
+			return &model.FormularioInicial{
+				PsicologoID: psicologoID,
+				Obrigatorio: obrigatorio,
+				Perguntas: []model.PerguntaFormulario{
+					{ID: "motivo", Texto: "O que te traz aqui?", Tipo: "texto", Obrigatoria: true},
+					{ID: "primeira_vez", Texto: "Já fez terapia?", Tipo: "escolha", Opcoes: []string{"sim", "nao"}},
+				},
+			}, nil
+		},
+		RegistrarRespostaFunc: func(ctx context.Context, r model.RespostaFormulario) (*model.RespostaFormulario, error) {
+			*registradas = append(*registradas, r)
+			return &r, nil
+		},
+	}
+	return h
+}
+
+func TestHandlerAgendarConsultaComFormulario(t *testing.T) {
+	t.Run("formulario obrigatorio ausente", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"alunoId": "aluno-1", "horarioId": "h1"})
+		req, _ := http.NewRequest("POST", "/consultas", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		var registradas []model.RespostaFormulario
+		h := novoConsultaHandlerComFormulario(true, &registradas)
+		h.HandlerAgendarConsulta(rr, req)
+
+		if status := rr.Code; status != http.StatusUnprocessableEntity {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusUnprocessableEntity)
+		}
+	})
+
+	t.Run("respostas validas sao registradas", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{
+			"alunoId":   "aluno-1",
+			"horarioId": "h1",
+			"respostasFormulario": map[string]string{
+				"motivo":       "ansiedade nas provas",
+				"primeira_vez": "sim",
+			},
+		})
+		req, _ := http.NewRequest("POST", "/consultas", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		var registradas []model.RespostaFormulario
+		h := novoConsultaHandlerComFormulario(true, &registradas)
+		h.HandlerAgendarConsulta(rr, req)
+
+		if status := rr.Code; status != http.StatusCreated {
+			t.Fatalf("status code incorreto: obteve %v, esperava %v", status, http.StatusCreated)
+		}
+		if len(registradas) != 1 || registradas[0].ConsultaID != "consulta-1" {
+			t.Errorf("resposta não registrada corretamente: %+v", registradas)
+		}
+	})
+
+	t.Run("falha ao gravar formulario obrigatorio desfaz o agendamento", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{
+			"alunoId":             "aluno-1",
+			"horarioId":           "h1",
+			"respostasFormulario": map[string]string{"motivo": "ansiedade nas provas"},
+		})
+		req, _ := http.NewRequest("POST", "/consultas", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		var registradas []model.RespostaFormulario
+		h := novoConsultaHandlerComFormulario(true, &registradas)
+		h.FormularioRepo.(*mocks.FormularioRepositoryMock).RegistrarRespostaFunc = func(ctx context.Context, r model.RespostaFormulario) (*model.RespostaFormulario, error) {
+			return nil, errors.New("firestore indisponível")
+		}
+		var apagadas []string
+		h.Repo.(*mocks.ConsultaRepositoryMock).DeletarConsultaFunc = func(ctx context.Context, id string) error {
+			apagadas = append(apagadas, id)
+			return nil
+		}
+		h.HandlerAgendarConsulta(rr, req)
+
+		if status := rr.Code; status != http.StatusInternalServerError {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusInternalServerError)
+		}
+		if len(apagadas) != 1 || apagadas[0] != "consulta-1" {
+			t.Errorf("a consulta deveria ser desfeita, apagadas: %v", apagadas)
+		}
+	})
+
+	t.Run("opcao invalida", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{
+			"alunoId":             "aluno-1",
+			"horarioId":           "h1",
+			"respostasFormulario": map[string]string{"motivo": "x", "primeira_vez": "talvez"},
+		})
+		req, _ := http.NewRequest("POST", "/consultas", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		var registradas []model.RespostaFormulario
+		h := novoConsultaHandlerComFormulario(false, &registradas)
+		h.HandlerAgendarConsulta(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestHandlerBuscarRespostaFormulario(t *testing.T) {
+	t.Run("apenas o psicologo da consulta", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/consultas/c1/formulario", nil)
+		req.SetPathValue("id", "c1")
+		req = req.WithContext(reqctx.ComAtor(req.Context(), reqctx.Ator{ID: "aluno-1"}))
+		rr := httptest.NewRecorder()
+
+		h := NewFormularioHandler(
+			&mocks.FormularioRepositoryMock{
+				BuscarRespostaPorConsultaFunc: func(ctx context.Context, id string) (*model.RespostaFormulario, error) {
+					return &model.RespostaFormulario{ConsultaID: id}, nil
+				},
+			},
+			&mocks.ConsultaRepositoryMock{
+				BuscarConsultaPorIDFunc: func(ctx context.Context, id string) (*model.Consulta, error) {
+					return &model.Consulta{ID: id, AlunoID: "aluno-1", PsicologoID: "psico-1"}, nil
+				},
+			},
+		)
+		h.HandlerBuscarRespostaFormulario(rr, req)
+
+		if status := rr.Code; status != http.StatusForbidden {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusForbidden)
+		}
+	})
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sgp/Internal/model"
+	"sgp/Internal/repository/mocks"
+	"sgp/Internal/service"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGerarHorariosNaTrocaDeHorario(t *testing.T) {
+	casos := []struct {
+		nome     string
+		fuso     string
+		de, ate  string
+		dias     []int
+		esperado []string // inícios em UTC
+	}{
+		{
+			// Nova York adianta o relógio em 09/03/2025: 14h local passa de 19h para 18h UTC.
+			nome: "inicio do horario de verao", fuso: "America/New_York", de: "2025-03-08", ate: "2025-03-10", dias: []int{0, 1, 6},
+			esperado: []string{"2025-03-08T19:00:00Z", "2025-03-09T18:00:00Z", "2025-03-10T18:00:00Z"},
+		},
+		{
+			nome: "fim do horario de verao", fuso: "America/New_York", de: "2025-11-01", ate: "2025-11-03", dias: []int{0, 1, 6},
+			esperado: []string{"2025-11-01T18:00:00Z", "2025-11-02T19:00:00Z", "2025-11-03T19:00:00Z"},
+		},
+		{
+			// São Paulo teve horário de verão até 2019; em 04/11/2018 o relógio foi de -03 para -02.
+			nome: "sao paulo em 2018", fuso: "America/Sao_Paulo", de: "2018-11-03", ate: "2018-11-05", dias: []int{0, 1, 6},
+			esperado: []string{"2018-11-03T17:00:00Z", "2018-11-04T16:00:00Z", "2018-11-05T16:00:00Z"},
+		},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			horarios, err := service.GerarHorarios(service.GradeHorarios{
+				PsicologoID: "psico-1", De: tc.de, Ate: tc.ate, DiasSemana: tc.dias,
+				Horarios: []string{"14:00"}, DuracaoMinutos: 50, FusoHorario: tc.fuso,
+			})
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if len(horarios) != len(tc.esperado) {
+				t.Fatalf("esperava %d horários, obteve %d", len(tc.esperado), len(horarios))
+			}
+			for i, h := range horarios {
+				if got := h.Inicio.Format(time.RFC3339); got != tc.esperado[i] {
+					t.Errorf("horário %d: obteve %s, esperava %s", i, got, tc.esperado[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGradeUsaFusoDoPsicologo(t *testing.T) {
+	var criados []model.HorarioDisponivel
+	h := NewHorarioDisponivelHandler(&mocks.HorarioDisponivelRepositoryMock{
+		CriarHorarioFunc: func(ctx context.Context, horario model.HorarioDisponivel) (*model.HorarioDisponivel, error) {
+			criados = append(criados, horario)
+			return &horario, nil
+		},
+	})
+	h.PsicologoRepo = &mocks.PsicologoRepositoryMock{
+		BuscarPsicologoPorIDFunc: func(ctx context.Context, id string) (*model.Psicologo, error) {
+			return &model.Psicologo{ID: id, FusoHorario: "America/Manaus"}, nil
+		},
+	}
+
+	body, _ := json.Marshal(service.GradeHorarios{
+		PsicologoID: "psico-1", De: "2025-06-02", Ate: "2025-06-02",
+		DiasSemana: []int{1}, Horarios: []string{"09:00"}, DuracaoMinutos: 50,
+	})
+	req, _ := http.NewRequest("POST", "/horarios/grade", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+	h.HandlerGerarHorarios(rr, req)
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v", status, http.StatusCreated)
+	}
+	// 09:00 em Manaus (-04) é 13:00 UTC.
+	if len(criados) != 1 || !criados[0].Inicio.Equal(time.Date(2025, 6, 2, 13, 0, 0, 0, time.UTC)) {
+		t.Errorf("horário gerado no fuso errado: %+v", criados)
+	}
+}
+
+func TestHandlerListarHorariosPorDiaLocal(t *testing.T) {
+	h := NewHorarioDisponivelHandler(&mocks.HorarioDisponivelRepositoryMock{
+		ListarHorariosPorPsicologoFunc: func(ctx context.Context, psicologoID string, status string) ([]*model.HorarioDisponivel, error) {
+			return []*model.HorarioDisponivel{
+				// 23h do dia 02 em São Paulo, mas já dia 03 em UTC.
+				{ID: "noite", Inicio: time.Date(2025, 6, 3, 2, 0, 0, 0, time.UTC), Fim: time.Date(2025, 6, 3, 3, 0, 0, 0, time.UTC)},
+				{ID: "dia-3", Inicio: time.Date(2025, 6, 3, 13, 0, 0, 0, time.UTC), Fim: time.Date(2025, 6, 3, 14, 0, 0, 0, time.UTC)},
+			}, nil
+		},
+	})
+
+	req, _ := http.NewRequest("GET", "/horarios?psicologoId=psico-1&data=2025-06-02", nil)
+	rr := httptest.NewRecorder()
+	h.HandlerListarHorarios(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v", status, http.StatusOK)
+	}
+	var horarios []struct {
+		ID     string `json:"id"`
+		Inicio string `json:"inicio"`
+	}
+	json.NewDecoder(rr.Body).Decode(&horarios)
+	if len(horarios) != 1 || horarios[0].ID != "noite" || horarios[0].Inicio != "2025-06-02T23:00:00-03:00" {
+		t.Errorf("filtro por dia local incorreto: %+v", horarios)
+	}
+}
+
+func TestOcupacaoNoDiaDaTrocaDeHorario(t *testing.T) {
+	var duracao time.Duration
+	h := NewSalaHandler(
+		&mocks.SalaRepositoryMock{
+			BuscarSalaPorIDFunc: func(ctx context.Context, id string) (*model.Sala, error) {
+				return &model.Sala{ID: id, Nome: "Sala 1", Capacidade: 2}, nil
+			},
+		},
+		&mocks.HorarioDisponivelRepositoryMock{
+			ListarHorariosPorSalaFunc: func(ctx context.Context, salaID string, de, ate time.Time) ([]*model.HorarioDisponivel, error) {
+				duracao = ate.Sub(de)
+				return nil, nil
+			},
+		},
+	)
+
+	req, _ := http.NewRequest("GET", "/salas/ocupacao?data=2025-03-09&salaId=sala-1&fuso=America/New_York", nil)
+	rr := httptest.NewRecorder()
+	h.HandlerOcupacaoSalas(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v", status, http.StatusOK)
+	}
+	if duracao != 23*time.Hour {
+		t.Errorf("o dia da troca deveria ter 23 horas, teve %v", duracao)
+	}
+}
+
+func TestFormatacaoDeDatasPorFuso(t *testing.T) {
+	inicio := time.Date(2025, 6, 2, 13, 0, 0, 0, time.UTC)
+
+	if got := service.FormatarDataHora(inicio, service.Fuso("")); got != "02/06/2025 às 10:00" {
+		t.Errorf("fuso padrão: obteve %q", got)
+	}
+	if got := service.FormatarDataHora(inicio, service.Fuso("America/Manaus")); got != "02/06/2025 às 09:00" {
+		t.Errorf("fuso do aluno: obteve %q", got)
+	}
+	if got := service.FormatarDataHora(inicio, service.Fuso("Fuso/Inexistente")); got != "02/06/2025 às 10:00" {
+		t.Errorf("fuso inválido deveria cair no padrão: obteve %q", got)
+	}
+
+	// O convite sai sempre em UTC, qualquer que seja o fuso do valor em memória.
+	local := inicio.In(service.Fuso(""))
+	ics := string(service.GerarICS(model.Consulta{ID: "c1", Inicio: local, Fim: local.Add(time.Hour)}, "Ana"))
+	if !strings.Contains(ics, "DTSTART:20250602T130000Z") {
+		t.Errorf("DTSTART deveria estar em UTC:\n%s", ics)
+	}
+}
+
+func TestPerfilComFusoInvalido(t *testing.T) {
+	if err := validarPerfilAluno(model.Aluno{FusoHorario: "America/Manaus"}); err != nil {
+		t.Errorf("fuso válido recusado: %v", err)
+	}
+	if err := validarPerfilAluno(model.Aluno{FusoHorario: "GMT-3 Brasília"}); err == nil {
+		t.Errorf("fuso inválido aceito")
+	}
+}
@@ -2,15 +2,26 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"sgp/Internal/model"
 	"sgp/Internal/repository"
+	"sgp/Internal/service"
 	"time"
 	"context"
 )
 
 type HorarioDisponivelHandler struct {
 	Repo repository.HorarioDisponivelRepository
+
+	// SalaRepo é opcional; quando presente, a sala informada precisa existir.
+	SalaRepo repository.SalaRepository
+	// Calendario é opcional; quando presente, feriados e fechamentos são respeitados.
+	Calendario *service.CalendarioService
+	// PsicologoRepo é opcional; fornece o fuso do psicólogo para grades e consultas por dia.
+	PsicologoRepo repository.PsicologoRepository
 }
 
 func NewHorarioDisponivelHandler(repo repository.HorarioDisponivelRepository) *HorarioDisponivelHandler {
@@ -31,14 +42,63 @@ func (h *HorarioDisponivelHandler) HandlerCriarHorario(w http.ResponseWriter, r
 		return
 	}
 
+	if horario.Modalidade != "" && horario.Modalidade != model.ModalidadePresencial && horario.Modalidade != model.ModalidadeOnline {
+		httpError(w, "Modalidade inválida: use 'presencial' ou 'online'", http.StatusBadRequest)
+		return
+	}
+	if err := validarTipoHorario(horario.Tipo, horario.Capacidade); err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	horario.Inscritos, horario.ListaEspera = 0, 0
+
 	if horario.Status != "bloqueado" {
 		horario.Status = "disponivel"
 	}
+	horario.Inicio, horario.Fim = horario.Inicio.UTC(), horario.Fim.UTC()
 
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
+	if horario.SalaID != "" {
+		if horario.Modalidade == model.ModalidadeOnline {
+			httpError(w, "Horários online não usam sala", http.StatusBadRequest)
+			return
+		}
+		if !horario.Fim.After(horario.Inicio) {
+			httpError(w, "O fim do horário deve ser posterior ao início", http.StatusBadRequest)
+			return
+		}
+		if h.SalaRepo != nil {
+			sala, err := h.SalaRepo.BuscarSalaPorID(ctx, horario.SalaID)
+			if err != nil {
+				httpError(w, "Sala não encontrada", http.StatusNotFound)
+				return
+			}
+			if err := capacidadeCabeNaSala(horario.Capacidade, sala); err != nil {
+				httpError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	if h.Calendario != nil && horario.Status == "disponivel" {
+		if err := h.Calendario.VerificarHorario(ctx, horario.Inicio, horario.Fim); err != nil {
+			if errors.Is(err, service.ErrDataBloqueada) {
+				httpError(w, err.Error(), http.StatusUnprocessableEntity)
+			} else {
+				log.Printf("ERRO ao consultar calendário: %v", err)
+				httpError(w, "Erro ao consultar calendário", http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+
 	novoHorario, err := h.Repo.CriarHorario(ctx, horario)
+	if errors.Is(err, repository.ErrConflitoSala) {
+		httpError(w, "A sala já está ocupada neste intervalo", http.StatusConflict)
+		return
+	}
 	if err != nil {
 		httpError(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -49,6 +109,101 @@ func (h *HorarioDisponivelHandler) HandlerCriarHorario(w http.ResponseWriter, r
 	json.NewEncoder(w).Encode(novoHorario)
 }
 
+// HandlerGerarHorarios responde à rota POST /horarios/grade. Cria os horários
+// semanais da grade, pulando os que caem em feriados, fechamentos ou em
+// salas já ocupadas; os pulados voltam em "ignorados" com o motivo.
+func (h *HorarioDisponivelHandler) HandlerGerarHorarios(w http.ResponseWriter, r *http.Request) {
+	var grade service.GradeHorarios
+	if err := json.NewDecoder(r.Body).Decode(&grade); err != nil {
+		httpError(w, "Requisição inválida", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if grade.Modalidade != "" && grade.Modalidade != model.ModalidadePresencial && grade.Modalidade != model.ModalidadeOnline {
+		httpError(w, "Modalidade inválida: use 'presencial' ou 'online'", http.StatusBadRequest)
+		return
+	}
+	if grade.SalaID != "" && grade.Modalidade == model.ModalidadeOnline {
+		httpError(w, "Horários online não usam sala", http.StatusBadRequest)
+		return
+	}
+	if err := validarTipoHorario(grade.Tipo, grade.Capacidade); err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if grade.FusoHorario == "" {
+		grade.FusoHorario = h.fusoDoPsicologo(ctx, grade.PsicologoID)
+	}
+	gerados, err := service.GerarHorarios(grade)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if grade.SalaID != "" && h.SalaRepo != nil {
+		sala, err := h.SalaRepo.BuscarSalaPorID(ctx, grade.SalaID)
+		if err != nil {
+			httpError(w, "Sala não encontrada", http.StatusNotFound)
+			return
+		}
+		if err := capacidadeCabeNaSala(grade.Capacidade, sala); err != nil {
+			httpError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var eventos []*model.EventoCalendario
+	if h.Calendario != nil && len(gerados) > 0 {
+		eventos, err = h.Calendario.Repo.ListarEventos(ctx, gerados[0].Inicio, gerados[len(gerados)-1].Fim)
+		if err != nil {
+			log.Printf("ERRO ao consultar calendário: %v", err)
+			httpError(w, "Erro ao consultar calendário", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	type ignorado struct {
+		Inicio time.Time `json:"inicio"`
+		Motivo string    `json:"motivo"`
+	}
+	criados := []*model.HorarioDisponivel{}
+	ignorados := []ignorado{}
+	for _, horario := range gerados {
+		if h.Calendario != nil {
+			if err := h.Calendario.VerificarIntervalo(eventos, horario.Inicio, horario.Fim); err != nil {
+				ignorados = append(ignorados, ignorado{horario.Inicio, err.Error()})
+				continue
+			}
+		}
+
+		novo, err := h.Repo.CriarHorario(ctx, horario)
+		if errors.Is(err, repository.ErrConflitoSala) {
+			ignorados = append(ignorados, ignorado{horario.Inicio, err.Error()})
+			continue
+		}
+		if err != nil {
+			log.Printf("ERRO ao criar horário da grade: %v", err)
+			httpError(w, "Erro ao criar horários", http.StatusInternalServerError)
+			return
+		}
+		criados = append(criados, novo)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"criados":   criados,
+		"ignorados": ignorados,
+	})
+}
+
+// HandlerListarHorarios responde à rota GET /horarios?psicologoId=...[&status=...][&data=AAAA-MM-DD][&fuso=...].
+// Os horários saem no fuso pedido (ou no do psicólogo), e "data" filtra pelo dia nesse fuso.
 func (h *HorarioDisponivelHandler) HandlerListarHorarios(w http.ResponseWriter, r *http.Request) {
 	psicologoId := r.URL.Query().Get("psicologoId")
 	status := r.URL.Query().Get("status")
@@ -58,15 +213,44 @@ func (h *HorarioDisponivelHandler) HandlerListarHorarios(w http.ResponseWriter,
 		return
 	}
 
+	nomeFuso := r.URL.Query().Get("fuso")
+	if err := service.ValidarFuso(nomeFuso); err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	horarios, err := h.Repo.ListarHorariosPorPsicologo(ctx, psicologoId, status)
+	if nomeFuso == "" {
+		nomeFuso = h.fusoDoPsicologo(ctx, psicologoId)
+	}
+	fuso := service.Fuso(nomeFuso)
+
+	var deDia, ateDia time.Time
+	if data := r.URL.Query().Get("data"); data != "" {
+		var err error
+		if deDia, ateDia, err = service.InicioDoDia(data, fuso); err != nil {
+			httpError(w, "Parâmetro 'data' inválido, use AAAA-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+
+	todos, err := h.Repo.ListarHorariosPorPsicologo(ctx, psicologoId, status)
 	if err != nil {
 		httpError(w, "Erro ao listar horários", http.StatusInternalServerError)
 		return
 	}
 
+	horarios := []*model.HorarioDisponivel{}
+	for _, horario := range todos {
+		if !deDia.IsZero() && (horario.Inicio.Before(deDia) || !horario.Inicio.Before(ateDia)) {
+			continue
+		}
+		horario.Inicio, horario.Fim = horario.Inicio.In(fuso), horario.Fim.In(fuso)
+		horarios = append(horarios, horario)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(horarios)
@@ -87,4 +271,39 @@ func (h *HorarioDisponivelHandler) HandlerDeletarHorario(w http.ResponseWriter,
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
-}
\ No newline at end of file
+}
+
+func (h *HorarioDisponivelHandler) fusoDoPsicologo(ctx context.Context, psicologoID string) string {
+	if h.PsicologoRepo == nil {
+		return ""
+	}
+	psicologo, err := h.PsicologoRepo.BuscarPsicologoPorID(ctx, psicologoID)
+	if err != nil {
+		return ""
+	}
+	return psicologo.FusoHorario
+}
+
+// validarTipoHorario exige capacidade nos horários de grupo e a recusa nos individuais.
+func validarTipoHorario(tipo string, capacidade int) error {
+	switch tipo {
+	case model.HorarioIndividual:
+		if capacidade != 0 {
+			return errors.New("Só horários de grupo têm capacidade")
+		}
+	case model.HorarioGrupo:
+		if capacidade < 2 {
+			return errors.New("Horários de grupo precisam de capacidade de pelo menos 2")
+		}
+	default:
+		return errors.New("Tipo inválido: use 'grupo' ou deixe vazio para atendimento individual")
+	}
+	return nil
+}
+
+func capacidadeCabeNaSala(capacidade int, sala *model.Sala) error {
+	if sala.Capacidade > 0 && capacidade > sala.Capacidade {
+		return fmt.Errorf("A sala '%s' comporta no máximo %d pessoas", sala.Nome, sala.Capacidade)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sgp/Internal/model"
+	"sgp/Internal/repository/mocks"
+	"testing"
+	"time"
+)
+
+func TestHandlerCriarHorarioDeGrupo(t *testing.T) {
+	inicio := time.Date(2025, 6, 2, 14, 0, 0, 0, time.UTC)
+	h := NewHorarioDisponivelHandler(&mocks.HorarioDisponivelRepositoryMock{
+		CriarHorarioFunc: func(ctx context.Context, horario model.HorarioDisponivel) (*model.HorarioDisponivel, error) {
+			horario.ID = "g1"
+			return &horario, nil
+		},
+	})
+	h.SalaRepo = &mocks.SalaRepositoryMock{
+		BuscarSalaPorIDFunc: func(ctx context.Context, id string) (*model.Sala, error) {
+			return &model.Sala{ID: id, Nome: "Sala 1", Capacidade: 10}, nil
+		},
+	}
+
+	casos := []struct {
+		nome       string
+		tipo       string
+		capacidade int
+		salaID     string
+		esperado   int
+	}{
+		{"grupo valido", model.HorarioGrupo, 8, "sala-1", http.StatusCreated},
+		{"grupo sem capacidade", model.HorarioGrupo, 0, "", http.StatusBadRequest},
+		{"grupo maior que a sala", model.HorarioGrupo, 12, "sala-1", http.StatusBadRequest},
+		{"individual com capacidade", model.HorarioIndividual, 4, "", http.StatusBadRequest},
+		{"tipo desconhecido", "palestra", 20, "", http.StatusBadRequest},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			body, _ := json.Marshal(model.HorarioDisponivel{
+				PsicologoID: "psico-1", Inicio: inicio, Fim: inicio.Add(90 * time.Minute),
+				SalaID: tc.salaID, Tipo: tc.tipo, Titulo: "Oficina de ansiedade", Capacidade: tc.capacidade,
+				Inscritos: 5, // ignorado na criação
+			})
+			req, _ := http.NewRequest("POST", "/horarios", bytes.NewBuffer(body))
+			rr := httptest.NewRecorder()
+			h.HandlerCriarHorario(rr, req)
+
+			if status := rr.Code; status != tc.esperado {
+				t.Fatalf("status code incorreto: obteve %v, esperava %v (%s)", status, tc.esperado, rr.Body.String())
+			}
+			if tc.esperado != http.StatusCreated {
+				return
+			}
+			var criado model.HorarioDisponivel
+			json.NewDecoder(rr.Body).Decode(&criado)
+			if criado.Inscritos != 0 || criado.Status != "disponivel" || criado.Capacidade != tc.capacidade {
+				t.Errorf("horário de grupo criado incorretamente: %+v", criado)
+			}
+		})
+	}
+}
@@ -0,0 +1,364 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/mail"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/service"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// TamanhoLoteImportacao fica abaixo do limite de 500 escritas por lote do Firestore.
+	TamanhoLoteImportacao = 200
+	TamanhoMaximoPlanilha = 10 << 20
+	TimeoutImportacao     = 2 * time.Minute
+)
+
+type ImportacaoHandler struct {
+	AlunoRepo     repository.AlunoRepository
+	PsicologoRepo repository.PsicologoRepository
+	TamanhoLote   int
+}
+
+func NewImportacaoHandler(alunoRepo repository.AlunoRepository, psicologoRepo repository.PsicologoRepository) *ImportacaoHandler {
+	return &ImportacaoHandler{AlunoRepo: alunoRepo, PsicologoRepo: psicologoRepo, TamanhoLote: TamanhoLoteImportacao}
+}
+
+// importador adapta a importação a um tipo de cadastro. montar valida uma
+// linha e guarda a entidade; gravar cria as entidades guardadas nas posições
+// dadas, numa escrita em lote, e devolve os IDs na mesma ordem.
+type importador struct {
+	obrigatorias []string
+	existentes   func(ctx context.Context) ([]string, error)
+	montar       func(campo func(string) string) error
+	gravar       func(ctx context.Context, posicoes []int) ([]string, error)
+}
+
+// HandlerImportar responde à rota POST /importacoes/{tipo}, com tipo "alunos"
+// ou "psicologos". A planilha (CSV ou XLSX) vem no corpo ou no campo
+// 'arquivo' de um formulário multipart; ?simular=true só valida. E-mails já
+// cadastrados são ignorados, então reexecutar a mesma planilha é seguro.
+func (h *ImportacaoHandler) HandlerImportar(w http.ResponseWriter, r *http.Request) {
+	tipo := r.PathValue("tipo")
+	var imp importador
+	switch tipo {
+	case "alunos":
+		imp = h.importadorAlunos()
+	case "psicologos":
+		imp = h.importadorPsicologos()
+	default:
+		httpError(w, "Tipo de importação inválido, use 'alunos' ou 'psicologos'", http.StatusNotFound)
+		return
+	}
+	simular, _ := strconv.ParseBool(r.URL.Query().Get("simular"))
+
+	conteudo, err := lerArquivoImportacao(w, r)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	linhas, err := service.LerPlanilha(conteudo)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(linhas) == 0 {
+		httpError(w, "A planilha está vazia", http.StatusBadRequest)
+		return
+	}
+
+	colunas := map[string]int{}
+	for i, nome := range linhas[0] {
+		colunas[strings.ToLower(strings.TrimSpace(nome))] = i
+	}
+	for _, obrigatoria := range imp.obrigatorias {
+		if _, ok := colunas[strings.ToLower(obrigatoria)]; !ok {
+			httpError(w, fmt.Sprintf("A planilha precisa da coluna '%s'", obrigatoria), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), TimeoutImportacao)
+	defer cancel()
+
+	cadastrados, err := imp.existentes(ctx)
+	if err != nil {
+		log.Printf("ERRO ao carregar %s existentes para importação: %v", tipo, err)
+		httpError(w, "Erro ao verificar cadastros existentes", http.StatusInternalServerError)
+		return
+	}
+	existentes := map[string]bool{}
+	for _, email := range cadastrados {
+		existentes[normalizarEmail(email)] = true
+	}
+
+	relatorio := model.RelatorioImportacao{Tipo: tipo, Simulacao: simular, Linhas: []*model.LinhaImportacao{}}
+	vistos := map[string]int{}
+	var aptas []*model.LinhaImportacao
+	for i, valores := range linhas[1:] {
+		if linhaVazia(valores) {
+			continue
+		}
+		campo := func(nome string) string {
+			if c, ok := colunas[strings.ToLower(nome)]; ok && c < len(valores) {
+				return strings.TrimSpace(valores[c])
+			}
+			return ""
+		}
+		linha := &model.LinhaImportacao{Linha: i + 2, Email: normalizarEmail(campo("email"))}
+		relatorio.Linhas = append(relatorio.Linhas, linha)
+
+		switch anterior, repetido := vistos[linha.Email]; {
+		case linha.Email != "" && existentes[linha.Email]:
+			linha.Status, linha.Motivo = model.LinhaIgnorada, "E-mail já cadastrado"
+		case linha.Email != "" && repetido:
+			linha.Status, linha.Motivo = model.LinhaComErro, fmt.Sprintf("E-mail repetido na linha %d", anterior)
+		default:
+			if err := imp.montar(campo); err != nil {
+				linha.Status, linha.Motivo = model.LinhaComErro, err.Error()
+				break
+			}
+			vistos[linha.Email] = linha.Linha
+			linha.Status = model.LinhaValida
+			aptas = append(aptas, linha)
+		}
+	}
+
+	if !simular {
+		tamanho := h.TamanhoLote
+		if tamanho <= 0 {
+			tamanho = TamanhoLoteImportacao
+		}
+		for inicio := 0; inicio < len(aptas); inicio += tamanho {
+			fim := min(inicio+tamanho, len(aptas))
+			posicoes := make([]int, 0, fim-inicio)
+			for p := inicio; p < fim; p++ {
+				posicoes = append(posicoes, p)
+			}
+			ids, err := imp.gravar(ctx, posicoes)
+			for j, linha := range aptas[inicio:fim] {
+				if err != nil {
+					linha.Status, linha.Motivo = model.LinhaComErro, "Falha ao gravar o lote; reexecute a importação"
+					continue
+				}
+				linha.Status, linha.ID = model.LinhaCriada, ids[j]
+			}
+			if err != nil {
+				log.Printf("ERRO ao gravar lote de %s (linhas %d a %d): %v", tipo, aptas[inicio].Linha, aptas[fim-1].Linha, err)
+			}
+		}
+	}
+
+	for _, linha := range relatorio.Linhas {
+		relatorio.Total++
+		switch linha.Status {
+		case model.LinhaCriada:
+			relatorio.Criados++
+		case model.LinhaValida:
+			relatorio.Validos++
+		case model.LinhaIgnorada:
+			relatorio.Ignorados++
+		case model.LinhaComErro:
+			relatorio.Erros++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(relatorio)
+}
+
+func (h *ImportacaoHandler) importadorAlunos() importador {
+	var alunos []model.Aluno
+	return importador{
+		obrigatorias: []string{"nome", "email"},
+		existentes: func(ctx context.Context) ([]string, error) {
+			cadastrados, err := h.AlunoRepo.ListarAlunos(ctx)
+			emails := make([]string, 0, len(cadastrados))
+			for _, a := range cadastrados {
+				emails = append(emails, a.Email)
+			}
+			return emails, err
+		},
+		montar: func(campo func(string) string) error {
+			aluno := model.Aluno{
+				Nome:           campo("nome"),
+				Email:          normalizarEmail(campo("email")),
+				NomeSocial:     campo("nomeSocial"),
+				Pronomes:       campo("pronomes"),
+				Curso:          campo("curso"),
+				Matricula:      campo("matricula"),
+				Telefone:       campo("telefone"),
+				DataNascimento: dataPlanilha(campo("dataNascimento")),
+				FusoHorario:    campo("fusoHorario"),
+			}
+			if err := validarEmailImportado(aluno.Nome, aluno.Email); err != nil {
+				return err
+			}
+			if err := validarPerfilAluno(aluno); err != nil {
+				return err
+			}
+			alunos = append(alunos, aluno)
+			return nil
+		},
+		gravar: func(ctx context.Context, posicoes []int) ([]string, error) {
+			lote := make([]model.Aluno, 0, len(posicoes))
+			for _, p := range posicoes {
+				lote = append(lote, alunos[p])
+			}
+			criados, err := h.AlunoRepo.CriarAlunosEmLote(ctx, lote)
+			if err != nil {
+				return nil, err
+			}
+			ids := make([]string, 0, len(criados))
+			for _, a := range criados {
+				ids = append(ids, a.ID)
+			}
+			return ids, nil
+		},
+	}
+}
+
+func (h *ImportacaoHandler) importadorPsicologos() importador {
+	var psicologos []model.Psicologo
+	return importador{
+		obrigatorias: []string{"nome", "email", "crp"},
+		existentes: func(ctx context.Context) ([]string, error) {
+			cadastrados, err := h.PsicologoRepo.ListarPsicologos(ctx)
+			emails := make([]string, 0, len(cadastrados))
+			for _, p := range cadastrados {
+				emails = append(emails, p.Email)
+			}
+			return emails, err
+		},
+		montar: func(campo func(string) string) error {
+			psicologo := model.Psicologo{
+				Nome:           campo("nome"),
+				Email:          normalizarEmail(campo("email")),
+				CRP:            campo("crp"),
+				Especialidades: listaPlanilha(campo("especialidades")),
+				Abordagem:      campo("abordagem"),
+				Idiomas:        listaPlanilha(campo("idiomas")),
+				Modalidades:    listaPlanilha(campo("modalidades")),
+				Bio:            campo("bio"),
+				FusoHorario:    campo("fusoHorario"),
+			}
+			if err := validarEmailImportado(psicologo.Nome, psicologo.Email); err != nil {
+				return err
+			}
+			if psicologo.CRP == "" {
+				return fmt.Errorf("Campo 'crp' é obrigatório")
+			}
+			for nome, limite := range map[string]*int{
+				"limiteSessoesSemana":   &psicologo.LimiteSessoesSemana,
+				"limitePacientesAtivos": &psicologo.LimitePacientesAtivos,
+			} {
+				if v := campo(nome); v != "" {
+					n, err := strconv.Atoi(v)
+					if err != nil {
+						return fmt.Errorf("Campo '%s' deve ser um número inteiro", nome)
+					}
+					*limite = n
+				}
+			}
+			if err := validarPerfilPsicologo(psicologo); err != nil {
+				return err
+			}
+			psicologos = append(psicologos, psicologo)
+			return nil
+		},
+		gravar: func(ctx context.Context, posicoes []int) ([]string, error) {
+			lote := make([]model.Psicologo, 0, len(posicoes))
+			for _, p := range posicoes {
+				lote = append(lote, psicologos[p])
+			}
+			criados, err := h.PsicologoRepo.CriarPsicologosEmLote(ctx, lote)
+			if err != nil {
+				return nil, err
+			}
+			ids := make([]string, 0, len(criados))
+			for _, p := range criados {
+				ids = append(ids, p.ID)
+			}
+			return ids, nil
+		},
+	}
+}
+
+// lerArquivoImportacao aceita a planilha crua no corpo ou no campo 'arquivo'
+// de um formulário multipart.
+func lerArquivoImportacao(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, TamanhoMaximoPlanilha)
+	defer r.Body.Close()
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		arquivo, _, err := r.FormFile("arquivo")
+		if err != nil {
+			return nil, fmt.Errorf("Envie a planilha no campo 'arquivo'")
+		}
+		defer arquivo.Close()
+		return io.ReadAll(arquivo)
+	}
+
+	conteudo, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Planilha ilegível ou maior que %d MB", TamanhoMaximoPlanilha>>20)
+	}
+	return conteudo, nil
+}
+
+func validarEmailImportado(nome, email string) error {
+	if nome == "" || email == "" {
+		return fmt.Errorf("Campos 'nome' e 'email' são obrigatórios")
+	}
+	if endereco, err := mail.ParseAddress(email); err != nil || endereco.Address != email {
+		return fmt.Errorf("E-mail inválido")
+	}
+	return nil
+}
+
+func normalizarEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func linhaVazia(valores []string) bool {
+	for _, v := range valores {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// listaPlanilha separa "ansiedade; luto, TCC" em itens.
+func listaPlanilha(valor string) []string {
+	itens := strings.FieldsFunc(valor, func(r rune) bool { return r == ',' || r == ';' || r == '|' })
+	lista := make([]string, 0, len(itens))
+	for _, item := range itens {
+		if item = strings.TrimSpace(item); item != "" {
+			lista = append(lista, item)
+		}
+	}
+	if len(lista) == 0 {
+		return nil
+	}
+	return lista
+}
+
+// dataPlanilha converte datas do XLSX, gravadas como dias desde 30/12/1899,
+// para AAAA-MM-DD; outros valores passam como vieram.
+func dataPlanilha(valor string) string {
+	if dias, err := strconv.ParseFloat(valor, 64); err == nil && dias > 0 {
+		return time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(dias)).Format("2006-01-02")
+	}
+	return valor
+}
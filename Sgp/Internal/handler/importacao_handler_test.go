@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sgp/Internal/model"
+	"sgp/Internal/repository/mocks"
+	"sgp/Internal/service"
+	"strings"
+	"testing"
+)
+
+// novoImportacaoHandler simula a coleção de alunos: o que é gravado passa a
+// existir na importação seguinte.
+func novoImportacaoHandler(alunos *[]*model.Aluno, lotes *int) *ImportacaoHandler {
+	h := NewImportacaoHandler(
+		&mocks.AlunoRepositoryMock{
+			ListarAlunosFunc: func(ctx context.Context) ([]*model.Aluno, error) {
+				return *alunos, nil
+			},
+			CriarAlunosEmLoteFunc: func(ctx context.Context, lote []model.Aluno) ([]*model.Aluno, error) {
+				*lotes++
+				var criados []*model.Aluno
+				for i := range lote {
+					a := lote[i]
+					a.ID = a.Email
+					criados = append(criados, &a)
+				}
+				*alunos = append(*alunos, criados...)
+				return criados, nil
+			},
+		},
+		&mocks.PsicologoRepositoryMock{
+			ListarPsicologosFunc: func(ctx context.Context) ([]*model.Psicologo, error) {
+				return nil, nil
+			},
+			CriarPsicologosEmLoteFunc: func(ctx context.Context, lote []model.Psicologo) ([]*model.Psicologo, error) {
+				*lotes++
+				var criados []*model.Psicologo
+				for i := range lote {
+					p := lote[i]
+					p.ID = "psico-" + p.CRP
+					criados = append(criados, &p)
+				}
+				return criados, nil
+			},
+		},
+	)
+	h.TamanhoLote = 2
+	return h
+}
+
+func importar(h *ImportacaoHandler, tipo, query string, req *http.Request) (int, model.RelatorioImportacao) {
+	req.SetPathValue("tipo", tipo)
+	req.URL.RawQuery = query
+	rr := httptest.NewRecorder()
+	h.HandlerImportar(rr, req)
+	var relatorio model.RelatorioImportacao
+	json.NewDecoder(rr.Body).Decode(&relatorio)
+	return rr.Code, relatorio
+}
+
+const planilhaAlunos = "\xef\xbb\xbfNome;Email;Curso;dataNascimento\n" +
+	"Ana Souza;ana@uni.br;Direito;2001-04-10\n" +
+	"Bruno Lima;BRUNO@uni.br;Física;\n" +
+	"Carla Dias;carla@uni.br;;\n" +
+	"\n" +
+	"Ana de novo;ana@uni.br;;\n" +
+	"Sem Email;;;\n" +
+	"Data Ruim;data@uni.br;;10/04/2001\n" +
+	"Diego Reis;diego@uni.br;Medicina;\n"
+
+func TestHandlerImportarAlunosCSV(t *testing.T) {
+	alunos := []*model.Aluno{{ID: "a-existente", Email: "Bruno@Uni.br"}}
+	lotes := 0
+	h := novoImportacaoHandler(&alunos, &lotes)
+	enviar := func(query string) (int, model.RelatorioImportacao) {
+		req, _ := http.NewRequest("POST", "/importacoes/alunos", strings.NewReader(planilhaAlunos))
+		return importar(h, "alunos", query, req)
+	}
+
+	code, simulacao := enviar("simular=true")
+	if code != http.StatusOK {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v", code, http.StatusOK)
+	}
+	if lotes != 0 || simulacao.Validos != 3 || simulacao.Ignorados != 1 || simulacao.Erros != 3 || simulacao.Total != 7 {
+		t.Fatalf("simulação incorreta (%d lotes): %+v", lotes, simulacao)
+	}
+	esperado := map[int]string{2: "valido", 3: "ignorado", 4: "valido", 6: "erro", 7: "erro", 8: "erro", 9: "valido"}
+	for _, l := range simulacao.Linhas {
+		if esperado[l.Linha] != l.Status {
+			t.Errorf("linha %d: status %q (%s), esperava %q", l.Linha, l.Status, l.Motivo, esperado[l.Linha])
+		}
+	}
+
+	_, importacao := enviar("")
+	if importacao.Criados != 3 || lotes != 2 || len(alunos) != 4 {
+		t.Fatalf("importação incorreta (%d lotes, %d alunos): %+v", lotes, len(alunos), importacao)
+	}
+	if alunos[1].Curso != "Direito" || alunos[1].DataNascimento != "2001-04-10" {
+		t.Errorf("aluno gravado incorretamente: %+v", alunos[1])
+	}
+
+	_, reexecucao := enviar("")
+	if reexecucao.Criados != 0 || reexecucao.Ignorados != 5 || lotes != 2 {
+		t.Errorf("reexecução não foi idempotente (%d lotes): %+v", lotes, reexecucao)
+	}
+}
+
+// xlsxDeTeste monta um XLSX mínimo, com textos compartilhados e uma célula
+// de texto embutido, como o Excel e o LibreOffice gravam.
+func xlsxDeTeste(t *testing.T) []byte {
+	return montarXLSX(t, map[string]string{
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<si><t>nome</t></si><si><t>email</t></si><si><t>crp</t></si><si><t>especialidades</t></si>` +
+			`<si><r><t>Helena </t></r><r><t>Prado</t></r></si><si><t>helena@uni.br</t></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c><c r="E1" t="s"><v>3</v></c></row>` +
+			`<row r="2"><c r="A2" t="s"><v>4</v></c><c r="B2" t="s"><v>5</v></c><c r="C2"><v>61234</v></c><c r="E2" t="inlineStr"><is><t>ansiedade; luto</t></is></c></row>` +
+			`<row r="3"><c r="A3" t="inlineStr"><is><t>Sem CRP</t></is></c><c r="B3" t="inlineStr"><is><t>semcrp@uni.br</t></is></c></row>` +
+			`</sheetData></worksheet>`,
+	})
+}
+
+func montarXLSX(t *testing.T, arquivos map[string]string) []byte {
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+	for nome, conteudo := range arquivos {
+		f, err := z.Create(nome)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(conteudo))
+	}
+	z.Close()
+	return buf.Bytes()
+}
+
+func TestHandlerImportarPsicologosXLSX(t *testing.T) {
+	var alunos []*model.Aluno
+	lotes := 0
+	h := novoImportacaoHandler(&alunos, &lotes)
+
+	var corpo bytes.Buffer
+	form := multipart.NewWriter(&corpo)
+	parte, _ := form.CreateFormFile("arquivo", "estagiarios.xlsx")
+	parte.Write(xlsxDeTeste(t))
+	form.Close()
+	req, _ := http.NewRequest("POST", "/importacoes/psicologos", &corpo)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	code, relatorio := importar(h, "psicologos", "", req)
+	if code != http.StatusOK {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v", code, http.StatusOK)
+	}
+	if relatorio.Criados != 1 || relatorio.Erros != 1 || lotes != 1 {
+		t.Fatalf("importação incorreta: %+v", relatorio)
+	}
+	if l := relatorio.Linhas[0]; l.ID != "psico-61234" || l.Email != "helena@uni.br" {
+		t.Errorf("linha importada incorretamente: %+v", l)
+	}
+}
+
+func TestHandlerImportarValidacao(t *testing.T) {
+	var alunos []*model.Aluno
+	lotes := 0
+	h := novoImportacaoHandler(&alunos, &lotes)
+
+	casos := []struct {
+		nome     string
+		tipo     string
+		planilha string
+		esperado int
+	}{
+		{"tipo invalido", "salas", "nome,email\n", http.StatusNotFound},
+		{"coluna faltando", "psicologos", "nome,email\nAna,ana@uni.br\n", http.StatusBadRequest},
+		{"planilha vazia", "alunos", "", http.StatusBadRequest},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/importacoes/"+tc.tipo, strings.NewReader(tc.planilha))
+			if code, _ := importar(h, tc.tipo, "", req); code != tc.esperado {
+				t.Errorf("status code incorreto: obteve %v, esperava %v", code, tc.esperado)
+			}
+		})
+	}
+}
+
+func TestHandlerImportarXLSXForaDosLimites(t *testing.T) {
+	var alunos []*model.Aluno
+	lotes := 0
+	h := novoImportacaoHandler(&alunos, &lotes)
+
+	aba := func(linhas string) string {
+		return `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + linhas + `</sheetData></worksheet>`
+	}
+	casos := []struct {
+		nome     string
+		arquivos map[string]string
+	}{
+		{"xml grande demais descompactado", map[string]string{
+			"xl/worksheets/sheet1.xml": aba(`<row r="1"><c r="A1" t="inlineStr"><is><t>` + strings.Repeat("a", service.TamanhoMaximoXMLPlanilha) + `</t></is></c></row>`),
+		}},
+		{"linha alem do limite", map[string]string{
+			"xl/worksheets/sheet1.xml": aba(`<row r="1048576"><c r="A1048576"><v>1</v></c></row>`),
+		}},
+		{"coluna alem do limite", map[string]string{
+			"xl/worksheets/sheet1.xml": aba(`<row r="1"><c r="XFD1"><v>1</v></c></row>`),
+		}},
+		{"referencia sem coluna", map[string]string{
+			"xl/worksheets/sheet1.xml": aba(`<row r="1"><c r="1"><v>1</v></c></row>`),
+		}},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/importacoes/alunos", bytes.NewReader(montarXLSX(t, tc.arquivos)))
+			if code, _ := importar(h, "alunos", "", req); code != http.StatusBadRequest {
+				t.Errorf("status code incorreto: obteve %v, esperava %v", code, http.StatusBadRequest)
+			}
+		})
+	}
+}
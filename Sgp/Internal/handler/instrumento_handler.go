@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/reqctx"
+	"sgp/Internal/service"
+	"sort"
+	"strings"
+	"time"
+)
+
+type InstrumentoHandler struct {
+	Repo          repository.InstrumentoRepository
+	AlunoRepo     repository.AlunoRepository
+	PsicologoRepo repository.PsicologoRepository
+	EmailService  *service.EmailService
+
+	// Plantao é opcional; quando presente, itens críticos também acionam o plantão.
+	Plantao *service.PlantaoService
+
+	// Tarefas acompanha os alertas assíncronos; nil só os dispara.
+	Tarefas *service.Tarefas
+
+	// ConsultaRepo libera a série de um aluno aos psicólogos que o atendem;
+	// sem ele, só o próprio aluno e os admins a veem.
+	ConsultaRepo repository.ConsultaRepository
+}
+
+func NewInstrumentoHandler(
+	repo repository.InstrumentoRepository,
+	alunoRepo repository.AlunoRepository,
+	psicologoRepo repository.PsicologoRepository,
+	emailService *service.EmailService,
+) *InstrumentoHandler {
+	return &InstrumentoHandler{
+		Repo:          repo,
+		AlunoRepo:     alunoRepo,
+		PsicologoRepo: psicologoRepo,
+		EmailService:  emailService,
+	}
+}
+
+// HandlerListarInstrumentos responde à rota GET /instrumentos
+func (h *InstrumentoHandler) HandlerListarInstrumentos(w http.ResponseWriter, r *http.Request) {
+	instrumentos := make([]model.Instrumento, 0, len(service.Instrumentos))
+	for _, i := range service.Instrumentos {
+		instrumentos = append(instrumentos, i)
+	}
+	sort.Slice(instrumentos, func(a, b int) bool { return instrumentos[a].ID < instrumentos[b].ID })
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(instrumentos)
+}
+
+// HandlerAtribuirInstrumento responde à rota POST /instrumentos/aplicacoes.
+// O psicólogo autenticado fica como responsável pela aplicação.
+func (h *InstrumentoHandler) HandlerAtribuirInstrumento(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		InstrumentoID string `json:"instrumentoId"`
+		AlunoID       string `json:"alunoId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		httpError(w, "Requisição inválida", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if payload.InstrumentoID == "" || payload.AlunoID == "" {
+		httpError(w, "Campos 'instrumentoId' e 'alunoId' são obrigatórios", http.StatusBadRequest)
+		return
+	}
+	if _, ok := service.Instrumentos[payload.InstrumentoID]; !ok {
+		httpError(w, "Instrumento desconhecido", http.StatusBadRequest)
+		return
+	}
+
+	ator := reqctx.AtorDe(r.Context())
+	if ator.ID == "" {
+		httpError(w, "Apenas psicólogos podem atribuir instrumentos", http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	aplicacao, err := h.Repo.CriarAplicacao(ctx, model.AplicacaoInstrumento{
+		InstrumentoID: payload.InstrumentoID,
+		AlunoID:       payload.AlunoID,
+		PsicologoID:   ator.ID,
+		Status:        "pendente",
+		AtribuidoEm:   time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("ERRO ao atribuir instrumento: %v", err)
+		httpError(w, "Erro ao atribuir instrumento", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(aplicacao)
+}
+
+// HandlerResponderInstrumento responde à rota POST /instrumentos/aplicacoes/{id}/respostas.
+// A pontuação é calculada no servidor e itens críticos geram alerta ao psicólogo.
+func (h *InstrumentoHandler) HandlerResponderInstrumento(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		httpError(w, "O ID da aplicação é obrigatório", http.StatusBadRequest)
+		return
+	}
+
+	var payload struct {
+		Respostas []int `json:"respostas"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		httpError(w, "Requisição inválida", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	aplicacao, err := h.Repo.BuscarAplicacaoPorID(ctx, id)
+	if err != nil {
+		httpError(w, "Aplicação não encontrada", http.StatusNotFound)
+		return
+	}
+	if reqctx.AtorDe(ctx).ID != aplicacao.AlunoID {
+		httpError(w, "Apenas o aluno pode responder este instrumento", http.StatusForbidden)
+		return
+	}
+	// Verificação rápida; a que vale é a da transação em ResponderAplicacao.
+	if aplicacao.Status != "pendente" {
+		httpError(w, "Este instrumento já foi respondido", http.StatusConflict)
+		return
+	}
+
+	resultado, err := service.PontuarInstrumento(service.Instrumentos[aplicacao.InstrumentoID], payload.Respostas)
+	if err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	aplicacao.Status = "respondido"
+	aplicacao.Respostas = payload.Respostas
+	aplicacao.Escore = resultado.Escore
+	aplicacao.Gravidade = resultado.Gravidade
+	aplicacao.ItensCriticos = resultado.ItensCriticos
+	aplicacao.Alerta = len(resultado.ItensCriticos) > 0
+	aplicacao.RespondidoEm = time.Now().UTC()
+
+	if err := h.Repo.ResponderAplicacao(ctx, id, *aplicacao); err != nil {
+		if errors.Is(err, repository.ErrTransicaoInvalida) {
+			httpError(w, "Este instrumento já foi respondido", http.StatusConflict)
+			return
+		}
+		log.Printf("ERRO ao salvar respostas da aplicação %s: %v", id, err)
+		httpError(w, "Erro ao salvar respostas", http.StatusInternalServerError)
+		return
+	}
+
+	if aplicacao.Alerta {
+		alerta := *aplicacao
+		motivo := strings.Join(resultado.Motivos, "; ")
+		h.Tarefas.Disparar(func() { h.alertarPsicologo(alerta, motivo) })
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(aplicacao)
+}
+
+// HandlerSerieInstrumento responde à rota GET /alunos/{id}/instrumentos/serie?instrumento=phq-9
+func (h *InstrumentoHandler) HandlerSerieInstrumento(w http.ResponseWriter, r *http.Request) {
+	alunoID := r.PathValue("id")
+	instrumentoID := r.URL.Query().Get("instrumento")
+	if alunoID == "" || instrumentoID == "" {
+		httpError(w, "O ID do aluno e o 'instrumento' são obrigatórios", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	podeVer, err := podeVerDadosSensiveis(ctx, h.ConsultaRepo, alunoID)
+	if err != nil {
+		log.Printf("ERRO ao verificar acesso à série do aluno %s: %v", alunoID, err)
+		httpError(w, "Erro ao verificar acesso", http.StatusInternalServerError)
+		return
+	}
+	if !podeVer {
+		httpError(w, "Acesso negado", http.StatusForbidden)
+		return
+	}
+
+	aplicacoes, err := h.Repo.ListarAplicacoesPorAluno(ctx, alunoID, instrumentoID)
+	if err != nil {
+		log.Printf("ERRO ao listar aplicações do aluno %s: %v", alunoID, err)
+		httpError(w, "Erro ao montar série do instrumento", http.StatusInternalServerError)
+		return
+	}
+
+	type ponto struct {
+		Data      time.Time `json:"data"`
+		Escore    int       `json:"escore"`
+		Gravidade string    `json:"gravidade"`
+		Alerta    bool      `json:"alerta"`
+	}
+	serie := []ponto{}
+	for _, a := range aplicacoes {
+		if a.Status == "respondido" {
+			serie = append(serie, ponto{Data: a.RespondidoEm, Escore: a.Escore, Gravidade: a.Gravidade, Alerta: a.Alerta})
+		}
+	}
+	sort.Slice(serie, func(i, j int) bool { return serie[i].Data.Before(serie[j].Data) })
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"alunoId":       alunoID,
+		"instrumentoId": instrumentoID,
+		"serie":         serie,
+	})
+}
+
+func (h *InstrumentoHandler) alertarPsicologo(aplicacao model.AplicacaoInstrumento, motivo string) {
+	bgCtx := context.Background()
+
+	if h.Plantao != nil {
+		_, err := h.Plantao.AbrirAlerta(bgCtx, model.AlertaPlantao{
+			Origem:       model.OrigemInstrumento,
+			AlunoID:      aplicacao.AlunoID,
+			ReferenciaID: aplicacao.ID,
+			Motivo:       motivo,
+		})
+		if err != nil {
+			log.Printf("ERRO ao abrir alerta de plantão para aplicação %s: %v", aplicacao.ID, err)
+		}
+	}
+
+	aluno, errA := h.AlunoRepo.BuscarAlunoPorID(bgCtx, aplicacao.AlunoID)
+	psico, errP := h.PsicologoRepo.BuscarPsicologoPorID(bgCtx, aplicacao.PsicologoID)
+	if errA != nil || errP != nil {
+		log.Printf("ERRO ao buscar dados para alerta de risco: AlunoErr: %v, PsicoErr: %v", errA, errP)
+		return
+	}
+
+	if err := h.EmailService.EnviarAlertaRisco(aluno.ID, psico.Email, psico.Nome, service.NomeDeTratamento(aluno), motivo); err != nil {
+		log.Printf("ERRO ao enviar alerta de risco (Resend): %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/repository/mocks"
+	"sgp/Internal/reqctx"
+	"testing"
+	"time"
+)
+
+func novoInstrumentoHandlerTeste(aplicacao model.AplicacaoInstrumento, salva *model.AplicacaoInstrumento) *InstrumentoHandler {
+	repo := &mocks.InstrumentoRepositoryMock{
+		BuscarAplicacaoPorIDFunc: func(ctx context.Context, id string) (*model.AplicacaoInstrumento, error) {
+			a := aplicacao
+			return &a, nil
+		},
+		ResponderAplicacaoFunc: func(ctx context.Context, id string, a model.AplicacaoInstrumento) error {
+			*salva = a
+			return nil
+		},
+	}
+	// Erros nas buscas evitam o envio de e-mail na goroutine de alerta.
+	alunoRepo := &mocks.AlunoRepositoryMock{
+		BuscarAlunoPorIDFunc: func(ctx context.Context, id string) (*model.Aluno, error) {
+			return nil, errors.New("ignorar email no teste")
+		},
+	}
+	psicologoRepo := &mocks.PsicologoRepositoryMock{
+		BuscarPsicologoPorIDFunc: func(ctx context.Context, id string) (*model.Psicologo, error) {
+			return nil, errors.New("ignorar email no teste")
+		},
+	}
+	return NewInstrumentoHandler(repo, alunoRepo, psicologoRepo, nil)
+}
+
+func TestHandlerResponderInstrumento(t *testing.T) {
+	pendente := model.AplicacaoInstrumento{ID: "ap1", InstrumentoID: "phq-9", AlunoID: "aluno-1", PsicologoID: "psico-1", Status: "pendente"}
+
+	t.Run("pontua PHQ-9 e sinaliza item 9", func(t *testing.T) {
+		body, _ := json.Marshal(map[string][]int{"respostas": {2, 2, 1, 2, 1, 1, 1, 0, 1}})
+		req, _ := http.NewRequest("POST", "/instrumentos/aplicacoes/ap1/respostas", bytes.NewBuffer(body))
+		req.SetPathValue("id", "ap1")
+		req = req.WithContext(reqctx.ComAtor(req.Context(), reqctx.Ator{ID: "aluno-1"}))
+		rr := httptest.NewRecorder()
+
+		var salva model.AplicacaoInstrumento
+		h := novoInstrumentoHandlerTeste(pendente, &salva)
+		h.HandlerResponderInstrumento(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Fatalf("status code incorreto: obteve %v, esperava %v", status, http.StatusOK)
+		}
+		if salva.Escore != 11 || salva.Gravidade != "moderada" {
+			t.Errorf("pontuação incorreta: escore %d, gravidade %q", salva.Escore, salva.Gravidade)
+		}
+		if !salva.Alerta || len(salva.ItensCriticos) != 1 || salva.ItensCriticos[0] != 8 {
+			t.Errorf("item 9 deveria gerar alerta: %+v", salva)
+		}
+	})
+
+	t.Run("resposta concorrente ja gravada", func(t *testing.T) {
+		body, _ := json.Marshal(map[string][]int{"respostas": {0, 0, 0, 0, 0, 0, 0, 0, 0}})
+		req, _ := http.NewRequest("POST", "/instrumentos/aplicacoes/ap1/respostas", bytes.NewBuffer(body))
+		req.SetPathValue("id", "ap1")
+		req = req.WithContext(reqctx.ComAtor(req.Context(), reqctx.Ator{ID: "aluno-1"}))
+		rr := httptest.NewRecorder()
+
+		var salva model.AplicacaoInstrumento
+		h := novoInstrumentoHandlerTeste(pendente, &salva)
+		// A leitura ainda viu "pendente", mas outra resposta chegou antes da transação.
+		h.Repo.(*mocks.InstrumentoRepositoryMock).ResponderAplicacaoFunc = func(ctx context.Context, id string, a model.AplicacaoInstrumento) error {
+			return repository.ErrTransicaoInvalida
+		}
+		h.HandlerResponderInstrumento(rr, req)
+
+		if status := rr.Code; status != http.StatusConflict {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusConflict)
+		}
+	})
+
+	t.Run("quantidade de respostas incorreta", func(t *testing.T) {
+		body, _ := json.Marshal(map[string][]int{"respostas": {0, 0, 0}})
+		req, _ := http.NewRequest("POST", "/instrumentos/aplicacoes/ap1/respostas", bytes.NewBuffer(body))
+		req.SetPathValue("id", "ap1")
+		req = req.WithContext(reqctx.ComAtor(req.Context(), reqctx.Ator{ID: "aluno-1"}))
+		rr := httptest.NewRecorder()
+
+		var salva model.AplicacaoInstrumento
+		h := novoInstrumentoHandlerTeste(pendente, &salva)
+		h.HandlerResponderInstrumento(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestHandlerSerieInstrumento(t *testing.T) {
+	agora := time.Now()
+	novoHandler := func() *InstrumentoHandler {
+		h := NewInstrumentoHandler(&mocks.InstrumentoRepositoryMock{
+			ListarAplicacoesPorAlunoFunc: func(ctx context.Context, alunoID, instrumentoID string) ([]*model.AplicacaoInstrumento, error) {
+				return []*model.AplicacaoInstrumento{
+					{Status: "respondido", Escore: 8, RespondidoEm: agora},
+					{Status: "pendente"},
+					{Status: "respondido", Escore: 15, RespondidoEm: agora.Add(-30 * 24 * time.Hour)},
+				}, nil
+			},
+		}, nil, nil, nil)
+		h.ConsultaRepo = &mocks.ConsultaRepositoryMock{
+			ListarConsultasPorPsicologoFunc: func(ctx context.Context, psicologoID, status string) ([]*model.Consulta, error) {
+				if psicologoID != "psico-1" {
+					return nil, nil
+				}
+				return []*model.Consulta{{AlunoID: "aluno-1", Status: "confirmada"}}, nil
+			},
+		}
+		return h
+	}
+
+	t.Run("serie ordenada por data", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/alunos/aluno-1/instrumentos/serie?instrumento=gad-7", nil)
+		req.SetPathValue("id", "aluno-1")
+		req = req.WithContext(reqctx.ComAtor(req.Context(), reqctx.Ator{ID: "psico-1", Papel: reqctx.PapelPsicologo}))
+		rr := httptest.NewRecorder()
+
+		novoHandler().HandlerSerieInstrumento(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Fatalf("status code incorreto: obteve %v, esperava %v", status, http.StatusOK)
+		}
+		var resp struct {
+			Serie []struct {
+				Escore int `json:"escore"`
+			} `json:"serie"`
+		}
+		json.NewDecoder(rr.Body).Decode(&resp)
+		if len(resp.Serie) != 2 || resp.Serie[0].Escore != 15 || resp.Serie[1].Escore != 8 {
+			t.Errorf("série incorreta: %+v", resp.Serie)
+		}
+	})
+
+	t.Run("psicologo que nao atende o aluno", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/alunos/aluno-1/instrumentos/serie?instrumento=gad-7", nil)
+		req.SetPathValue("id", "aluno-1")
+		req = req.WithContext(reqctx.ComAtor(req.Context(), reqctx.Ator{ID: "psico-2", Papel: reqctx.PapelPsicologo}))
+		rr := httptest.NewRecorder()
+
+		novoHandler().HandlerSerieInstrumento(rr, req)
+
+		if status := rr.Code; status != http.StatusForbidden {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusForbidden)
+		}
+	})
+}
@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sgp/Internal/model"
+	"sgp/Internal/reqctx"
+	"sgp/Internal/service"
+	"time"
+)
+
+type PlantaoHandler struct {
+	Service *service.PlantaoService
+}
+
+func NewPlantaoHandler(s *service.PlantaoService) *PlantaoHandler {
+	return &PlantaoHandler{Service: s}
+}
+
+// HandlerCriarTurno responde à rota POST /plantao/turnos
+func (h *PlantaoHandler) HandlerCriarTurno(w http.ResponseWriter, r *http.Request) {
+	var turno model.TurnoPlantao
+	if err := json.NewDecoder(r.Body).Decode(&turno); err != nil {
+		httpError(w, "Requisição inválida", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if turno.PsicologoID == "" {
+		httpError(w, "O campo 'psicologoId' é obrigatório", http.StatusBadRequest)
+		return
+	}
+	if !turno.Fim.After(turno.Inicio) {
+		httpError(w, "O fim do turno deve ser posterior ao início", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	if _, err := h.Service.PsicologoRepo.BuscarPsicologoPorID(ctx, turno.PsicologoID); err != nil {
+		httpError(w, "Psicólogo não encontrado", http.StatusNotFound)
+		return
+	}
+
+	criado, err := h.Service.Repo.CriarTurno(ctx, turno)
+	if err != nil {
+		log.Printf("ERRO ao criar turno de plantão: %v", err)
+		httpError(w, "Erro ao criar turno de plantão", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(criado)
+}
+
+// HandlerListarTurnos responde à rota GET /plantao/turnos?de=...&ate=...
+// Sem parâmetros, retorna a escala dos próximos 7 dias.
+func (h *PlantaoHandler) HandlerListarTurnos(w http.ResponseWriter, r *http.Request) {
+	de := time.Now().UTC()
+	ate := de.AddDate(0, 0, 7)
+
+	var err error
+	if v := r.URL.Query().Get("de"); v != "" {
+		if de, err = time.Parse(time.RFC3339, v); err != nil {
+			httpError(w, "Parâmetro 'de' inválido, use RFC3339", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("ate"); v != "" {
+		if ate, err = time.Parse(time.RFC3339, v); err != nil {
+			httpError(w, "Parâmetro 'ate' inválido, use RFC3339", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	turnos, err := h.Service.Repo.ListarTurnos(ctx, de, ate)
+	if err != nil {
+		log.Printf("ERRO ao listar turnos de plantão: %v", err)
+		httpError(w, "Erro ao listar turnos de plantão", http.StatusInternalServerError)
+		return
+	}
+	if turnos == nil {
+		turnos = []*model.TurnoPlantao{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(turnos)
+}
+
+// HandlerDeletarTurno responde à rota DELETE /plantao/turnos/{id}
+func (h *PlantaoHandler) HandlerDeletarTurno(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		httpError(w, "O ID do turno é obrigatório", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	if err := h.Service.Repo.DeletarTurno(ctx, id); err != nil {
+		log.Printf("ERRO ao deletar turno %s: %v", id, err)
+		httpError(w, "Erro ao deletar turno", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandlerPlantaoAgora responde à rota GET /plantao/agora com quem está de
+// plantão neste momento, na ordem em que será acionado.
+func (h *PlantaoHandler) HandlerPlantaoAgora(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	agora := time.Now().UTC()
+	turnos, err := h.Service.Plantonistas(ctx, agora)
+	if err != nil {
+		log.Printf("ERRO ao buscar plantonistas: %v", err)
+		httpError(w, "Erro ao buscar plantão", http.StatusInternalServerError)
+		return
+	}
+
+	type plantonista struct {
+		Turno     *model.TurnoPlantao `json:"turno"`
+		Psicologo *model.Psicologo    `json:"psicologo"`
+	}
+	plantonistas := []plantonista{}
+	for _, t := range turnos {
+		psicologo, err := h.Service.PsicologoRepo.BuscarPsicologoPorID(ctx, t.PsicologoID)
+		if err != nil {
+			log.Printf("ERRO ao buscar plantonista %s: %v", t.PsicologoID, err)
+			continue
+		}
+		plantonistas = append(plantonistas, plantonista{Turno: t, Psicologo: psicologo})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"em":           agora,
+		"plantonistas": plantonistas,
+	})
+}
+
+// HandlerListarAlertas responde à rota GET /plantao/alertas?status=pendente
+func (h *PlantaoHandler) HandlerListarAlertas(w http.ResponseWriter, r *http.Request) {
+	statusFiltro := r.URL.Query().Get("status")
+	if statusFiltro == "" {
+		statusFiltro = model.AlertaPendente
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	alertas, err := h.Service.Repo.ListarAlertasPorStatus(ctx, statusFiltro)
+	if err != nil {
+		log.Printf("ERRO ao listar alertas de plantão: %v", err)
+		httpError(w, "Erro ao listar alertas", http.StatusInternalServerError)
+		return
+	}
+	if alertas == nil {
+		alertas = []*model.AlertaPlantao{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(alertas)
+}
+
+// HandlerReconhecerAlerta responde à rota POST /plantao/alertas/{id}/reconhecer.
+// O psicólogo autenticado assume o caso e o escalonamento para.
+func (h *PlantaoHandler) HandlerReconhecerAlerta(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		httpError(w, "O ID do alerta é obrigatório", http.StatusBadRequest)
+		return
+	}
+
+	ator := reqctx.AtorDe(r.Context())
+	if ator.ID == "" {
+		httpError(w, "Apenas psicólogos podem reconhecer alertas", http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	alerta, err := h.Service.Reconhecer(ctx, id, ator.ID)
+	if err != nil {
+		if errors.Is(err, service.ErrAlertaEncerrado) {
+			httpError(w, err.Error(), http.StatusConflict)
+			return
+		}
+		log.Printf("ERRO ao reconhecer alerta %s: %v", id, err)
+		httpError(w, "Alerta não encontrado", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(alerta)
+}
@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/repository/mocks"
+	"sgp/Internal/reqctx"
+	"sgp/Internal/service"
+	"testing"
+	"time"
+)
+
+func novoPlantaoTeste(turnos []*model.TurnoPlantao, alertas map[string]*model.AlertaPlantao) *PlantaoHandler {
+	repo := &mocks.PlantaoRepositoryMock{
+		ListarTurnosFunc: func(ctx context.Context, de, ate time.Time) ([]*model.TurnoPlantao, error) {
+			var ativos []*model.TurnoPlantao
+			for _, t := range turnos {
+				if t.Fim.After(de) && t.Inicio.Before(ate) {
+					ativos = append(ativos, t)
+				}
+			}
+			return ativos, nil
+		},
+		BuscarAlertaPorIDFunc: func(ctx context.Context, id string) (*model.AlertaPlantao, error) {
+			a, ok := alertas[id]
+			if !ok {
+				return nil, errors.New("alerta não encontrado")
+			}
+			copia := *a
+			return &copia, nil
+		},
+		RepassarAlertaFunc: func(ctx context.Context, id string, acionadoEm time.Time, a model.AlertaPlantao) error {
+			atual := alertas[id]
+			if atual.Status != model.AlertaPendente || !atual.AcionadoEm.Equal(acionadoEm) {
+				return repository.ErrTransicaoInvalida
+			}
+			alertas[id] = &a
+			return nil
+		},
+		ReconhecerAlertaFunc: func(ctx context.Context, id, psicologoID string, em time.Time) (*model.AlertaPlantao, error) {
+			atual, ok := alertas[id]
+			if !ok {
+				return nil, errors.New("alerta não encontrado")
+			}
+			if atual.Status == model.AlertaReconhecido {
+				return nil, repository.ErrTransicaoInvalida
+			}
+			atual.Status, atual.ReconhecidoPor, atual.ReconhecidoEm = model.AlertaReconhecido, psicologoID, em
+			copia := *atual
+			return &copia, nil
+		},
+		ListarAlertasPorStatusFunc: func(ctx context.Context, status string) ([]*model.AlertaPlantao, error) {
+			var lista []*model.AlertaPlantao
+			for _, a := range alertas {
+				if a.Status == status {
+					copia := *a
+					lista = append(lista, &copia)
+				}
+			}
+			return lista, nil
+		},
+	}
+	psicologoRepo := &mocks.PsicologoRepositoryMock{
+		BuscarPsicologoPorIDFunc: func(ctx context.Context, id string) (*model.Psicologo, error) {
+			return &model.Psicologo{ID: id, Nome: "Psicólogo " + id, Email: id + "@exemplo.com"}, nil
+		},
+	}
+
+	// EmailService nil: o serviço só registra os acionamentos, sem enviar e-mail
+	s := service.NewPlantaoService(repo, psicologoRepo, &mocks.AlunoRepositoryMock{}, nil)
+	s.Prazo = 10 * time.Minute
+	return NewPlantaoHandler(s)
+}
+
+func TestHandlerPlantaoAgora(t *testing.T) {
+	agora := time.Now().UTC()
+	turnos := []*model.TurnoPlantao{
+		{ID: "t1", PsicologoID: "psico-b", Inicio: agora.Add(-time.Hour), Fim: agora.Add(time.Hour), Ordem: 2},
+		{ID: "t2", PsicologoID: "psico-a", Inicio: agora.Add(-time.Hour), Fim: agora.Add(time.Hour), Ordem: 1},
+		{ID: "t3", PsicologoID: "psico-c", Inicio: agora.Add(time.Hour), Fim: agora.Add(2 * time.Hour)},
+	}
+	h := novoPlantaoTeste(turnos, map[string]*model.AlertaPlantao{})
+
+	req, _ := http.NewRequest("GET", "/plantao/agora", nil)
+	rr := httptest.NewRecorder()
+	h.HandlerPlantaoAgora(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v", rr.Code, http.StatusOK)
+	}
+
+	var resposta struct {
+		Plantonistas []struct {
+			Psicologo model.Psicologo `json:"psicologo"`
+		} `json:"plantonistas"`
+	}
+	json.NewDecoder(rr.Body).Decode(&resposta)
+	if len(resposta.Plantonistas) != 2 {
+		t.Fatalf("número incorreto de plantonistas: obteve %d, esperava 2", len(resposta.Plantonistas))
+	}
+	if resposta.Plantonistas[0].Psicologo.ID != "psico-a" {
+		t.Errorf("ordem incorreta: primeiro plantonista foi %s", resposta.Plantonistas[0].Psicologo.ID)
+	}
+}
+
+func TestEscalonamentoPlantao(t *testing.T) {
+	agora := time.Now().UTC()
+	turnos := []*model.TurnoPlantao{
+		{ID: "t1", PsicologoID: "psico-a", Inicio: agora.Add(-time.Hour), Fim: agora.Add(time.Hour), Ordem: 1},
+		{ID: "t2", PsicologoID: "psico-b", Inicio: agora.Add(-time.Hour), Fim: agora.Add(time.Hour), Ordem: 2},
+	}
+	alertas := map[string]*model.AlertaPlantao{
+		"vencido": {ID: "vencido", Status: model.AlertaPendente, Acionados: []string{"psico-a"}, AcionadoEm: agora.Add(-11 * time.Minute)},
+		"recente": {ID: "recente", Status: model.AlertaPendente, Acionados: []string{"psico-a"}, AcionadoEm: agora.Add(-time.Minute)},
+		"ultimo":  {ID: "ultimo", Status: model.AlertaPendente, Acionados: []string{"psico-a", "psico-b"}, AcionadoEm: agora.Add(-time.Hour)},
+	}
+	h := novoPlantaoTeste(turnos, alertas)
+
+	n, err := h.Service.Escalonar(context.Background(), agora)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("número incorreto de alertas repassados: obteve %d, esperava 2", n)
+	}
+
+	if a := alertas["vencido"]; len(a.Acionados) != 2 || a.Acionados[1] != "psico-b" || a.Status != model.AlertaPendente {
+		t.Errorf("alerta vencido deveria ir para psico-b: %+v", a)
+	}
+	if a := alertas["recente"]; len(a.Acionados) != 1 {
+		t.Errorf("alerta recente não deveria ser repassado: %+v", a)
+	}
+	if a := alertas["ultimo"]; a.Status != model.AlertaSemPlantao {
+		t.Errorf("alerta sem próximo plantonista deveria ir para a reserva, status %q", a.Status)
+	}
+}
+
+func TestEscalonamentoPlantaoContinuaAposFalha(t *testing.T) {
+	agora := time.Now().UTC()
+	turnos := []*model.TurnoPlantao{
+		{ID: "t1", PsicologoID: "psico-a", Inicio: agora.Add(-time.Hour), Fim: agora.Add(time.Hour), Ordem: 1},
+		{ID: "t2", PsicologoID: "psico-b", Inicio: agora.Add(-time.Hour), Fim: agora.Add(time.Hour), Ordem: 2},
+	}
+	vencido := func(id string) *model.AlertaPlantao {
+		return &model.AlertaPlantao{ID: id, Status: model.AlertaPendente, Acionados: []string{"psico-a"}, AcionadoEm: agora.Add(-time.Hour)}
+	}
+	alertas := map[string]*model.AlertaPlantao{"falha": vencido("falha"), "reconhecido": vencido("reconhecido"), "ok": vencido("ok")}
+	h := novoPlantaoTeste(turnos, alertas)
+
+	repo := h.Service.Repo.(*mocks.PlantaoRepositoryMock)
+	repassar := repo.RepassarAlertaFunc
+	repo.RepassarAlertaFunc = func(ctx context.Context, id string, acionadoEm time.Time, a model.AlertaPlantao) error {
+		switch id {
+		case "falha":
+			return errors.New("firestore indisponível")
+		case "reconhecido":
+			// Reconhecido entre a listagem e o repasse.
+			alertas[id].Status = model.AlertaReconhecido
+		}
+		return repassar(ctx, id, acionadoEm, a)
+	}
+
+	n, err := h.Service.Escalonar(context.Background(), agora)
+	if err != nil {
+		t.Fatalf("falha num alerta não deveria interromper o lote: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("número incorreto de alertas repassados: obteve %d, esperava 1", n)
+	}
+	if a := alertas["ok"]; len(a.Acionados) != 2 {
+		t.Errorf("alerta ok deveria ser repassado: %+v", a)
+	}
+	if a := alertas["reconhecido"]; a.Status != model.AlertaReconhecido || len(a.Acionados) != 1 {
+		t.Errorf("repasse não deveria sobrescrever o reconhecimento: %+v", a)
+	}
+}
+
+func TestHandlerReconhecerAlerta(t *testing.T) {
+	alertas := map[string]*model.AlertaPlantao{
+		"alerta-1": {ID: "alerta-1", Status: model.AlertaPendente, Acionados: []string{"psico-a"}},
+	}
+	h := novoPlantaoTeste(nil, alertas)
+
+	reconhecer := func() *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/plantao/alertas/alerta-1/reconhecer", nil)
+		req.SetPathValue("id", "alerta-1")
+		req = req.WithContext(reqctx.ComAtor(req.Context(), reqctx.Ator{ID: "psico-a", Papel: reqctx.PapelPsicologo}))
+		rr := httptest.NewRecorder()
+		h.HandlerReconhecerAlerta(rr, req)
+		return rr
+	}
+
+	if rr := reconhecer(); rr.Code != http.StatusOK {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v", rr.Code, http.StatusOK)
+	}
+	if a := alertas["alerta-1"]; a.Status != model.AlertaReconhecido || a.ReconhecidoPor != "psico-a" {
+		t.Errorf("alerta não foi reconhecido: %+v", a)
+	}
+	if rr := reconhecer(); rr.Code != http.StatusConflict {
+		t.Errorf("segundo reconhecimento deveria falhar: obteve %v, esperava %v", rr.Code, http.StatusConflict)
+	}
+}
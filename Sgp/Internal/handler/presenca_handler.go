@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/reqctx"
+	"sgp/Internal/service"
+	"time"
+)
+
+// AntecedenciaCheckin é quanto antes do Inicio o check-in já é aceito.
+const AntecedenciaCheckin = 15 * time.Minute
+
+type PresencaHandler struct {
+	Repo repository.ConsultaRepository
+}
+
+func NewPresencaHandler(repo repository.ConsultaRepository) *PresencaHandler {
+	return &PresencaHandler{Repo: repo}
+}
+
+// HandlerCodigoCheckin responde à rota GET /consultas/{id}/checkin/codigo.
+// O psicólogo mostra o código (ou o QR com o campo "qr") para o aluno.
+func (h *PresencaHandler) HandlerCodigoCheckin(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	consulta, err := h.Repo.BuscarConsultaPorID(ctx, id)
+	if err != nil {
+		httpError(w, "Consulta não encontrada", http.StatusNotFound)
+		return
+	}
+	if reqctx.AtorDe(ctx).ID != consulta.PsicologoID {
+		httpError(w, "Apenas o psicólogo da consulta pode gerar o código", http.StatusForbidden)
+		return
+	}
+
+	codigo := consulta.CodigoCheckin
+	if codigo == "" {
+		if codigo, err = gerarCodigoCheckin(); err != nil {
+			log.Printf("ERRO ao gerar código de check-in: %v", err)
+			httpError(w, "Erro ao gerar código", http.StatusInternalServerError)
+			return
+		}
+		if err := h.Repo.DefinirCodigoCheckin(ctx, id, codigo); err != nil {
+			log.Printf("ERRO ao salvar código de check-in da consulta %s: %v", id, err)
+			httpError(w, "Erro ao gerar código", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"codigo": codigo,
+		"qr":     fmt.Sprintf("sgp:checkin:%s:%s", id, codigo),
+	})
+}
+
+// HandlerCheckin responde à rota POST /consultas/{id}/checkin.
+// O psicólogo faz check-in direto; o aluno precisa enviar o código da sessão.
+func (h *PresencaHandler) HandlerCheckin(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var payload struct {
+		Codigo string `json:"codigo"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			httpError(w, "Requisição inválida", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	consulta, err := h.Repo.BuscarConsultaPorID(ctx, id)
+	if err != nil {
+		httpError(w, "Consulta não encontrada", http.StatusNotFound)
+		return
+	}
+
+	ator := reqctx.AtorDe(ctx)
+	switch {
+	case ator.ID != "" && ator.ID == consulta.PsicologoID:
+	case ator.ID != "" && ator.ID == consulta.AlunoID:
+		if consulta.CodigoCheckin == "" || subtle.ConstantTimeCompare([]byte(payload.Codigo), []byte(consulta.CodigoCheckin)) != 1 {
+			httpError(w, "Código de check-in inválido", http.StatusForbidden)
+			return
+		}
+	default:
+		httpError(w, "Apenas o aluno ou o psicólogo da consulta podem fazer check-in", http.StatusForbidden)
+		return
+	}
+
+	agora := time.Now().UTC()
+	if agora.Before(consulta.Inicio.Add(-AntecedenciaCheckin)) || !agora.Before(consulta.Fim) {
+		httpError(w, "Fora da janela de check-in da consulta", http.StatusUnprocessableEntity)
+		return
+	}
+
+	h.registrar(w, ctx, consulta, "confirmada", "em andamento", agora)
+}
+
+// HandlerConcluirConsulta responde à rota POST /consultas/{id}/concluir
+func (h *PresencaHandler) HandlerConcluirConsulta(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	consulta, err := h.Repo.BuscarConsultaPorID(ctx, id)
+	if err != nil {
+		httpError(w, "Consulta não encontrada", http.StatusNotFound)
+		return
+	}
+	if reqctx.AtorDe(ctx).ID != consulta.PsicologoID {
+		httpError(w, "Apenas o psicólogo da consulta pode concluí-la", http.StatusForbidden)
+		return
+	}
+
+	h.registrar(w, ctx, consulta, "em andamento", "concluida", time.Now().UTC())
+}
+
+func (h *PresencaHandler) registrar(w http.ResponseWriter, ctx context.Context, consulta *model.Consulta, esperado, novoStatus string, em time.Time) {
+	if err := h.Repo.RegistrarPresenca(ctx, consulta.ID, esperado, novoStatus, em); err != nil {
+		if errors.Is(err, repository.ErrTransicaoInvalida) {
+			httpError(w, fmt.Sprintf("A consulta precisa estar '%s' (status atual: '%s')", esperado, consulta.Status), http.StatusConflict)
+			return
+		}
+		log.Printf("ERRO ao registrar presença na consulta %s: %v", consulta.ID, err)
+		httpError(w, "Erro ao registrar presença", http.StatusInternalServerError)
+		return
+	}
+
+	consulta.Status = novoStatus
+	if novoStatus == "em andamento" {
+		consulta.CheckinEm = em
+	} else {
+		consulta.ConcluidaEm = em
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(consulta)
+}
+
+// HandlerPresencaAluno responde à rota GET /alunos/{id}/presenca
+func (h *PresencaHandler) HandlerPresencaAluno(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	// A frequência é dado sensível: só o próprio aluno, seus psicólogos e o admin.
+	podeVer, err := podeVerDadosSensiveis(ctx, h.Repo, id)
+	if err != nil {
+		log.Printf("ERRO ao verificar acesso à presença do aluno %s: %v", id, err)
+		httpError(w, "Erro ao verificar acesso", http.StatusInternalServerError)
+		return
+	}
+	if !podeVer {
+		httpError(w, "Acesso negado", http.StatusForbidden)
+		return
+	}
+
+	consultas, err := h.Repo.ListarConsultasPorAluno(ctx, id)
+	if err != nil {
+		log.Printf("ERRO ao listar consultas do aluno %s: %v", id, err)
+		httpError(w, "Erro ao calcular presença", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(service.CalcularPresenca(consultas, time.Now()))
+}
+
+// HandlerPresencaPsicologo responde à rota GET /psicologos/{id}/presenca
+func (h *PresencaHandler) HandlerPresencaPsicologo(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	ator := reqctx.AtorDe(r.Context())
+	if ator.ID != id && ator.Papel != reqctx.PapelAdmin {
+		httpError(w, "Acesso negado", http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	consultas, err := h.Repo.ListarConsultasPorPsicologo(ctx, id, "")
+	if err != nil {
+		log.Printf("ERRO ao listar consultas do psicólogo %s: %v", id, err)
+		httpError(w, "Erro ao calcular presença", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(service.CalcularPresenca(consultas, time.Now()))
+}
+
+func gerarCodigoCheckin() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
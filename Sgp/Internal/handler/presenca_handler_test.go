@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/repository/mocks"
+	"sgp/Internal/reqctx"
+	"sgp/Internal/service"
+	"testing"
+	"time"
+)
+
+func novoPresencaHandler(consulta *model.Consulta, registros *[]string) *PresencaHandler {
+	return NewPresencaHandler(&mocks.ConsultaRepositoryMock{
+		BuscarConsultaPorIDFunc: func(ctx context.Context, id string) (*model.Consulta, error) {
+			c := *consulta
+			return &c, nil
+		},
+		RegistrarPresencaFunc: func(ctx context.Context, id, esperado, novoStatus string, em time.Time) error {
+			if consulta.Status != esperado {
+				return repository.ErrTransicaoInvalida
+			}
+			*registros = append(*registros, novoStatus)
+			return nil
+		},
+	})
+}
+
+func TestHandlerCheckin(t *testing.T) {
+	agora := time.Now().UTC()
+	consulta := model.Consulta{
+		ID: "c1", AlunoID: "aluno-1", PsicologoID: "psico-1", Status: "confirmada",
+		Inicio: agora.Add(5 * time.Minute), Fim: agora.Add(55 * time.Minute), CodigoCheckin: "123456",
+	}
+	distante := consulta
+	distante.Inicio, distante.Fim = agora.Add(2*time.Hour), agora.Add(3*time.Hour)
+	pendente := consulta
+	pendente.Status = "aguardando aprovacao"
+
+	casos := []struct {
+		nome     string
+		ator     string
+		codigo   string
+		consulta model.Consulta
+		esperado int
+	}{
+		{"psicologo sem codigo", "psico-1", "", consulta, http.StatusOK},
+		{"aluno com codigo", "aluno-1", "123456", consulta, http.StatusOK},
+		{"aluno com codigo errado", "aluno-1", "000000", consulta, http.StatusForbidden},
+		{"terceiro", "outro", "123456", consulta, http.StatusForbidden},
+		{"fora da janela", "psico-1", "", distante, http.StatusUnprocessableEntity},
+		{"status invalido", "psico-1", "", pendente, http.StatusConflict},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			body, _ := json.Marshal(map[string]string{"codigo": tc.codigo})
+			req, _ := http.NewRequest("POST", "/consultas/c1/checkin", bytes.NewBuffer(body))
+			req.SetPathValue("id", "c1")
+			req = req.WithContext(reqctx.ComAtor(req.Context(), reqctx.Ator{ID: tc.ator}))
+			rr := httptest.NewRecorder()
+
+			var registros []string
+			novoPresencaHandler(&tc.consulta, &registros).HandlerCheckin(rr, req)
+
+			if status := rr.Code; status != tc.esperado {
+				t.Fatalf("status code incorreto: obteve %v, esperava %v (%s)", status, tc.esperado, rr.Body.String())
+			}
+			if tc.esperado == http.StatusOK && (len(registros) != 1 || registros[0] != "em andamento") {
+				t.Errorf("check-in não registrado: %v", registros)
+			}
+		})
+	}
+}
+
+func TestHandlerCodigoCheckin(t *testing.T) {
+	var definido string
+	h := NewPresencaHandler(&mocks.ConsultaRepositoryMock{
+		BuscarConsultaPorIDFunc: func(ctx context.Context, id string) (*model.Consulta, error) {
+			return &model.Consulta{ID: id, AlunoID: "aluno-1", PsicologoID: "psico-1"}, nil
+		},
+		DefinirCodigoCheckinFunc: func(ctx context.Context, id, codigo string) error {
+			definido = codigo
+			return nil
+		},
+	})
+
+	req, _ := http.NewRequest("GET", "/consultas/c1/checkin/codigo", nil)
+	req.SetPathValue("id", "c1")
+	req = req.WithContext(reqctx.ComAtor(req.Context(), reqctx.Ator{ID: "psico-1"}))
+	rr := httptest.NewRecorder()
+	h.HandlerCodigoCheckin(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v", status, http.StatusOK)
+	}
+	var resposta map[string]string
+	json.NewDecoder(rr.Body).Decode(&resposta)
+	if len(definido) != 6 || resposta["codigo"] != definido {
+		t.Errorf("código inválido: salvo %q, retornado %q", definido, resposta["codigo"])
+	}
+}
+
+func TestMarcarFaltas(t *testing.T) {
+	agora := time.Date(2025, 5, 10, 15, 0, 0, 0, time.UTC)
+	var faltas []string
+	s := service.NewPresencaService(&mocks.ConsultaRepositoryMock{
+		ListarConsultasPorStatusFunc: func(ctx context.Context, status string) ([]*model.Consulta, error) {
+			return []*model.Consulta{
+				{ID: "atrasada", Status: "confirmada", Inicio: agora.Add(-30 * time.Minute)},
+				{ID: "recente", Status: "confirmada", Inicio: agora.Add(-10 * time.Minute)},
+				{ID: "futura", Status: "confirmada", Inicio: agora.Add(time.Hour)},
+			}, nil
+		},
+		RegistrarPresencaFunc: func(ctx context.Context, id, esperado, novoStatus string, em time.Time) error {
+			if esperado != "confirmada" || novoStatus != "falta" {
+				t.Errorf("transição inesperada: %s -> %s", esperado, novoStatus)
+			}
+			faltas = append(faltas, id)
+			return nil
+		},
+	})
+
+	n, err := s.MarcarFaltas(context.Background(), agora)
+	if err != nil || n != 1 || len(faltas) != 1 || faltas[0] != "atrasada" {
+		t.Errorf("faltas marcadas incorretamente: n=%d err=%v faltas=%v", n, err, faltas)
+	}
+}
+
+func TestHandlerPresencaAluno(t *testing.T) {
+	agora := time.Now()
+	h := NewPresencaHandler(&mocks.ConsultaRepositoryMock{
+		ListarConsultasPorAlunoFunc: func(ctx context.Context, alunoID string) ([]*model.Consulta, error) {
+			return []*model.Consulta{
+				{Status: "concluida", Inicio: agora.Add(-72 * time.Hour)},
+				{Status: "concluida", Inicio: agora.Add(-48 * time.Hour)},
+				{Status: "falta", Inicio: agora.Add(-24 * time.Hour)},
+				{Status: "confirmada", Inicio: agora.Add(24 * time.Hour)},
+			}, nil
+		},
+	})
+
+	req, _ := http.NewRequest("GET", "/alunos/aluno-1/presenca", nil)
+	req.SetPathValue("id", "aluno-1")
+	req = req.WithContext(reqctx.ComAtor(req.Context(), reqctx.Ator{ID: "aluno-1", Papel: reqctx.PapelAluno}))
+	rr := httptest.NewRecorder()
+	h.HandlerPresencaAluno(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v", status, http.StatusOK)
+	}
+	var estatisticas model.EstatisticasPresenca
+	json.NewDecoder(rr.Body).Decode(&estatisticas)
+	if estatisticas.Total != 3 || estatisticas.Faltas != 1 || estatisticas.Concluidas != 2 {
+		t.Errorf("estatísticas incorretas: %+v", estatisticas)
+	}
+}
+
+func TestHandlerPresencaAlunoSoParaQuemAtende(t *testing.T) {
+	casos := []struct {
+		nome     string
+		ator     reqctx.Ator
+		esperado int
+	}{
+		{"psicologo do aluno", reqctx.Ator{ID: "psi-1", Papel: reqctx.PapelPsicologo}, http.StatusOK},
+		{"psicologo de outros alunos", reqctx.Ator{ID: "psi-2", Papel: reqctx.PapelPsicologo}, http.StatusForbidden},
+		{"outro aluno", reqctx.Ator{ID: "aluno-2", Papel: reqctx.PapelAluno}, http.StatusForbidden},
+		{"admin", reqctx.Ator{ID: "admin-1", Papel: reqctx.PapelAdmin}, http.StatusOK},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			h := NewPresencaHandler(&mocks.ConsultaRepositoryMock{
+				ListarConsultasPorPsicologoFunc: func(ctx context.Context, psicologoID string, status string) ([]*model.Consulta, error) {
+					if psicologoID != "psi-1" {
+						return nil, nil
+					}
+					return []*model.Consulta{{AlunoID: "aluno-1", Status: "concluida"}}, nil
+				},
+				ListarConsultasPorAlunoFunc: func(ctx context.Context, alunoID string) ([]*model.Consulta, error) {
+					return nil, nil
+				},
+			})
+
+			req, _ := http.NewRequest("GET", "/alunos/aluno-1/presenca", nil)
+			req.SetPathValue("id", "aluno-1")
+			req = req.WithContext(reqctx.ComAtor(req.Context(), tc.ator))
+			rr := httptest.NewRecorder()
+			h.HandlerPresencaAluno(rr, req)
+
+			if status := rr.Code; status != tc.esperado {
+				t.Errorf("status code incorreto: obteve %v, esperava %v", status, tc.esperado)
+			}
+		})
+	}
+}
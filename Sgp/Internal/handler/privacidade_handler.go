@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/reqctx"
+	"sgp/Internal/service"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const TimeoutPrivacidade = 30 * time.Second
+
+// PrivacidadeHandler atende os direitos do titular previstos na LGPD:
+// acesso (exportação) e eliminação (anonimização) dos dados de um aluno.
+type PrivacidadeHandler struct {
+	AlunoRepo       repository.AlunoRepository
+	ConsultaRepo    repository.ConsultaRepository
+	NotificacaoRepo repository.NotificacaoRepository
+	AuditoriaRepo   repository.AuditoriaRepository
+
+	// Opcionais: quando presentes, seus dados também entram na exportação.
+	ConsentimentoRepo repository.ConsentimentoRepository
+	FormularioRepo    repository.FormularioRepository
+	InstrumentoRepo   repository.InstrumentoRepository
+	EpisodioRepo      repository.EpisodioRepository
+}
+
+func NewPrivacidadeHandler(
+	alunoRepo repository.AlunoRepository,
+	consultaRepo repository.ConsultaRepository,
+	notificacaoRepo repository.NotificacaoRepository,
+	auditoriaRepo repository.AuditoriaRepository,
+) *PrivacidadeHandler {
+	return &PrivacidadeHandler{
+		AlunoRepo:       alunoRepo,
+		ConsultaRepo:    consultaRepo,
+		NotificacaoRepo: notificacaoRepo,
+		AuditoriaRepo:   auditoriaRepo,
+	}
+}
+
+// HandlerExportarDadosAluno responde à rota GET /alunos/{id}/dados.
+// Pode ser chamada pelo próprio aluno ou por um admin.
+func (h *PrivacidadeHandler) HandlerExportarDadosAluno(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		httpError(w, "O ID do aluno é obrigatório", http.StatusBadRequest)
+		return
+	}
+
+	ator := reqctx.AtorDe(r.Context())
+	if ator.ID != id && ator.Papel != reqctx.PapelAdmin {
+		httpError(w, "Apenas o titular ou um admin pode exportar estes dados", http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), TimeoutPrivacidade)
+	defer cancel()
+
+	exportacao, err := h.ExportarDadosAluno(ctx, id)
+	if err != nil {
+		log.Printf("ERRO ao exportar dados do aluno %s: %v", id, err)
+		if status.Code(err) == codes.NotFound {
+			httpError(w, "Aluno não encontrado", http.StatusNotFound)
+		} else {
+			httpError(w, "Erro ao exportar dados do aluno", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	repository.RegistrarAuditoria(ctx, h.AuditoriaRepo, repository.AcaoExportar, "aluno", id, nil, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="dados-aluno-%s.json"`, id))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(exportacao)
+}
+
+// ExportarDadosAluno reúne tudo o que está ligado ao aluno. É exportado para
+// ser reaproveitado fora do HTTP (ex: pela CLI).
+func (h *PrivacidadeHandler) ExportarDadosAluno(ctx context.Context, id string) (*model.ExportacaoAluno, error) {
+	aluno, err := h.AlunoRepo.BuscarAlunoPorID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	consultas, err := h.ConsultaRepo.ListarConsultasPorAluno(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	notificacoes, err := h.NotificacaoRepo.ListarNotificacoesPorAluno(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	filtros := []model.FiltroAuditoria{
+		{Entidade: "aluno", EntidadeID: id},
+		{AtorID: id},
+	}
+	for _, c := range consultas {
+		filtros = append(filtros, model.FiltroAuditoria{Entidade: "consulta", EntidadeID: c.ID})
+	}
+
+	var auditoria []*model.RegistroAuditoria
+	vistos := map[string]bool{}
+	for _, filtro := range filtros {
+		registros, err := h.listarAuditoriaCompleta(ctx, filtro)
+		if err != nil {
+			return nil, err
+		}
+		for _, registro := range registros {
+			if !vistos[registro.ID] {
+				vistos[registro.ID] = true
+				auditoria = append(auditoria, registro)
+			}
+		}
+	}
+
+	var aceites []*model.AceiteConsentimento
+	if h.ConsentimentoRepo != nil {
+		if aceites, err = h.ConsentimentoRepo.ListarAceitesPorAluno(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	var formularios []*model.RespostaFormulario
+	if h.FormularioRepo != nil {
+		if formularios, err = h.FormularioRepo.ListarRespostasPorAluno(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	var instrumentos []*model.AplicacaoInstrumento
+	if h.InstrumentoRepo != nil {
+		if instrumentos, err = h.InstrumentoRepo.ListarAplicacoesPorAluno(ctx, id, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	var episodios []*model.Episodio
+	if h.EpisodioRepo != nil {
+		if episodios, err = h.EpisodioRepo.ListarEpisodiosPorAluno(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	return &model.ExportacaoAluno{
+		GeradoEm:     time.Now().UTC(),
+		Aluno:        aluno,
+		Consultas:    consultas,
+		Notificacoes: notificacoes,
+		Auditoria:    auditoria,
+		Aceites:      aceites,
+		Formularios:  formularios,
+		Instrumentos: instrumentos,
+		Episodios:    episodios,
+	}, nil
+}
+
+// HandlerAnonimizarAluno responde à rota POST /alunos/{id}/anonimizar.
+//
+// Nome e e-mail são apagados do perfil e das notificações, e consultas
+// futuras são canceladas. Consultas passadas, notas de sessão e a trilha de
+// auditoria são mantidas: são registros que a clínica tem obrigação legal de
+// guardar e sustentam as estatísticas agregadas. Da auditoria do aluno saem
+// só os retratos do cadastro, que repetiam os dados pessoais.
+func (h *PrivacidadeHandler) HandlerAnonimizarAluno(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		httpError(w, "O ID do aluno é obrigatório", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), TimeoutPrivacidade)
+	defer cancel()
+
+	if _, err := h.AlunoRepo.BuscarAlunoPorID(ctx, id); err != nil {
+		if status.Code(err) == codes.NotFound {
+			httpError(w, "Aluno não encontrado", http.StatusNotFound)
+		} else {
+			httpError(w, "Erro ao buscar aluno", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := h.AnonimizarAluno(ctx, id); err != nil {
+		log.Printf("ERRO ao anonimizar aluno %s: %v", id, err)
+		httpError(w, "Erro ao anonimizar aluno", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Dados do aluno anonimizados com sucesso"})
+}
+
+func (h *PrivacidadeHandler) AnonimizarAluno(ctx context.Context, id string) error {
+	consultas, err := h.ConsultaRepo.ListarConsultasPorAluno(ctx, id)
+	if err != nil {
+		return err
+	}
+	agora := time.Now()
+	for _, c := range consultas {
+		if (service.ConsultaAtiva(c) || c.Status == "lista de espera") && c.Inicio.After(agora) {
+			if err := h.ConsultaRepo.AtualizaStatusConsulta(ctx, c.ID, "cancelada pelo aluno"); err != nil {
+				return err
+			}
+		}
+	}
+
+	notificacoes, err := h.NotificacaoRepo.ListarNotificacoesPorAluno(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, n := range notificacoes {
+		n.Destinatario = ""
+		n.Html = ""
+		if err := h.NotificacaoRepo.AtualizarNotificacao(ctx, n.ID, *n); err != nil {
+			return err
+		}
+	}
+
+	return h.AlunoRepo.AnonimizarAluno(ctx, id)
+}
+
+func (h *PrivacidadeHandler) listarAuditoriaCompleta(ctx context.Context, filtro model.FiltroAuditoria) ([]*model.RegistroAuditoria, error) {
+	var todos []*model.RegistroAuditoria
+	filtro.Limite = LimiteAuditoriaMaximo
+	for {
+		registros, cursor, err := h.AuditoriaRepo.ListarAuditoria(ctx, filtro)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, registros...)
+		if cursor == "" {
+			return todos, nil
+		}
+		filtro.Cursor = cursor
+	}
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sgp/Internal/model"
+	"sgp/Internal/repository/mocks"
+	"sgp/Internal/reqctx"
+	"testing"
+	"time"
+)
+
+func novoPrivacidadeHandlerTeste(auditorias *[]model.RegistroAuditoria) *PrivacidadeHandler {
+	return NewPrivacidadeHandler(
+		&mocks.AlunoRepositoryMock{
+			BuscarAlunoPorIDFunc: func(ctx context.Context, id string) (*model.Aluno, error) {
+				return &model.Aluno{ID: id, Nome: "Maria", Email: "maria@test.com"}, nil
+			},
+			AnonimizarAlunoFunc: func(ctx context.Context, id string) error {
+				return nil
+			},
+		},
+		&mocks.ConsultaRepositoryMock{
+			ListarConsultasPorAlunoFunc: func(ctx context.Context, alunoID string) ([]*model.Consulta, error) {
+				return []*model.Consulta{
+					{ID: "c1", Status: "concluida", Inicio: time.Now().Add(-48 * time.Hour)},
+					{ID: "c2", Status: "confirmada", Inicio: time.Now().Add(48 * time.Hour)},
+				}, nil
+			},
+			AtualizaStatusConsultaFunc: func(ctx context.Context, id string, s string) error {
+				if id != "c2" {
+					return context.Canceled
+				}
+				return nil
+			},
+		},
+		&mocks.NotificacaoRepositoryMock{
+			ListarNotificacoesPorAlunoFunc: func(ctx context.Context, alunoID string) ([]*model.Notificacao, error) {
+				return []*model.Notificacao{{ID: "n1", Destinatario: "maria@test.com", Html: "<h1>Olá, Maria!</h1>"}}, nil
+			},
+			AtualizarNotificacaoFunc: func(ctx context.Context, id string, n model.Notificacao) error {
+				if n.Destinatario != "" || n.Html != "" {
+					return context.Canceled
+				}
+				return nil
+			},
+		},
+		&mocks.AuditoriaRepositoryMock{
+			RegistrarFunc: func(ctx context.Context, r model.RegistroAuditoria) error {
+				*auditorias = append(*auditorias, r)
+				return nil
+			},
+			ListarAuditoriaFunc: func(ctx context.Context, f model.FiltroAuditoria) ([]*model.RegistroAuditoria, string, error) {
+				return []*model.RegistroAuditoria{{ID: "a1"}}, "", nil
+			},
+		},
+	)
+}
+
+func TestHandlerExportarDadosAluno(t *testing.T) {
+	t.Run("titular exporta seus dados", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/alunos/aluno-1/dados", nil)
+		req.SetPathValue("id", "aluno-1")
+		req = req.WithContext(reqctx.ComAtor(req.Context(), reqctx.Ator{ID: "aluno-1"}))
+		rr := httptest.NewRecorder()
+
+		var auditorias []model.RegistroAuditoria
+		h := novoPrivacidadeHandlerTeste(&auditorias)
+		h.HandlerExportarDadosAluno(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Fatalf("status code incorreto: obteve %v, esperava %v", status, http.StatusOK)
+		}
+		var exportacao model.ExportacaoAluno
+		json.NewDecoder(rr.Body).Decode(&exportacao)
+		if len(exportacao.Consultas) != 2 || len(exportacao.Notificacoes) != 1 || len(exportacao.Auditoria) != 1 {
+			t.Errorf("exportação incompleta: %+v", exportacao)
+		}
+		if len(auditorias) != 1 || auditorias[0].Acao != "exportar" {
+			t.Errorf("exportação deveria gerar registro de auditoria, obteve %+v", auditorias)
+		}
+	})
+
+	t.Run("outro aluno nao pode exportar", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/alunos/aluno-1/dados", nil)
+		req.SetPathValue("id", "aluno-1")
+		req = req.WithContext(reqctx.ComAtor(req.Context(), reqctx.Ator{ID: "aluno-2"}))
+		rr := httptest.NewRecorder()
+
+		var auditorias []model.RegistroAuditoria
+		h := novoPrivacidadeHandlerTeste(&auditorias)
+		h.HandlerExportarDadosAluno(rr, req)
+
+		if status := rr.Code; status != http.StatusForbidden {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusForbidden)
+		}
+	})
+}
+
+func TestHandlerAnonimizarAluno(t *testing.T) {
+	t.Run("sucesso ao anonimizar", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/alunos/aluno-1/anonimizar", nil)
+		req.SetPathValue("id", "aluno-1")
+		rr := httptest.NewRecorder()
+
+		var auditorias []model.RegistroAuditoria
+		h := novoPrivacidadeHandlerTeste(&auditorias)
+		h.HandlerAnonimizarAluno(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusOK)
+		}
+	})
+}
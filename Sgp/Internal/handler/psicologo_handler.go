@@ -3,10 +3,13 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"sgp/Internal/model"
 	"sgp/Internal/repository"
+	"sgp/Internal/service"
+	"strings"
 	"time"
 
 	"google.golang.org/grpc/codes"
@@ -17,6 +20,9 @@ const TimeoutPsicologo = 5 * time.Second
 
 type PsicologoHandler struct {
 	Repo repository.PsicologoRepository
+
+	// HorarioRepo é exigido pelas recomendações, que só sugerem quem tem horário livre.
+	HorarioRepo repository.HorarioDisponivelRepository
 }
 
 func NewPsicologoHandler(repo repository.PsicologoRepository) *PsicologoHandler {
@@ -46,6 +52,10 @@ func (h *PsicologoHandler) HandlerCriarPsicologo(
 
 		return
 	}
+	if err := validarPerfilPsicologo(psicologo); err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), TimeoutPsicologo)
 	defer cancel()
@@ -123,6 +133,11 @@ func (h *PsicologoHandler) HandlerAtualizarPsicologo(
 	}
 	defer r.Body.Close()
 
+	if err := validarPerfilPsicologo(psicologo); err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), TimeoutPsicologo)
 	defer cancel()
 
@@ -186,3 +201,67 @@ func (h *PsicologoHandler) HandlerBuscarPsicologoPorNome(w http.ResponseWriter,
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"id": id})
 }
+
+// HandlerRecomendarPsicologos responde à rota
+// GET /psicologos/recomendacoes?necessidades=ansiedade,luto&idioma=...&modalidade=online
+func (h *PsicologoHandler) HandlerRecomendarPsicologos(w http.ResponseWriter, r *http.Request) {
+	if h.HorarioRepo == nil {
+		httpError(w, "Recomendações indisponíveis", http.StatusServiceUnavailable)
+		return
+	}
+
+	criterios := service.CriteriosMatching{
+		Idioma:     r.URL.Query().Get("idioma"),
+		Modalidade: r.URL.Query().Get("modalidade"),
+	}
+	for _, n := range strings.Split(r.URL.Query().Get("necessidades"), ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			criterios.Necessidades = append(criterios.Necessidades, n)
+		}
+	}
+	if criterios.Modalidade != "" && criterios.Modalidade != model.ModalidadePresencial && criterios.Modalidade != model.ModalidadeOnline {
+		httpError(w, "Parâmetro 'modalidade' deve ser 'presencial' ou 'online'", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), TimeoutPsicologo)
+	defer cancel()
+
+	psicologos, err := h.Repo.ListarPsicologos(ctx)
+	if err != nil {
+		httpError(w, "Erro ao listar psicólogos", http.StatusInternalServerError)
+		return
+	}
+
+	horarios := map[string][]*model.HorarioDisponivel{}
+	for _, p := range psicologos {
+		if !service.PsicologoAtivo(p) {
+			continue
+		}
+		livres, err := h.HorarioRepo.ListarHorariosPorPsicologo(ctx, p.ID, "disponivel")
+		if err != nil {
+			log.Printf("ERRO ao listar horários do psicólogo %s: %v", p.ID, err)
+			httpError(w, "Erro ao consultar disponibilidade", http.StatusInternalServerError)
+			return
+		}
+		horarios[p.ID] = livres
+	}
+
+	sugestoes := service.RanquearPsicologos(psicologos, horarios, criterios, time.Now())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(sugestoes)
+}
+
+func validarPerfilPsicologo(psicologo model.Psicologo) error {
+	if psicologo.LimiteSessoesSemana < 0 || psicologo.LimitePacientesAtivos < 0 {
+		return fmt.Errorf("Os limites de carga não podem ser negativos")
+	}
+	for _, m := range psicologo.Modalidades {
+		if m != model.ModalidadePresencial && m != model.ModalidadeOnline {
+			return fmt.Errorf("Modalidade inválida '%s': use 'presencial' ou 'online'", m)
+		}
+	}
+	return service.ValidarFuso(psicologo.FusoHorario)
+}
@@ -10,6 +10,7 @@ import (
 	"sgp/Internal/model"
 	"sgp/Internal/repository/mocks"
 	"testing"
+	"time"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -206,4 +207,56 @@ func TestHandlerBuscarPsicologoPorNome(t *testing.T) {
 			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusNotFound)
 		}
 	})
-}
\ No newline at end of file
+}
+func TestHandlerRecomendarPsicologos(t *testing.T) {
+	inativo := false
+	agora := time.Now()
+	psicologos := []*model.Psicologo{
+		{ID: "sem-horario", Especialidades: []string{"Ansiedade"}},
+		{ID: "inativo", Especialidades: []string{"Ansiedade"}, Ativo: &inativo},
+		{ID: "so-online", Especialidades: []string{"Ansiedade"}, Modalidades: []string{"online"}},
+		{ID: "generalista", Modalidades: []string{"presencial"}},
+		{ID: "especialista", Especialidades: []string{"ansiedade", "Luto"}, Idiomas: []string{"Português", "Inglês"}},
+	}
+	mockRepo := &mocks.PsicologoRepositoryMock{
+		ListarPsicologosFunc: func(ctx context.Context) ([]*model.Psicologo, error) {
+			return psicologos, nil
+		},
+	}
+	mockHorarioRepo := &mocks.HorarioDisponivelRepositoryMock{
+		ListarHorariosPorPsicologoFunc: func(ctx context.Context, psicologoID string, status string) ([]*model.HorarioDisponivel, error) {
+			if psicologoID == "sem-horario" {
+				// Só horários passados não contam como disponibilidade
+				return []*model.HorarioDisponivel{{Inicio: agora.Add(-time.Hour), Status: "disponivel"}}, nil
+			}
+			return []*model.HorarioDisponivel{{Inicio: agora.Add(24 * time.Hour), Status: "disponivel"}}, nil
+		},
+	}
+
+	h := NewPsicologoHandler(mockRepo)
+	h.HorarioRepo = mockHorarioRepo
+
+	req, _ := http.NewRequest("GET", "/psicologos/recomendacoes?necessidades=ansiedade,luto&modalidade=presencial&idioma=portugues", nil)
+	rr := httptest.NewRecorder()
+	h.HandlerRecomendarPsicologos(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v", rr.Code, http.StatusOK)
+	}
+
+	var sugestoes []struct {
+		Psicologo model.Psicologo `json:"psicologo"`
+		Pontuacao int             `json:"pontuacao"`
+	}
+	json.NewDecoder(rr.Body).Decode(&sugestoes)
+
+	esperado := []string{"especialista", "generalista"}
+	if len(sugestoes) != len(esperado) {
+		t.Fatalf("número incorreto de sugestões: obteve %+v", sugestoes)
+	}
+	for i, id := range esperado {
+		if sugestoes[i].Psicologo.ID != id {
+			t.Errorf("posição %d: obteve %s, esperava %s", i, sugestoes[i].Psicologo.ID, id)
+		}
+	}
+}
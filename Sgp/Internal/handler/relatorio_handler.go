@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/service"
+	"strings"
+	"time"
+)
+
+type RelatorioHandler struct {
+	ConsultaRepo  repository.ConsultaRepository
+	HorarioRepo   repository.HorarioDisponivelRepository
+	PsicologoRepo repository.PsicologoRepository
+}
+
+func NewRelatorioHandler(consultaRepo repository.ConsultaRepository, horarioRepo repository.HorarioDisponivelRepository, psicologoRepo repository.PsicologoRepository) *RelatorioHandler {
+	return &RelatorioHandler{ConsultaRepo: consultaRepo, HorarioRepo: horarioRepo, PsicologoRepo: psicologoRepo}
+}
+
+// HandlerRelatorioAtendimento responde à rota
+// GET /relatorios/atendimento?de=...&ate=...&psicologoId=...&formato=csv
+// Sem período, cobre os últimos 30 dias; o CSV também vem com Accept: text/csv.
+func (h *RelatorioHandler) HandlerRelatorioAtendimento(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	ate := time.Now().UTC()
+	de := ate.AddDate(0, 0, -30)
+
+	var err error
+	if v := q.Get("de"); v != "" {
+		if de, err = time.Parse(time.RFC3339, v); err != nil {
+			httpError(w, "Parâmetro 'de' inválido, use RFC3339", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("ate"); v != "" {
+		if ate, err = time.Parse(time.RFC3339, v); err != nil {
+			httpError(w, "Parâmetro 'ate' inválido, use RFC3339", http.StatusBadRequest)
+			return
+		}
+	}
+	if !ate.After(de) {
+		httpError(w, "'ate' deve ser posterior a 'de'", http.StatusBadRequest)
+		return
+	}
+	formato := q.Get("formato")
+	if formato == "" && strings.Contains(r.Header.Get("Accept"), "text/csv") {
+		formato = "csv"
+	}
+	if formato != "" && formato != "json" && formato != "csv" {
+		httpError(w, "Parâmetro 'formato' inválido, use 'json' ou 'csv'", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	psicologoID := q.Get("psicologoId")
+	if psicologoID != "" {
+		if _, err := h.PsicologoRepo.BuscarPsicologoPorID(ctx, psicologoID); err != nil {
+			httpError(w, "Psicólogo não encontrado", http.StatusNotFound)
+			return
+		}
+	}
+
+	// Só o período é lido; o filtro por psicólogo é feito em memória.
+	horarios, err := h.HorarioRepo.ListarHorariosNoPeriodo(ctx, de, ate)
+	if err != nil {
+		log.Printf("ERRO ao listar horários para o relatório: %v", err)
+		httpError(w, "Erro ao gerar relatório", http.StatusInternalServerError)
+		return
+	}
+	consultas, err := h.ConsultaRepo.ListarConsultasNoPeriodo(ctx, "", de, ate)
+	if err != nil {
+		log.Printf("ERRO ao listar consultas para o relatório: %v", err)
+		httpError(w, "Erro ao gerar relatório", http.StatusInternalServerError)
+		return
+	}
+	if psicologoID != "" {
+		var horariosDoPsicologo []*model.HorarioDisponivel
+		for _, horario := range horarios {
+			if horario.PsicologoID == psicologoID {
+				horariosDoPsicologo = append(horariosDoPsicologo, horario)
+			}
+		}
+		horarios = horariosDoPsicologo
+
+		var consultasDoPsicologo []*model.Consulta
+		for _, c := range consultas {
+			if c.PsicologoID == psicologoID {
+				consultasDoPsicologo = append(consultasDoPsicologo, c)
+			}
+		}
+		consultas = consultasDoPsicologo
+	}
+
+	relatorio := service.CalcularRelatorio(horarios, consultas, de, ate)
+	relatorio.PsicologoID = psicologoID
+
+	if formato == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="relatorio-atendimento.csv"`)
+		w.WriteHeader(http.StatusOK)
+		csv.NewWriter(w).WriteAll(service.LinhasRelatorio(relatorio))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(relatorio)
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sgp/Internal/model"
+	"sgp/Internal/repository/mocks"
+	"testing"
+	"time"
+)
+
+func novoRelatorioHandler() *RelatorioHandler {
+	dia := func(d, h int) time.Time { return time.Date(2026, 1, d, h, 0, 0, 0, time.UTC) }
+	horarios := map[string][]*model.HorarioDisponivel{
+		"psico-1": {
+			{ID: "h1", Inicio: dia(10, 10), Status: "agendado"},
+			{ID: "h2", Inicio: dia(11, 10), Status: "disponivel"},
+			{ID: "h3", Inicio: dia(12, 10), Status: "bloqueado"},
+			{ID: "h4", Inicio: dia(5, 10).AddDate(0, 1, 0), Status: "agendado"},
+		},
+		"psico-2": {
+			{ID: "h5", Inicio: dia(20, 10), Status: "disponivel", Tipo: model.HorarioGrupo, Capacidade: 6, Inscritos: 2},
+		},
+	}
+	consultas := map[string][]*model.Consulta{
+		"psico-1": {
+			{ID: "c1", AlunoID: "aluno-a", Status: "concluida", Inicio: dia(10, 10), DataAgendamento: dia(8, 10), ConfirmadaEm: dia(8, 12)},
+			{ID: "c2", AlunoID: "aluno-a", Status: "concluida", Inicio: dia(17, 10), DataAgendamento: dia(9, 10)},
+			{ID: "c3", AlunoID: "aluno-b", Status: "falta", Inicio: dia(12, 10), DataAgendamento: dia(11, 10), ConfirmadaEm: dia(11, 14)},
+			{ID: "c4", AlunoID: "aluno-c", Status: "cancelada pelo aluno", Inicio: dia(15, 10), DataAgendamento: dia(14, 10)},
+			{ID: "c7", AlunoID: "aluno-f", Status: "recusada", Inicio: dia(16, 10), DataAgendamento: dia(14, 12)},
+			{ID: "c5", AlunoID: "aluno-e", Status: "concluida", Inicio: dia(5, 10).AddDate(0, 1, 0), DataAgendamento: dia(20, 10)},
+		},
+		"psico-2": {
+			{ID: "c6", AlunoID: "aluno-d", Status: "em andamento", Inicio: dia(20, 10), DataAgendamento: dia(15, 10)},
+		},
+	}
+
+	// Os mocks devolvem só o que começa no período, com o psicólogo preenchido.
+	return NewRelatorioHandler(
+		&mocks.ConsultaRepositoryMock{
+			ListarConsultasNoPeriodoFunc: func(ctx context.Context, statusFiltro string, de, ate time.Time) ([]*model.Consulta, error) {
+				var noPeriodo []*model.Consulta
+				for psicologoID, cs := range consultas {
+					for _, c := range cs {
+						if !c.Inicio.Before(de) && c.Inicio.Before(ate) {
+							c.PsicologoID = psicologoID
+							noPeriodo = append(noPeriodo, c)
+						}
+					}
+				}
+				return noPeriodo, nil
+			},
+		},
+		&mocks.HorarioDisponivelRepositoryMock{
+			ListarHorariosNoPeriodoFunc: func(ctx context.Context, de, ate time.Time) ([]*model.HorarioDisponivel, error) {
+				var noPeriodo []*model.HorarioDisponivel
+				for psicologoID, hs := range horarios {
+					for _, h := range hs {
+						if !h.Inicio.Before(de) && h.Inicio.Before(ate) {
+							h.PsicologoID = psicologoID
+							noPeriodo = append(noPeriodo, h)
+						}
+					}
+				}
+				return noPeriodo, nil
+			},
+		},
+		&mocks.PsicologoRepositoryMock{
+			ListarPsicologosFunc: func(ctx context.Context) ([]*model.Psicologo, error) {
+				return []*model.Psicologo{{ID: "psico-1"}, {ID: "psico-2"}}, nil
+			},
+			BuscarPsicologoPorIDFunc: func(ctx context.Context, id string) (*model.Psicologo, error) {
+				if _, ok := horarios[id]; !ok {
+					return nil, errors.New("psicólogo não encontrado")
+				}
+				return &model.Psicologo{ID: id}, nil
+			},
+		},
+	)
+}
+
+const periodoRelatorio = "de=2026-01-01T00:00:00Z&ate=2026-02-01T00:00:00Z"
+
+func TestHandlerRelatorioAtendimento(t *testing.T) {
+	h := novoRelatorioHandler()
+	req, _ := http.NewRequest("GET", "/relatorios/atendimento?"+periodoRelatorio, nil)
+	rr := httptest.NewRecorder()
+	h.HandlerRelatorioAtendimento(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v (%s)", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var rel model.RelatorioAtendimento
+	json.NewDecoder(rr.Body).Decode(&rel)
+
+	if rel.HorariosOfertados != 3 || rel.HorariosOcupados != 2 {
+		t.Errorf("ocupação incorreta: %d de %d", rel.HorariosOcupados, rel.HorariosOfertados)
+	}
+	if rel.MedianaEsperaNoPeriodoHoras != 48 || rel.AmostrasEsperaNoPeriodo != 3 {
+		t.Errorf("espera incorreta: mediana %v com %d amostras", rel.MedianaEsperaNoPeriodoHoras, rel.AmostrasEsperaNoPeriodo)
+	}
+	if rel.MedianaConfirmacaoHoras != 3 || rel.AmostrasConfirmacao != 2 {
+		t.Errorf("confirmação incorreta: mediana %v com %d amostras", rel.MedianaConfirmacaoHoras, rel.AmostrasConfirmacao)
+	}
+	// Recusada conta como cancelamento, como em CalcularPresenca.
+	if rel.TotalConsultas != 6 || rel.PorStatus["concluida"] != 2 || rel.TaxaCancelamento != 2.0/6 || rel.TaxaFalta != 0.25 {
+		t.Errorf("taxas incorretas: %+v", rel)
+	}
+	if rel.AlunosAtendidos != 2 {
+		t.Errorf("alunos atendidos: obteve %d, esperava 2", rel.AlunosAtendidos)
+	}
+}
+
+func TestHandlerRelatorioAtendimentoFiltrosECSV(t *testing.T) {
+	h := novoRelatorioHandler()
+
+	t.Run("por psicologo em csv", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/relatorios/atendimento?formato=csv&psicologoId=psico-2&"+periodoRelatorio, nil)
+		rr := httptest.NewRecorder()
+		h.HandlerRelatorioAtendimento(rr, req)
+
+		if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+			t.Fatalf("resposta incorreta: %v %q", rr.Code, rr.Header().Get("Content-Type"))
+		}
+		linhas, err := csv.NewReader(rr.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("CSV inválido: %v", err)
+		}
+		valores := map[string]string{}
+		for _, l := range linhas {
+			valores[l[0]] = l[1]
+		}
+		if valores["psicologoId"] != "psico-2" || valores["totalConsultas"] != "1" || valores["taxaOcupacao"] != "1.0000" || valores["status:em andamento"] != "1" {
+			t.Errorf("CSV incorreto: %v", valores)
+		}
+	})
+
+	casos := []struct {
+		nome     string
+		query    string
+		esperado int
+	}{
+		{"psicologo inexistente", "psicologoId=psico-x&" + periodoRelatorio, http.StatusNotFound},
+		{"periodo invertido", "de=2026-02-01T00:00:00Z&ate=2026-01-01T00:00:00Z", http.StatusBadRequest},
+		{"formato invalido", "formato=xlsx", http.StatusBadRequest},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/relatorios/atendimento?"+tc.query, nil)
+			rr := httptest.NewRecorder()
+			h.HandlerRelatorioAtendimento(rr, req)
+			if rr.Code != tc.esperado {
+				t.Errorf("status code incorreto: obteve %v, esperava %v", rr.Code, tc.esperado)
+			}
+		})
+	}
+}
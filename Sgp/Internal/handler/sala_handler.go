@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/service"
+	"sort"
+	"time"
+)
+
+type SalaHandler struct {
+	Repo        repository.SalaRepository
+	HorarioRepo repository.HorarioDisponivelRepository
+}
+
+func NewSalaHandler(repo repository.SalaRepository, horarioRepo repository.HorarioDisponivelRepository) *SalaHandler {
+	return &SalaHandler{Repo: repo, HorarioRepo: horarioRepo}
+}
+
+// HandlerCriarSala responde à rota POST /salas
+func (h *SalaHandler) HandlerCriarSala(w http.ResponseWriter, r *http.Request) {
+	var sala model.Sala
+	if err := json.NewDecoder(r.Body).Decode(&sala); err != nil {
+		httpError(w, "Requisição inválida", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if err := validarSala(sala); err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	criada, err := h.Repo.CriarSala(ctx, sala)
+	if err != nil {
+		log.Printf("ERRO ao criar sala: %v", err)
+		httpError(w, "Erro ao criar sala", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(criada)
+}
+
+// HandlerListarSalas responde à rota GET /salas
+func (h *SalaHandler) HandlerListarSalas(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	salas, err := h.Repo.ListarSalas(ctx)
+	if err != nil {
+		log.Printf("ERRO ao listar salas: %v", err)
+		httpError(w, "Erro ao listar salas", http.StatusInternalServerError)
+		return
+	}
+	if salas == nil {
+		salas = []*model.Sala{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(salas)
+}
+
+// HandlerAtualizarSala responde à rota PUT /salas/{id}
+func (h *SalaHandler) HandlerAtualizarSala(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var sala model.Sala
+	if err := json.NewDecoder(r.Body).Decode(&sala); err != nil {
+		httpError(w, "Requisição inválida", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if err := validarSala(sala); err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	if _, err := h.Repo.BuscarSalaPorID(ctx, id); err != nil {
+		httpError(w, "Sala não encontrada", http.StatusNotFound)
+		return
+	}
+	if err := h.Repo.AtualizarSala(ctx, id, sala); err != nil {
+		log.Printf("ERRO ao atualizar sala %s: %v", id, err)
+		httpError(w, "Erro ao atualizar sala", http.StatusInternalServerError)
+		return
+	}
+
+	sala.ID = id
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(sala)
+}
+
+// HandlerDeletarSala responde à rota DELETE /salas/{id}.
+// Salas com horários futuros não podem ser removidas.
+func (h *SalaHandler) HandlerDeletarSala(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	agora := time.Now().UTC()
+	futuros, err := h.HorarioRepo.ListarHorariosPorSala(ctx, id, agora, agora.AddDate(10, 0, 0))
+	if err != nil {
+		log.Printf("ERRO ao verificar horários da sala %s: %v", id, err)
+		httpError(w, "Erro ao deletar sala", http.StatusInternalServerError)
+		return
+	}
+	if len(futuros) > 0 {
+		httpError(w, "A sala tem horários futuros; remova-os antes", http.StatusConflict)
+		return
+	}
+
+	if err := h.Repo.DeletarSala(ctx, id); err != nil {
+		log.Printf("ERRO ao deletar sala %s: %v", id, err)
+		httpError(w, "Erro ao deletar sala", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandlerOcupacaoSalas responde à rota GET /salas/ocupacao?data=AAAA-MM-DD[&salaId=...][&fuso=...].
+// O dia é o do fuso informado (padrão: o da clínica); sem data, usa hoje.
+func (h *SalaHandler) HandlerOcupacaoSalas(w http.ResponseWriter, r *http.Request) {
+	nomeFuso := r.URL.Query().Get("fuso")
+	if err := service.ValidarFuso(nomeFuso); err != nil {
+		httpError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fuso := service.Fuso(nomeFuso)
+
+	data := r.URL.Query().Get("data")
+	if data == "" {
+		data = time.Now().In(fuso).Format("2006-01-02")
+	}
+	dia, fimDoDia, err := service.InicioDoDia(data, fuso)
+	if err != nil {
+		httpError(w, "Parâmetro 'data' inválido, use AAAA-MM-DD", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	var salas []*model.Sala
+	if salaID := r.URL.Query().Get("salaId"); salaID != "" {
+		sala, err := h.Repo.BuscarSalaPorID(ctx, salaID)
+		if err != nil {
+			httpError(w, "Sala não encontrada", http.StatusNotFound)
+			return
+		}
+		salas = []*model.Sala{sala}
+	} else if salas, err = h.Repo.ListarSalas(ctx); err != nil {
+		log.Printf("ERRO ao listar salas: %v", err)
+		httpError(w, "Erro ao montar ocupação das salas", http.StatusInternalServerError)
+		return
+	}
+
+	ocupacao := []model.OcupacaoSala{}
+	for _, sala := range salas {
+		horarios, err := h.HorarioRepo.ListarHorariosPorSala(ctx, sala.ID, dia, fimDoDia)
+		if err != nil {
+			log.Printf("ERRO ao listar horários da sala %s: %v", sala.ID, err)
+			httpError(w, "Erro ao montar ocupação das salas", http.StatusInternalServerError)
+			return
+		}
+		ocupacao = append(ocupacao, ocupacaoDoDia(sala, data, horarios, dia, fimDoDia))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ocupacao)
+}
+
+// ocupacaoDoDia conta apenas a parte de cada horário que cai dentro do dia.
+func ocupacaoDoDia(sala *model.Sala, data string, horarios []*model.HorarioDisponivel, dia, fimDoDia time.Time) model.OcupacaoSala {
+	sort.Slice(horarios, func(i, j int) bool { return horarios[i].Inicio.Before(horarios[j].Inicio) })
+
+	var ocupado time.Duration
+	for _, hr := range horarios {
+		inicio, fim := hr.Inicio, hr.Fim
+		if inicio.Before(dia) {
+			inicio = dia
+		}
+		if fim.After(fimDoDia) {
+			fim = fimDoDia
+		}
+		ocupado += fim.Sub(inicio)
+		hr.Inicio, hr.Fim = hr.Inicio.In(dia.Location()), hr.Fim.In(dia.Location())
+	}
+	if horarios == nil {
+		horarios = []*model.HorarioDisponivel{}
+	}
+
+	return model.OcupacaoSala{
+		Sala:            sala,
+		Data:            data,
+		Horarios:        horarios,
+		MinutosOcupados: int(ocupado.Minutes()),
+	}
+}
+
+func validarSala(sala model.Sala) error {
+	if sala.Nome == "" {
+		return fmt.Errorf("O campo 'nome' é obrigatório")
+	}
+	if sala.Capacidade < 1 {
+		return fmt.Errorf("A capacidade da sala deve ser de pelo menos 1 pessoa")
+	}
+	return nil
+}
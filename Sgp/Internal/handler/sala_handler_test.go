@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/repository/mocks"
+	"sgp/Internal/service"
+	"testing"
+	"time"
+)
+
+func TestHandlerCriarHorarioComSala(t *testing.T) {
+	inicio := time.Date(2025, 6, 2, 14, 0, 0, 0, time.UTC)
+
+	novoHandler := func(conflito bool) *HorarioDisponivelHandler {
+		h := NewHorarioDisponivelHandler(&mocks.HorarioDisponivelRepositoryMock{
+			CriarHorarioFunc: func(ctx context.Context, horario model.HorarioDisponivel) (*model.HorarioDisponivel, error) {
+				if conflito {
+					return nil, repository.ErrConflitoSala
+				}
+				horario.ID = "h1"
+				return &horario, nil
+			},
+		})
+		h.SalaRepo = &mocks.SalaRepositoryMock{
+			BuscarSalaPorIDFunc: func(ctx context.Context, id string) (*model.Sala, error) {
+				if id != "sala-1" {
+					return nil, errors.New("sala não encontrada")
+				}
+				return &model.Sala{ID: id, Nome: "Sala 1", Capacidade: 2}, nil
+			},
+		}
+		return h
+	}
+
+	casos := []struct {
+		nome       string
+		salaID     string
+		modalidade string
+		conflito   bool
+		esperado   int
+	}{
+		{"sala livre", "sala-1", "", false, http.StatusCreated},
+		{"sala ocupada", "sala-1", "", true, http.StatusConflict},
+		{"sala inexistente", "sala-9", "", false, http.StatusNotFound},
+		{"online com sala", "sala-1", model.ModalidadeOnline, false, http.StatusBadRequest},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			body, _ := json.Marshal(model.HorarioDisponivel{
+				PsicologoID: "psico-1", Inicio: inicio, Fim: inicio.Add(time.Hour),
+				SalaID: tc.salaID, Modalidade: tc.modalidade,
+			})
+			req, _ := http.NewRequest("POST", "/horarios", bytes.NewBuffer(body))
+			rr := httptest.NewRecorder()
+
+			novoHandler(tc.conflito).HandlerCriarHorario(rr, req)
+
+			if status := rr.Code; status != tc.esperado {
+				t.Errorf("status code incorreto: obteve %v, esperava %v", status, tc.esperado)
+			}
+		})
+	}
+}
+
+func TestHandlerOcupacaoSalas(t *testing.T) {
+	// Sem 'fuso', o dia é o da clínica (America/Sao_Paulo).
+	dia := time.Date(2025, 6, 2, 0, 0, 0, 0, service.Fuso(""))
+	h := NewSalaHandler(
+		&mocks.SalaRepositoryMock{
+			ListarSalasFunc: func(ctx context.Context) ([]*model.Sala, error) {
+				return []*model.Sala{{ID: "sala-1", Nome: "Sala 1", Capacidade: 2}, {ID: "sala-2", Nome: "Sala 2", Capacidade: 4}}, nil
+			},
+		},
+		&mocks.HorarioDisponivelRepositoryMock{
+			ListarHorariosPorSalaFunc: func(ctx context.Context, salaID string, de, ate time.Time) ([]*model.HorarioDisponivel, error) {
+				if !de.Equal(dia) || !ate.Equal(dia.AddDate(0, 0, 1)) {
+					t.Errorf("intervalo incorreto: %v - %v", de, ate)
+				}
+				if salaID != "sala-1" {
+					return nil, nil
+				}
+				return []*model.HorarioDisponivel{
+					{ID: "tarde", Inicio: dia.Add(14 * time.Hour), Fim: dia.Add(15 * time.Hour)},
+					// Começa na véspera: só os 30 minutos do dia contam.
+					{ID: "madrugada", Inicio: dia.Add(-30 * time.Minute), Fim: dia.Add(30 * time.Minute)},
+				}, nil
+			},
+		},
+	)
+
+	req, _ := http.NewRequest("GET", "/salas/ocupacao?data=2025-06-02", nil)
+	rr := httptest.NewRecorder()
+	h.HandlerOcupacaoSalas(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("status code incorreto: obteve %v, esperava %v", status, http.StatusOK)
+	}
+	var ocupacao []model.OcupacaoSala
+	json.NewDecoder(rr.Body).Decode(&ocupacao)
+	if len(ocupacao) != 2 {
+		t.Fatalf("esperava 2 salas, obteve %d", len(ocupacao))
+	}
+	if ocupacao[0].MinutosOcupados != 90 || ocupacao[0].Horarios[0].ID != "madrugada" {
+		t.Errorf("ocupação incorreta da sala 1: %+v", ocupacao[0])
+	}
+	if ocupacao[1].MinutosOcupados != 0 || len(ocupacao[1].Horarios) != 0 {
+		t.Errorf("sala 2 deveria estar livre: %+v", ocupacao[1])
+	}
+}
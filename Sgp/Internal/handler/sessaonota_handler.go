@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/reqctx"
+	"sgp/Internal/service"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SessaoNotaHandler expõe as notas clínicas apenas por consulta. Não existe
+// rota de listagem, e as notas nunca entram em e-mails ou outras respostas.
+type SessaoNotaHandler struct {
+	Repo         repository.SessaoNotaRepository
+	ConsultaRepo repository.ConsultaRepository
+	Cifra        *service.CifraEnvelope
+}
+
+func NewSessaoNotaHandler(
+	repo repository.SessaoNotaRepository,
+	consultaRepo repository.ConsultaRepository,
+	cifra *service.CifraEnvelope,
+) *SessaoNotaHandler {
+	return &SessaoNotaHandler{Repo: repo, ConsultaRepo: consultaRepo, Cifra: cifra}
+}
+
+// HandlerSalvarNota responde à rota PUT /consultas/{id}/nota
+func (h *SessaoNotaHandler) HandlerSalvarNota(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Conteudo string `json:"conteudo"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		httpError(w, "Requisição inválida", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if payload.Conteudo == "" {
+		httpError(w, "O campo 'conteudo' é obrigatório", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	consulta, ok := h.consultaDoPsicologo(ctx, w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	cifrado, err := h.Cifra.Cifrar(payload.Conteudo, consulta.ID)
+	if err != nil {
+		log.Printf("ERRO ao cifrar nota da consulta %s: %v", consulta.ID, err)
+		httpError(w, "Erro ao salvar nota", http.StatusInternalServerError)
+		return
+	}
+
+	nota, err := h.Repo.SalvarNota(ctx, model.SessaoNota{
+		ConsultaID:  consulta.ID,
+		PsicologoID: consulta.PsicologoID,
+		Cifrado:     cifrado,
+	})
+	if err != nil {
+		log.Printf("ERRO ao salvar nota da consulta %s: %v", consulta.ID, err)
+		httpError(w, "Erro ao salvar nota", http.StatusInternalServerError)
+		return
+	}
+	nota.Conteudo = payload.Conteudo
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(nota)
+}
+
+// HandlerBuscarNota responde à rota GET /consultas/{id}/nota
+func (h *SessaoNotaHandler) HandlerBuscarNota(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	consulta, ok := h.consultaDoPsicologo(ctx, w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	nota, err := h.Repo.BuscarNota(ctx, consulta.ID)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			httpError(w, "Nota não encontrada", http.StatusNotFound)
+		} else {
+			log.Printf("ERRO ao buscar nota da consulta %s: %v", consulta.ID, err)
+			httpError(w, "Erro ao buscar nota", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := h.decifrar(nota); err != nil {
+		log.Printf("ERRO ao decifrar nota da consulta %s: %v", consulta.ID, err)
+		httpError(w, "Erro ao buscar nota", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(nota)
+}
+
+// HandlerListarVersoesNota responde à rota GET /consultas/{id}/nota/versoes
+func (h *SessaoNotaHandler) HandlerListarVersoesNota(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), Timeout)
+	defer cancel()
+
+	consulta, ok := h.consultaDoPsicologo(ctx, w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	versoes, err := h.Repo.ListarVersoesNota(ctx, consulta.ID)
+	if err != nil {
+		log.Printf("ERRO ao listar versões da nota da consulta %s: %v", consulta.ID, err)
+		httpError(w, "Erro ao listar versões da nota", http.StatusInternalServerError)
+		return
+	}
+
+	for _, nota := range versoes {
+		if err := h.decifrar(nota); err != nil {
+			log.Printf("ERRO ao decifrar versão %d da nota da consulta %s: %v", nota.Versao, consulta.ID, err)
+			httpError(w, "Erro ao listar versões da nota", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(versoes)
+}
+
+// consultaDoPsicologo busca a consulta e garante que o ator da requisição é o
+// psicólogo responsável por ela. Em caso de falha já escreve a resposta.
+func (h *SessaoNotaHandler) consultaDoPsicologo(ctx context.Context, w http.ResponseWriter, id string) (*model.Consulta, bool) {
+	if h.Cifra == nil {
+		httpError(w, "Notas de sessão indisponíveis: chave de cifragem não configurada", http.StatusServiceUnavailable)
+		return nil, false
+	}
+	if id == "" {
+		httpError(w, "O ID da consulta é obrigatório", http.StatusBadRequest)
+		return nil, false
+	}
+
+	consulta, err := h.ConsultaRepo.BuscarConsultaPorID(ctx, id)
+	if err != nil {
+		httpError(w, "Consulta não encontrada", http.StatusNotFound)
+		return nil, false
+	}
+
+	ator := reqctx.AtorDe(ctx)
+	if ator.ID == "" || ator.ID != consulta.PsicologoID {
+		httpError(w, "Apenas o psicólogo responsável pode acessar esta nota", http.StatusForbidden)
+		return nil, false
+	}
+	return consulta, true
+}
+
+func (h *SessaoNotaHandler) decifrar(nota *model.SessaoNota) error {
+	conteudo, err := h.Cifra.Decifrar(nota.Cifrado, nota.ConsultaID)
+	if err != nil {
+		return err
+	}
+	nota.Conteudo = conteudo
+	return nil
+}
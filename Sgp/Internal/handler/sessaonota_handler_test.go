@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sgp/Internal/model"
+	"sgp/Internal/repository/mocks"
+	"sgp/Internal/reqctx"
+	"sgp/Internal/service"
+	"strings"
+	"testing"
+)
+
+func novaCifraTeste(t *testing.T) *service.CifraEnvelope {
+	cifra, err := service.NewCifraEnvelope(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)))
+	if err != nil {
+		t.Fatalf("erro ao criar cifra: %v", err)
+	}
+	return cifra
+}
+
+func TestHandlerSalvarNota(t *testing.T) {
+	consultaRepo := &mocks.ConsultaRepositoryMock{
+		BuscarConsultaPorIDFunc: func(ctx context.Context, id string) (*model.Consulta, error) {
+			return &model.Consulta{ID: id, PsicologoID: "psico-1"}, nil
+		},
+	}
+
+	t.Run("sucesso ao salvar nota cifrada", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"conteudo": "paciente relatou melhora"})
+		req, _ := http.NewRequest("PUT", "/consultas/c1/nota", bytes.NewBuffer(body))
+		req.SetPathValue("id", "c1")
+		req = req.WithContext(reqctx.ComAtor(req.Context(), reqctx.Ator{ID: "psico-1"}))
+		rr := httptest.NewRecorder()
+
+		var salva model.SessaoNota
+		mockRepo := &mocks.SessaoNotaRepositoryMock{
+			SalvarNotaFunc: func(ctx context.Context, n model.SessaoNota) (*model.SessaoNota, error) {
+				salva = n
+				n.Versao = 1
+				return &n, nil
+			},
+		}
+
+		cifra := novaCifraTeste(t)
+		h := NewSessaoNotaHandler(mockRepo, consultaRepo, cifra)
+		h.HandlerSalvarNota(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusOK)
+		}
+		if strings.Contains(string(salva.Cifrado.Texto), "melhora") {
+			t.Errorf("conteúdo foi persistido em texto puro")
+		}
+		texto, err := cifra.Decifrar(salva.Cifrado, "c1")
+		if err != nil || texto != "paciente relatou melhora" {
+			t.Errorf("conteúdo decifrado incorreto: %q, %v", texto, err)
+		}
+	})
+
+	t.Run("psicologo que nao e dono da consulta", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"conteudo": "qualquer"})
+		req, _ := http.NewRequest("PUT", "/consultas/c1/nota", bytes.NewBuffer(body))
+		req.SetPathValue("id", "c1")
+		req = req.WithContext(reqctx.ComAtor(req.Context(), reqctx.Ator{ID: "psico-2"}))
+		rr := httptest.NewRecorder()
+
+		h := NewSessaoNotaHandler(&mocks.SessaoNotaRepositoryMock{}, consultaRepo, novaCifraTeste(t))
+		h.HandlerSalvarNota(rr, req)
+
+		if status := rr.Code; status != http.StatusForbidden {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", status, http.StatusForbidden)
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sgp/Internal/model"
+	"sgp/Internal/repository/mocks"
+	"testing"
+	"time"
+)
+
+func TestHandlerAgendarConsultaComTriagem(t *testing.T) {
+	agendar := func(t *testing.T, payload map[string]interface{}) (*httptest.ResponseRecorder, *model.Consulta) {
+		var recebida model.Consulta
+		mockConsultaRepo := &mocks.ConsultaRepositoryMock{
+			AgendarConsultaFunc: func(ctx context.Context, c model.Consulta) (*model.Consulta, error) {
+				recebida = c
+				c.ID = "consulta-1"
+				c.Inicio = time.Now().Add(24 * time.Hour)
+				return &c, nil
+			},
+		}
+		// Erro na busca do aluno evita o uso do EmailService nil nas goroutines
+		mockAlunoRepo := &mocks.AlunoRepositoryMock{
+			BuscarAlunoPorIDFunc: func(ctx context.Context, id string) (*model.Aluno, error) {
+				return nil, errors.New("ignorar email no teste")
+			},
+		}
+		mockPsicologoRepo := &mocks.PsicologoRepositoryMock{
+			BuscarPsicologoPorIDFunc: func(ctx context.Context, id string) (*model.Psicologo, error) {
+				return nil, errors.New("ignorar email no teste")
+			},
+		}
+
+		h := NewConsultaHandler(mockConsultaRepo, mockAlunoRepo, mockPsicologoRepo, nil)
+		h.EmailsPlantao = []string{"plantao@exemplo.com"}
+
+		body, _ := json.Marshal(payload)
+		req, _ := http.NewRequest("POST", "/consultas", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+		h.HandlerAgendarConsulta(rr, req)
+		return rr, &recebida
+	}
+
+	t.Run("triagem eleva a urgência", func(t *testing.T) {
+		rr, recebida := agendar(t, map[string]interface{}{
+			"alunoId":   "aluno-1",
+			"horarioId": "horario-1",
+			"urgencia":  "prioritaria",
+			"triagem":   map[string]bool{"risco": true, "crise": false},
+		})
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("status code incorreto: obteve %v, esperava %v", rr.Code, http.StatusCreated)
+		}
+		if recebida.Urgencia != model.UrgenciaUrgente {
+			t.Errorf("urgência incorreta: obteve %q, esperava %q", recebida.Urgencia, model.UrgenciaUrgente)
+		}
+	})
+
+	t.Run("sem triagem a consulta é de rotina", func(t *testing.T) {
+		rr, recebida := agendar(t, map[string]interface{}{"alunoId": "aluno-1", "horarioId": "horario-1"})
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("status code incorreto: obteve %v, esperava %v", rr.Code, http.StatusCreated)
+		}
+		if recebida.Urgencia != model.UrgenciaRotina {
+			t.Errorf("urgência incorreta: obteve %q, esperava %q", recebida.Urgencia, model.UrgenciaRotina)
+		}
+	})
+
+	t.Run("pergunta desconhecida", func(t *testing.T) {
+		rr, _ := agendar(t, map[string]interface{}{
+			"alunoId":   "aluno-1",
+			"horarioId": "horario-1",
+			"triagem":   map[string]bool{"inexistente": true},
+		})
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("status code incorreto: obteve %v, esperava %v", rr.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestHandlerListarConsultasPorUrgencia(t *testing.T) {
+	base := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	mockRepo := &mocks.ConsultaRepositoryMock{
+		ListarConsultasPorPsicologoFunc: func(ctx context.Context, psicologoID string, statusFiltro string) ([]*model.Consulta, error) {
+			return []*model.Consulta{
+				{ID: "rotina", DataAgendamento: base},
+				{ID: "urgente-tarde", Urgencia: model.UrgenciaUrgente, DataAgendamento: base.Add(2 * time.Hour)},
+				{ID: "prioritaria", Urgencia: model.UrgenciaPrioritaria, DataAgendamento: base},
+				{ID: "urgente-cedo", Urgencia: model.UrgenciaUrgente, DataAgendamento: base.Add(time.Hour)},
+			}, nil
+		},
+	}
+	h := NewConsultaHandler(mockRepo, &mocks.AlunoRepositoryMock{}, &mocks.PsicologoRepositoryMock{}, nil)
+
+	listar := func(query string) []string {
+		req, _ := http.NewRequest("GET", "/consultas/psicologo?psicologoId=psico-1&"+query, nil)
+		rr := httptest.NewRecorder()
+		h.HandlerListarConsultasPorPsicologo(rr, req)
+		var consultas []*model.Consulta
+		json.NewDecoder(rr.Body).Decode(&consultas)
+		ids := []string{}
+		for _, c := range consultas {
+			ids = append(ids, c.ID)
+		}
+		return ids
+	}
+
+	t.Run("ordena por urgência", func(t *testing.T) {
+		ids := listar("ordenar=urgencia")
+		esperado := []string{"urgente-cedo", "urgente-tarde", "prioritaria", "rotina"}
+		if len(ids) != len(esperado) {
+			t.Fatalf("número incorreto de consultas: obteve %v", ids)
+		}
+		for i := range esperado {
+			if ids[i] != esperado[i] {
+				t.Errorf("ordem incorreta: obteve %v, esperava %v", ids, esperado)
+				break
+			}
+		}
+	})
+
+	t.Run("filtra por urgência", func(t *testing.T) {
+		if ids := listar("urgencia=rotina"); len(ids) != 1 || ids[0] != "rotina" {
+			t.Errorf("filtro incorreto: obteve %v", ids)
+		}
+	})
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"sgp/Internal/reqctx"
 	"strings"
 
 	"firebase.google.com/go/v4/auth"
@@ -43,7 +44,10 @@ func (am *AuthMiddleware) Verify(next http.Handler) http.Handler {
 			return
 		}
 
+		papel, _ := token.Claims["role"].(string)
+
 		ctx := context.WithValue(r.Context(), "user", token)
+		ctx = reqctx.ComAtor(ctx, reqctx.Ator{ID: token.UID, Papel: papel})
 		r = r.WithContext(ctx)
 
 		next.ServeHTTP(w, r)
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sgp/Internal/reqctx"
+)
+
+const HeaderRequestID = "X-Request-ID"
+
+// RequestID reaproveita o X-Request-ID enviado pelo cliente ou gera um novo,
+// devolvendo-o na resposta e guardando-o no contexto da requisição.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(HeaderRequestID)
+		if id == "" {
+			id = novoRequestID()
+		}
+
+		w.Header().Set(HeaderRequestID, id)
+		next.ServeHTTP(w, r.WithContext(reqctx.ComRequestID(r.Context(), id)))
+	})
+}
+
+// IdentidadeDev é usado apenas no modo sem autenticação: lê o ator dos
+// headers X-User-ID e X-User-Role para que auditoria e regras de posse
+// continuem funcionando em desenvolvimento.
+func IdentidadeDev(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ator := reqctx.Ator{
+			ID:    r.Header.Get("X-User-ID"),
+			Papel: r.Header.Get("X-User-Role"),
+		}
+		next.ServeHTTP(w, r.WithContext(reqctx.ComAtor(r.Context(), ator)))
+	})
+}
+
+// ExigirPapel bloqueia a rota para atores cujo papel não está na lista.
+func ExigirPapel(papeis ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ator := reqctx.AtorDe(r.Context())
+			for _, papel := range papeis {
+				if ator.Papel == papel {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			http.Error(w, "acesso negado", http.StatusForbidden)
+		})
+	}
+}
+
+func novoRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
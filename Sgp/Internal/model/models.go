@@ -6,6 +6,27 @@ type Aluno struct {
 	ID string `json:"id" firestore:"-"`
 	Nome string `json:"nome" firestore:"nome"`
 	Email string `json:"email" firestore:"email"`
+	NomeSocial string `json:"nomeSocial,omitempty" firestore:"nomeSocial,omitempty"`
+	Pronomes string `json:"pronomes,omitempty" firestore:"pronomes,omitempty"`
+	Curso string `json:"curso,omitempty" firestore:"curso,omitempty"`
+	// FusoHorario é um nome IANA (ex: "America/Manaus"); vazio usa America/Sao_Paulo.
+	FusoHorario string `json:"fusoHorario,omitempty" firestore:"fusoHorario,omitempty"`
+
+	// Dados sensíveis: visíveis só ao próprio aluno, aos psicólogos que o
+	// atendem e aos admins.
+	Matricula string `json:"matricula,omitempty" firestore:"matricula,omitempty"`
+	Telefone string `json:"telefone,omitempty" firestore:"telefone,omitempty"`
+	DataNascimento string `json:"dataNascimento,omitempty" firestore:"dataNascimento,omitempty"` // AAAA-MM-DD
+	ContatosEmergencia []ContatoEmergencia `json:"contatosEmergencia,omitempty" firestore:"contatosEmergencia,omitempty"`
+
+	Anonimizado bool `json:"anonimizado,omitempty" firestore:"anonimizado,omitempty"`
+	AnonimizadoEm time.Time `json:"anonimizadoEm,omitempty" firestore:"anonimizadoEm,omitempty"`
+}
+
+type ContatoEmergencia struct {
+	Nome       string `json:"nome" firestore:"nome"`
+	Parentesco string `json:"parentesco,omitempty" firestore:"parentesco,omitempty"`
+	Telefone   string `json:"telefone" firestore:"telefone"`
 }
 
 type Psicologo struct{
@@ -13,8 +34,25 @@ type Psicologo struct{
 	Nome string `json:"nome" firestore:"nome"`
 	Email string `json:"email" firestore:"email"`
 	CRP string `json:"crp" firestore:"crp"`
+	Especialidades []string `json:"especialidades,omitempty" firestore:"especialidades,omitempty"`
+	Abordagem string `json:"abordagem,omitempty" firestore:"abordagem,omitempty"`
+	Idiomas []string `json:"idiomas,omitempty" firestore:"idiomas,omitempty"`
+	Modalidades []string `json:"modalidades,omitempty" firestore:"modalidades,omitempty"` // "presencial" e/ou "online"
+	Bio string `json:"bio,omitempty" firestore:"bio,omitempty"`
+	// Ativo nil conta como ativo, para não desligar cadastros anteriores ao campo.
+	Ativo *bool `json:"ativo,omitempty" firestore:"ativo,omitempty"`
+	// Limites de carga; zero significa sem limite.
+	LimiteSessoesSemana int `json:"limiteSessoesSemana,omitempty" firestore:"limiteSessoesSemana,omitempty"`
+	LimitePacientesAtivos int `json:"limitePacientesAtivos,omitempty" firestore:"limitePacientesAtivos,omitempty"`
+	// FusoHorario é um nome IANA; vazio usa America/Sao_Paulo.
+	FusoHorario string `json:"fusoHorario,omitempty" firestore:"fusoHorario,omitempty"`
 }
 
+const (
+	ModalidadePresencial = "presencial"
+	ModalidadeOnline     = "online"
+)
+
 type Consulta struct {
 	ID              string    `json:"id" firestore:"-"`
 	AlunoID         string    `json:"alunoId" firestore:"alunoId"`
@@ -24,12 +62,465 @@ type Consulta struct {
 	Fim             time.Time `json:"fim" firestore:"fim"`
 	Status          string    `json:"status" firestore:"status"`
 	DataAgendamento time.Time `json:"dataAgendamento" firestore:"dataAgendamento"`
+	Urgencia        string    `json:"urgencia,omitempty" firestore:"urgencia,omitempty"`
+	Modalidade      string    `json:"modalidade,omitempty" firestore:"modalidade,omitempty"`
+	LinkReuniao     string    `json:"linkReuniao,omitempty" firestore:"linkReuniao,omitempty"`
+	ConfirmadaEm    time.Time `json:"confirmadaEm,omitempty" firestore:"confirmadaEm,omitempty"`
+	CheckinEm       time.Time `json:"checkinEm,omitempty" firestore:"checkinEm,omitempty"`
+	ConcluidaEm     time.Time `json:"concluidaEm,omitempty" firestore:"concluidaEm,omitempty"`
+	Grupo           bool      `json:"grupo,omitempty" firestore:"grupo,omitempty"` // inscrição em horário de grupo
+	EpisodioID      string    `json:"episodioId,omitempty" firestore:"episodioId,omitempty"`
+	// CodigoCheckin é mostrado pelo psicólogo (em texto ou QR) e digitado pelo aluno.
+	CodigoCheckin string `json:"-" firestore:"codigoCheckin,omitempty"`
 }
 
+// Níveis de urgência de uma consulta, do menos para o mais urgente.
+const (
+	UrgenciaRotina      = "rotina"
+	UrgenciaPrioritaria = "prioritaria"
+	UrgenciaUrgente     = "urgente"
+)
+
 type HorarioDisponivel struct {
 	ID          string    `json:"id" firestore:"-"`
 	PsicologoID string    `json:"psicologoId" firestore:"psicologoId"`
 	Inicio      time.Time `json:"inicio" firestore:"inicio"`
 	Fim         time.Time `json:"fim" firestore:"fim"`
 	Status      string    `json:"status" firestore:"status"`
-}
\ No newline at end of file
+	Modalidade  string    `json:"modalidade,omitempty" firestore:"modalidade,omitempty"` // vazio é presencial
+	SalaID      string    `json:"salaId,omitempty" firestore:"salaId,omitempty"`         // só para sessões presenciais
+	// ReservadoPara é o aluno que pode agendar o horário enquanto ele está
+	// "reservado"; depois de ReservadoAte a reserva é liberada.
+	ReservadoPara string    `json:"reservadoPara,omitempty" firestore:"reservadoPara,omitempty"`
+	ReservadoAte  time.Time `json:"reservadoAte,omitempty" firestore:"reservadoAte,omitempty"`
+
+	// Campos dos horários de grupo (terapia em grupo e oficinas). Um horário
+	// de grupo recebe várias consultas até a capacidade; depois disso os
+	// pedidos entram na lista de espera e o status passa a "lotado".
+	Tipo        string `json:"tipo,omitempty" firestore:"tipo,omitempty"` // vazio é individual
+	Titulo      string `json:"titulo,omitempty" firestore:"titulo,omitempty"`
+	Capacidade  int    `json:"capacidade,omitempty" firestore:"capacidade,omitempty"`
+	Inscritos   int    `json:"inscritos" firestore:"inscritos,omitempty"`
+	ListaEspera int    `json:"listaEspera" firestore:"listaEspera,omitempty"`
+}
+
+// Tipos de horário.
+const (
+	HorarioIndividual = ""
+	HorarioGrupo      = "grupo"
+)
+
+// RegistroAuditoria é uma entrada imutável da trilha de auditoria.
+type RegistroAuditoria struct {
+	ID         string                 `json:"id" firestore:"-"`
+	AtorID     string                 `json:"atorId" firestore:"atorId"`
+	Acao       string                 `json:"acao" firestore:"acao"`
+	Entidade   string                 `json:"entidade" firestore:"entidade"`
+	EntidadeID string                 `json:"entidadeId" firestore:"entidadeId"`
+	Antes      map[string]interface{} `json:"antes,omitempty" firestore:"antes,omitempty"`
+	Depois     map[string]interface{} `json:"depois,omitempty" firestore:"depois,omitempty"`
+	Campos     []string               `json:"campos,omitempty" firestore:"campos,omitempty"`
+	Timestamp  time.Time              `json:"timestamp" firestore:"timestamp"`
+	RequestID  string                 `json:"requestId" firestore:"requestId"`
+}
+
+type FiltroAuditoria struct {
+	Entidade   string
+	EntidadeID string
+	AtorID     string
+	Limite     int
+	Cursor     string
+}
+
+// SessaoNota é a anotação clínica privada de uma consulta. O texto puro só
+// existe em memória; no banco fica apenas o conteúdo cifrado.
+type SessaoNota struct {
+	ID           string          `json:"id" firestore:"-"`
+	ConsultaID   string          `json:"consultaId" firestore:"consultaId"`
+	PsicologoID  string          `json:"psicologoId" firestore:"psicologoId"`
+	Versao       int             `json:"versao" firestore:"versao"`
+	Conteudo     string          `json:"conteudo" firestore:"-"`
+	Cifrado      ConteudoCifrado `json:"-" firestore:"cifrado"`
+	AtualizadoEm time.Time       `json:"atualizadoEm" firestore:"atualizadoEm"`
+}
+
+// ConteudoCifrado guarda o texto cifrado com uma chave de dados (DEK) própria,
+// que por sua vez é cifrada pela chave mestra da configuração.
+type ConteudoCifrado struct {
+	ChaveCifrada []byte `firestore:"chaveCifrada"`
+	NonceChave   []byte `firestore:"nonceChave"`
+	Nonce        []byte `firestore:"nonce"`
+	Texto        []byte `firestore:"texto"`
+}
+
+// Notificacao registra cada e-mail disparado, com conteúdo suficiente para reenvio.
+type Notificacao struct {
+	ID           string    `json:"id" firestore:"-"`
+	AlunoID      string    `json:"alunoId" firestore:"alunoId"`
+	Tipo         string    `json:"tipo" firestore:"tipo"`
+	Destinatario string    `json:"destinatario" firestore:"destinatario"`
+	Assunto      string    `json:"assunto" firestore:"assunto"`
+	Html         string    `json:"html" firestore:"html"`
+	Status       string    `json:"status" firestore:"status"`
+	Erro         string    `json:"erro,omitempty" firestore:"erro,omitempty"`
+	Tentativas   int       `json:"tentativas" firestore:"tentativas"`
+	CriadoEm     time.Time `json:"criadoEm" firestore:"criadoEm"`
+	EnviadoEm    time.Time `json:"enviadoEm,omitempty" firestore:"enviadoEm,omitempty"`
+}
+
+// ExportacaoAluno é o arquivo entregue ao titular num pedido de acesso (LGPD art. 18).
+type ExportacaoAluno struct {
+	GeradoEm     time.Time               `json:"geradoEm"`
+	Aluno        *Aluno                  `json:"aluno"`
+	Consultas    []*Consulta             `json:"consultas"`
+	Notificacoes []*Notificacao          `json:"notificacoes"`
+	Auditoria    []*RegistroAuditoria    `json:"auditoria"`
+	Aceites      []*AceiteConsentimento  `json:"aceites"`
+	Formularios  []*RespostaFormulario   `json:"formularios"`
+	Instrumentos []*AplicacaoInstrumento `json:"instrumentos"`
+	Episodios    []*Episodio             `json:"episodios"`
+}
+
+// DocumentoConsentimento é uma versão publicada do termo de consentimento
+// informado. A versão mais alta é sempre a vigente.
+type DocumentoConsentimento struct {
+	ID          string    `json:"id" firestore:"-"`
+	Versao      int       `json:"versao" firestore:"versao"`
+	Titulo      string    `json:"titulo" firestore:"titulo"`
+	Conteudo    string    `json:"conteudo" firestore:"conteudo"`
+	PublicadoEm time.Time `json:"publicadoEm" firestore:"publicadoEm"`
+}
+
+type AceiteConsentimento struct {
+	ID       string    `json:"id" firestore:"-"`
+	AlunoID  string    `json:"alunoId" firestore:"alunoId"`
+	Versao   int       `json:"versao" firestore:"versao"`
+	AceitoEm time.Time `json:"aceitoEm" firestore:"aceitoEm"`
+	IP       string    `json:"ip" firestore:"ip"`
+}
+
+// FormularioInicial é o questionário de acolhimento configurado por cada
+// psicólogo, respondido pelo aluno no primeiro agendamento com ele.
+type FormularioInicial struct {
+	ID           string               `json:"id" firestore:"-"`
+	PsicologoID  string               `json:"psicologoId" firestore:"psicologoId"`
+	Obrigatorio  bool                 `json:"obrigatorio" firestore:"obrigatorio"`
+	Perguntas    []PerguntaFormulario `json:"perguntas" firestore:"perguntas"`
+	AtualizadoEm time.Time            `json:"atualizadoEm" firestore:"atualizadoEm"`
+}
+
+type PerguntaFormulario struct {
+	ID          string   `json:"id" firestore:"id"`
+	Texto       string   `json:"texto" firestore:"texto"`
+	Tipo        string   `json:"tipo" firestore:"tipo"` // "texto" ou "escolha"
+	Opcoes      []string `json:"opcoes,omitempty" firestore:"opcoes,omitempty"`
+	Obrigatoria bool     `json:"obrigatoria" firestore:"obrigatoria"`
+}
+
+type RespostaFormulario struct {
+	ID          string            `json:"id" firestore:"-"`
+	PsicologoID string            `json:"psicologoId" firestore:"psicologoId"`
+	AlunoID     string            `json:"alunoId" firestore:"alunoId"`
+	ConsultaID  string            `json:"consultaId" firestore:"consultaId"`
+	Respostas   map[string]string `json:"respostas" firestore:"respostas"`
+	EnviadoEm   time.Time         `json:"enviadoEm" firestore:"enviadoEm"`
+}
+
+// Instrumento descreve um questionário padronizado (ex: PHQ-9) e suas regras
+// de pontuação. Os instrumentos disponíveis são definidos no código.
+type Instrumento struct {
+	ID            string           `json:"id"`
+	Nome          string           `json:"nome"`
+	Instrucao     string           `json:"instrucao"`
+	Itens         []string         `json:"itens"`
+	Opcoes        []OpcaoResposta  `json:"opcoes"`
+	Faixas        []FaixaGravidade `json:"faixas"`
+	ItensCriticos []ItemCritico    `json:"itensCriticos,omitempty"`
+}
+
+type OpcaoResposta struct {
+	Valor int    `json:"valor"`
+	Texto string `json:"texto"`
+}
+
+type FaixaGravidade struct {
+	Min       int    `json:"min"`
+	Max       int    `json:"max"`
+	Gravidade string `json:"gravidade"`
+}
+
+// ItemCritico dispara alerta quando a resposta do item (índice a partir de 0)
+// for maior ou igual a ValorMinimo.
+type ItemCritico struct {
+	Indice      int    `json:"indice"`
+	ValorMinimo int    `json:"valorMinimo"`
+	Motivo      string `json:"motivo"`
+}
+
+// AplicacaoInstrumento é um instrumento atribuído por um psicólogo a um aluno.
+type AplicacaoInstrumento struct {
+	ID            string    `json:"id" firestore:"-"`
+	InstrumentoID string    `json:"instrumentoId" firestore:"instrumentoId"`
+	AlunoID       string    `json:"alunoId" firestore:"alunoId"`
+	PsicologoID   string    `json:"psicologoId" firestore:"psicologoId"`
+	Status        string    `json:"status" firestore:"status"` // "pendente" ou "respondido"
+	Respostas     []int     `json:"respostas,omitempty" firestore:"respostas,omitempty"`
+	Escore        int       `json:"escore" firestore:"escore"`
+	Gravidade     string    `json:"gravidade,omitempty" firestore:"gravidade,omitempty"`
+	Alerta        bool      `json:"alerta" firestore:"alerta"`
+	ItensCriticos []int     `json:"itensCriticos,omitempty" firestore:"itensCriticos,omitempty"`
+	AtribuidoEm   time.Time `json:"atribuidoEm" firestore:"atribuidoEm"`
+	RespondidoEm  time.Time `json:"respondidoEm,omitempty" firestore:"respondidoEm,omitempty"`
+}
+
+// TurnoPlantao coloca um psicólogo de plantão numa janela de tempo. Quando há
+// mais de um turno ao mesmo tempo, Ordem define quem é acionado primeiro.
+type TurnoPlantao struct {
+	ID          string    `json:"id" firestore:"-"`
+	PsicologoID string    `json:"psicologoId" firestore:"psicologoId"`
+	Inicio      time.Time `json:"inicio" firestore:"inicio"`
+	Fim         time.Time `json:"fim" firestore:"fim"`
+	Ordem       int       `json:"ordem" firestore:"ordem"`
+}
+
+const (
+	AlertaPendente    = "pendente"
+	AlertaReconhecido = "reconhecido"
+	AlertaSemPlantao  = "sem plantonista"
+	OrigemTriagem     = "triagem"
+	OrigemInstrumento = "instrumento"
+)
+
+// AlertaPlantao é um caso urgente encaminhado ao plantão. Acionados guarda,
+// em ordem, os psicólogos já avisados; o último é o responsável atual.
+type AlertaPlantao struct {
+	ID             string    `json:"id" firestore:"-"`
+	Origem         string    `json:"origem" firestore:"origem"`
+	AlunoID        string    `json:"alunoId" firestore:"alunoId"`
+	ReferenciaID   string    `json:"referenciaId" firestore:"referenciaId"` // consulta ou aplicação de instrumento
+	Motivo         string    `json:"motivo" firestore:"motivo"`
+	Status         string    `json:"status" firestore:"status"`
+	Acionados      []string  `json:"acionados" firestore:"acionados"`
+	CriadoEm       time.Time `json:"criadoEm" firestore:"criadoEm"`
+	AcionadoEm     time.Time `json:"acionadoEm" firestore:"acionadoEm"`
+	ReconhecidoPor string    `json:"reconhecidoPor,omitempty" firestore:"reconhecidoPor,omitempty"`
+	ReconhecidoEm  time.Time `json:"reconhecidoEm,omitempty" firestore:"reconhecidoEm,omitempty"`
+}
+
+// EstatisticasPresenca resume o comparecimento nas consultas já iniciadas.
+type EstatisticasPresenca struct {
+	Total              int     `json:"total"`
+	Concluidas         int     `json:"concluidas"`
+	EmAndamento        int     `json:"emAndamento"`
+	Faltas             int     `json:"faltas"`
+	Canceladas         int     `json:"canceladas"`
+	TaxaComparecimento float64 `json:"taxaComparecimento"` // (concluídas + em andamento) / (as mesmas + faltas)
+}
+
+// RelatorioAtendimento resume o atendimento da clínica num período, para a
+// coordenação. Tempos são em horas; medianas sem amostras ficam zeradas.
+type RelatorioAtendimento struct {
+	De          time.Time `json:"de"`
+	Ate         time.Time `json:"ate"`
+	PsicologoID string    `json:"psicologoId,omitempty"`
+
+	HorariosOfertados int     `json:"horariosOfertados"` // exceto bloqueados
+	HorariosOcupados  int     `json:"horariosOcupados"`
+	TaxaOcupacao      float64 `json:"taxaOcupacao"`
+
+	// Espera do agendamento até a primeira sessão de cada aluno dentro do
+	// período. Quem já era atendido antes também entra: não é a espera pelo
+	// primeiro atendimento no serviço.
+	MedianaEsperaNoPeriodoHoras float64 `json:"medianaEsperaNoPeriodoHoras"`
+	AmostrasEsperaNoPeriodo     int     `json:"amostrasEsperaNoPeriodo"`
+	// Latência do agendamento até a confirmação pelo psicólogo.
+	MedianaConfirmacaoHoras float64 `json:"medianaConfirmacaoHoras"`
+	AmostrasConfirmacao     int     `json:"amostrasConfirmacao"`
+
+	TotalConsultas   int            `json:"totalConsultas"`
+	PorStatus        map[string]int `json:"porStatus"`
+	TaxaCancelamento float64        `json:"taxaCancelamento"` // canceladas ou recusadas / total
+	TaxaFalta        float64        `json:"taxaFalta"`        // faltas / (faltas + comparecimentos)
+	AlunosAtendidos  int            `json:"alunosAtendidos"`  // distintos, com sessão realizada
+}
+
+// RelatorioImportacao é o resultado, linha a linha, de uma importação em
+// massa. Na simulação nada é gravado e as linhas aptas ficam como "valido".
+type RelatorioImportacao struct {
+	Tipo      string             `json:"tipo"` // "alunos" ou "psicologos"
+	Simulacao bool               `json:"simulacao"`
+	Total     int                `json:"total"`
+	Criados   int                `json:"criados"`
+	Validos   int                `json:"validos"`
+	Ignorados int                `json:"ignorados"`
+	Erros     int                `json:"erros"`
+	Linhas    []*LinhaImportacao `json:"linhas"`
+}
+
+type LinhaImportacao struct {
+	Linha  int    `json:"linha"` // na planilha, contando o cabeçalho
+	Email  string `json:"email,omitempty"`
+	Status string `json:"status"`
+	Motivo string `json:"motivo,omitempty"`
+	ID     string `json:"id,omitempty"`
+}
+
+// Status de uma linha importada.
+const (
+	LinhaCriada   = "criado"
+	LinhaValida   = "valido"
+	LinhaIgnorada = "ignorado" // e-mail já cadastrado
+	LinhaComErro  = "erro"
+)
+
+// RelatorioReconciliacao lista as inconsistências entre consultas, horários
+// e cadastros encontradas numa execução do reconciliador. Sem Aplicado, as
+// correções são só descritas.
+type RelatorioReconciliacao struct {
+	ExecutadoEm     time.Time         `json:"executadoEm"`
+	Aplicado        bool              `json:"aplicado"`
+	Total           int               `json:"total"`
+	Corrigidas      int               `json:"corrigidas"`
+	Descartadas     int               `json:"descartadas"`
+	Falhas          int               `json:"falhas"`
+	PorTipo         map[string]int    `json:"porTipo"`
+	Inconsistencias []*Inconsistencia `json:"inconsistencias"`
+}
+
+type Inconsistencia struct {
+	Tipo       string `json:"tipo"`
+	Entidade   string `json:"entidade"` // "consulta" ou "horario"
+	EntidadeID string `json:"entidadeId"`
+	Detalhe    string `json:"detalhe"`
+	Correcao   string `json:"correcao"` // o que foi feito, ou seria feito na simulação
+	Corrigida  bool   `json:"corrigida"`
+	// Descartada indica que o dado mudou entre a verificação e a correção,
+	// que então não foi feita; a próxima execução o verifica de novo.
+	Descartada bool   `json:"descartada,omitempty"`
+	Erro       string `json:"erro,omitempty"`
+}
+
+
+// Tipos de inconsistência tratados pelo reconciliador.
+const (
+	InconsistenciaConsultaSemHorario   = "consulta_sem_horario"
+	InconsistenciaConsultaSemAluno     = "consulta_sem_aluno"
+	InconsistenciaConsultaSemPsicologo = "consulta_sem_psicologo"
+	InconsistenciaHorarioAgendadoLivre = "horario_agendado_sem_consulta"
+	InconsistenciaHorarioLivreOcupado  = "horario_livre_com_consulta"
+	InconsistenciaHorarioSemPsicologo  = "horario_sem_psicologo"
+	InconsistenciaReservaSemAluno      = "reserva_sem_aluno"
+	InconsistenciaContagemGrupo        = "contagem_grupo_divergente"
+)
+
+// Sala é um espaço físico da clínica usado nas sessões presenciais.
+type Sala struct {
+	ID         string `json:"id" firestore:"-"`
+	Nome       string `json:"nome" firestore:"nome"`
+	Local      string `json:"local" firestore:"local"` // ex: "Bloco B, 2º andar"
+	Capacidade int    `json:"capacidade" firestore:"capacidade"`
+}
+
+// OcupacaoSala resume o uso de uma sala em um dia.
+type OcupacaoSala struct {
+	Sala            *Sala                `json:"sala"`
+	Data            string               `json:"data"`
+	Horarios        []*HorarioDisponivel `json:"horarios"`
+	MinutosOcupados int                  `json:"minutosOcupados"`
+}
+
+// Tipos de evento do calendário institucional.
+const (
+	EventoFeriado       = "feriado"
+	EventoFechamento    = "fechamento"     // recesso, férias coletivas, obras etc.
+	EventoPeriodoLetivo = "periodo letivo" // quando há períodos cadastrados, só se atende dentro deles
+)
+
+// EventoCalendario marca um intervalo [Inicio, Fim) no calendário da universidade.
+type EventoCalendario struct {
+	ID       string    `json:"id" firestore:"-"`
+	Tipo     string    `json:"tipo" firestore:"tipo"`
+	Nome     string    `json:"nome" firestore:"nome"`
+	Inicio   time.Time `json:"inicio" firestore:"inicio"`
+	Fim      time.Time `json:"fim" firestore:"fim"`
+	CriadoEm time.Time `json:"criadoEm" firestore:"criadoEm"`
+}
+
+// Episodio é um caso em acompanhamento: liga o aluno, o psicólogo responsável,
+// os objetivos e as consultas do tratamento, da abertura até a alta. Um aluno
+// tem no máximo um episódio aberto por vez.
+type Episodio struct {
+	ID                 string                  `json:"id" firestore:"-"`
+	AlunoID            string                  `json:"alunoId" firestore:"alunoId"`
+	PsicologoID        string                  `json:"psicologoId" firestore:"psicologoId"`
+	Status             string                  `json:"status" firestore:"status"`
+	Objetivos          []string                `json:"objetivos,omitempty" firestore:"objetivos,omitempty"`
+	SessoesPlanejadas  int                     `json:"sessoesPlanejadas,omitempty" firestore:"sessoesPlanejadas,omitempty"`
+	AbertoEm           time.Time               `json:"abertoEm" firestore:"abertoEm"`
+	EncerradoEm        time.Time               `json:"encerradoEm,omitempty" firestore:"encerradoEm,omitempty"`
+	MotivoEncerramento string                  `json:"motivoEncerramento,omitempty" firestore:"motivoEncerramento,omitempty"`
+	Transferencias     []TransferenciaEpisodio `json:"transferencias,omitempty" firestore:"transferencias,omitempty"`
+}
+
+const (
+	EpisodioAberto    = "aberto"
+	EpisodioEncerrado = "encerrado"
+)
+
+// TransferenciaEpisodio registra a troca do psicólogo responsável.
+type TransferenciaEpisodio struct {
+	DePsicologoID   string    `json:"dePsicologoId" firestore:"dePsicologoId"`
+	ParaPsicologoID string    `json:"paraPsicologoId" firestore:"paraPsicologoId"`
+	Motivo          string    `json:"motivo,omitempty" firestore:"motivo,omitempty"`
+	AtorID          string    `json:"atorId,omitempty" firestore:"atorId,omitempty"`
+	Em              time.Time `json:"em" firestore:"em"`
+}
+
+// ProgressoEpisodio é o episódio com as suas consultas, como devolvido pela API.
+type ProgressoEpisodio struct {
+	*Episodio
+	Consultas         []*Consulta `json:"consultas"`
+	SessoesRealizadas int         `json:"sessoesRealizadas"`
+}
+
+// Encaminhamento registra a indicação de um aluno a outro psicólogo da
+// clínica ou a um serviço externo. Em encaminhamentos internos, o aceite
+// passa o episódio de cuidado ao psicólogo de destino.
+type Encaminhamento struct {
+	ID              string `json:"id" firestore:"-"`
+	AlunoID         string `json:"alunoId" firestore:"alunoId"`
+	DePsicologoID   string `json:"dePsicologoId" firestore:"dePsicologoId"`
+	ParaPsicologoID string `json:"paraPsicologoId,omitempty" firestore:"paraPsicologoId,omitempty"`
+	ServicoExterno  string `json:"servicoExterno,omitempty" firestore:"servicoExterno,omitempty"` // nome e contato, quando não é interno
+	Motivo          string `json:"motivo" firestore:"motivo"`
+	Status          string `json:"status" firestore:"status"`
+	EpisodioID      string `json:"episodioId,omitempty" firestore:"episodioId,omitempty"`
+	// HorarioID é o primeiro horário do psicólogo de destino, reservado ao
+	// aluno até a resposta; ConsultaID é a consulta criada nele no aceite.
+	HorarioID    string `json:"horarioId,omitempty" firestore:"horarioId,omitempty"`
+	ConsultaID   string `json:"consultaId,omitempty" firestore:"consultaId,omitempty"`
+	MotivoRecusa string `json:"motivoRecusa,omitempty" firestore:"motivoRecusa,omitempty"`
+	// CompartilharDados é a autorização do aluno para o destino ver os dados
+	// iniciais (o acolhimento feito com a origem); pode ser revogada.
+	CompartilharDados bool      `json:"compartilharDados" firestore:"compartilharDados"`
+	ConsentimentoEm   time.Time `json:"consentimentoEm,omitempty" firestore:"consentimentoEm,omitempty"`
+	CriadoEm          time.Time `json:"criadoEm" firestore:"criadoEm"`
+	RespondidoEm      time.Time `json:"respondidoEm,omitempty" firestore:"respondidoEm,omitempty"`
+	ConcluidoEm       time.Time `json:"concluidoEm,omitempty" firestore:"concluidoEm,omitempty"`
+}
+
+const (
+	EncaminhamentoCriado    = "criado"
+	EncaminhamentoAceito    = "aceito"
+	EncaminhamentoRecusado  = "recusado"
+	EncaminhamentoConcluido = "concluido"
+)
+
+// DadosIniciaisEncaminhamento é o que o psicólogo de destino vê com o
+// consentimento do aluno: o formulário inicial respondido com a origem e a
+// primeira aplicação respondida de cada instrumento que ela atribuiu.
+type DadosIniciaisEncaminhamento struct {
+	Encaminhamento *Encaminhamento         `json:"encaminhamento"`
+	Formularios    []*RespostaFormulario   `json:"formularios"`
+	Instrumentos   []*AplicacaoInstrumento `json:"instrumentos"`
+}
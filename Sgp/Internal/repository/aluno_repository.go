@@ -2,12 +2,20 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sgp/Internal/model"
+	"time"
 	"cloud.google.com/go/firestore"
 	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// ErrAlunoAnonimizado recusa escritas num aluno já anonimizado, que não pode
+// voltar a ter dados pessoais.
+var ErrAlunoAnonimizado = errors.New("o aluno foi anonimizado e não pode mais ser alterado")
+
 
 type AlunoRepositoryImpl struct {
 	Client *firestore.Client
@@ -18,10 +26,7 @@ func NewAlunoRepository(client *firestore.Client) *AlunoRepositoryImpl {
 }
 
 func (r *AlunoRepositoryImpl) CriarAluno(ctx context.Context, aluno model.Aluno) (*model.Aluno, error) {
-	docRef, _, err := r.Client.Collection("Alunos").Add(ctx, map[string]interface{}{
-		"nome":  aluno.Nome,
-		"email": aluno.Email,
-	})
+	docRef, _, err := r.Client.Collection("Alunos").Add(ctx, dadosAluno(aluno))
 
 	if err != nil {
 		return nil, err
@@ -31,6 +36,21 @@ func (r *AlunoRepositoryImpl) CriarAluno(ctx context.Context, aluno model.Aluno)
 	return &aluno, nil
 }
 
+func (r *AlunoRepositoryImpl) CriarAlunosEmLote(ctx context.Context, alunos []model.Aluno) ([]*model.Aluno, error) {
+	batch := r.Client.Batch()
+	criados := make([]*model.Aluno, 0, len(alunos))
+	for _, aluno := range alunos {
+		docRef := r.Client.Collection("Alunos").NewDoc()
+		batch.Create(docRef, dadosAluno(aluno))
+		aluno.ID = docRef.ID
+		criados = append(criados, &aluno)
+	}
+	if _, err := batch.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("erro ao gravar lote de %d alunos: %w", len(alunos), err)
+	}
+	return criados, nil
+}
+
 func (r *AlunoRepositoryImpl) BuscarAlunoPorID(ctx context.Context, id string) (*model.Aluno, error) {
 	doc, err := r.Client.Collection("Alunos").Doc(id).Get(ctx)
 
@@ -74,11 +94,29 @@ func (r *AlunoRepositoryImpl) ListarAlunos(ctx context.Context) ([]*model.Aluno,
 	return alunos, nil
 }
 
+// AtualizarAluno substitui o documento, na mesma transação em que confere
+// que o aluno não foi anonimizado.
 func (r *AlunoRepositoryImpl) AtualizarAluno(ctx context.Context, id string, aluno model.Aluno) error {
-	_, err := r.Client.Collection("Alunos").Doc(id).Set(ctx, map[string]interface{}{
-		"nome":  aluno.Nome,
-		"email": aluno.Email,
+	ref := r.Client.Collection("Alunos").Doc(id)
+	err := r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if doc.Exists() {
+			var atual model.Aluno
+			if err := doc.DataTo(&atual); err != nil {
+				return err
+			}
+			if atual.Anonimizado {
+				return ErrAlunoAnonimizado
+			}
+		}
+		return tx.Set(ref, dadosAluno(aluno))
 	})
+	if errors.Is(err, ErrAlunoAnonimizado) {
+		return err
+	}
 	if err != nil {
 		return fmt.Errorf("erro ao atualizar o aluno com ID '%s': %v", id, err)
 	}
@@ -109,3 +147,46 @@ func (r *AlunoRepositoryImpl) GetAlunoIDPorNome(ctx context.Context, nome string
 
 	return doc.Ref.ID, nil
 }
+
+
+// AnonimizarAluno remove os dados pessoais do documento mas mantém o ID,
+// preservando as consultas e estatísticas que apontam para ele.
+func (r *AlunoRepositoryImpl) AnonimizarAluno(ctx context.Context, id string) error {
+	_, err := r.Client.Collection("Alunos").Doc(id).Set(ctx, map[string]interface{}{
+		"nome":          "Titular anonimizado",
+		"email":         "",
+		"anonimizado":   true,
+		"anonimizadoEm": time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao anonimizar o aluno com ID '%s': %v", id, err)
+	}
+	return nil
+}
+
+// dadosAluno monta o documento gravado no Firestore. Os campos de anonimização
+// ficam de fora: só AnonimizarAluno os escreve.
+func dadosAluno(aluno model.Aluno) map[string]interface{} {
+	dados := map[string]interface{}{
+		"nome":  aluno.Nome,
+		"email": aluno.Email,
+	}
+	opcionais := map[string]string{
+		"nomeSocial":     aluno.NomeSocial,
+		"pronomes":       aluno.Pronomes,
+		"curso":          aluno.Curso,
+		"fusoHorario":    aluno.FusoHorario,
+		"matricula":      aluno.Matricula,
+		"telefone":       aluno.Telefone,
+		"dataNascimento": aluno.DataNascimento,
+	}
+	for campo, valor := range opcionais {
+		if valor != "" {
+			dados[campo] = valor
+		}
+	}
+	if len(aluno.ContatosEmergencia) > 0 {
+		dados["contatosEmergencia"] = aluno.ContatosEmergencia
+	}
+	return dados
+}
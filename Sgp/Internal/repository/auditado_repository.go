@@ -0,0 +1,441 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+	"sgp/Internal/model"
+	"sgp/Internal/reqctx"
+	"sort"
+	"time"
+)
+
+const (
+	AcaoCriar         = "criar"
+	AcaoAtualizar     = "atualizar"
+	AcaoDeletar       = "deletar"
+	AcaoAlterarStatus = "alterar_status"
+	AcaoAnonimizar    = "anonimizar"
+	AcaoExportar      = "exportar"
+)
+
+// Os repositórios "Auditado" envolvem as implementações reais e gravam um
+// RegistroAuditoria a cada escrita. Leituras passam direto pela interface embutida.
+
+type AlunoRepositoryAuditado struct {
+	AlunoRepository
+	Auditoria AuditoriaRepository
+}
+
+func NewAlunoRepositoryAuditado(repo AlunoRepository, auditoria AuditoriaRepository) *AlunoRepositoryAuditado {
+	return &AlunoRepositoryAuditado{AlunoRepository: repo, Auditoria: auditoria}
+}
+
+func (r *AlunoRepositoryAuditado) CriarAluno(ctx context.Context, aluno model.Aluno) (*model.Aluno, error) {
+	criado, err := r.AlunoRepository.CriarAluno(ctx, aluno)
+	if err != nil {
+		return nil, err
+	}
+	RegistrarAuditoria(ctx, r.Auditoria, AcaoCriar, "aluno", criado.ID, nil, criado)
+	return criado, nil
+}
+
+func (r *AlunoRepositoryAuditado) CriarAlunosEmLote(ctx context.Context, alunos []model.Aluno) ([]*model.Aluno, error) {
+	criados, err := r.AlunoRepository.CriarAlunosEmLote(ctx, alunos)
+	if err != nil {
+		return nil, err
+	}
+	for _, criado := range criados {
+		RegistrarAuditoria(ctx, r.Auditoria, AcaoCriar, "aluno", criado.ID, nil, criado)
+	}
+	return criados, nil
+}
+
+func (r *AlunoRepositoryAuditado) AtualizarAluno(ctx context.Context, id string, aluno model.Aluno) error {
+	antes, _ := r.AlunoRepository.BuscarAlunoPorID(ctx, id)
+	if err := r.AlunoRepository.AtualizarAluno(ctx, id, aluno); err != nil {
+		return err
+	}
+	aluno.ID = id
+	RegistrarAuditoria(ctx, r.Auditoria, AcaoAtualizar, "aluno", id, antes, aluno)
+	return nil
+}
+
+func (r *AlunoRepositoryAuditado) DeletarAluno(ctx context.Context, id string) error {
+	antes, _ := r.AlunoRepository.BuscarAlunoPorID(ctx, id)
+	if err := r.AlunoRepository.DeletarAluno(ctx, id); err != nil {
+		return err
+	}
+	RegistrarAuditoria(ctx, r.Auditoria, AcaoDeletar, "aluno", id, antes, nil)
+	return nil
+}
+
+// AnonimizarAluno não guarda o "antes" para não copiar para a auditoria os
+// dados pessoais que estão sendo apagados, e esvazia os retratos que os
+// registros anteriores do aluno guardaram. Quem fez o quê e quando continua na trilha.
+func (r *AlunoRepositoryAuditado) AnonimizarAluno(ctx context.Context, id string) error {
+	if err := r.AlunoRepository.AnonimizarAluno(ctx, id); err != nil {
+		return err
+	}
+	if err := r.Auditoria.OcultarDadosRegistros(ctx, "aluno", id); err != nil {
+		return err
+	}
+	RegistrarAuditoria(ctx, r.Auditoria, AcaoAnonimizar, "aluno", id, nil,
+		map[string]interface{}{"anonimizado": true})
+	return nil
+}
+
+type PsicologoRepositoryAuditado struct {
+	PsicologoRepository
+	Auditoria AuditoriaRepository
+}
+
+func NewPsicologoRepositoryAuditado(repo PsicologoRepository, auditoria AuditoriaRepository) *PsicologoRepositoryAuditado {
+	return &PsicologoRepositoryAuditado{PsicologoRepository: repo, Auditoria: auditoria}
+}
+
+func (r *PsicologoRepositoryAuditado) CriarPsicologo(ctx context.Context, psicologo model.Psicologo) (*model.Psicologo, error) {
+	criado, err := r.PsicologoRepository.CriarPsicologo(ctx, psicologo)
+	if err != nil {
+		return nil, err
+	}
+	RegistrarAuditoria(ctx, r.Auditoria, AcaoCriar, "psicologo", criado.ID, nil, criado)
+	return criado, nil
+}
+
+func (r *PsicologoRepositoryAuditado) CriarPsicologosEmLote(ctx context.Context, psicologos []model.Psicologo) ([]*model.Psicologo, error) {
+	criados, err := r.PsicologoRepository.CriarPsicologosEmLote(ctx, psicologos)
+	if err != nil {
+		return nil, err
+	}
+	for _, criado := range criados {
+		RegistrarAuditoria(ctx, r.Auditoria, AcaoCriar, "psicologo", criado.ID, nil, criado)
+	}
+	return criados, nil
+}
+
+func (r *PsicologoRepositoryAuditado) AtualizarPsicologo(ctx context.Context, id string, psicologo model.Psicologo) error {
+	antes, _ := r.PsicologoRepository.BuscarPsicologoPorID(ctx, id)
+	if err := r.PsicologoRepository.AtualizarPsicologo(ctx, id, psicologo); err != nil {
+		return err
+	}
+	psicologo.ID = id
+	RegistrarAuditoria(ctx, r.Auditoria, AcaoAtualizar, "psicologo", id, antes, psicologo)
+	return nil
+}
+
+func (r *PsicologoRepositoryAuditado) DeletarPsicologo(ctx context.Context, id string) error {
+	antes, _ := r.PsicologoRepository.BuscarPsicologoPorID(ctx, id)
+	if err := r.PsicologoRepository.DeletarPsicologo(ctx, id); err != nil {
+		return err
+	}
+	RegistrarAuditoria(ctx, r.Auditoria, AcaoDeletar, "psicologo", id, antes, nil)
+	return nil
+}
+
+type HorarioDisponivelRepositoryAuditado struct {
+	HorarioDisponivelRepository
+	Auditoria AuditoriaRepository
+}
+
+func NewHorarioDisponivelRepositoryAuditado(repo HorarioDisponivelRepository, auditoria AuditoriaRepository) *HorarioDisponivelRepositoryAuditado {
+	return &HorarioDisponivelRepositoryAuditado{HorarioDisponivelRepository: repo, Auditoria: auditoria}
+}
+
+func (r *HorarioDisponivelRepositoryAuditado) CriarHorario(ctx context.Context, horario model.HorarioDisponivel) (*model.HorarioDisponivel, error) {
+	criado, err := r.HorarioDisponivelRepository.CriarHorario(ctx, horario)
+	if err != nil {
+		return nil, err
+	}
+	RegistrarAuditoria(ctx, r.Auditoria, AcaoCriar, "horario", criado.ID, nil, criado)
+	return criado, nil
+}
+
+func (r *HorarioDisponivelRepositoryAuditado) AtualizarStatusHorario(ctx context.Context, id string, novoStatus string) error {
+	antes, _ := r.HorarioDisponivelRepository.BuscarHorarioPorID(ctx, id)
+	if err := r.HorarioDisponivelRepository.AtualizarStatusHorario(ctx, id, novoStatus); err != nil {
+		return err
+	}
+	registrarStatus(ctx, r.Auditoria, "horario", id, statusHorario(antes), novoStatus)
+	return nil
+}
+
+func (r *HorarioDisponivelRepositoryAuditado) ReservarHorario(ctx context.Context, id string, alunoID string, ate time.Time) error {
+	return auditarStatusHorario(ctx, r.HorarioDisponivelRepository, r.Auditoria, id, func() error {
+		return r.HorarioDisponivelRepository.ReservarHorario(ctx, id, alunoID, ate)
+	})
+}
+
+func (r *HorarioDisponivelRepositoryAuditado) LiberarReserva(ctx context.Context, id string, alunoID string) error {
+	return auditarStatusHorario(ctx, r.HorarioDisponivelRepository, r.Auditoria, id, func() error {
+		return r.HorarioDisponivelRepository.LiberarReserva(ctx, id, alunoID)
+	})
+}
+
+func (r *HorarioDisponivelRepositoryAuditado) ExpirarReserva(ctx context.Context, id string, agora time.Time) error {
+	return auditarStatusHorario(ctx, r.HorarioDisponivelRepository, r.Auditoria, id, func() error {
+		return r.HorarioDisponivelRepository.ExpirarReserva(ctx, id, agora)
+	})
+}
+
+func (r *HorarioDisponivelRepositoryAuditado) ReconciliarStatusHorario(ctx context.Context, id string, esperado, novoStatus string) error {
+	if err := r.HorarioDisponivelRepository.ReconciliarStatusHorario(ctx, id, esperado, novoStatus); err != nil {
+		return err
+	}
+	registrarStatus(ctx, r.Auditoria, "horario", id, esperado, novoStatus)
+	return nil
+}
+
+func (r *HorarioDisponivelRepositoryAuditado) RecontarVagasGrupo(ctx context.Context, id string, inscritosVistos, esperaVistos int) error {
+	antes, _ := r.HorarioDisponivelRepository.BuscarHorarioPorID(ctx, id)
+	if err := r.HorarioDisponivelRepository.RecontarVagasGrupo(ctx, id, inscritosVistos, esperaVistos); err != nil {
+		return err
+	}
+	depois, _ := r.HorarioDisponivelRepository.BuscarHorarioPorID(ctx, id)
+	RegistrarAuditoria(ctx, r.Auditoria, AcaoAtualizar, "horario", id, antes, depois)
+	return nil
+}
+
+func (r *HorarioDisponivelRepositoryAuditado) DeletarHorario(ctx context.Context, id string) error {
+	antes, _ := r.HorarioDisponivelRepository.BuscarHorarioPorID(ctx, id)
+	if err := r.HorarioDisponivelRepository.DeletarHorario(ctx, id); err != nil {
+		return err
+	}
+	RegistrarAuditoria(ctx, r.Auditoria, AcaoDeletar, "horario", id, antes, nil)
+	return nil
+}
+
+// ConsultaRepositoryAuditado usa Horarios só para ler o status do horário
+// antes e depois das operações que o alteram na mesma transação da consulta.
+type ConsultaRepositoryAuditado struct {
+	ConsultaRepository
+	Horarios  HorarioDisponivelRepository
+	Auditoria AuditoriaRepository
+}
+
+func NewConsultaRepositoryAuditado(repo ConsultaRepository, horarios HorarioDisponivelRepository, auditoria AuditoriaRepository) *ConsultaRepositoryAuditado {
+	return &ConsultaRepositoryAuditado{ConsultaRepository: repo, Horarios: horarios, Auditoria: auditoria}
+}
+
+func (r *ConsultaRepositoryAuditado) AgendarConsulta(ctx context.Context, consulta model.Consulta) (*model.Consulta, error) {
+	var criada *model.Consulta
+	err := auditarStatusHorario(ctx, r.Horarios, r.Auditoria, consulta.HorarioID, func() error {
+		var err error
+		criada, err = r.ConsultaRepository.AgendarConsulta(ctx, consulta)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	RegistrarAuditoria(ctx, r.Auditoria, AcaoCriar, "consulta", criada.ID, nil, criada)
+	return criada, nil
+}
+
+func (r *ConsultaRepositoryAuditado) AtualizaStatusConsulta(ctx context.Context, id string, novoStatus string) error {
+	antes, _ := r.ConsultaRepository.BuscarConsultaPorID(ctx, id)
+	err := auditarStatusHorario(ctx, r.Horarios, r.Auditoria, horarioDa(antes), func() error {
+		return r.ConsultaRepository.AtualizaStatusConsulta(ctx, id, novoStatus)
+	})
+	if err != nil {
+		return err
+	}
+	registrarStatus(ctx, r.Auditoria, "consulta", id, statusConsulta(antes), novoStatus)
+	return nil
+}
+
+func (r *ConsultaRepositoryAuditado) TrocarStatusConsulta(ctx context.Context, id string, esperado, novoStatus string) error {
+	antes, _ := r.ConsultaRepository.BuscarConsultaPorID(ctx, id)
+	err := auditarStatusHorario(ctx, r.Horarios, r.Auditoria, horarioDa(antes), func() error {
+		return r.ConsultaRepository.TrocarStatusConsulta(ctx, id, esperado, novoStatus)
+	})
+	if err != nil {
+		return err
+	}
+	registrarStatus(ctx, r.Auditoria, "consulta", id, esperado, novoStatus)
+	return nil
+}
+
+func (r *ConsultaRepositoryAuditado) RegistrarPresenca(ctx context.Context, id string, esperado, novoStatus string, em time.Time) error {
+	if err := r.ConsultaRepository.RegistrarPresenca(ctx, id, esperado, novoStatus, em); err != nil {
+		return err
+	}
+	registrarStatus(ctx, r.Auditoria, "consulta", id, esperado, novoStatus)
+	return nil
+}
+
+func (r *ConsultaRepositoryAuditado) DeletarConsulta(ctx context.Context, id string) error {
+	antes, _ := r.ConsultaRepository.BuscarConsultaPorID(ctx, id)
+	err := auditarStatusHorario(ctx, r.Horarios, r.Auditoria, horarioDa(antes), func() error {
+		return r.ConsultaRepository.DeletarConsulta(ctx, id)
+	})
+	if err != nil {
+		return err
+	}
+	RegistrarAuditoria(ctx, r.Auditoria, AcaoDeletar, "consulta", id, antes, nil)
+	return nil
+}
+
+// DefinirCodigoCheckin registra só que o código mudou: quem lê a auditoria
+// não deve conseguir fazer o check-in no lugar do aluno.
+func (r *ConsultaRepositoryAuditado) DefinirCodigoCheckin(ctx context.Context, id string, codigo string) error {
+	antes, _ := r.ConsultaRepository.BuscarConsultaPorID(ctx, id)
+	if err := r.ConsultaRepository.DefinirCodigoCheckin(ctx, id, codigo); err != nil {
+		return err
+	}
+	anterior := ""
+	if antes != nil {
+		anterior = antes.CodigoCheckin
+	}
+	RegistrarAuditoria(ctx, r.Auditoria, AcaoAtualizar, "consulta", id,
+		map[string]interface{}{"codigoCheckin": mascararCodigo(anterior)},
+		map[string]interface{}{"codigoCheckin": mascararCodigo(codigo)})
+	return nil
+}
+
+func (r *ConsultaRepositoryAuditado) DefinirLinkReuniao(ctx context.Context, id string, link string) error {
+	return r.auditarAtualizacao(ctx, id, func() error {
+		return r.ConsultaRepository.DefinirLinkReuniao(ctx, id, link)
+	})
+}
+
+func (r *ConsultaRepositoryAuditado) DefinirEpisodio(ctx context.Context, id string, episodioID string) error {
+	return r.auditarAtualizacao(ctx, id, func() error {
+		return r.ConsultaRepository.DefinirEpisodio(ctx, id, episodioID)
+	})
+}
+
+// AuditarPromocao envolve o AoPromover do repositório real: a promoção da
+// lista de espera acontece dentro da transação de outra escrita, e só o
+// repositório a vê. Em main, o resultado é ligado ao AoPromover.
+func (r *ConsultaRepositoryAuditado) AuditarPromocao(aviso func(ctx context.Context, promovida model.Consulta)) func(ctx context.Context, promovida model.Consulta) {
+	return func(ctx context.Context, promovida model.Consulta) {
+		registrarStatus(ctx, r.Auditoria, "consulta", promovida.ID, "lista de espera", promovida.Status)
+		if aviso != nil {
+			aviso(ctx, promovida)
+		}
+	}
+}
+
+func mascararCodigo(codigo string) string {
+	if codigo == "" {
+		return ""
+	}
+	return "******"
+}
+
+// auditarAtualizacao registra a consulta como estava antes e depois de op.
+func (r *ConsultaRepositoryAuditado) auditarAtualizacao(ctx context.Context, id string, op func() error) error {
+	antes, _ := r.ConsultaRepository.BuscarConsultaPorID(ctx, id)
+	if err := op(); err != nil {
+		return err
+	}
+	depois, _ := r.ConsultaRepository.BuscarConsultaPorID(ctx, id)
+	RegistrarAuditoria(ctx, r.Auditoria, AcaoAtualizar, "consulta", id, antes, depois)
+	return nil
+}
+
+// auditarStatusHorario lê o status do horário antes e depois de op e registra
+// a mudança que de fato aconteceu, se houve alguma.
+func auditarStatusHorario(ctx context.Context, horarios HorarioDisponivelRepository, auditoria AuditoriaRepository, id string, op func() error) error {
+	if horarios == nil || id == "" {
+		return op()
+	}
+	antes, _ := horarios.BuscarHorarioPorID(ctx, id)
+	if err := op(); err != nil {
+		return err
+	}
+	depois, _ := horarios.BuscarHorarioPorID(ctx, id)
+	if de, para := statusHorario(antes), statusHorario(depois); de != para {
+		registrarStatus(ctx, auditoria, "horario", id, de, para)
+	}
+	return nil
+}
+
+func horarioDa(c *model.Consulta) string {
+	if c == nil {
+		return ""
+	}
+	return c.HorarioID
+}
+
+func statusHorario(h *model.HorarioDisponivel) string {
+	if h == nil {
+		return ""
+	}
+	return h.Status
+}
+
+func statusConsulta(c *model.Consulta) string {
+	if c == nil {
+		return ""
+	}
+	return c.Status
+}
+
+func registrarStatus(ctx context.Context, auditoria AuditoriaRepository, entidade, id, de, para string) {
+	RegistrarAuditoria(ctx, auditoria, AcaoAlterarStatus, entidade, id,
+		map[string]interface{}{"status": de},
+		map[string]interface{}{"status": para})
+}
+
+// RegistrarAuditoria nunca falha a operação principal, que já foi persistida;
+// erros de gravação da auditoria são apenas logados.
+func RegistrarAuditoria(ctx context.Context, auditoria AuditoriaRepository, acao, entidade, id string, antes, depois interface{}) {
+	if auditoria == nil {
+		return
+	}
+
+	ator := reqctx.AtorDe(ctx)
+	registro := model.RegistroAuditoria{
+		AtorID:     ator.ID,
+		Acao:       acao,
+		Entidade:   entidade,
+		EntidadeID: id,
+		Antes:      paraMapa(antes),
+		Depois:     paraMapa(depois),
+		Timestamp:  time.Now().UTC(),
+		RequestID:  reqctx.RequestIDDe(ctx),
+	}
+	registro.Campos = camposAlterados(registro.Antes, registro.Depois)
+
+	if err := auditoria.Registrar(ctx, registro); err != nil {
+		log.Printf("ERRO ao registrar auditoria (%s %s/%s): %v", acao, entidade, id, err)
+	}
+}
+
+func paraMapa(v interface{}) map[string]interface{} {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil
+	}
+	if m, ok := v.(map[string]interface{}); ok {
+		return m
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil
+	}
+	delete(m, "id")
+	return m
+}
+
+func camposAlterados(antes, depois map[string]interface{}) []string {
+	var campos []string
+	for k, v := range depois {
+		if !reflect.DeepEqual(antes[k], v) {
+			campos = append(campos, k)
+		}
+	}
+	for k := range antes {
+		if _, ok := depois[k]; !ok {
+			campos = append(campos, k)
+		}
+	}
+	sort.Strings(campos)
+	return campos
+}
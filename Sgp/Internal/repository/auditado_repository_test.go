@@ -0,0 +1,253 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/repository/memoria"
+	"sgp/Internal/reqctx"
+	"testing"
+	"time"
+)
+
+func transicoesHorario(t *testing.T, auditoria repository.AuditoriaRepository, id string) [][2]interface{} {
+	t.Helper()
+	registros, _, err := auditoria.ListarAuditoria(context.Background(), model.FiltroAuditoria{Entidade: "horario", EntidadeID: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var transicoes [][2]interface{}
+	for i := len(registros) - 1; i >= 0; i-- {
+		r := registros[i]
+		if r.Acao != repository.AcaoAlterarStatus {
+			continue
+		}
+		transicoes = append(transicoes, [2]interface{}{r.Antes["status"], r.Depois["status"]})
+	}
+	return transicoes
+}
+
+func TestAuditoriaRegistraStatusRealDoHorario(t *testing.T) {
+	ctx := context.Background()
+	banco := memoria.NovoBanco()
+	auditoria := memoria.NewAuditoriaRepository(banco)
+	horarios := repository.NewHorarioDisponivelRepositoryAuditado(memoria.NewHorarioDisponivelRepository(banco), auditoria)
+	consultas := repository.NewConsultaRepositoryAuditado(memoria.NewConsultaRepository(banco), memoria.NewHorarioDisponivelRepository(banco), auditoria)
+
+	inicio := time.Now().Add(48 * time.Hour)
+	h, err := horarios.CriarHorario(ctx, model.HorarioDisponivel{PsicologoID: "p1", Inicio: inicio, Fim: inicio.Add(time.Hour), Status: "disponivel"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reservado por um encaminhamento e agendado pelo próprio aluno.
+	if err := horarios.ReservarHorario(ctx, h.ID, "a1", inicio); err != nil {
+		t.Fatal(err)
+	}
+	c, err := consultas.AgendarConsulta(ctx, model.Consulta{AlunoID: "a1", HorarioID: h.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := consultas.AtualizaStatusConsulta(ctx, c.ID, "cancelada pelo aluno"); err != nil {
+		t.Fatal(err)
+	}
+	// Liberar uma reserva que já não existe não gera registro.
+	if err := horarios.LiberarReserva(ctx, h.ID, "a1"); err != nil {
+		t.Fatal(err)
+	}
+
+	esperado := [][2]interface{}{
+		{"disponivel", "reservado"},
+		{"reservado", "agendado"},
+		{"agendado", "disponivel"},
+	}
+	obtido := transicoesHorario(t, auditoria, h.ID)
+	if len(obtido) != len(esperado) {
+		t.Fatalf("transições = %v, esperado %v", obtido, esperado)
+	}
+	for i := range esperado {
+		if obtido[i] != esperado[i] {
+			t.Errorf("transição %d = %v, esperado %v", i, obtido[i], esperado[i])
+		}
+	}
+}
+
+func TestAnonimizarAlunoLimpaRetratosDaAuditoria(t *testing.T) {
+	ctx := context.Background()
+	banco := memoria.NovoBanco()
+	auditoria := memoria.NewAuditoriaRepository(banco)
+	alunos := repository.NewAlunoRepositoryAuditado(memoria.NewAlunoRepository(banco), auditoria)
+
+	a, err := alunos.CriarAluno(ctx, model.Aluno{Nome: "Maria Souza", Email: "maria@test.com", Telefone: "11999990000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	atualizado := *a
+	atualizado.ContatosEmergencia = []model.ContatoEmergencia{{Nome: "João Souza"}}
+	if err := alunos.AtualizarAluno(ctx, a.ID, atualizado); err != nil {
+		t.Fatal(err)
+	}
+	outro, err := alunos.CriarAluno(ctx, model.Aluno{Nome: "Ana Lima", Email: "ana@test.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := alunos.AnonimizarAluno(ctx, a.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	registros, _, err := auditoria.ListarAuditoria(ctx, model.FiltroAuditoria{Entidade: "aluno", EntidadeID: a.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registros) != 3 {
+		t.Fatalf("a trilha deveria manter os 3 registros, obteve %d", len(registros))
+	}
+	for _, r := range registros {
+		if r.Acao == repository.AcaoAnonimizar {
+			continue
+		}
+		if r.Antes != nil || r.Depois != nil {
+			t.Errorf("registro %s (%s) ainda guarda dados: antes=%v depois=%v", r.ID, r.Acao, r.Antes, r.Depois)
+		}
+	}
+
+	registros, _, err = auditoria.ListarAuditoria(ctx, model.FiltroAuditoria{Entidade: "aluno", EntidadeID: outro.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registros) != 1 || registros[0].Depois["nome"] != "Ana Lima" {
+		t.Errorf("a auditoria de outro aluno não deveria mudar: %+v", registros)
+	}
+}
+
+func TestAlunoAnonimizadoNaoVoltaASerAlterado(t *testing.T) {
+	ctx := context.Background()
+	banco := memoria.NovoBanco()
+	auditoria := memoria.NewAuditoriaRepository(banco)
+	alunos := repository.NewAlunoRepositoryAuditado(memoria.NewAlunoRepository(banco), auditoria)
+
+	// O cliente não consegue se marcar como anonimizado.
+	a, err := alunos.CriarAluno(ctx, model.Aluno{Nome: "Maria Souza", Email: "maria@test.com", Anonimizado: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if salvo, _ := alunos.BuscarAlunoPorID(ctx, a.ID); salvo.Anonimizado {
+		t.Fatal("o cadastro não deveria aceitar o campo anonimizado")
+	}
+
+	if err := alunos.AnonimizarAluno(ctx, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	err = alunos.AtualizarAluno(ctx, a.ID, model.Aluno{Nome: "Maria Souza", Email: "maria@test.com"})
+	if !errors.Is(err, repository.ErrAlunoAnonimizado) {
+		t.Fatalf("atualizar um aluno anonimizado deveria falhar com ErrAlunoAnonimizado, obteve %v", err)
+	}
+	salvo, err := alunos.BuscarAlunoPorID(ctx, a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !salvo.Anonimizado || salvo.AnonimizadoEm.IsZero() || salvo.Email != "" || salvo.Nome != "Titular anonimizado" {
+		t.Errorf("o registro deveria continuar anonimizado: %+v", salvo)
+	}
+	registros, _, _ := auditoria.ListarAuditoria(ctx, model.FiltroAuditoria{Entidade: "aluno", EntidadeID: a.ID})
+	for _, r := range registros {
+		if r.Acao == repository.AcaoAtualizar {
+			t.Errorf("a atualização recusada não deveria entrar na auditoria: %+v", r)
+		}
+	}
+}
+
+// ultimoRegistroConsulta devolve o registro mais recente da consulta.
+func ultimoRegistroConsulta(t *testing.T, auditoria repository.AuditoriaRepository, id string) *model.RegistroAuditoria {
+	t.Helper()
+	registros, _, err := auditoria.ListarAuditoria(context.Background(), model.FiltroAuditoria{Entidade: "consulta", EntidadeID: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registros) == 0 {
+		t.Fatal("nenhum registro de auditoria para a consulta")
+	}
+	return registros[0]
+}
+
+func TestAuditoriaDosCamposDaConsulta(t *testing.T) {
+	casos := []struct {
+		nome    string
+		campo   string
+		valor   string
+		definir func(ctx context.Context, r *repository.ConsultaRepositoryAuditado, id string) error
+	}{
+		{"codigo de check-in", "codigoCheckin", "******", func(ctx context.Context, r *repository.ConsultaRepositoryAuditado, id string) error {
+			return r.DefinirCodigoCheckin(ctx, id, "123456")
+		}},
+		{"link de reuniao", "linkReuniao", "https://meet.example/sala", func(ctx context.Context, r *repository.ConsultaRepositoryAuditado, id string) error {
+			return r.DefinirLinkReuniao(ctx, id, "https://meet.example/sala")
+		}},
+		{"episodio", "episodioId", "ep1", func(ctx context.Context, r *repository.ConsultaRepositoryAuditado, id string) error {
+			return r.DefinirEpisodio(ctx, id, "ep1")
+		}},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			banco := memoria.NovoBanco()
+			auditoria := memoria.NewAuditoriaRepository(banco)
+			horarios := memoria.NewHorarioDisponivelRepository(banco)
+			consultas := repository.NewConsultaRepositoryAuditado(memoria.NewConsultaRepository(banco), horarios, auditoria)
+			h := novoHorario(t, horarios, model.HorarioDisponivel{Status: "disponivel"})
+			c, err := consultas.AgendarConsulta(context.Background(), model.Consulta{AlunoID: "a1", HorarioID: h.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := reqctx.ComRequestID(reqctx.ComAtor(context.Background(), reqctx.Ator{ID: "p1", Papel: reqctx.PapelPsicologo}), "req-1")
+			if err := tc.definir(ctx, consultas, c.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			r := ultimoRegistroConsulta(t, auditoria, c.ID)
+			if r.Acao != repository.AcaoAtualizar || r.AtorID != "p1" || r.RequestID != "req-1" {
+				t.Errorf("registro sem ação, ator ou requisição: %+v", r)
+			}
+			if len(r.Campos) != 1 || r.Campos[0] != tc.campo || r.Depois[tc.campo] != tc.valor || r.Antes[tc.campo] == tc.valor {
+				t.Errorf("antes/depois de %s incorretos: campos=%v antes=%v depois=%v", tc.campo, r.Campos, r.Antes[tc.campo], r.Depois[tc.campo])
+			}
+		})
+	}
+}
+
+func TestAuditoriaDaPromocaoDaListaDeEspera(t *testing.T) {
+	banco := memoria.NovoBanco()
+	auditoria := memoria.NewAuditoriaRepository(banco)
+	horarios := memoria.NewHorarioDisponivelRepository(banco)
+	real := memoria.NewConsultaRepository(banco)
+	consultas := repository.NewConsultaRepositoryAuditado(real, horarios, auditoria)
+	var avisadas []string
+	real.AoPromover = consultas.AuditarPromocao(func(ctx context.Context, promovida model.Consulta) {
+		avisadas = append(avisadas, promovida.ID)
+	})
+
+	ctx := reqctx.ComRequestID(reqctx.ComAtor(context.Background(), reqctx.Ator{ID: "a1", Papel: reqctx.PapelAluno}), "req-2")
+	g := novoHorario(t, horarios, model.HorarioDisponivel{Tipo: model.HorarioGrupo, Capacidade: 1, Status: "disponivel"})
+	primeira, err := consultas.AgendarConsulta(ctx, model.Consulta{AlunoID: "a1", HorarioID: g.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	espera, err := consultas.AgendarConsulta(ctx, model.Consulta{AlunoID: "a2", HorarioID: g.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := consultas.AtualizaStatusConsulta(ctx, primeira.ID, "cancelada pelo aluno"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(avisadas) != 1 || avisadas[0] != espera.ID {
+		t.Fatalf("o aviso de promoção deveria seguir para a2: %v", avisadas)
+	}
+	r := ultimoRegistroConsulta(t, auditoria, espera.ID)
+	if r.Acao != repository.AcaoAlterarStatus || r.AtorID != "a1" || r.RequestID != "req-2" ||
+		r.Antes["status"] != "lista de espera" || r.Depois["status"] != "aguardando aprovacao" {
+		t.Errorf("promoção não auditada: %+v", r)
+	}
+}
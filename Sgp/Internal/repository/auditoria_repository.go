@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sgp/Internal/model"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+const LimiteAuditoriaPadrao = 50
+
+// limiteLoteFirestore é o máximo de escritas num único lote.
+const limiteLoteFirestore = 500
+
+type AuditoriaRepositoryImpl struct {
+	Client *firestore.Client
+}
+
+func NewAuditoriaRepository(client *firestore.Client) *AuditoriaRepositoryImpl {
+	return &AuditoriaRepositoryImpl{Client: client}
+}
+
+// Registrar usa Create num documento novo para nunca sobrescrever uma entrada existente.
+func (r *AuditoriaRepositoryImpl) Registrar(ctx context.Context, registro model.RegistroAuditoria) error {
+	ref := r.Client.Collection("Auditoria").NewDoc()
+	if _, err := ref.Create(ctx, registro); err != nil {
+		return fmt.Errorf("erro ao registrar auditoria: %w", err)
+	}
+	return nil
+}
+
+// ListarAuditoria pagina do mais recente para o mais antigo. O cursor é o ID
+// do último registro da página anterior.
+func (r *AuditoriaRepositoryImpl) ListarAuditoria(ctx context.Context, filtro model.FiltroAuditoria) ([]*model.RegistroAuditoria, string, error) {
+	limite := filtro.Limite
+	if limite <= 0 {
+		limite = LimiteAuditoriaPadrao
+	}
+
+	col := r.Client.Collection("Auditoria")
+	query := col.Query
+	if filtro.Entidade != "" {
+		query = query.Where("entidade", "==", filtro.Entidade)
+	}
+	if filtro.EntidadeID != "" {
+		query = query.Where("entidadeId", "==", filtro.EntidadeID)
+	}
+	if filtro.AtorID != "" {
+		query = query.Where("atorId", "==", filtro.AtorID)
+	}
+	query = query.OrderBy("timestamp", firestore.Desc).Limit(limite)
+
+	if filtro.Cursor != "" {
+		cursorDoc, err := col.Doc(filtro.Cursor).Get(ctx)
+		if err != nil {
+			return nil, "", fmt.Errorf("cursor de auditoria inválido: %w", err)
+		}
+		query = query.StartAfter(cursorDoc)
+	}
+
+	var registros []*model.RegistroAuditoria
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("erro ao listar auditoria: %w", err)
+		}
+
+		var registro model.RegistroAuditoria
+		if err := doc.DataTo(&registro); err != nil {
+			fmt.Printf("erro ao converter dados do doc '%s': %v", doc.Ref.ID, err)
+			continue
+		}
+		registro.ID = doc.Ref.ID
+		registros = append(registros, &registro)
+	}
+
+	proximoCursor := ""
+	if len(registros) == limite {
+		proximoCursor = registros[len(registros)-1].ID
+	}
+	return registros, proximoCursor, nil
+}
+
+// OcultarDadosRegistros é a única escrita que altera registros existentes:
+// serve à anonimização, que não pode deixar cópias dos dados na auditoria.
+func (r *AuditoriaRepositoryImpl) OcultarDadosRegistros(ctx context.Context, entidade, entidadeID string) error {
+	docs, err := r.Client.Collection("Auditoria").
+		Where("entidade", "==", entidade).
+		Where("entidadeId", "==", entidadeID).
+		Documents(ctx).GetAll()
+	if err != nil {
+		return fmt.Errorf("erro ao buscar auditoria de %s '%s': %w", entidade, entidadeID, err)
+	}
+
+	for inicio := 0; inicio < len(docs); inicio += limiteLoteFirestore {
+		fim := min(inicio+limiteLoteFirestore, len(docs))
+		batch := r.Client.Batch()
+		for _, doc := range docs[inicio:fim] {
+			batch.Update(doc.Ref, []firestore.Update{
+				{Path: "antes", Value: firestore.Delete},
+				{Path: "depois", Value: firestore.Delete},
+			})
+		}
+		if _, err := batch.Commit(ctx); err != nil {
+			return fmt.Errorf("erro ao ocultar auditoria de %s '%s': %w", entidade, entidadeID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sgp/Internal/model"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+type CalendarioRepositoryImpl struct {
+	Client *firestore.Client
+}
+
+func NewCalendarioRepository(client *firestore.Client) CalendarioRepository {
+	return &CalendarioRepositoryImpl{Client: client}
+}
+
+func (r *CalendarioRepositoryImpl) CriarEvento(ctx context.Context, evento model.EventoCalendario) (*model.EventoCalendario, error) {
+	docRef, _, err := r.Client.Collection("Calendario").Add(ctx, evento)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar evento no calendário: %w", err)
+	}
+	evento.ID = docRef.ID
+	return &evento, nil
+}
+
+// ListarEventos filtra pelo fim no Firestore e confere o início em memória.
+func (r *CalendarioRepositoryImpl) ListarEventos(ctx context.Context, de, ate time.Time) ([]*model.EventoCalendario, error) {
+	var eventos []*model.EventoCalendario
+
+	iter := r.Client.Collection("Calendario").Where("fim", ">", de).Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao listar eventos do calendário: %w", err)
+		}
+
+		var evento model.EventoCalendario
+		if err := doc.DataTo(&evento); err != nil {
+			fmt.Printf("erro ao converter dados do doc '%s': %v", doc.Ref.ID, err)
+			continue
+		}
+		if !evento.Inicio.Before(ate) {
+			continue
+		}
+		evento.ID = doc.Ref.ID
+		eventos = append(eventos, &evento)
+	}
+	return eventos, nil
+}
+
+func (r *CalendarioRepositoryImpl) DeletarEvento(ctx context.Context, id string) error {
+	if _, err := r.Client.Collection("Calendario").Doc(id).Delete(ctx); err != nil {
+		return fmt.Errorf("erro ao deletar evento com ID '%s': %w", id, err)
+	}
+	return nil
+}
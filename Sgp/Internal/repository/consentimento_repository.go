@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sgp/Internal/model"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type ConsentimentoRepositoryImpl struct {
+	Client *firestore.Client
+}
+
+func NewConsentimentoRepository(client *firestore.Client) *ConsentimentoRepositoryImpl {
+	return &ConsentimentoRepositoryImpl{Client: client}
+}
+
+// PublicarDocumento cria a próxima versão do termo. O ID do documento é a
+// própria versão, então duas publicações simultâneas não geram a mesma versão.
+func (r *ConsentimentoRepositoryImpl) PublicarDocumento(ctx context.Context, documento model.DocumentoConsentimento) (*model.DocumentoConsentimento, error) {
+	col := r.Client.Collection("DocumentosConsentimento")
+
+	err := r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docs, err := tx.Documents(col.OrderBy("versao", firestore.Desc).Limit(1)).GetAll()
+		if err != nil {
+			return fmt.Errorf("erro ao buscar versão vigente do consentimento: %w", err)
+		}
+
+		documento.Versao = 1
+		if len(docs) > 0 {
+			var atual model.DocumentoConsentimento
+			if err := docs[0].DataTo(&atual); err != nil {
+				return err
+			}
+			documento.Versao = atual.Versao + 1
+		}
+		documento.PublicadoEm = time.Now().UTC()
+		documento.ID = fmt.Sprintf("v%d", documento.Versao)
+
+		return tx.Create(col.Doc(documento.ID), documento)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &documento, nil
+}
+
+func (r *ConsentimentoRepositoryImpl) BuscarDocumentoVigente(ctx context.Context) (*model.DocumentoConsentimento, error) {
+	iter := r.Client.Collection("DocumentosConsentimento").OrderBy("versao", firestore.Desc).Limit(1).Documents(ctx)
+	defer iter.Stop()
+
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar termo de consentimento vigente: %w", err)
+	}
+
+	var documento model.DocumentoConsentimento
+	if err := doc.DataTo(&documento); err != nil {
+		return nil, err
+	}
+	documento.ID = doc.Ref.ID
+	return &documento, nil
+}
+
+// RegistrarAceite usa um ID determinístico por aluno e versão, tornando o
+// aceite idempotente. O primeiro aceite é o que vale: repetir devolve o
+// registro original, com a data e o IP de quando o aluno aceitou.
+func (r *ConsentimentoRepositoryImpl) RegistrarAceite(ctx context.Context, aceite model.AceiteConsentimento) (*model.AceiteConsentimento, error) {
+	aceite.ID = idAceite(aceite.AlunoID, aceite.Versao)
+	_, err := r.Client.Collection("AceitesConsentimento").Doc(aceite.ID).Create(ctx, aceite)
+	if status.Code(err) == codes.AlreadyExists {
+		original, err := r.BuscarAceite(ctx, aceite.AlunoID, aceite.Versao)
+		if err != nil {
+			return nil, err
+		}
+		if original != nil {
+			return original, nil
+		}
+		return nil, fmt.Errorf("aceite '%s' existe mas não pôde ser lido", aceite.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao registrar aceite do aluno '%s': %w", aceite.AlunoID, err)
+	}
+	return &aceite, nil
+}
+
+func (r *ConsentimentoRepositoryImpl) BuscarAceite(ctx context.Context, alunoID string, versao int) (*model.AceiteConsentimento, error) {
+	doc, err := r.Client.Collection("AceitesConsentimento").Doc(idAceite(alunoID, versao)).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar aceite do aluno '%s': %w", alunoID, err)
+	}
+
+	var aceite model.AceiteConsentimento
+	if err := doc.DataTo(&aceite); err != nil {
+		return nil, err
+	}
+	aceite.ID = doc.Ref.ID
+	return &aceite, nil
+}
+
+func (r *ConsentimentoRepositoryImpl) ListarAceitesPorAluno(ctx context.Context, alunoID string) ([]*model.AceiteConsentimento, error) {
+	var aceites []*model.AceiteConsentimento
+
+	iter := r.Client.Collection("AceitesConsentimento").Where("alunoId", "==", alunoID).Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao listar aceites do aluno '%s': %w", alunoID, err)
+		}
+
+		var aceite model.AceiteConsentimento
+		if err := doc.DataTo(&aceite); err != nil {
+			fmt.Printf("erro ao converter dados do doc '%s': %v", doc.Ref.ID, err)
+			continue
+		}
+		aceite.ID = doc.Ref.ID
+		aceites = append(aceites, &aceite)
+	}
+	return aceites, nil
+}
+
+func idAceite(alunoID string, versao int) string {
+	return fmt.Sprintf("%s_v%d", alunoID, versao)
+}
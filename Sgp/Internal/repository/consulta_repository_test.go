@@ -0,0 +1,276 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"testing"
+	"time"
+)
+
+func TestConsultaGrupoCapacidadeEListaDeEspera(t *testing.T) {
+	for _, impl := range implementacoes(t) {
+		t.Run(impl.nome, func(t *testing.T) {
+			ctx := context.Background()
+			var promovidas []model.Consulta
+			repo, horarios := impl.novos(t, func(ctx context.Context, c model.Consulta) { promovidas = append(promovidas, c) })
+
+			h := novoHorario(t, horarios, model.HorarioDisponivel{Tipo: model.HorarioGrupo, Capacidade: 2, Status: "disponivel"})
+
+			var consultas []*model.Consulta
+			for _, aluno := range []string{"a1", "a2", "a3", "a4"} {
+				c, err := repo.AgendarConsulta(ctx, model.Consulta{AlunoID: aluno, HorarioID: h.ID})
+				if err != nil {
+					t.Fatal(err)
+				}
+				consultas = append(consultas, c)
+				time.Sleep(time.Millisecond) // a fila segue a ordem de agendamento
+			}
+			statuses := []string{consultas[0].Status, consultas[1].Status, consultas[2].Status, consultas[3].Status}
+			if statuses[1] != "aguardando aprovacao" || statuses[2] != "lista de espera" || statuses[3] != "lista de espera" {
+				t.Fatalf("status iniciais incorretos: %v", statuses)
+			}
+			if _, err := repo.AgendarConsulta(ctx, model.Consulta{AlunoID: "a1", HorarioID: h.ID}); !errors.Is(err, repository.ErrJaInscrito) {
+				t.Errorf("segunda inscrição do mesmo aluno deveria falhar com ErrJaInscrito, obteve %v", err)
+			}
+
+			// Recusar quem tem vaga promove o primeiro da fila.
+			if err := repo.AtualizaStatusConsulta(ctx, consultas[0].ID, "recusada"); err != nil {
+				t.Fatal(err)
+			}
+			if len(promovidas) != 1 || promovidas[0].ID != consultas[2].ID || promovidas[0].Status != "aguardando aprovacao" {
+				t.Fatalf("deveria promover e avisar a3, obteve %+v", promovidas)
+			}
+			if g := buscarHorario(t, horarios, h.ID); g.Inscritos != 2 || g.ListaEspera != 1 || g.Status != "lotado" {
+				t.Errorf("contagens após promoção: %+v", g)
+			}
+
+			// Quem sai da fila só libera o lugar nela.
+			if err := repo.AtualizaStatusConsulta(ctx, consultas[3].ID, "cancelada pelo aluno"); err != nil {
+				t.Fatal(err)
+			}
+			// Apagar uma consulta com vaga, sem fila, reabre o grupo.
+			if err := repo.DeletarConsulta(ctx, consultas[1].ID); err != nil {
+				t.Fatal(err)
+			}
+			if g := buscarHorario(t, horarios, h.ID); g.Inscritos != 1 || g.ListaEspera != 0 || g.Status != "disponivel" {
+				t.Errorf("contagens finais: %+v", g)
+			}
+			if len(promovidas) != 1 {
+				t.Errorf("não havia mais ninguém para promover: %+v", promovidas)
+			}
+		})
+	}
+}
+
+func TestConsultaIndividualLiberaHorario(t *testing.T) {
+	casos := []struct {
+		nome          string
+		sair          func(repo repository.ConsultaRepository, id string) error
+		bloquear      bool
+		statusHorario string
+	}{
+		{"recusada", func(repo repository.ConsultaRepository, id string) error {
+			return repo.AtualizaStatusConsulta(context.Background(), id, "recusada")
+		}, false, "disponivel"},
+		{"cancelada pela clinica", func(repo repository.ConsultaRepository, id string) error {
+			return repo.AtualizaStatusConsulta(context.Background(), id, "cancelada pela clinica")
+		}, false, "disponivel"},
+		{"apagada", func(repo repository.ConsultaRepository, id string) error {
+			return repo.DeletarConsulta(context.Background(), id)
+		}, false, "disponivel"},
+		{"horario bloqueado continua bloqueado", func(repo repository.ConsultaRepository, id string) error {
+			return repo.AtualizaStatusConsulta(context.Background(), id, "cancelada pela clinica")
+		}, true, "bloqueado"},
+	}
+	for _, impl := range implementacoes(t) {
+		for _, tc := range casos {
+			t.Run(impl.nome+"/"+tc.nome, func(t *testing.T) {
+				ctx := context.Background()
+				repo, horarios := impl.novos(t, nil)
+				h := novoHorario(t, horarios, model.HorarioDisponivel{Status: "disponivel"})
+
+				c, err := repo.AgendarConsulta(ctx, model.Consulta{AlunoID: "a1", HorarioID: h.ID})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if c.PsicologoID != h.PsicologoID || !c.Inicio.Equal(h.Inicio) || !c.Fim.Equal(h.Fim) {
+					t.Errorf("consulta sem os dados do horário: %+v", c)
+				}
+				if _, err := repo.AgendarConsulta(ctx, model.Consulta{AlunoID: "a2", HorarioID: h.ID}); err == nil {
+					t.Error("horário agendado não deveria aceitar outro aluno")
+				}
+				if err := repo.AtualizaStatusConsulta(ctx, c.ID, "confirmada"); err != nil {
+					t.Fatal(err)
+				}
+				if g := buscarHorario(t, horarios, h.ID); g.Status != "agendado" {
+					t.Fatalf("confirmar não deveria liberar o horário: %s", g.Status)
+				}
+				if tc.bloquear {
+					if err := horarios.AtualizarStatusHorario(ctx, h.ID, "bloqueado"); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				if err := tc.sair(repo, c.ID); err != nil {
+					t.Fatal(err)
+				}
+				if g := buscarHorario(t, horarios, h.ID); g.Status != tc.statusHorario {
+					t.Errorf("status do horário = %q, esperado %q", g.Status, tc.statusHorario)
+				}
+			})
+		}
+	}
+}
+
+func TestReservaDeEncaminhamento(t *testing.T) {
+	for _, impl := range implementacoes(t) {
+		t.Run(impl.nome, func(t *testing.T) {
+			ctx := context.Background()
+			consultas, horarios := impl.novos(t, nil)
+			agora := time.Now()
+
+			h := novoHorario(t, horarios, model.HorarioDisponivel{Status: "disponivel"})
+			if err := horarios.ReservarHorario(ctx, h.ID, "a1", agora.Add(time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+			if err := horarios.LiberarReserva(ctx, h.ID, "a2"); err != nil || buscarHorario(t, horarios, h.ID).Status != "reservado" {
+				t.Errorf("reserva de outro aluno não deveria ser liberada (err=%v)", err)
+			}
+			if _, err := consultas.AgendarConsulta(ctx, model.Consulta{AlunoID: "a2", HorarioID: h.ID}); err == nil {
+				t.Error("horário reservado não deveria aceitar outro aluno")
+			}
+			if err := horarios.ExpirarReserva(ctx, h.ID, agora); !errors.Is(err, repository.ErrTransicaoInvalida) {
+				t.Errorf("reserva no prazo expirada: err=%v", err)
+			}
+			if err := horarios.ExpirarReserva(ctx, h.ID, agora.Add(2*time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+			if liberado := buscarHorario(t, horarios, h.ID); liberado.Status != "disponivel" || liberado.ReservadoPara != "" || !liberado.ReservadoAte.IsZero() {
+				t.Errorf("reserva vencida não liberada: %+v", liberado)
+			}
+
+			// Agendado pelo aluno, o horário perde o prazo e não expira mais.
+			if err := horarios.ReservarHorario(ctx, h.ID, "a1", agora.Add(time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := consultas.AgendarConsulta(ctx, model.Consulta{AlunoID: "a1", HorarioID: h.ID}); err != nil {
+				t.Fatal(err)
+			}
+			if err := horarios.ExpirarReserva(ctx, h.ID, agora.Add(2*time.Hour)); !errors.Is(err, repository.ErrTransicaoInvalida) {
+				t.Errorf("horário agendado expirado: err=%v", err)
+			}
+			if agendado := buscarHorario(t, horarios, h.ID); agendado.Status != "agendado" || !agendado.ReservadoAte.IsZero() {
+				t.Errorf("horário agendado incorretamente: %+v", agendado)
+			}
+		})
+	}
+}
+
+func TestCorrecoesCondicionais(t *testing.T) {
+	for _, impl := range implementacoes(t) {
+		t.Run(impl.nome, func(t *testing.T) {
+			ctx := context.Background()
+			consultas, horarios := impl.novos(t, nil)
+
+			// Só libera o horário se ele seguir agendado e sem consulta.
+			h := novoHorario(t, horarios, model.HorarioDisponivel{Status: "disponivel"})
+			c, err := consultas.AgendarConsulta(ctx, model.Consulta{AlunoID: "a1", HorarioID: h.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := horarios.ReconciliarStatusHorario(ctx, h.ID, "agendado", "disponivel"); !errors.Is(err, repository.ErrTransicaoInvalida) {
+				t.Errorf("horário com consulta liberado: err=%v", err)
+			}
+			if err := horarios.ReconciliarStatusHorario(ctx, h.ID, "disponivel", "bloqueado"); !errors.Is(err, repository.ErrTransicaoInvalida) {
+				t.Errorf("status diferente do esperado aceito: err=%v", err)
+			}
+
+			// Só cancela a consulta que continua no status visto.
+			if err := consultas.TrocarStatusConsulta(ctx, c.ID, "confirmada", "cancelada pela clinica"); !errors.Is(err, repository.ErrTransicaoInvalida) {
+				t.Errorf("consulta em outro status cancelada: err=%v", err)
+			}
+			if err := consultas.TrocarStatusConsulta(ctx, c.ID, "aguardando aprovacao", "cancelada pela clinica"); err != nil {
+				t.Fatal(err)
+			}
+			if g := buscarHorario(t, horarios, h.ID); g.Status != "disponivel" {
+				t.Errorf("o cancelamento deveria devolver o horário: %s", g.Status)
+			}
+			if err := horarios.ReconciliarStatusHorario(ctx, h.ID, "disponivel", "agendado"); !errors.Is(err, repository.ErrTransicaoInvalida) {
+				t.Errorf("horário sem consulta marcado como agendado: err=%v", err)
+			}
+
+			// A recontagem exige as contagens vistas e usa as consultas relidas.
+			g := novoHorario(t, horarios, model.HorarioDisponivel{Tipo: model.HorarioGrupo, Capacidade: 1, Status: "disponivel"})
+			for _, aluno := range []string{"a1", "a2"} {
+				if _, err := consultas.AgendarConsulta(ctx, model.Consulta{AlunoID: aluno, HorarioID: g.ID}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := horarios.RecontarVagasGrupo(ctx, g.ID, 0, 0); !errors.Is(err, repository.ErrTransicaoInvalida) {
+				t.Errorf("recontagem com contagens velhas aceita: err=%v", err)
+			}
+			if err := horarios.RecontarVagasGrupo(ctx, g.ID, 1, 1); err != nil {
+				t.Fatal(err)
+			}
+			if grupo := buscarHorario(t, horarios, g.ID); grupo.Inscritos != 1 || grupo.ListaEspera != 1 || grupo.Status != "lotado" {
+				t.Errorf("recontagem incorreta: %+v", grupo)
+			}
+		})
+	}
+}
+
+func TestTransicoesQueOcupariamVagaSaoRecusadas(t *testing.T) {
+	for _, impl := range implementacoes(t) {
+		t.Run(impl.nome+"/lista de espera nao confirma direto", func(t *testing.T) {
+			ctx := context.Background()
+			consultas, horarios := impl.novos(t, nil)
+			g := novoHorario(t, horarios, model.HorarioDisponivel{Tipo: model.HorarioGrupo, Capacidade: 1, Status: "disponivel"})
+			if _, err := consultas.AgendarConsulta(ctx, model.Consulta{AlunoID: "a1", HorarioID: g.ID}); err != nil {
+				t.Fatal(err)
+			}
+			espera, err := consultas.AgendarConsulta(ctx, model.Consulta{AlunoID: "a2", HorarioID: g.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := consultas.AtualizaStatusConsulta(ctx, espera.ID, "confirmada"); !errors.Is(err, repository.ErrStatusNaoPermitido) {
+				t.Fatalf("a lista de espera não deveria ser confirmada sem promoção: err=%v", err)
+			}
+			if c, _ := consultas.BuscarConsultaPorID(ctx, espera.ID); c.Status != "lista de espera" {
+				t.Errorf("status mudou apesar da recusa: %s", c.Status)
+			}
+			if grupo := buscarHorario(t, horarios, g.ID); grupo.Inscritos != 1 || grupo.ListaEspera != 1 {
+				t.Errorf("contagens mudaram apesar da recusa: %+v", grupo)
+			}
+		})
+
+		t.Run(impl.nome+"/cancelada nao volta a ocupar o horario", func(t *testing.T) {
+			ctx := context.Background()
+			consultas, horarios := impl.novos(t, nil)
+			h := novoHorario(t, horarios, model.HorarioDisponivel{Status: "disponivel"})
+			cancelada, err := consultas.AgendarConsulta(ctx, model.Consulta{AlunoID: "a1", HorarioID: h.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := consultas.AtualizaStatusConsulta(ctx, cancelada.ID, "cancelada pelo aluno"); err != nil {
+				t.Fatal(err)
+			}
+			nova, err := consultas.AgendarConsulta(ctx, model.Consulta{AlunoID: "a2", HorarioID: h.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := consultas.AtualizaStatusConsulta(ctx, cancelada.ID, "confirmada"); !errors.Is(err, repository.ErrStatusNaoPermitido) {
+				t.Fatalf("a consulta cancelada não deveria voltar a ocupar o horário: err=%v", err)
+			}
+			if c, _ := consultas.BuscarConsultaPorID(ctx, cancelada.ID); c.Status != "cancelada pelo aluno" {
+				t.Errorf("status mudou apesar da recusa: %s", c.Status)
+			}
+			if c, _ := consultas.BuscarConsultaPorID(ctx, nova.ID); c.Status != "aguardando aprovacao" {
+				t.Errorf("a nova consulta deveria continuar no horário: %s", c.Status)
+			}
+		})
+	}
+}
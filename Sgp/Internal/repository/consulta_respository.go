@@ -4,15 +4,35 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 	"sgp/Internal/model"
 	"cloud.google.com/go/firestore"
 	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+var ErrTransicaoInvalida = errors.New("a consulta não está mais no status esperado")
+
+var ErrJaInscrito = errors.New("o aluno já tem um pedido ativo neste horário de grupo")
+
+// ErrStatusNaoPermitido recusa uma troca de status vetada por TransicaoPermitida.
+var ErrStatusNaoPermitido = errors.New("a consulta não pode passar do status atual para o pedido")
+
+// camposPresenca guarda o instante de cada etapa do comparecimento.
+var camposPresenca = map[string]string{
+	"em andamento": "checkinEm",
+	"concluida":    "concluidaEm",
+}
+
 type ConsultaRepositoryImpl struct {
 	Client *firestore.Client
+
+	// AoPromover, se definido, é chamado quando uma vaga de grupo liberada
+	// passa ao primeiro da lista de espera, para avisar o aluno promovido.
+	AoPromover func(ctx context.Context, promovida model.Consulta)
 }
 
 func NewConsultaRepository(client *firestore.Client) *ConsultaRepositoryImpl {
@@ -29,22 +49,32 @@ func (r *ConsultaRepositoryImpl) AgendarConsulta(ctx context.Context, consulta m
 
 		var horario model.HorarioDisponivel
 		horarioDoc.DataTo(&horario)
-		if horario.Status != "disponivel" {
-			return fmt.Errorf("o horário selecionado não está mais disponível")
-		}
 
-		// O horário fica "agendado" para que não possa ser pego por outro aluno
-		if err := tx.Update(horarioRef, []firestore.Update{{Path: "status", Value: "agendado"}}); err != nil {
-			return err
+		// MODIFICADO: Status inicial agora é "aguardando aprovacao"
+		consulta.Status = "aguardando aprovacao"
+		if horario.Tipo == model.HorarioGrupo {
+			status, err := r.inscreverNoGrupo(tx, horarioRef, &horario, consulta.AlunoID)
+			if err != nil {
+				return err
+			}
+			consulta.Status = status
+			consulta.Grupo = true
+		} else {
+			if err := OcuparHorario(&horario, consulta.AlunoID); err != nil {
+				return err
+			}
+			err := tx.Update(horarioRef, []firestore.Update{
+				{Path: "status", Value: horario.Status},
+				{Path: "reservadoPara", Value: firestore.Delete},
+				{Path: "reservadoAte", Value: firestore.Delete},
+			})
+			if err != nil {
+				return err
+			}
 		}
 
 		// Preenche os dados da consulta com o status pendente
-		consulta.Inicio = horario.Inicio
-		consulta.Fim = horario.Fim
-		consulta.PsicologoID = horario.PsicologoID
-		// MODIFICADO: Status inicial agora é "aguardando aprovacao"
-		consulta.Status = "aguardando aprovacao"
-		consulta.DataAgendamento = time.Now()
+		PreencherConsulta(&consulta, &horario, time.Now())
 
 		consultaRef := r.Client.Collection("Consultas").NewDoc()
 		consulta.ID = consultaRef.ID
@@ -58,40 +88,56 @@ func (r *ConsultaRepositoryImpl) AgendarConsulta(ctx context.Context, consulta m
 	return &consulta, nil
 }
 
+// AtualizaStatusConsulta roda em transação: ao sair de um status que segura
+// o horário (ver LiberaVaga), a vaga volta na mesma escrita, qualquer que
+// seja o novo status.
 func (r *ConsultaRepositoryImpl) AtualizaStatusConsulta(ctx context.Context, id string, novoStatus string) error {
+	return r.atualizarStatus(ctx, id, "", novoStatus)
+}
+
+func (r *ConsultaRepositoryImpl) TrocarStatusConsulta(ctx context.Context, id string, esperado, novoStatus string) error {
+	return r.atualizarStatus(ctx, id, esperado, novoStatus)
+}
+
+// atualizarStatus aceita qualquer status atual quando esperado é vazio.
+func (r *ConsultaRepositoryImpl) atualizarStatus(ctx context.Context, id string, esperado, novoStatus string) error {
 	consultaRef := r.Client.Collection("Consultas").Doc(id)
 
-	// Se o status for cancelada pelo aluno o horário fica como disponivel novamente.
-	if  novoStatus == "cancelada pelo aluno" {
-		return r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
-			consultaDoc, err := tx.Get(consultaRef)
-			if err != nil {
-				return fmt.Errorf("erro ao buscar consulta para atualização: %w", err)
-			}
+	var promovida *model.Consulta
+	err := r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		promovida = nil
+		consultaDoc, err := tx.Get(consultaRef)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar consulta para atualização: %w", err)
+		}
 
-			var consulta model.Consulta
-			consultaDoc.DataTo(&consulta)
-
-			if consulta.HorarioID != "" {
-				horarioRef := r.Client.Collection("horariosDisponiveis").Doc(consulta.HorarioID)
-				
-				if _, err := tx.Get(horarioRef); err == nil {
-					if err := tx.Update(horarioRef, []firestore.Update{{Path: "status", Value: "disponivel"}}); err != nil {
-						return fmt.Errorf("erro ao reverter status do horário: %w", err)
-					}
-				}
+		var consulta model.Consulta
+		if err := consultaDoc.DataTo(&consulta); err != nil {
+			return err
+		}
+		if esperado != "" && consulta.Status != esperado {
+			return ErrTransicaoInvalida
+		}
+		if !TransicaoPermitida(consulta.Status, novoStatus) {
+			return ErrStatusNaoPermitido
+		}
+		if LiberaVaga(consulta.Status, novoStatus) {
+			if promovida, err = r.liberarVaga(tx, consulta); err != nil {
+				return err
 			}
+		}
 
-			return tx.Update(consultaRef, []firestore.Update{{Path: "status", Value: novoStatus}})
-		})
-	}
-
-	_, err := consultaRef.Update(ctx, []firestore.Update{
-		{Path: "status", Value: novoStatus},
+		updates := []firestore.Update{{Path: "status", Value: novoStatus}}
+		// O instante da confirmação alimenta a latência nos relatórios.
+		if novoStatus == "confirmada" {
+			updates = append(updates, firestore.Update{Path: "confirmadaEm", Value: time.Now().UTC()})
+		}
+		return tx.Update(consultaRef, updates)
 	})
 	if err != nil {
-		return fmt.Errorf("erro ao atualizar status da consulta com ID '%s': %v", id, err)
+		return fmt.Errorf("erro ao atualizar status da consulta com ID '%s': %w", id, err)
 	}
+	r.avisarPromocao(ctx, promovida)
 	return nil
 }
 
@@ -152,11 +198,36 @@ func (r *ConsultaRepositoryImpl) ListarConsultasPorAluno(ctx context.Context, al
 	return consultas, nil
 }
 
+// DeletarConsulta devolve a vaga que a consulta segurava antes de apagá-la.
 func (r *ConsultaRepositoryImpl) DeletarConsulta(ctx context.Context, id string) error {
-	_, err := r.Client.Collection("Consultas").Doc(id).Delete(ctx)
+	consultaRef := r.Client.Collection("Consultas").Doc(id)
+
+	var promovida *model.Consulta
+	err := r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		promovida = nil
+		consultaDoc, err := tx.Get(consultaRef)
+		if status.Code(err) == codes.NotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var consulta model.Consulta
+		if err := consultaDoc.DataTo(&consulta); err != nil {
+			return err
+		}
+		if LiberaVaga(consulta.Status, "") {
+			if promovida, err = r.liberarVaga(tx, consulta); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(consultaRef)
+	})
 	if err != nil {
 		return fmt.Errorf("erro ao deletar consulta com ID '%s': %v", id, err)
 	}
+	r.avisarPromocao(ctx, promovida)
 	return nil
 }
 
@@ -172,3 +243,236 @@ func (r *ConsultaRepositoryImpl) BuscarConsultaPorID(ctx context.Context, id str
 	consulta.ID = doc.Ref.ID
 	return &consulta, nil
 }
+
+func (r *ConsultaRepositoryImpl) ListarConsultasPorStatus(ctx context.Context, status string) ([]*model.Consulta, error) {
+	var consultas []*model.Consulta
+
+	iter := r.Client.Collection("Consultas").Where("status", "==", status).Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao listar consultas com status '%s': %w", status, err)
+		}
+
+		var consulta model.Consulta
+		if err := doc.DataTo(&consulta); err != nil {
+			fmt.Printf("erro ao converter dados do doc '%s': %v", doc.Ref.ID, err)
+			continue
+		}
+		consulta.ID = doc.Ref.ID
+		consultas = append(consultas, &consulta)
+	}
+	return consultas, nil
+}
+
+// ListarConsultasNoPeriodo filtra por fim no Firestore e por início em memória,
+// como o ListarEventos do calendário.
+func (r *ConsultaRepositoryImpl) ListarConsultasNoPeriodo(ctx context.Context, statusFiltro string, de, ate time.Time) ([]*model.Consulta, error) {
+	var consultas []*model.Consulta
+
+	query := r.Client.Collection("Consultas").Where("fim", ">", de)
+	if statusFiltro != "" {
+		query = query.Where("status", "==", statusFiltro)
+	}
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao listar consultas do período: %w", err)
+		}
+
+		var consulta model.Consulta
+		if err := doc.DataTo(&consulta); err != nil {
+			fmt.Printf("erro ao converter dados do doc '%s': %v", doc.Ref.ID, err)
+			continue
+		}
+		if !consulta.Inicio.Before(ate) {
+			continue
+		}
+		consulta.ID = doc.Ref.ID
+		consultas = append(consultas, &consulta)
+	}
+	return consultas, nil
+}
+
+// RegistrarPresenca roda em transação para que o check-in e a marcação
+// automática de falta não sobrescrevam um ao outro.
+func (r *ConsultaRepositoryImpl) RegistrarPresenca(ctx context.Context, id string, esperado, novoStatus string, em time.Time) error {
+	consultaRef := r.Client.Collection("Consultas").Doc(id)
+
+	return r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(consultaRef)
+		if err != nil {
+			return fmt.Errorf("consulta não encontrada: %w", err)
+		}
+		var consulta model.Consulta
+		if err := doc.DataTo(&consulta); err != nil {
+			return err
+		}
+		if consulta.Status != esperado {
+			return ErrTransicaoInvalida
+		}
+
+		updates := []firestore.Update{{Path: "status", Value: novoStatus}}
+		if campo, ok := camposPresenca[novoStatus]; ok {
+			updates = append(updates, firestore.Update{Path: campo, Value: em})
+		}
+		return tx.Update(consultaRef, updates)
+	})
+}
+
+func (r *ConsultaRepositoryImpl) DefinirCodigoCheckin(ctx context.Context, id string, codigo string) error {
+	_, err := r.Client.Collection("Consultas").Doc(id).Update(ctx, []firestore.Update{
+		{Path: "codigoCheckin", Value: codigo},
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao definir código de check-in da consulta '%s': %w", id, err)
+	}
+	return nil
+}
+
+func (r *ConsultaRepositoryImpl) DefinirEpisodio(ctx context.Context, id string, episodioID string) error {
+	_, err := r.Client.Collection("Consultas").Doc(id).Update(ctx, []firestore.Update{
+		{Path: "episodioId", Value: episodioID},
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao vincular consulta '%s' ao episódio: %w", id, err)
+	}
+	return nil
+}
+
+func (r *ConsultaRepositoryImpl) DefinirLinkReuniao(ctx context.Context, id string, link string) error {
+	_, err := r.Client.Collection("Consultas").Doc(id).Update(ctx, []firestore.Update{
+		{Path: "linkReuniao", Value: link},
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao definir link de reunião da consulta '%s': %w", id, err)
+	}
+	return nil
+}
+
+// inscreverNoGrupo aplica InscreverNoGrupo depois de conferir que o aluno não
+// tem outro pedido no grupo. Roda dentro da transação de AgendarConsulta, antes das escritas.
+func (r *ConsultaRepositoryImpl) inscreverNoGrupo(tx *firestore.Transaction, horarioRef *firestore.DocumentRef, horario *model.HorarioDisponivel, alunoID string) (string, error) {
+	query := r.Client.Collection("Consultas").Where("horarioId", "==", horarioRef.ID).Where("alunoId", "==", alunoID)
+	docs, err := tx.Documents(query).GetAll()
+	if err != nil {
+		return "", fmt.Errorf("erro ao verificar inscrições do aluno no grupo: %w", err)
+	}
+	for _, doc := range docs {
+		var existente model.Consulta
+		if doc.DataTo(&existente) == nil && OcupaVaga(existente.Status) {
+			return "", ErrJaInscrito
+		}
+	}
+
+	status, err := InscreverNoGrupo(horario)
+	if err != nil {
+		return "", err
+	}
+	return status, tx.Update(horarioRef, []firestore.Update{
+		{Path: "inscritos", Value: horario.Inscritos},
+		{Path: "listaEspera", Value: horario.ListaEspera},
+		{Path: "status", Value: horario.Status},
+	})
+}
+
+// liberarVaga devolve o horário que a consulta segurava. O individual só
+// volta a "disponivel" se ainda estiver "agendado"; um horário bloqueado
+// continua bloqueado.
+func (r *ConsultaRepositoryImpl) liberarVaga(tx *firestore.Transaction, consulta model.Consulta) (*model.Consulta, error) {
+	if consulta.Grupo {
+		return r.liberarVagaNoGrupo(tx, consulta)
+	}
+	if consulta.HorarioID == "" {
+		return nil, nil
+	}
+	horarioRef := r.Client.Collection("horariosDisponiveis").Doc(consulta.HorarioID)
+	horarioDoc, err := tx.Get(horarioRef)
+	if err != nil {
+		// O horário pode ter sido removido; só a consulta muda.
+		return nil, nil
+	}
+	var horario model.HorarioDisponivel
+	if err := horarioDoc.DataTo(&horario); err != nil {
+		return nil, err
+	}
+	if !LiberarHorario(&horario) {
+		return nil, nil
+	}
+	if err := tx.Update(horarioRef, []firestore.Update{{Path: "status", Value: horario.Status}}); err != nil {
+		return nil, fmt.Errorf("erro ao reverter status do horário: %w", err)
+	}
+	return nil, nil
+}
+
+// liberarVagaNoGrupo aplica LiberarVagaNoGrupo e, se for o caso, promove o
+// pedido mais antigo da lista de espera na mesma transação. Retorna a
+// consulta promovida.
+func (r *ConsultaRepositoryImpl) liberarVagaNoGrupo(tx *firestore.Transaction, consulta model.Consulta) (*model.Consulta, error) {
+	horarioRef := r.Client.Collection("horariosDisponiveis").Doc(consulta.HorarioID)
+	horarioDoc, err := tx.Get(horarioRef)
+	if err != nil {
+		// O horário pode ter sido removido; só a consulta muda.
+		return nil, nil
+	}
+	var horario model.HorarioDisponivel
+	if err := horarioDoc.DataTo(&horario); err != nil {
+		return nil, err
+	}
+
+	var proximo *firestore.DocumentSnapshot
+	var promovida model.Consulta
+	if OcupaVaga(consulta.Status) {
+		query := r.Client.Collection("Consultas").Where("horarioId", "==", consulta.HorarioID).Where("status", "==", "lista de espera")
+		docs, err := tx.Documents(query).GetAll()
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar lista de espera do grupo: %w", err)
+		}
+		for _, doc := range docs {
+			var c model.Consulta
+			if doc.DataTo(&c) != nil || doc.Ref.ID == consulta.ID {
+				continue
+			}
+			if proximo == nil || c.DataAgendamento.Before(promovida.DataAgendamento) {
+				proximo, promovida = doc, c
+			}
+		}
+	}
+
+	if LiberarVagaNoGrupo(&horario, consulta.Status, proximo != nil) {
+		if err := tx.Update(proximo.Ref, []firestore.Update{{Path: "status", Value: "aguardando aprovacao"}}); err != nil {
+			return nil, err
+		}
+		promovida.ID = proximo.Ref.ID
+		promovida.Status = "aguardando aprovacao"
+	} else {
+		proximo = nil
+	}
+	err = tx.Update(horarioRef, []firestore.Update{
+		{Path: "inscritos", Value: horario.Inscritos},
+		{Path: "listaEspera", Value: horario.ListaEspera},
+		{Path: "status", Value: horario.Status},
+	})
+	if err != nil || proximo == nil {
+		return nil, err
+	}
+	return &promovida, nil
+}
+
+// avisarPromocao chama AoPromover depois que a transação foi gravada.
+func (r *ConsultaRepositoryImpl) avisarPromocao(ctx context.Context, promovida *model.Consulta) {
+	if promovida != nil && r.AoPromover != nil {
+		r.AoPromover(ctx, *promovida)
+	}
+}
@@ -0,0 +1,78 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/repository/memoria"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// implementacao é um backend dos testes de contrato: os mesmos casos rodam
+// no memoria e, com FIRESTORE_EMULATOR_HOST definido, no emulador do
+// Firestore, para que as duas implementações não se afastem.
+type implementacao struct {
+	nome string
+	// novos devolve os repositórios do backend. No emulador o banco é
+	// compartilhado, por isso cada caso só olha o que ele mesmo criou.
+	novos func(t *testing.T, aoPromover func(context.Context, model.Consulta)) (repository.ConsultaRepository, repository.HorarioDisponivelRepository)
+}
+
+func implementacoes(t *testing.T) []implementacao {
+	impls := []implementacao{{
+		nome: "memoria",
+		novos: func(t *testing.T, aoPromover func(context.Context, model.Consulta)) (repository.ConsultaRepository, repository.HorarioDisponivelRepository) {
+			banco := memoria.NovoBanco()
+			consultas := memoria.NewConsultaRepository(banco)
+			consultas.AoPromover = aoPromover
+			return consultas, memoria.NewHorarioDisponivelRepository(banco)
+		},
+	}}
+
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		t.Log("FIRESTORE_EMULATOR_HOST não definido; os testes de contrato rodam só no memoria")
+		return impls
+	}
+	return append(impls, implementacao{
+		nome: "firestore",
+		novos: func(t *testing.T, aoPromover func(context.Context, model.Consulta)) (repository.ConsultaRepository, repository.HorarioDisponivelRepository) {
+			projeto := os.Getenv("GOOGLE_CLOUD_PROJECT")
+			if projeto == "" {
+				projeto = "demo-sgp"
+			}
+			client, err := firestore.NewClient(context.Background(), projeto)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { client.Close() })
+			consultas := repository.NewConsultaRepository(client)
+			consultas.AoPromover = aoPromover
+			return consultas, repository.NewHorarioDisponivelRepository(client)
+		},
+	})
+}
+
+func novoHorario(t *testing.T, horarios repository.HorarioDisponivelRepository, h model.HorarioDisponivel) *model.HorarioDisponivel {
+	t.Helper()
+	h.PsicologoID = "p1"
+	h.Inicio = time.Now().Add(48 * time.Hour).UTC().Truncate(time.Microsecond)
+	h.Fim = h.Inicio.Add(time.Hour)
+	criado, err := horarios.CriarHorario(context.Background(), h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return criado
+}
+
+func buscarHorario(t *testing.T, horarios repository.HorarioDisponivelRepository, id string) *model.HorarioDisponivel {
+	t.Helper()
+	h, err := horarios.BuscarHorarioPorID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sgp/Internal/model"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+type EncaminhamentoRepositoryImpl struct {
+	Client *firestore.Client
+}
+
+func NewEncaminhamentoRepository(client *firestore.Client) *EncaminhamentoRepositoryImpl {
+	return &EncaminhamentoRepositoryImpl{Client: client}
+}
+
+func (r *EncaminhamentoRepositoryImpl) CriarEncaminhamento(ctx context.Context, encaminhamento model.Encaminhamento) (*model.Encaminhamento, error) {
+	docRef, _, err := r.Client.Collection("Encaminhamentos").Add(ctx, encaminhamento)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar encaminhamento: %w", err)
+	}
+	encaminhamento.ID = docRef.ID
+	return &encaminhamento, nil
+}
+
+func (r *EncaminhamentoRepositoryImpl) BuscarEncaminhamentoPorID(ctx context.Context, id string) (*model.Encaminhamento, error) {
+	doc, err := r.Client.Collection("Encaminhamentos").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("encaminhamento não encontrado: %w", err)
+	}
+	var encaminhamento model.Encaminhamento
+	if err := doc.DataTo(&encaminhamento); err != nil {
+		return nil, err
+	}
+	encaminhamento.ID = doc.Ref.ID
+	return &encaminhamento, nil
+}
+
+// AtualizarEncaminhamento roda em transação para que aceite e recusa
+// simultâneos não sobrescrevam um ao outro.
+func (r *EncaminhamentoRepositoryImpl) AtualizarEncaminhamento(ctx context.Context, id string, esperado string, encaminhamento model.Encaminhamento) error {
+	ref := r.Client.Collection("Encaminhamentos").Doc(id)
+	return r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return fmt.Errorf("encaminhamento não encontrado: %w", err)
+		}
+		var atual model.Encaminhamento
+		if err := doc.DataTo(&atual); err != nil {
+			return err
+		}
+		if atual.Status != esperado {
+			return ErrTransicaoInvalida
+		}
+		return tx.Set(ref, encaminhamento)
+	})
+}
+
+func (r *EncaminhamentoRepositoryImpl) ListarEncaminhamentosPorPsicologo(ctx context.Context, psicologoID string) ([]*model.Encaminhamento, error) {
+	var encaminhamentos []*model.Encaminhamento
+	for _, campo := range []string{"dePsicologoId", "paraPsicologoId"} {
+		lista, err := r.listar(ctx, r.Client.Collection("Encaminhamentos").Where(campo, "==", psicologoID))
+		if err != nil {
+			return nil, fmt.Errorf("erro ao listar encaminhamentos do psicólogo '%s': %w", psicologoID, err)
+		}
+		encaminhamentos = append(encaminhamentos, lista...)
+	}
+	return encaminhamentos, nil
+}
+
+func (r *EncaminhamentoRepositoryImpl) ListarEncaminhamentosPorAluno(ctx context.Context, alunoID string) ([]*model.Encaminhamento, error) {
+	encaminhamentos, err := r.listar(ctx, r.Client.Collection("Encaminhamentos").Where("alunoId", "==", alunoID))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar encaminhamentos do aluno '%s': %w", alunoID, err)
+	}
+	return encaminhamentos, nil
+}
+
+func (r *EncaminhamentoRepositoryImpl) listar(ctx context.Context, query firestore.Query) ([]*model.Encaminhamento, error) {
+	var encaminhamentos []*model.Encaminhamento
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var encaminhamento model.Encaminhamento
+		if err := doc.DataTo(&encaminhamento); err != nil {
+			fmt.Printf("erro ao converter dados do doc '%s': %v", doc.Ref.ID, err)
+			continue
+		}
+		encaminhamento.ID = doc.Ref.ID
+		encaminhamentos = append(encaminhamentos, &encaminhamento)
+	}
+	return encaminhamentos, nil
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sgp/Internal/model"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+var ErrEpisodioAberto = errors.New("o aluno já tem um episódio de cuidado aberto")
+
+type EpisodioRepositoryImpl struct {
+	Client *firestore.Client
+}
+
+func NewEpisodioRepository(client *firestore.Client) *EpisodioRepositoryImpl {
+	return &EpisodioRepositoryImpl{Client: client}
+}
+
+// AbrirEpisodio confere e grava na mesma transação para que dois pedidos
+// simultâneos não abram dois episódios para o mesmo aluno.
+func (r *EpisodioRepositoryImpl) AbrirEpisodio(ctx context.Context, episodio model.Episodio) (*model.Episodio, error) {
+	colecao := r.Client.Collection("Episodios")
+	err := r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		query := colecao.Where("alunoId", "==", episodio.AlunoID).Where("status", "==", model.EpisodioAberto).Limit(1)
+		abertos, err := tx.Documents(query).GetAll()
+		if err != nil {
+			return fmt.Errorf("erro ao verificar episódios do aluno: %w", err)
+		}
+		if len(abertos) > 0 {
+			return ErrEpisodioAberto
+		}
+
+		ref := colecao.NewDoc()
+		episodio.ID = ref.ID
+		return tx.Create(ref, episodio)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &episodio, nil
+}
+
+// AssumirEpisodio lê e grava na mesma transação, como AbrirEpisodio, para que
+// uma transferência ou abertura simultânea não se perca.
+func (r *EpisodioRepositoryImpl) AssumirEpisodio(ctx context.Context, alunoID string, transferencia model.TransferenciaEpisodio) (*model.Episodio, error) {
+	colecao := r.Client.Collection("Episodios")
+	var episodio model.Episodio
+	err := r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		query := colecao.Where("alunoId", "==", alunoID).Where("status", "==", model.EpisodioAberto).Limit(1)
+		abertos, err := tx.Documents(query).GetAll()
+		if err != nil {
+			return fmt.Errorf("erro ao verificar episódios do aluno: %w", err)
+		}
+
+		if len(abertos) == 0 {
+			ref := colecao.NewDoc()
+			episodio = model.Episodio{
+				ID:          ref.ID,
+				AlunoID:     alunoID,
+				PsicologoID: transferencia.ParaPsicologoID,
+				Status:      model.EpisodioAberto,
+				AbertoEm:    transferencia.Em,
+			}
+			return tx.Create(ref, episodio)
+		}
+
+		episodio = model.Episodio{}
+		if err := abertos[0].DataTo(&episodio); err != nil {
+			return err
+		}
+		episodio.ID = abertos[0].Ref.ID
+		if episodio.PsicologoID == transferencia.ParaPsicologoID {
+			return nil
+		}
+		transferencia.DePsicologoID = episodio.PsicologoID
+		episodio.Transferencias = append(episodio.Transferencias, transferencia)
+		episodio.PsicologoID = transferencia.ParaPsicologoID
+		return tx.Set(abertos[0].Ref, episodio)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &episodio, nil
+}
+
+func (r *EpisodioRepositoryImpl) BuscarEpisodioPorID(ctx context.Context, id string) (*model.Episodio, error) {
+	doc, err := r.Client.Collection("Episodios").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("episódio não encontrado: %w", err)
+	}
+	var episodio model.Episodio
+	if err := doc.DataTo(&episodio); err != nil {
+		return nil, err
+	}
+	episodio.ID = doc.Ref.ID
+	return &episodio, nil
+}
+
+func (r *EpisodioRepositoryImpl) BuscarEpisodioAberto(ctx context.Context, alunoID string) (*model.Episodio, error) {
+	iter := r.Client.Collection("Episodios").
+		Where("alunoId", "==", alunoID).
+		Where("status", "==", model.EpisodioAberto).
+		Limit(1).Documents(ctx)
+	defer iter.Stop()
+
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar episódio aberto do aluno '%s': %w", alunoID, err)
+	}
+	var episodio model.Episodio
+	if err := doc.DataTo(&episodio); err != nil {
+		return nil, err
+	}
+	episodio.ID = doc.Ref.ID
+	return &episodio, nil
+}
+
+func (r *EpisodioRepositoryImpl) ListarEpisodiosPorAluno(ctx context.Context, alunoID string) ([]*model.Episodio, error) {
+	var episodios []*model.Episodio
+
+	iter := r.Client.Collection("Episodios").Where("alunoId", "==", alunoID).Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao listar episódios do aluno '%s': %w", alunoID, err)
+		}
+
+		var episodio model.Episodio
+		if err := doc.DataTo(&episodio); err != nil {
+			fmt.Printf("erro ao converter dados do doc '%s': %v", doc.Ref.ID, err)
+			continue
+		}
+		episodio.ID = doc.Ref.ID
+		episodios = append(episodios, &episodio)
+	}
+	return episodios, nil
+}
+
+func (r *EpisodioRepositoryImpl) AtualizarEpisodio(ctx context.Context, id string, psicologoEsperado string, episodio model.Episodio) error {
+	ref := r.Client.Collection("Episodios").Doc(id)
+	return r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return fmt.Errorf("episódio não encontrado: %w", err)
+		}
+		var atual model.Episodio
+		if err := doc.DataTo(&atual); err != nil {
+			return err
+		}
+		if atual.Status != model.EpisodioAberto || atual.PsicologoID != psicologoEsperado {
+			return ErrTransicaoInvalida
+		}
+		return tx.Set(ref, episodio)
+	})
+}
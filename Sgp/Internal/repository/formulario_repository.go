@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sgp/Internal/model"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type FormularioRepositoryImpl struct {
+	Client *firestore.Client
+}
+
+func NewFormularioRepository(client *firestore.Client) *FormularioRepositoryImpl {
+	return &FormularioRepositoryImpl{Client: client}
+}
+
+// SalvarFormulario guarda um formulário por psicólogo, usando o ID dele como ID do documento.
+func (r *FormularioRepositoryImpl) SalvarFormulario(ctx context.Context, formulario model.FormularioInicial) (*model.FormularioInicial, error) {
+	formulario.AtualizadoEm = time.Now().UTC()
+	_, err := r.Client.Collection("FormulariosIniciais").Doc(formulario.PsicologoID).Set(ctx, formulario)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao salvar formulário do psicólogo '%s': %w", formulario.PsicologoID, err)
+	}
+	formulario.ID = formulario.PsicologoID
+	return &formulario, nil
+}
+
+func (r *FormularioRepositoryImpl) BuscarFormularioPorPsicologo(ctx context.Context, psicologoID string) (*model.FormularioInicial, error) {
+	doc, err := r.Client.Collection("FormulariosIniciais").Doc(psicologoID).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar formulário do psicólogo '%s': %w", psicologoID, err)
+	}
+
+	var formulario model.FormularioInicial
+	if err := doc.DataTo(&formulario); err != nil {
+		return nil, err
+	}
+	formulario.ID = doc.Ref.ID
+	return &formulario, nil
+}
+
+// RegistrarResposta guarda no máximo uma resposta por consulta.
+func (r *FormularioRepositoryImpl) RegistrarResposta(ctx context.Context, resposta model.RespostaFormulario) (*model.RespostaFormulario, error) {
+	_, err := r.Client.Collection("RespostasFormulario").Doc(resposta.ConsultaID).Create(ctx, resposta)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao registrar resposta da consulta '%s': %w", resposta.ConsultaID, err)
+	}
+	resposta.ID = resposta.ConsultaID
+	return &resposta, nil
+}
+
+func (r *FormularioRepositoryImpl) BuscarRespostaPorConsulta(ctx context.Context, consultaID string) (*model.RespostaFormulario, error) {
+	doc, err := r.Client.Collection("RespostasFormulario").Doc(consultaID).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar resposta da consulta '%s': %w", consultaID, err)
+	}
+
+	var resposta model.RespostaFormulario
+	if err := doc.DataTo(&resposta); err != nil {
+		return nil, err
+	}
+	resposta.ID = doc.Ref.ID
+	return &resposta, nil
+}
+
+func (r *FormularioRepositoryImpl) ListarRespostasPorAluno(ctx context.Context, alunoID string) ([]*model.RespostaFormulario, error) {
+	var respostas []*model.RespostaFormulario
+
+	iter := r.Client.Collection("RespostasFormulario").Where("alunoId", "==", alunoID).Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao listar respostas do aluno '%s': %w", alunoID, err)
+		}
+
+		var resposta model.RespostaFormulario
+		if err := doc.DataTo(&resposta); err != nil {
+			fmt.Printf("erro ao converter dados do doc '%s': %v", doc.Ref.ID, err)
+			continue
+		}
+		resposta.ID = doc.Ref.ID
+		respostas = append(respostas, &resposta)
+	}
+	return respostas, nil
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"fmt"
+	"sgp/Internal/model"
+	"time"
+)
+
+// Regras de ocupação de horários compartilhadas pelas implementações do
+// ConsultaRepository. Elas só mexem nos contadores do horário; ler e gravar
+// as consultas afetadas fica a cargo de cada implementação.
+
+// OcupaVaga indica se a consulta segura o horário: no individual, o status
+// "agendado"; no grupo, um dos inscritos.
+func OcupaVaga(status string) bool {
+	switch status {
+	case "aguardando aprovacao", "confirmada", "em andamento", "concluida", "falta":
+		return true
+	}
+	return false
+}
+
+// LiberaVaga indica se a troca de status devolve o que a consulta segurava:
+// a vaga ou o lugar na lista de espera. Um status vazio representa a
+// consulta apagada.
+func LiberaVaga(de, para string) bool {
+	segurava := OcupaVaga(de) || de == "lista de espera"
+	return segurava && !OcupaVaga(para) && para != "lista de espera"
+}
+
+// TransicaoPermitida recusa as trocas de status que mexeriam na ocupação
+// sem passar pelas regras dela: entrar na lista de espera (só o agendamento
+// faz isso), sair dela direto para um status que ocupa vaga (só a promoção
+// faz isso) e voltar a um status ativo depois de a consulta ter liberado a
+// vaga ou terminado.
+func TransicaoPermitida(de, para string) bool {
+	if de == para {
+		return true
+	}
+	switch {
+	case para == "lista de espera":
+		return false
+	case de == "lista de espera":
+		return !OcupaVaga(para)
+	case de == "concluida" || de == "falta":
+		return para == "concluida" || para == "falta" || !OcupaVaga(para)
+	case !OcupaVaga(de):
+		return !OcupaVaga(para)
+	}
+	return true
+}
+
+// PreencherConsulta copia para a consulta os dados do horário agendado.
+func PreencherConsulta(consulta *model.Consulta, horario *model.HorarioDisponivel, agora time.Time) {
+	consulta.Inicio = horario.Inicio
+	consulta.Fim = horario.Fim
+	consulta.PsicologoID = horario.PsicologoID
+	consulta.Modalidade = horario.Modalidade
+	consulta.DataAgendamento = agora
+}
+
+// OcuparHorario passa o horário individual a "agendado", para que não possa
+// ser pego por outro aluno. Um horário reservado só serve ao aluno a quem foi
+// reservado, e a reserva se desfaz.
+func OcuparHorario(horario *model.HorarioDisponivel, alunoID string) error {
+	reservadoAoAluno := horario.Status == "reservado" && horario.ReservadoPara == alunoID
+	if horario.Status != "disponivel" && !reservadoAoAluno {
+		return fmt.Errorf("o horário selecionado não está mais disponível")
+	}
+	horario.Status = "agendado"
+	horario.ReservadoPara = ""
+	horario.ReservadoAte = time.Time{}
+	return nil
+}
+
+// LiberarHorario devolve o horário individual só se ele ainda estiver
+// "agendado"; um horário bloqueado continua bloqueado. Retorna se mudou.
+func LiberarHorario(horario *model.HorarioDisponivel) bool {
+	if horario.Status != "agendado" {
+		return false
+	}
+	horario.Status = "disponivel"
+	return true
+}
+
+// InscreverNoGrupo ocupa uma vaga do horário de grupo ou, se ele estiver
+// lotado, coloca o pedido na lista de espera. Retorna o status inicial da consulta.
+func InscreverNoGrupo(horario *model.HorarioDisponivel) (string, error) {
+	if horario.Status != "disponivel" && horario.Status != "lotado" {
+		return "", fmt.Errorf("o horário selecionado não está mais disponível")
+	}
+
+	if horario.Inscritos < horario.Capacidade {
+		horario.Inscritos++
+		horario.Status = "disponivel"
+		if horario.Inscritos >= horario.Capacidade {
+			horario.Status = "lotado"
+		}
+		return "aguardando aprovacao", nil
+	}
+	horario.ListaEspera++
+	horario.Status = "lotado"
+	return "lista de espera", nil
+}
+
+// LiberarVagaNoGrupo desfaz a inscrição de uma consulta que estava em
+// statusAnterior. Se ela ocupava vaga e há fila, a vaga passa direto ao
+// primeiro da lista de espera: o retorno indica que o chamador deve mudar
+// esse pedido para "aguardando aprovacao", e o grupo continua lotado.
+func LiberarVagaNoGrupo(horario *model.HorarioDisponivel, statusAnterior string, haEspera bool) (promover bool) {
+	if statusAnterior == "lista de espera" {
+		horario.ListaEspera = max(horario.ListaEspera-1, 0)
+		return false
+	}
+	if !OcupaVaga(statusAnterior) {
+		return false
+	}
+	if haEspera {
+		horario.ListaEspera = max(horario.ListaEspera-1, 0)
+		return true
+	}
+	horario.Inscritos = max(horario.Inscritos-1, 0)
+	if horario.Status == "lotado" {
+		horario.Status = "disponivel"
+	}
+	return false
+}
+
+// ContarVagas soma, entre as consultas de um horário, as que ocupam vaga e as
+// que estão na lista de espera.
+func ContarVagas(consultas []*model.Consulta) (ocupantes, espera int) {
+	for _, c := range consultas {
+		switch {
+		case c.Status == "lista de espera":
+			espera++
+		case OcupaVaga(c.Status):
+			ocupantes++
+		}
+	}
+	return ocupantes, espera
+}
+
+// ConfereOcupacao diz se o status do horário individual combina com o número
+// de consultas que o ocupam: "disponivel" e "reservado" pedem nenhuma e
+// "agendado", ao menos uma. Os demais status não dependem das consultas.
+func ConfereOcupacao(status string, ocupantes int) bool {
+	switch status {
+	case "disponivel", "reservado":
+		return ocupantes == 0
+	case "agendado":
+		return ocupantes > 0
+	}
+	return true
+}
+
+// RecontarGrupo grava as contagens no horário de grupo e alterna entre
+// "disponivel" e "lotado" conforme a capacidade; um horário bloqueado
+// continua bloqueado.
+func RecontarGrupo(horario *model.HorarioDisponivel, inscritos, espera int) {
+	horario.Inscritos = inscritos
+	horario.ListaEspera = espera
+	if horario.Status == "disponivel" || horario.Status == "lotado" {
+		horario.Status = "disponivel"
+		if inscritos >= horario.Capacidade {
+			horario.Status = "lotado"
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"sgp/Internal/model"
+	"testing"
+	"time"
+)
+
+func TestLiberaVaga(t *testing.T) {
+	casos := []struct {
+		de, para string
+		esperado bool
+	}{
+		{"aguardando aprovacao", "recusada", true},
+		{"confirmada", "cancelada", true},
+		{"confirmada", "cancelada pela clinica", true},
+		{"lista de espera", "cancelada pelo aluno", true},
+		{"confirmada", "", true},
+		{"aguardando aprovacao", "confirmada", false},
+		{"confirmada", "em andamento", false},
+		{"lista de espera", "aguardando aprovacao", false},
+		{"recusada", "cancelada", false},
+		{"cancelada pelo aluno", "", false},
+	}
+	for _, tc := range casos {
+		if obtido := LiberaVaga(tc.de, tc.para); obtido != tc.esperado {
+			t.Errorf("LiberaVaga(%q, %q) = %v, esperado %v", tc.de, tc.para, obtido, tc.esperado)
+		}
+	}
+}
+
+func TestTransicaoPermitida(t *testing.T) {
+	casos := []struct {
+		de, para string
+		esperado bool
+	}{
+		{"aguardando aprovacao", "confirmada", true},
+		{"confirmada", "cancelada pelo aluno", true},
+		{"lista de espera", "cancelada pelo aluno", true},
+		{"concluida", "falta", true},
+		{"confirmada", "confirmada", true},
+		{"lista de espera", "confirmada", false},
+		{"lista de espera", "aguardando aprovacao", false},
+		{"confirmada", "lista de espera", false},
+		{"cancelada pelo aluno", "confirmada", false},
+		{"recusada", "aguardando aprovacao", false},
+		{"cancelada pela clinica", "lista de espera", false},
+		{"concluida", "confirmada", false},
+		{"falta", "em andamento", false},
+	}
+	for _, tc := range casos {
+		if obtido := TransicaoPermitida(tc.de, tc.para); obtido != tc.esperado {
+			t.Errorf("TransicaoPermitida(%q, %q) = %v, esperado %v", tc.de, tc.para, obtido, tc.esperado)
+		}
+	}
+}
+
+func TestInscreverNoGrupo(t *testing.T) {
+	horario := &model.HorarioDisponivel{Tipo: model.HorarioGrupo, Capacidade: 2, Status: "disponivel"}
+
+	esperados := []struct {
+		status            string
+		inscritos, espera int
+		statusHorario     string
+	}{
+		{"aguardando aprovacao", 1, 0, "disponivel"},
+		{"aguardando aprovacao", 2, 0, "lotado"},
+		{"lista de espera", 2, 1, "lotado"},
+		{"lista de espera", 2, 2, "lotado"},
+	}
+	for i, e := range esperados {
+		status, err := InscreverNoGrupo(horario)
+		if err != nil {
+			t.Fatalf("inscrição %d: %v", i+1, err)
+		}
+		if status != e.status || horario.Inscritos != e.inscritos || horario.ListaEspera != e.espera || horario.Status != e.statusHorario {
+			t.Errorf("inscrição %d: status %q, horário %+v; esperado %+v", i+1, status, horario, e)
+		}
+	}
+
+	horario.Status = "bloqueado"
+	if _, err := InscreverNoGrupo(horario); err == nil {
+		t.Error("horário bloqueado não deveria aceitar inscrição")
+	}
+}
+
+func TestLiberarVagaNoGrupo(t *testing.T) {
+	t.Run("vaga passa ao primeiro da espera", func(t *testing.T) {
+		horario := &model.HorarioDisponivel{Capacidade: 2, Inscritos: 2, ListaEspera: 1, Status: "lotado"}
+		if !LiberarVagaNoGrupo(horario, "confirmada", true) {
+			t.Fatal("deveria promover o pedido da lista de espera")
+		}
+		if horario.Inscritos != 2 || horario.ListaEspera != 0 || horario.Status != "lotado" {
+			t.Errorf("contagens incorretas: %+v", horario)
+		}
+	})
+
+	t.Run("sem espera a vaga reabre o grupo", func(t *testing.T) {
+		horario := &model.HorarioDisponivel{Capacidade: 2, Inscritos: 2, Status: "lotado"}
+		if LiberarVagaNoGrupo(horario, "aguardando aprovacao", false) {
+			t.Fatal("não há ninguém para promover")
+		}
+		if horario.Inscritos != 1 || horario.Status != "disponivel" {
+			t.Errorf("contagens incorretas: %+v", horario)
+		}
+	})
+
+	t.Run("saida da lista de espera", func(t *testing.T) {
+		horario := &model.HorarioDisponivel{Capacidade: 2, Inscritos: 2, ListaEspera: 2, Status: "lotado"}
+		if LiberarVagaNoGrupo(horario, "lista de espera", true) {
+			t.Fatal("quem sai da fila não libera vaga")
+		}
+		if horario.Inscritos != 2 || horario.ListaEspera != 1 || horario.Status != "lotado" {
+			t.Errorf("contagens incorretas: %+v", horario)
+		}
+	})
+}
+
+func TestOcuparELiberarHorario(t *testing.T) {
+	casos := []struct {
+		nome    string
+		horario model.HorarioDisponivel
+		alunoID string
+		aceita  bool
+	}{
+		{"disponivel", model.HorarioDisponivel{Status: "disponivel"}, "a1", true},
+		{"reservado ao aluno", model.HorarioDisponivel{Status: "reservado", ReservadoPara: "a1", ReservadoAte: time.Now()}, "a1", true},
+		{"reservado a outro aluno", model.HorarioDisponivel{Status: "reservado", ReservadoPara: "a2"}, "a1", false},
+		{"agendado", model.HorarioDisponivel{Status: "agendado"}, "a1", false},
+		{"bloqueado", model.HorarioDisponivel{Status: "bloqueado"}, "a1", false},
+	}
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			horario := tc.horario
+			err := OcuparHorario(&horario, tc.alunoID)
+			if (err == nil) != tc.aceita {
+				t.Fatalf("OcuparHorario = %v, aceita esperado %v", err, tc.aceita)
+			}
+			if !tc.aceita {
+				if horario.Status != tc.horario.Status {
+					t.Errorf("horário recusado não deveria mudar: %+v", horario)
+				}
+				return
+			}
+			if horario.Status != "agendado" || horario.ReservadoPara != "" || !horario.ReservadoAte.IsZero() {
+				t.Errorf("horário ocupado incorretamente: %+v", horario)
+			}
+			if !LiberarHorario(&horario) || horario.Status != "disponivel" {
+				t.Errorf("horário agendado deveria voltar a disponivel: %+v", horario)
+			}
+		})
+	}
+
+	bloqueado := model.HorarioDisponivel{Status: "bloqueado"}
+	if LiberarHorario(&bloqueado) || bloqueado.Status != "bloqueado" {
+		t.Errorf("horário bloqueado não deveria ser liberado: %+v", bloqueado)
+	}
+}
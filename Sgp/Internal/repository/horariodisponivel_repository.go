@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sgp/Internal/model"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	"google.golang.org/api/iterator"
@@ -17,7 +19,16 @@ func NewHorarioDisponivelRepository(client *firestore.Client) HorarioDisponivelR
 	return &HorarioDisponivelRepositoryImpl{Client: client}
 }
 
+// ErrConflitoSala indica que a sala já está reservada em parte do intervalo.
+var ErrConflitoSala = errors.New("a sala já está ocupada neste intervalo")
+
+var ErrHorarioIndisponivel = errors.New("o horário selecionado não está disponível")
+
 func (r *HorarioDisponivelRepositoryImpl) CriarHorario(ctx context.Context, horario model.HorarioDisponivel) (*model.HorarioDisponivel, error) {
+	if horario.SalaID != "" {
+		return r.criarHorarioEmSala(ctx, horario)
+	}
+
 	docRef, _, err := r.Client.Collection("horariosDisponiveis").Add(ctx, horario)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao criar horário: %w", err)
@@ -26,6 +37,36 @@ func (r *HorarioDisponivelRepositoryImpl) CriarHorario(ctx context.Context, hora
 	return &horario, nil
 }
 
+// criarHorarioEmSala confere a sobreposição e grava na mesma transação, para
+// que dois psicólogos não reservem a mesma sala ao mesmo tempo.
+func (r *HorarioDisponivelRepositoryImpl) criarHorarioEmSala(ctx context.Context, horario model.HorarioDisponivel) (*model.HorarioDisponivel, error) {
+	colecao := r.Client.Collection("horariosDisponiveis")
+	docRef := colecao.NewDoc()
+
+	err := r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docs, err := tx.Documents(colecao.Where("salaId", "==", horario.SalaID).Where("fim", ">", horario.Inicio)).GetAll()
+		if err != nil {
+			return fmt.Errorf("erro ao verificar ocupação da sala: %w", err)
+		}
+		for _, doc := range docs {
+			var existente model.HorarioDisponivel
+			if err := doc.DataTo(&existente); err != nil {
+				return err
+			}
+			if existente.Inicio.Before(horario.Fim) {
+				return ErrConflitoSala
+			}
+		}
+		return tx.Create(docRef, horario)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	horario.ID = docRef.ID
+	return &horario, nil
+}
+
 func (r *HorarioDisponivelRepositoryImpl) ListarHorariosPorPsicologo(ctx context.Context, psicologoID string, status string) ([]*model.HorarioDisponivel, error) {
 	var horarios []*model.HorarioDisponivel
 	query := r.Client.Collection("horariosDisponiveis").Where("psicologoId", "==", psicologoID)
@@ -57,7 +98,7 @@ func (r *HorarioDisponivelRepositoryImpl) ListarHorariosPorPsicologo(ctx context
 func (r *HorarioDisponivelRepositoryImpl) BuscarHorarioPorID(ctx context.Context, id string) (*model.HorarioDisponivel, error) {
 	doc, err := r.Client.Collection("horariosDisponiveis").Doc(id).Get(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("horário com ID '%s' não encontrado", id)
+		return nil, fmt.Errorf("horário com ID '%s' não encontrado: %w", id, err)
 	}
 	var horario model.HorarioDisponivel
 	if err := doc.DataTo(&horario); err != nil {
@@ -78,3 +119,216 @@ func (r *HorarioDisponivelRepositoryImpl) DeletarHorario(ctx context.Context, id
 	_, err := r.Client.Collection("horariosDisponiveis").Doc(id).Delete(ctx)
 	return err
 }
+
+// ListarHorariosPorSala segue a mesma ideia de ListarTurnos: o Firestore filtra
+// pelo fim e o início é conferido em memória.
+func (r *HorarioDisponivelRepositoryImpl) ListarHorariosPorSala(ctx context.Context, salaID string, de, ate time.Time) ([]*model.HorarioDisponivel, error) {
+	horarios, err := r.listarAte(ctx, r.Client.Collection("horariosDisponiveis").Where("salaId", "==", salaID).Where("fim", ">", de), ate)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar horários da sala: %w", err)
+	}
+	return horarios, nil
+}
+
+func (r *HorarioDisponivelRepositoryImpl) ListarHorariosNoPeriodo(ctx context.Context, de, ate time.Time) ([]*model.HorarioDisponivel, error) {
+	horarios, err := r.listarAte(ctx, r.Client.Collection("horariosDisponiveis").Where("fim", ">", de), ate)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar horários do período: %w", err)
+	}
+	return horarios, nil
+}
+
+// listarAte percorre a consulta descartando os horários que começam em ate ou depois.
+func (r *HorarioDisponivelRepositoryImpl) listarAte(ctx context.Context, query firestore.Query, ate time.Time) ([]*model.HorarioDisponivel, error) {
+	var horarios []*model.HorarioDisponivel
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var h model.HorarioDisponivel
+		if err := doc.DataTo(&h); err != nil {
+			continue
+		}
+		if !h.Inicio.Before(ate) {
+			continue
+		}
+		h.ID = doc.Ref.ID
+		horarios = append(horarios, &h)
+	}
+	return horarios, nil
+}
+
+func (r *HorarioDisponivelRepositoryImpl) ReservarHorario(ctx context.Context, id string, alunoID string, ate time.Time) error {
+	ref := r.Client.Collection("horariosDisponiveis").Doc(id)
+	return r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return fmt.Errorf("horário não encontrado: %w", err)
+		}
+		var horario model.HorarioDisponivel
+		if err := doc.DataTo(&horario); err != nil {
+			return err
+		}
+		if horario.Status != "disponivel" || horario.Tipo == model.HorarioGrupo {
+			return ErrHorarioIndisponivel
+		}
+		return tx.Update(ref, []firestore.Update{
+			{Path: "status", Value: "reservado"},
+			{Path: "reservadoPara", Value: alunoID},
+			{Path: "reservadoAte", Value: ate},
+		})
+	})
+}
+
+// LiberarReserva não mexe em horários que já saíram de "reservado" (por
+// exemplo, agendados pelo próprio aluno) nem nos reservados a outro aluno.
+func (r *HorarioDisponivelRepositoryImpl) LiberarReserva(ctx context.Context, id string, alunoID string) error {
+	return r.liberarReserva(ctx, id, func(horario *model.HorarioDisponivel) error {
+		if horario.Status != "reservado" || horario.ReservadoPara != alunoID {
+			return errSemMudanca
+		}
+		return nil
+	})
+}
+
+func (r *HorarioDisponivelRepositoryImpl) ExpirarReserva(ctx context.Context, id string, agora time.Time) error {
+	return r.liberarReserva(ctx, id, func(horario *model.HorarioDisponivel) error {
+		if horario.Status != "reservado" || horario.ReservadoAte.IsZero() || !horario.ReservadoAte.Before(agora) {
+			return ErrTransicaoInvalida
+		}
+		return nil
+	})
+}
+
+// errSemMudanca encerra a transação de liberarReserva sem gravar e sem erro.
+var errSemMudanca = errors.New("nada a liberar")
+
+// liberarReserva devolve o horário a "disponivel" se pode aceitar o que foi
+// lido na transação.
+func (r *HorarioDisponivelRepositoryImpl) liberarReserva(ctx context.Context, id string, pode func(*model.HorarioDisponivel) error) error {
+	ref := r.Client.Collection("horariosDisponiveis").Doc(id)
+	err := r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return fmt.Errorf("horário não encontrado: %w", err)
+		}
+		var horario model.HorarioDisponivel
+		if err := doc.DataTo(&horario); err != nil {
+			return err
+		}
+		if err := pode(&horario); err != nil {
+			return err
+		}
+		return tx.Update(ref, []firestore.Update{
+			{Path: "status", Value: "disponivel"},
+			{Path: "reservadoPara", Value: firestore.Delete},
+			{Path: "reservadoAte", Value: firestore.Delete},
+		})
+	})
+	if errors.Is(err, errSemMudanca) {
+		return nil
+	}
+	return err
+}
+
+func (r *HorarioDisponivelRepositoryImpl) ListarHorariosPorStatus(ctx context.Context, status string) ([]*model.HorarioDisponivel, error) {
+	var horarios []*model.HorarioDisponivel
+	iter := r.Client.Collection("horariosDisponiveis").Where("status", "==", status).Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao listar horários: %w", err)
+		}
+		var h model.HorarioDisponivel
+		if err := doc.DataTo(&h); err != nil {
+			continue
+		}
+		h.ID = doc.Ref.ID
+		horarios = append(horarios, &h)
+	}
+	return horarios, nil
+}
+
+func (r *HorarioDisponivelRepositoryImpl) ReconciliarStatusHorario(ctx context.Context, id string, esperado, novoStatus string) error {
+	ref := r.Client.Collection("horariosDisponiveis").Doc(id)
+	return r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		horario, consultas, err := r.lerComConsultas(tx, ref)
+		if err != nil {
+			return err
+		}
+		ocupantes, _ := ContarVagas(consultas)
+		if horario.Status != esperado || !ConfereOcupacao(novoStatus, ocupantes) {
+			return ErrTransicaoInvalida
+		}
+		updates := []firestore.Update{{Path: "status", Value: novoStatus}}
+		if esperado == "reservado" {
+			updates = append(updates,
+				firestore.Update{Path: "reservadoPara", Value: firestore.Delete},
+				firestore.Update{Path: "reservadoAte", Value: firestore.Delete},
+			)
+		}
+		return tx.Update(ref, updates)
+	})
+}
+
+func (r *HorarioDisponivelRepositoryImpl) RecontarVagasGrupo(ctx context.Context, id string, inscritosVistos, esperaVistos int) error {
+	ref := r.Client.Collection("horariosDisponiveis").Doc(id)
+	return r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		horario, consultas, err := r.lerComConsultas(tx, ref)
+		if err != nil {
+			return err
+		}
+		if horario.Tipo != model.HorarioGrupo {
+			return ErrHorarioIndisponivel
+		}
+		if horario.Inscritos != inscritosVistos || horario.ListaEspera != esperaVistos {
+			return ErrTransicaoInvalida
+		}
+		inscritos, espera := ContarVagas(consultas)
+		RecontarGrupo(horario, inscritos, espera)
+		return tx.Update(ref, []firestore.Update{
+			{Path: "inscritos", Value: horario.Inscritos},
+			{Path: "listaEspera", Value: horario.ListaEspera},
+			{Path: "status", Value: horario.Status},
+		})
+	})
+}
+
+// lerComConsultas lê o horário e as consultas dele dentro da transação, para
+// que um agendamento concorrente a faça recomeçar.
+func (r *HorarioDisponivelRepositoryImpl) lerComConsultas(tx *firestore.Transaction, ref *firestore.DocumentRef) (*model.HorarioDisponivel, []*model.Consulta, error) {
+	doc, err := tx.Get(ref)
+	if err != nil {
+		return nil, nil, fmt.Errorf("horário não encontrado: %w", err)
+	}
+	var horario model.HorarioDisponivel
+	if err := doc.DataTo(&horario); err != nil {
+		return nil, nil, err
+	}
+
+	docs, err := tx.Documents(r.Client.Collection("Consultas").Where("horarioId", "==", ref.ID)).GetAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao contar as consultas do horário: %w", err)
+	}
+	consultas := make([]*model.Consulta, 0, len(docs))
+	for _, d := range docs {
+		var c model.Consulta
+		if err := d.DataTo(&c); err != nil {
+			return nil, nil, err
+		}
+		consultas = append(consultas, &c)
+	}
+	return &horario, consultas, nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sgp/Internal/model"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+type InstrumentoRepositoryImpl struct {
+	Client *firestore.Client
+}
+
+func NewInstrumentoRepository(client *firestore.Client) *InstrumentoRepositoryImpl {
+	return &InstrumentoRepositoryImpl{Client: client}
+}
+
+func (r *InstrumentoRepositoryImpl) CriarAplicacao(ctx context.Context, aplicacao model.AplicacaoInstrumento) (*model.AplicacaoInstrumento, error) {
+	docRef, _, err := r.Client.Collection("AplicacoesInstrumento").Add(ctx, aplicacao)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar aplicação de instrumento: %w", err)
+	}
+	aplicacao.ID = docRef.ID
+	return &aplicacao, nil
+}
+
+func (r *InstrumentoRepositoryImpl) BuscarAplicacaoPorID(ctx context.Context, id string) (*model.AplicacaoInstrumento, error) {
+	doc, err := r.Client.Collection("AplicacoesInstrumento").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("aplicação não encontrada: %w", err)
+	}
+	var aplicacao model.AplicacaoInstrumento
+	if err := doc.DataTo(&aplicacao); err != nil {
+		return nil, err
+	}
+	aplicacao.ID = doc.Ref.ID
+	return &aplicacao, nil
+}
+
+// ResponderAplicacao grava as respostas numa transação que exige a aplicação
+// ainda "pendente"; se outra resposta chegou antes, retorna ErrTransicaoInvalida.
+func (r *InstrumentoRepositoryImpl) ResponderAplicacao(ctx context.Context, id string, aplicacao model.AplicacaoInstrumento) error {
+	ref := r.Client.Collection("AplicacoesInstrumento").Doc(id)
+	return r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return fmt.Errorf("aplicação não encontrada: %w", err)
+		}
+		var atual model.AplicacaoInstrumento
+		if err := doc.DataTo(&atual); err != nil {
+			return err
+		}
+		if atual.Status != "pendente" {
+			return ErrTransicaoInvalida
+		}
+		return tx.Set(ref, aplicacao)
+	})
+}
+
+// ListarAplicacoesPorAluno filtra por instrumento quando instrumentoID não for vazio.
+func (r *InstrumentoRepositoryImpl) ListarAplicacoesPorAluno(ctx context.Context, alunoID string, instrumentoID string) ([]*model.AplicacaoInstrumento, error) {
+	var aplicacoes []*model.AplicacaoInstrumento
+
+	query := r.Client.Collection("AplicacoesInstrumento").Where("alunoId", "==", alunoID)
+	if instrumentoID != "" {
+		query = query.Where("instrumentoId", "==", instrumentoID)
+	}
+
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao listar aplicações do aluno '%s': %w", alunoID, err)
+		}
+
+		var aplicacao model.AplicacaoInstrumento
+		if err := doc.DataTo(&aplicacao); err != nil {
+			fmt.Printf("erro ao converter dados do doc '%s': %v", doc.Ref.ID, err)
+			continue
+		}
+		aplicacao.ID = doc.Ref.ID
+		aplicacoes = append(aplicacoes, &aplicacao)
+	}
+	return aplicacoes, nil
+}
@@ -0,0 +1,126 @@
+package memoria
+
+import (
+	"context"
+	"fmt"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sort"
+	"time"
+)
+
+var _ repository.AlunoRepository = &AlunoRepository{}
+
+type AlunoRepository struct {
+	Banco *Banco
+}
+
+func NewAlunoRepository(banco *Banco) *AlunoRepository {
+	return &AlunoRepository{Banco: banco}
+}
+
+func (r *AlunoRepository) CriarAluno(ctx context.Context, aluno model.Aluno) (*model.Aluno, error) {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	aluno.ID = r.Banco.novoID("aluno")
+	semAnonimizacao(&aluno)
+	r.Banco.Alunos[aluno.ID] = &aluno
+	copia := aluno
+	return &copia, nil
+}
+
+func (r *AlunoRepository) CriarAlunosEmLote(ctx context.Context, alunos []model.Aluno) ([]*model.Aluno, error) {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	criados := make([]*model.Aluno, 0, len(alunos))
+	for _, aluno := range alunos {
+		aluno.ID = r.Banco.novoID("aluno")
+		semAnonimizacao(&aluno)
+		guardado := aluno
+		r.Banco.Alunos[aluno.ID] = &guardado
+		criados = append(criados, &aluno)
+	}
+	return criados, nil
+}
+
+func (r *AlunoRepository) ListarAlunos(ctx context.Context) ([]*model.Aluno, error) {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	alunos := make([]*model.Aluno, 0, len(r.Banco.Alunos))
+	for _, a := range r.Banco.Alunos {
+		copia := *a
+		alunos = append(alunos, &copia)
+	}
+	sort.Slice(alunos, func(i, j int) bool { return alunos[i].Nome < alunos[j].Nome })
+	return alunos, nil
+}
+
+func (r *AlunoRepository) BuscarAlunoPorID(ctx context.Context, id string) (*model.Aluno, error) {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	a, ok := r.Banco.Alunos[id]
+	if !ok {
+		return nil, naoEncontrado("Alunos", id)
+	}
+	copia := *a
+	return &copia, nil
+}
+
+// AtualizarAluno substitui o documento inteiro e o cria se não existir, como o
+// Set do Firestore, mas recusa um aluno anonimizado.
+func (r *AlunoRepository) AtualizarAluno(ctx context.Context, id string, aluno model.Aluno) error {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	if atual, ok := r.Banco.Alunos[id]; ok && atual.Anonimizado {
+		return repository.ErrAlunoAnonimizado
+	}
+	aluno.ID = id
+	semAnonimizacao(&aluno)
+	r.Banco.Alunos[id] = &aluno
+	return nil
+}
+
+func (r *AlunoRepository) DeletarAluno(ctx context.Context, id string) error {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	delete(r.Banco.Alunos, id)
+	return nil
+}
+
+func (r *AlunoRepository) GetAlunoIDPorNome(ctx context.Context, nome string) (string, error) {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	for id, a := range r.Banco.Alunos {
+		if a.Nome == nome {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("aluno com o nome '%s' não encontrado", nome)
+}
+
+func (r *AlunoRepository) AnonimizarAluno(ctx context.Context, id string) error {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	r.Banco.Alunos[id] = &model.Aluno{
+		ID:            id,
+		Nome:          "Titular anonimizado",
+		Anonimizado:   true,
+		AnonimizadoEm: time.Now().UTC(),
+	}
+	return nil
+}
+
+// semAnonimizacao descarta os campos de anonimização vindos de fora; como no
+// Firestore, só AnonimizarAluno os escreve.
+func semAnonimizacao(aluno *model.Aluno) {
+	aluno.Anonimizado = false
+	aluno.AnonimizadoEm = time.Time{}
+}
@@ -0,0 +1,74 @@
+package memoria
+
+import (
+	"context"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+)
+
+var _ repository.AuditoriaRepository = &AuditoriaRepository{}
+
+type AuditoriaRepository struct {
+	Banco *Banco
+}
+
+func NewAuditoriaRepository(banco *Banco) *AuditoriaRepository {
+	return &AuditoriaRepository{Banco: banco}
+}
+
+func (r *AuditoriaRepository) Registrar(ctx context.Context, registro model.RegistroAuditoria) error {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	registro.ID = r.Banco.novoID("auditoria")
+	r.Banco.Auditoria = append(r.Banco.Auditoria, &registro)
+	return nil
+}
+
+// ListarAuditoria devolve do mais recente para o mais antigo, paginando pelo
+// ID do último registro, como a implementação no Firestore.
+func (r *AuditoriaRepository) ListarAuditoria(ctx context.Context, filtro model.FiltroAuditoria) ([]*model.RegistroAuditoria, string, error) {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	limite := filtro.Limite
+	if limite <= 0 {
+		limite = repository.LimiteAuditoriaPadrao
+	}
+
+	var registros []*model.RegistroAuditoria
+	passouCursor := filtro.Cursor == ""
+	for i := len(r.Banco.Auditoria) - 1; i >= 0 && len(registros) < limite; i-- {
+		registro := r.Banco.Auditoria[i]
+		if !passouCursor {
+			passouCursor = registro.ID == filtro.Cursor
+			continue
+		}
+		if (filtro.Entidade != "" && registro.Entidade != filtro.Entidade) ||
+			(filtro.EntidadeID != "" && registro.EntidadeID != filtro.EntidadeID) ||
+			(filtro.AtorID != "" && registro.AtorID != filtro.AtorID) {
+			continue
+		}
+		copia := *registro
+		registros = append(registros, &copia)
+	}
+
+	proximoCursor := ""
+	if len(registros) == limite {
+		proximoCursor = registros[len(registros)-1].ID
+	}
+	return registros, proximoCursor, nil
+}
+
+func (r *AuditoriaRepository) OcultarDadosRegistros(ctx context.Context, entidade, entidadeID string) error {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	for _, registro := range r.Banco.Auditoria {
+		if registro.Entidade == entidade && registro.EntidadeID == entidadeID {
+			registro.Antes = nil
+			registro.Depois = nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Package memoria implementa os repositórios principais em memória, para a
+// CLI (backend "memoria"), demonstrações e testes sem Firestore. O estado
+// pode ser salvo em um arquivo JSON e carregado na próxima execução.
+package memoria
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sgp/Internal/model"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Conta faz as vezes de um usuário do Firebase Auth.
+type Conta struct {
+	UID        string `json:"uid"`
+	Email      string `json:"email"`
+	Nome       string `json:"nome"`
+	Papel      string `json:"papel,omitempty"` // custom claim "role"
+	Desativada bool   `json:"desativada,omitempty"`
+}
+
+// Banco guarda as coleções. Os repositórios que o compartilham enxergam as
+// escritas uns dos outros, como no Firestore.
+type Banco struct {
+	mu sync.Mutex
+
+	Alunos       map[string]*model.Aluno             `json:"alunos"`
+	Psicologos   map[string]*model.Psicologo         `json:"psicologos"`
+	Horarios     map[string]*model.HorarioDisponivel `json:"horarios"`
+	Consultas    map[string]*model.Consulta          `json:"consultas"`
+	Notificacoes map[string]*model.Notificacao       `json:"notificacoes"`
+	Auditoria    []*model.RegistroAuditoria          `json:"auditoria"`
+	Contas       map[string]*Conta                   `json:"contas"`
+	Sequencia    int                                 `json:"sequencia"`
+}
+
+func NovoBanco() *Banco {
+	b := &Banco{}
+	b.iniciarColecoes()
+	return b
+}
+
+// CarregarBanco lê o estado salvo por Salvar; um arquivo inexistente é um banco vazio.
+func CarregarBanco(caminho string) (*Banco, error) {
+	dados, err := os.ReadFile(caminho)
+	if errors.Is(err, os.ErrNotExist) {
+		return NovoBanco(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o banco em memória de '%s': %w", caminho, err)
+	}
+
+	b := &Banco{}
+	if err := json.Unmarshal(dados, b); err != nil {
+		return nil, fmt.Errorf("banco em memória inválido em '%s': %w", caminho, err)
+	}
+	b.iniciarColecoes()
+	return b, nil
+}
+
+func (b *Banco) Salvar(caminho string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	dados, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(caminho, dados, 0o600)
+}
+
+func (b *Banco) iniciarColecoes() {
+	if b.Alunos == nil {
+		b.Alunos = map[string]*model.Aluno{}
+	}
+	if b.Psicologos == nil {
+		b.Psicologos = map[string]*model.Psicologo{}
+	}
+	if b.Horarios == nil {
+		b.Horarios = map[string]*model.HorarioDisponivel{}
+	}
+	if b.Consultas == nil {
+		b.Consultas = map[string]*model.Consulta{}
+	}
+	if b.Notificacoes == nil {
+		b.Notificacoes = map[string]*model.Notificacao{}
+	}
+	if b.Contas == nil {
+		b.Contas = map[string]*Conta{}
+	}
+}
+
+// novoID deve ser chamado com o lock já adquirido.
+func (b *Banco) novoID(prefixo string) string {
+	b.Sequencia++
+	return fmt.Sprintf("%s-%d", prefixo, b.Sequencia)
+}
+
+// naoEncontrado imita o erro do Firestore, para que quem confere
+// codes.NotFound se comporte igual nos dois backends.
+func naoEncontrado(colecao, id string) error {
+	return status.Errorf(codes.NotFound, "%s/%s não encontrado", colecao, id)
+}
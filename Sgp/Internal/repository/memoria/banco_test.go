@@ -0,0 +1,119 @@
+package memoria
+
+import (
+	"context"
+	"path/filepath"
+	"sgp/Internal/model"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBancoSalvarECarregar(t *testing.T) {
+	ctx := context.Background()
+	caminho := filepath.Join(t.TempDir(), "estado.json")
+
+	banco, err := CarregarBanco(caminho)
+	if err != nil {
+		t.Fatalf("arquivo inexistente deveria ser um banco vazio: %v", err)
+	}
+	aluno, err := NewAlunoRepository(banco).CriarAluno(ctx, model.Aluno{Nome: "Ana", Email: "ana@uni.br"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inicio := time.Date(2025, 6, 2, 13, 0, 0, 0, time.UTC)
+	horario, err := NewHorarioDisponivelRepository(banco).CriarHorario(ctx, model.HorarioDisponivel{PsicologoID: "p1", Inicio: inicio, Fim: inicio.Add(time.Hour), Status: "disponivel"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewContas(banco).CriarConta(ctx, "ana@uni.br", "Ana", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := banco.Salvar(caminho); err != nil {
+		t.Fatal(err)
+	}
+
+	carregado, err := CarregarBanco(caminho)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, err := NewAlunoRepository(carregado).BuscarAlunoPorID(ctx, aluno.ID); err != nil || a.Email != "ana@uni.br" {
+		t.Errorf("aluno não recarregado: %+v (err=%v)", a, err)
+	}
+	if h, err := NewHorarioDisponivelRepository(carregado).BuscarHorarioPorID(ctx, horario.ID); err != nil || !h.Inicio.Equal(inicio) {
+		t.Errorf("horário não recarregado: %+v (err=%v)", h, err)
+	}
+	if len(carregado.Contas) != 1 {
+		t.Errorf("contas não recarregadas: %+v", carregado.Contas)
+	}
+
+	// A sequência continua de onde parou, sem repetir IDs.
+	outro, err := NewAlunoRepository(carregado).CriarAluno(ctx, model.Aluno{Nome: "Bia"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outro.ID == aluno.ID || outro.ID == horario.ID {
+		t.Errorf("ID repetido depois de recarregar: %s", outro.ID)
+	}
+}
+
+func TestBancoDevolveCopias(t *testing.T) {
+	ctx := context.Background()
+	banco := NovoBanco()
+	repo := NewHorarioDisponivelRepository(banco)
+
+	criado, err := repo.CriarHorario(ctx, model.HorarioDisponivel{PsicologoID: "p1", Status: "disponivel"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	criado.Status = "bloqueado"
+	listados, err := repo.ListarHorariosPorPsicologo(ctx, "p1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listados[0].Status = "bloqueado"
+
+	if h, _ := repo.BuscarHorarioPorID(ctx, criado.ID); h.Status != "disponivel" {
+		t.Errorf("alterar o retorno não deveria mudar o banco: %+v", h)
+	}
+}
+
+func TestNaoEncontradoComoNoFirestore(t *testing.T) {
+	ctx := context.Background()
+	banco := NovoBanco()
+
+	if _, err := NewConsultaRepository(banco).BuscarConsultaPorID(ctx, "nao-existe"); status.Code(err) != codes.NotFound {
+		t.Errorf("consulta inexistente: esperava NotFound, obteve %v", err)
+	}
+	if _, err := NewHorarioDisponivelRepository(banco).BuscarHorarioPorID(ctx, "nao-existe"); status.Code(err) != codes.NotFound {
+		t.Errorf("horário inexistente: esperava NotFound, obteve %v", err)
+	}
+	if err := NewContas(banco).DesativarConta(ctx, "nao-existe"); status.Code(err) != codes.NotFound {
+		t.Errorf("conta inexistente: esperava NotFound, obteve %v", err)
+	}
+}
+
+func TestContas(t *testing.T) {
+	ctx := context.Background()
+	banco := NovoBanco()
+	contas := NewContas(banco)
+
+	uid, err := contas.CriarConta(ctx, "ana@uni.br", "Ana", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := contas.CriarConta(ctx, "ana@uni.br", "Outra Ana", ""); err == nil {
+		t.Error("e-mail repetido deveria falhar, como no Firebase Auth")
+	}
+	if err := contas.DefinirPapel(ctx, uid, "psicologo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := contas.DesativarConta(ctx, uid); err != nil {
+		t.Fatal(err)
+	}
+	if conta := banco.Contas[uid]; conta.Papel != "psicologo" || !conta.Desativada {
+		t.Errorf("conta alterada incorretamente: %+v", conta)
+	}
+}
@@ -0,0 +1,238 @@
+package memoria
+
+import (
+	"context"
+	"fmt"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sort"
+	"time"
+)
+
+var _ repository.ConsultaRepository = &ConsultaRepository{}
+
+// ConsultaRepository aplica as mesmas regras de grupo.go que a implementação
+// no Firestore; os testes de contrato em repository rodam nas duas.
+type ConsultaRepository struct {
+	Banco *Banco
+
+	// AoPromover tem o mesmo papel que no Firestore; é chamado fora do lock.
+	AoPromover func(ctx context.Context, promovida model.Consulta)
+}
+
+func NewConsultaRepository(banco *Banco) *ConsultaRepository {
+	return &ConsultaRepository{Banco: banco}
+}
+
+func (r *ConsultaRepository) AgendarConsulta(ctx context.Context, consulta model.Consulta) (*model.Consulta, error) {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	horario, ok := r.Banco.Horarios[consulta.HorarioID]
+	if !ok {
+		return nil, fmt.Errorf("erro ao buscar horário para agendamento: %w", naoEncontrado("horariosDisponiveis", consulta.HorarioID))
+	}
+
+	consulta.Status = "aguardando aprovacao"
+	if horario.Tipo == model.HorarioGrupo {
+		status, err := r.inscreverNoGrupo(horario, consulta.AlunoID)
+		if err != nil {
+			return nil, err
+		}
+		consulta.Status = status
+		consulta.Grupo = true
+	} else {
+		if err := repository.OcuparHorario(horario, consulta.AlunoID); err != nil {
+			return nil, err
+		}
+	}
+
+	repository.PreencherConsulta(&consulta, horario, time.Now())
+	consulta.ID = r.Banco.novoID("consulta")
+
+	r.Banco.Consultas[consulta.ID] = &consulta
+	copia := consulta
+	return &copia, nil
+}
+
+func (r *ConsultaRepository) inscreverNoGrupo(horario *model.HorarioDisponivel, alunoID string) (string, error) {
+	for _, c := range r.Banco.Consultas {
+		if c.HorarioID == horario.ID && c.AlunoID == alunoID && repository.OcupaVaga(c.Status) {
+			return "", repository.ErrJaInscrito
+		}
+	}
+	return repository.InscreverNoGrupo(horario)
+}
+
+func (r *ConsultaRepository) AtualizaStatusConsulta(ctx context.Context, id string, novoStatus string) error {
+	return r.atualizarStatus(ctx, id, "", novoStatus)
+}
+
+func (r *ConsultaRepository) TrocarStatusConsulta(ctx context.Context, id string, esperado, novoStatus string) error {
+	return r.atualizarStatus(ctx, id, esperado, novoStatus)
+}
+
+func (r *ConsultaRepository) atualizarStatus(ctx context.Context, id string, esperado, novoStatus string) error {
+	r.Banco.mu.Lock()
+	consulta, ok := r.Banco.Consultas[id]
+	if !ok {
+		r.Banco.mu.Unlock()
+		return fmt.Errorf("erro ao atualizar status da consulta com ID '%s': %w", id, naoEncontrado("Consultas", id))
+	}
+	if esperado != "" && consulta.Status != esperado {
+		r.Banco.mu.Unlock()
+		return fmt.Errorf("erro ao atualizar status da consulta com ID '%s': %w", id, repository.ErrTransicaoInvalida)
+	}
+	if !repository.TransicaoPermitida(consulta.Status, novoStatus) {
+		r.Banco.mu.Unlock()
+		return fmt.Errorf("erro ao atualizar status da consulta com ID '%s': %w", id, repository.ErrStatusNaoPermitido)
+	}
+
+	var promovida *model.Consulta
+	if repository.LiberaVaga(consulta.Status, novoStatus) {
+		promovida = r.liberarVaga(consulta)
+	}
+	if novoStatus == "confirmada" {
+		consulta.ConfirmadaEm = time.Now().UTC()
+	}
+	consulta.Status = novoStatus
+	r.Banco.mu.Unlock()
+
+	r.avisarPromocao(ctx, promovida)
+	return nil
+}
+
+// liberarVaga segue a implementação no Firestore: o horário individual só
+// volta a "disponivel" se ainda estiver "agendado", e o de grupo pode
+// promover o primeiro da lista de espera, que é retornado.
+func (r *ConsultaRepository) liberarVaga(consulta *model.Consulta) *model.Consulta {
+	horario, ok := r.Banco.Horarios[consulta.HorarioID]
+	if !ok {
+		return nil
+	}
+	if !consulta.Grupo {
+		repository.LiberarHorario(horario)
+		return nil
+	}
+
+	var proximo *model.Consulta
+	if repository.OcupaVaga(consulta.Status) {
+		for _, c := range r.Banco.Consultas {
+			if c.ID != consulta.ID && c.HorarioID == horario.ID && c.Status == "lista de espera" && (proximo == nil || c.DataAgendamento.Before(proximo.DataAgendamento)) {
+				proximo = c
+			}
+		}
+	}
+	if !repository.LiberarVagaNoGrupo(horario, consulta.Status, proximo != nil) {
+		return nil
+	}
+	proximo.Status = "aguardando aprovacao"
+	copia := *proximo
+	return &copia
+}
+
+func (r *ConsultaRepository) avisarPromocao(ctx context.Context, promovida *model.Consulta) {
+	if promovida != nil && r.AoPromover != nil {
+		r.AoPromover(ctx, *promovida)
+	}
+}
+
+func (r *ConsultaRepository) ListarConsultasPorPsicologo(ctx context.Context, psicologoID string, statusFiltro string) ([]*model.Consulta, error) {
+	return r.filtrar(func(c *model.Consulta) bool {
+		return c.PsicologoID == psicologoID && (statusFiltro == "" || c.Status == statusFiltro)
+	}), nil
+}
+
+func (r *ConsultaRepository) ListarConsultasPorAluno(ctx context.Context, alunoID string) ([]*model.Consulta, error) {
+	return r.filtrar(func(c *model.Consulta) bool { return c.AlunoID == alunoID }), nil
+}
+
+func (r *ConsultaRepository) ListarConsultasPorStatus(ctx context.Context, status string) ([]*model.Consulta, error) {
+	return r.filtrar(func(c *model.Consulta) bool { return c.Status == status }), nil
+}
+
+func (r *ConsultaRepository) ListarConsultasNoPeriodo(ctx context.Context, statusFiltro string, de, ate time.Time) ([]*model.Consulta, error) {
+	return r.filtrar(func(c *model.Consulta) bool {
+		return (statusFiltro == "" || c.Status == statusFiltro) && c.Inicio.Before(ate) && de.Before(c.Fim)
+	}), nil
+}
+
+func (r *ConsultaRepository) DeletarConsulta(ctx context.Context, id string) error {
+	r.Banco.mu.Lock()
+	var promovida *model.Consulta
+	if c, ok := r.Banco.Consultas[id]; ok && repository.LiberaVaga(c.Status, "") {
+		promovida = r.liberarVaga(c)
+	}
+	delete(r.Banco.Consultas, id)
+	r.Banco.mu.Unlock()
+
+	r.avisarPromocao(ctx, promovida)
+	return nil
+}
+
+func (r *ConsultaRepository) BuscarConsultaPorID(ctx context.Context, id string) (*model.Consulta, error) {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	c, ok := r.Banco.Consultas[id]
+	if !ok {
+		return nil, naoEncontrado("Consultas", id)
+	}
+	copia := *c
+	return &copia, nil
+}
+
+func (r *ConsultaRepository) RegistrarPresenca(ctx context.Context, id string, esperado, novoStatus string, em time.Time) error {
+	return r.alterar(id, func(c *model.Consulta) error {
+		if c.Status != esperado {
+			return repository.ErrTransicaoInvalida
+		}
+		c.Status = novoStatus
+		switch novoStatus {
+		case "em andamento":
+			c.CheckinEm = em
+		case "concluida":
+			c.ConcluidaEm = em
+		}
+		return nil
+	})
+}
+
+func (r *ConsultaRepository) DefinirCodigoCheckin(ctx context.Context, id string, codigo string) error {
+	return r.alterar(id, func(c *model.Consulta) error { c.CodigoCheckin = codigo; return nil })
+}
+
+func (r *ConsultaRepository) DefinirLinkReuniao(ctx context.Context, id string, link string) error {
+	return r.alterar(id, func(c *model.Consulta) error { c.LinkReuniao = link; return nil })
+}
+
+func (r *ConsultaRepository) DefinirEpisodio(ctx context.Context, id string, episodioID string) error {
+	return r.alterar(id, func(c *model.Consulta) error { c.EpisodioID = episodioID; return nil })
+}
+
+func (r *ConsultaRepository) alterar(id string, mudanca func(*model.Consulta) error) error {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	c, ok := r.Banco.Consultas[id]
+	if !ok {
+		return naoEncontrado("Consultas", id)
+	}
+	return mudanca(c)
+}
+
+// filtrar devolve cópias, em ordem de início.
+func (r *ConsultaRepository) filtrar(incluir func(*model.Consulta) bool) []*model.Consulta {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	var consultas []*model.Consulta
+	for _, c := range r.Banco.Consultas {
+		if incluir(c) {
+			copia := *c
+			consultas = append(consultas, &copia)
+		}
+	}
+	sort.Slice(consultas, func(i, j int) bool { return consultas[i].Inicio.Before(consultas[j].Inicio) })
+	return consultas
+}
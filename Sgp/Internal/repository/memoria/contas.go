@@ -0,0 +1,49 @@
+package memoria
+
+import (
+	"context"
+	"fmt"
+)
+
+// Contas simula as operações do Firebase Auth usadas pela CLI.
+type Contas struct {
+	Banco *Banco
+}
+
+func NewContas(banco *Banco) *Contas {
+	return &Contas{Banco: banco}
+}
+
+func (c *Contas) CriarConta(ctx context.Context, email, nome, senha string) (string, error) {
+	c.Banco.mu.Lock()
+	defer c.Banco.mu.Unlock()
+
+	for _, conta := range c.Banco.Contas {
+		if conta.Email == email {
+			return "", fmt.Errorf("já existe uma conta com o e-mail %s", email)
+		}
+	}
+	uid := c.Banco.novoID("uid")
+	c.Banco.Contas[uid] = &Conta{UID: uid, Email: email, Nome: nome}
+	return uid, nil
+}
+
+func (c *Contas) DesativarConta(ctx context.Context, uid string) error {
+	return c.alterar(uid, func(conta *Conta) { conta.Desativada = true })
+}
+
+func (c *Contas) DefinirPapel(ctx context.Context, uid, papel string) error {
+	return c.alterar(uid, func(conta *Conta) { conta.Papel = papel })
+}
+
+func (c *Contas) alterar(uid string, mudanca func(*Conta)) error {
+	c.Banco.mu.Lock()
+	defer c.Banco.mu.Unlock()
+
+	conta, ok := c.Banco.Contas[uid]
+	if !ok {
+		return naoEncontrado("contas", uid)
+	}
+	mudanca(conta)
+	return nil
+}
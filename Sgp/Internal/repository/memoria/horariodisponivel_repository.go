@@ -0,0 +1,204 @@
+package memoria
+
+import (
+	"context"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sort"
+	"time"
+)
+
+var _ repository.HorarioDisponivelRepository = &HorarioDisponivelRepository{}
+
+type HorarioDisponivelRepository struct {
+	Banco *Banco
+}
+
+func NewHorarioDisponivelRepository(banco *Banco) *HorarioDisponivelRepository {
+	return &HorarioDisponivelRepository{Banco: banco}
+}
+
+func (r *HorarioDisponivelRepository) CriarHorario(ctx context.Context, horario model.HorarioDisponivel) (*model.HorarioDisponivel, error) {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	if horario.SalaID != "" {
+		for _, existente := range r.Banco.Horarios {
+			if existente.SalaID == horario.SalaID && existente.Fim.After(horario.Inicio) && existente.Inicio.Before(horario.Fim) {
+				return nil, repository.ErrConflitoSala
+			}
+		}
+	}
+
+	horario.ID = r.Banco.novoID("horario")
+	r.Banco.Horarios[horario.ID] = &horario
+	copia := horario
+	return &copia, nil
+}
+
+func (r *HorarioDisponivelRepository) ListarHorariosPorPsicologo(ctx context.Context, psicologoID string, status string) ([]*model.HorarioDisponivel, error) {
+	return r.filtrar(func(h *model.HorarioDisponivel) bool {
+		return h.PsicologoID == psicologoID && (status == "" || h.Status == status)
+	}), nil
+}
+
+func (r *HorarioDisponivelRepository) BuscarHorarioPorID(ctx context.Context, id string) (*model.HorarioDisponivel, error) {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	h, ok := r.Banco.Horarios[id]
+	if !ok {
+		return nil, naoEncontrado("horariosDisponiveis", id)
+	}
+	copia := *h
+	return &copia, nil
+}
+
+func (r *HorarioDisponivelRepository) AtualizarStatusHorario(ctx context.Context, id string, novoStatus string) error {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	h, ok := r.Banco.Horarios[id]
+	if !ok {
+		return naoEncontrado("horariosDisponiveis", id)
+	}
+	h.Status = novoStatus
+	return nil
+}
+
+func (r *HorarioDisponivelRepository) DeletarHorario(ctx context.Context, id string) error {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	delete(r.Banco.Horarios, id)
+	return nil
+}
+
+func (r *HorarioDisponivelRepository) ListarHorariosPorStatus(ctx context.Context, status string) ([]*model.HorarioDisponivel, error) {
+	return r.filtrar(func(h *model.HorarioDisponivel) bool { return h.Status == status }), nil
+}
+
+func (r *HorarioDisponivelRepository) ReconciliarStatusHorario(ctx context.Context, id string, esperado, novoStatus string) error {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	h, ok := r.Banco.Horarios[id]
+	if !ok {
+		return naoEncontrado("horariosDisponiveis", id)
+	}
+	ocupantes, _ := repository.ContarVagas(r.consultasDo(id))
+	if h.Status != esperado || !repository.ConfereOcupacao(novoStatus, ocupantes) {
+		return repository.ErrTransicaoInvalida
+	}
+	if esperado == "reservado" {
+		liberarReserva(h)
+	}
+	h.Status = novoStatus
+	return nil
+}
+
+func (r *HorarioDisponivelRepository) RecontarVagasGrupo(ctx context.Context, id string, inscritosVistos, esperaVistos int) error {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	h, ok := r.Banco.Horarios[id]
+	if !ok {
+		return naoEncontrado("horariosDisponiveis", id)
+	}
+	if h.Tipo != model.HorarioGrupo {
+		return repository.ErrHorarioIndisponivel
+	}
+	if h.Inscritos != inscritosVistos || h.ListaEspera != esperaVistos {
+		return repository.ErrTransicaoInvalida
+	}
+	inscritos, espera := repository.ContarVagas(r.consultasDo(id))
+	repository.RecontarGrupo(h, inscritos, espera)
+	return nil
+}
+
+// consultasDo deve ser chamado com o lock já adquirido.
+func (r *HorarioDisponivelRepository) consultasDo(horarioID string) []*model.Consulta {
+	var consultas []*model.Consulta
+	for _, c := range r.Banco.Consultas {
+		if c.HorarioID == horarioID {
+			consultas = append(consultas, c)
+		}
+	}
+	return consultas
+}
+
+func (r *HorarioDisponivelRepository) ListarHorariosPorSala(ctx context.Context, salaID string, de, ate time.Time) ([]*model.HorarioDisponivel, error) {
+	return r.filtrar(func(h *model.HorarioDisponivel) bool {
+		return h.SalaID == salaID && h.Fim.After(de) && h.Inicio.Before(ate)
+	}), nil
+}
+
+func (r *HorarioDisponivelRepository) ListarHorariosNoPeriodo(ctx context.Context, de, ate time.Time) ([]*model.HorarioDisponivel, error) {
+	return r.filtrar(func(h *model.HorarioDisponivel) bool {
+		return h.Fim.After(de) && h.Inicio.Before(ate)
+	}), nil
+}
+
+func (r *HorarioDisponivelRepository) ReservarHorario(ctx context.Context, id string, alunoID string, ate time.Time) error {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	h, ok := r.Banco.Horarios[id]
+	if !ok {
+		return naoEncontrado("horariosDisponiveis", id)
+	}
+	if h.Status != "disponivel" || h.Tipo == model.HorarioGrupo {
+		return repository.ErrHorarioIndisponivel
+	}
+	h.Status = "reservado"
+	h.ReservadoPara = alunoID
+	h.ReservadoAte = ate
+	return nil
+}
+
+func (r *HorarioDisponivelRepository) LiberarReserva(ctx context.Context, id string, alunoID string) error {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	if h, ok := r.Banco.Horarios[id]; ok && h.Status == "reservado" && h.ReservadoPara == alunoID {
+		liberarReserva(h)
+	}
+	return nil
+}
+
+func (r *HorarioDisponivelRepository) ExpirarReserva(ctx context.Context, id string, agora time.Time) error {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	h, ok := r.Banco.Horarios[id]
+	if !ok {
+		return naoEncontrado("horariosDisponiveis", id)
+	}
+	if h.Status != "reservado" || h.ReservadoAte.IsZero() || !h.ReservadoAte.Before(agora) {
+		return repository.ErrTransicaoInvalida
+	}
+	liberarReserva(h)
+	return nil
+}
+
+func liberarReserva(h *model.HorarioDisponivel) {
+	h.Status = "disponivel"
+	h.ReservadoPara = ""
+	h.ReservadoAte = time.Time{}
+}
+
+// filtrar devolve cópias, em ordem de início.
+func (r *HorarioDisponivelRepository) filtrar(incluir func(*model.HorarioDisponivel) bool) []*model.HorarioDisponivel {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	var horarios []*model.HorarioDisponivel
+	for _, h := range r.Banco.Horarios {
+		if incluir(h) {
+			copia := *h
+			horarios = append(horarios, &copia)
+		}
+	}
+	sort.Slice(horarios, func(i, j int) bool { return horarios[i].Inicio.Before(horarios[j].Inicio) })
+	return horarios
+}
@@ -0,0 +1,61 @@
+package memoria
+
+import (
+	"context"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sort"
+)
+
+var _ repository.NotificacaoRepository = &NotificacaoRepository{}
+
+type NotificacaoRepository struct {
+	Banco *Banco
+}
+
+func NewNotificacaoRepository(banco *Banco) *NotificacaoRepository {
+	return &NotificacaoRepository{Banco: banco}
+}
+
+func (r *NotificacaoRepository) RegistrarNotificacao(ctx context.Context, notificacao model.Notificacao) (*model.Notificacao, error) {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	notificacao.ID = r.Banco.novoID("notificacao")
+	r.Banco.Notificacoes[notificacao.ID] = &notificacao
+	copia := notificacao
+	return &copia, nil
+}
+
+func (r *NotificacaoRepository) AtualizarNotificacao(ctx context.Context, id string, notificacao model.Notificacao) error {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	notificacao.ID = id
+	r.Banco.Notificacoes[id] = &notificacao
+	return nil
+}
+
+func (r *NotificacaoRepository) ListarNotificacoesPorAluno(ctx context.Context, alunoID string) ([]*model.Notificacao, error) {
+	return r.filtrar(func(n *model.Notificacao) bool { return n.AlunoID == alunoID }), nil
+}
+
+func (r *NotificacaoRepository) ListarNotificacoesPorStatus(ctx context.Context, status string) ([]*model.Notificacao, error) {
+	return r.filtrar(func(n *model.Notificacao) bool { return n.Status == status }), nil
+}
+
+// filtrar devolve cópias, das mais antigas para as mais recentes.
+func (r *NotificacaoRepository) filtrar(incluir func(*model.Notificacao) bool) []*model.Notificacao {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	var notificacoes []*model.Notificacao
+	for _, n := range r.Banco.Notificacoes {
+		if incluir(n) {
+			copia := *n
+			notificacoes = append(notificacoes, &copia)
+		}
+	}
+	sort.Slice(notificacoes, func(i, j int) bool { return notificacoes[i].CriadoEm.Before(notificacoes[j].CriadoEm) })
+	return notificacoes
+}
@@ -0,0 +1,98 @@
+package memoria
+
+import (
+	"context"
+	"fmt"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sort"
+)
+
+var _ repository.PsicologoRepository = &PsicologoRepository{}
+
+type PsicologoRepository struct {
+	Banco *Banco
+}
+
+func NewPsicologoRepository(banco *Banco) *PsicologoRepository {
+	return &PsicologoRepository{Banco: banco}
+}
+
+func (r *PsicologoRepository) CriarPsicologo(ctx context.Context, psicologo model.Psicologo) (*model.Psicologo, error) {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	psicologo.ID = r.Banco.novoID("psicologo")
+	r.Banco.Psicologos[psicologo.ID] = &psicologo
+	copia := psicologo
+	return &copia, nil
+}
+
+func (r *PsicologoRepository) CriarPsicologosEmLote(ctx context.Context, psicologos []model.Psicologo) ([]*model.Psicologo, error) {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	criados := make([]*model.Psicologo, 0, len(psicologos))
+	for _, psicologo := range psicologos {
+		psicologo.ID = r.Banco.novoID("psicologo")
+		guardado := psicologo
+		r.Banco.Psicologos[psicologo.ID] = &guardado
+		criados = append(criados, &psicologo)
+	}
+	return criados, nil
+}
+
+func (r *PsicologoRepository) ListarPsicologos(ctx context.Context) ([]*model.Psicologo, error) {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	psicologos := make([]*model.Psicologo, 0, len(r.Banco.Psicologos))
+	for _, p := range r.Banco.Psicologos {
+		copia := *p
+		psicologos = append(psicologos, &copia)
+	}
+	sort.Slice(psicologos, func(i, j int) bool { return psicologos[i].Nome < psicologos[j].Nome })
+	return psicologos, nil
+}
+
+func (r *PsicologoRepository) BuscarPsicologoPorID(ctx context.Context, id string) (*model.Psicologo, error) {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	p, ok := r.Banco.Psicologos[id]
+	if !ok {
+		return nil, naoEncontrado("Psicologos", id)
+	}
+	copia := *p
+	return &copia, nil
+}
+
+// AtualizarPsicologo substitui o documento inteiro e o cria se não existir, como o Set do Firestore.
+func (r *PsicologoRepository) AtualizarPsicologo(ctx context.Context, id string, psicologo model.Psicologo) error {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	psicologo.ID = id
+	r.Banco.Psicologos[id] = &psicologo
+	return nil
+}
+
+func (r *PsicologoRepository) DeletarPsicologo(ctx context.Context, id string) error {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	delete(r.Banco.Psicologos, id)
+	return nil
+}
+
+func (r *PsicologoRepository) GetPsicologoIDPorNome(ctx context.Context, nome string) (string, error) {
+	r.Banco.mu.Lock()
+	defer r.Banco.mu.Unlock()
+
+	for id, p := range r.Banco.Psicologos {
+		if p.Nome == nome {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("psicólogo com o nome '%s' não encontrado", nome)
+}
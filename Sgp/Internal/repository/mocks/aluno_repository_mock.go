@@ -14,6 +14,8 @@ type AlunoRepositoryMock struct{
 	BuscarAlunoPorIDFunc func(ctx context.Context, id string)(*model.Aluno, error)
 	DeletarAlunoFunc func(ctx context.Context, id string)(error)
 	GetAlunoIDPorNomeFunc func(ctx context.Context, nome string)(string, error)
+	AnonimizarAlunoFunc func(ctx context.Context, id string)(error)
+	CriarAlunosEmLoteFunc func(ctx context.Context, alunos []model.Aluno)([]*model.Aluno, error)
 }
 
 
@@ -39,4 +41,12 @@ func (m *AlunoRepositoryMock) DeletarAluno(ctx context.Context, id string) error
 
 func (m *AlunoRepositoryMock) GetAlunoIDPorNome(ctx context.Context, nome string) (string, error) {
 	return m.GetAlunoIDPorNomeFunc(ctx, nome)
-}
\ No newline at end of file
+}
+
+func (m *AlunoRepositoryMock) AnonimizarAluno(ctx context.Context, id string) error {
+	return m.AnonimizarAlunoFunc(ctx, id)
+}
+
+func (m *AlunoRepositoryMock) CriarAlunosEmLote(ctx context.Context, alunos []model.Aluno) ([]*model.Aluno, error) {
+	return m.CriarAlunosEmLoteFunc(ctx, alunos)
+}
@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+)
+
+var _ repository.AuditoriaRepository = &AuditoriaRepositoryMock{}
+
+type AuditoriaRepositoryMock struct {
+	RegistrarFunc             func(ctx context.Context, registro model.RegistroAuditoria) error
+	ListarAuditoriaFunc       func(ctx context.Context, filtro model.FiltroAuditoria) ([]*model.RegistroAuditoria, string, error)
+	OcultarDadosRegistrosFunc func(ctx context.Context, entidade, entidadeID string) error
+}
+
+func (m *AuditoriaRepositoryMock) Registrar(ctx context.Context, registro model.RegistroAuditoria) error {
+	return m.RegistrarFunc(ctx, registro)
+}
+
+func (m *AuditoriaRepositoryMock) ListarAuditoria(ctx context.Context, filtro model.FiltroAuditoria) ([]*model.RegistroAuditoria, string, error) {
+	return m.ListarAuditoriaFunc(ctx, filtro)
+}
+
+func (m *AuditoriaRepositoryMock) OcultarDadosRegistros(ctx context.Context, entidade, entidadeID string) error {
+	return m.OcultarDadosRegistrosFunc(ctx, entidade, entidadeID)
+}
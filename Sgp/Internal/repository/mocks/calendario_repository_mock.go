@@ -0,0 +1,28 @@
+package mocks
+
+import (
+	"context"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"time"
+)
+
+var _ repository.CalendarioRepository = &CalendarioRepositoryMock{}
+
+type CalendarioRepositoryMock struct {
+	CriarEventoFunc   func(ctx context.Context, evento model.EventoCalendario) (*model.EventoCalendario, error)
+	ListarEventosFunc func(ctx context.Context, de, ate time.Time) ([]*model.EventoCalendario, error)
+	DeletarEventoFunc func(ctx context.Context, id string) error
+}
+
+func (m *CalendarioRepositoryMock) CriarEvento(ctx context.Context, evento model.EventoCalendario) (*model.EventoCalendario, error) {
+	return m.CriarEventoFunc(ctx, evento)
+}
+
+func (m *CalendarioRepositoryMock) ListarEventos(ctx context.Context, de, ate time.Time) ([]*model.EventoCalendario, error) {
+	return m.ListarEventosFunc(ctx, de, ate)
+}
+
+func (m *CalendarioRepositoryMock) DeletarEvento(ctx context.Context, id string) error {
+	return m.DeletarEventoFunc(ctx, id)
+}
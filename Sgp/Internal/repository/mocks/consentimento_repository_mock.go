@@ -0,0 +1,37 @@
+package mocks
+
+import (
+	"context"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+)
+
+var _ repository.ConsentimentoRepository = &ConsentimentoRepositoryMock{}
+
+type ConsentimentoRepositoryMock struct {
+	PublicarDocumentoFunc      func(ctx context.Context, d model.DocumentoConsentimento) (*model.DocumentoConsentimento, error)
+	BuscarDocumentoVigenteFunc func(ctx context.Context) (*model.DocumentoConsentimento, error)
+	RegistrarAceiteFunc        func(ctx context.Context, a model.AceiteConsentimento) (*model.AceiteConsentimento, error)
+	BuscarAceiteFunc           func(ctx context.Context, alunoID string, versao int) (*model.AceiteConsentimento, error)
+	ListarAceitesPorAlunoFunc  func(ctx context.Context, alunoID string) ([]*model.AceiteConsentimento, error)
+}
+
+func (m *ConsentimentoRepositoryMock) PublicarDocumento(ctx context.Context, d model.DocumentoConsentimento) (*model.DocumentoConsentimento, error) {
+	return m.PublicarDocumentoFunc(ctx, d)
+}
+
+func (m *ConsentimentoRepositoryMock) BuscarDocumentoVigente(ctx context.Context) (*model.DocumentoConsentimento, error) {
+	return m.BuscarDocumentoVigenteFunc(ctx)
+}
+
+func (m *ConsentimentoRepositoryMock) RegistrarAceite(ctx context.Context, a model.AceiteConsentimento) (*model.AceiteConsentimento, error) {
+	return m.RegistrarAceiteFunc(ctx, a)
+}
+
+func (m *ConsentimentoRepositoryMock) BuscarAceite(ctx context.Context, alunoID string, versao int) (*model.AceiteConsentimento, error) {
+	return m.BuscarAceiteFunc(ctx, alunoID, versao)
+}
+
+func (m *ConsentimentoRepositoryMock) ListarAceitesPorAluno(ctx context.Context, alunoID string) ([]*model.AceiteConsentimento, error) {
+	return m.ListarAceitesPorAlunoFunc(ctx, alunoID)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"sgp/Internal/model"
 	"sgp/Internal/repository"
+	"time"
 )
 
 var _ repository.ConsultaRepository = &ConsultaRepositoryMock{}
@@ -11,10 +12,17 @@ var _ repository.ConsultaRepository = &ConsultaRepositoryMock{}
 type ConsultaRepositoryMock struct {
 	AgendarConsultaFunc             func(ctx context.Context, consulta model.Consulta) (*model.Consulta, error)
 	AtualizaStatusConsultaFunc      func(ctx context.Context, id string, novoStatus string) error
+	TrocarStatusConsultaFunc        func(ctx context.Context, id string, esperado, novoStatus string) error
 	ListarConsultasPorPsicologoFunc func(ctx context.Context, psicologoID string, statusFiltro string) ([]*model.Consulta, error)
 	ListarConsultasPorAlunoFunc     func(ctx context.Context, alunoID string) ([]*model.Consulta, error)
 	DeletarConsultaFunc             func(ctx context.Context, id string) error
 	BuscarConsultaPorIDFunc         func(ctx context.Context, id string) (*model.Consulta, error)
+	ListarConsultasPorStatusFunc    func(ctx context.Context, status string) ([]*model.Consulta, error)
+	ListarConsultasNoPeriodoFunc    func(ctx context.Context, statusFiltro string, de, ate time.Time) ([]*model.Consulta, error)
+	RegistrarPresencaFunc           func(ctx context.Context, id string, esperado, novoStatus string, em time.Time) error
+	DefinirCodigoCheckinFunc        func(ctx context.Context, id string, codigo string) error
+	DefinirLinkReuniaoFunc          func(ctx context.Context, id string, link string) error
+	DefinirEpisodioFunc             func(ctx context.Context, id string, episodioID string) error
 }
 
 func (m *ConsultaRepositoryMock) AgendarConsulta(ctx context.Context, c model.Consulta) (*model.Consulta, error) {
@@ -25,6 +33,10 @@ func (m *ConsultaRepositoryMock) AtualizaStatusConsulta(ctx context.Context, id
 	return m.AtualizaStatusConsultaFunc(ctx, id, s)
 }
 
+func (m *ConsultaRepositoryMock) TrocarStatusConsulta(ctx context.Context, id string, esperado, novoStatus string) error {
+	return m.TrocarStatusConsultaFunc(ctx, id, esperado, novoStatus)
+}
+
 func (m *ConsultaRepositoryMock) ListarConsultasPorPsicologo(ctx context.Context, pID string, sF string) ([]*model.Consulta, error) {
 	return m.ListarConsultasPorPsicologoFunc(ctx, pID, sF)
 }
@@ -39,4 +51,28 @@ func (m *ConsultaRepositoryMock) DeletarConsulta(ctx context.Context, id string)
 
 func (m *ConsultaRepositoryMock) BuscarConsultaPorID(ctx context.Context, id string) (*model.Consulta, error) {
 	return m.BuscarConsultaPorIDFunc(ctx, id)
-}
\ No newline at end of file
+}
+
+func (m *ConsultaRepositoryMock) ListarConsultasPorStatus(ctx context.Context, status string) ([]*model.Consulta, error) {
+	return m.ListarConsultasPorStatusFunc(ctx, status)
+}
+
+func (m *ConsultaRepositoryMock) ListarConsultasNoPeriodo(ctx context.Context, statusFiltro string, de, ate time.Time) ([]*model.Consulta, error) {
+	return m.ListarConsultasNoPeriodoFunc(ctx, statusFiltro, de, ate)
+}
+
+func (m *ConsultaRepositoryMock) RegistrarPresenca(ctx context.Context, id string, esperado, novoStatus string, em time.Time) error {
+	return m.RegistrarPresencaFunc(ctx, id, esperado, novoStatus, em)
+}
+
+func (m *ConsultaRepositoryMock) DefinirCodigoCheckin(ctx context.Context, id string, codigo string) error {
+	return m.DefinirCodigoCheckinFunc(ctx, id, codigo)
+}
+
+func (m *ConsultaRepositoryMock) DefinirLinkReuniao(ctx context.Context, id string, link string) error {
+	return m.DefinirLinkReuniaoFunc(ctx, id, link)
+}
+
+func (m *ConsultaRepositoryMock) DefinirEpisodio(ctx context.Context, id string, episodioID string) error {
+	return m.DefinirEpisodioFunc(ctx, id, episodioID)
+}
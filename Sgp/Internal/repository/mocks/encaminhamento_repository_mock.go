@@ -0,0 +1,37 @@
+package mocks
+
+import (
+	"context"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+)
+
+var _ repository.EncaminhamentoRepository = &EncaminhamentoRepositoryMock{}
+
+type EncaminhamentoRepositoryMock struct {
+	CriarEncaminhamentoFunc               func(ctx context.Context, encaminhamento model.Encaminhamento) (*model.Encaminhamento, error)
+	BuscarEncaminhamentoPorIDFunc         func(ctx context.Context, id string) (*model.Encaminhamento, error)
+	AtualizarEncaminhamentoFunc           func(ctx context.Context, id string, esperado string, encaminhamento model.Encaminhamento) error
+	ListarEncaminhamentosPorPsicologoFunc func(ctx context.Context, psicologoID string) ([]*model.Encaminhamento, error)
+	ListarEncaminhamentosPorAlunoFunc     func(ctx context.Context, alunoID string) ([]*model.Encaminhamento, error)
+}
+
+func (m *EncaminhamentoRepositoryMock) CriarEncaminhamento(ctx context.Context, encaminhamento model.Encaminhamento) (*model.Encaminhamento, error) {
+	return m.CriarEncaminhamentoFunc(ctx, encaminhamento)
+}
+
+func (m *EncaminhamentoRepositoryMock) BuscarEncaminhamentoPorID(ctx context.Context, id string) (*model.Encaminhamento, error) {
+	return m.BuscarEncaminhamentoPorIDFunc(ctx, id)
+}
+
+func (m *EncaminhamentoRepositoryMock) AtualizarEncaminhamento(ctx context.Context, id string, esperado string, encaminhamento model.Encaminhamento) error {
+	return m.AtualizarEncaminhamentoFunc(ctx, id, esperado, encaminhamento)
+}
+
+func (m *EncaminhamentoRepositoryMock) ListarEncaminhamentosPorPsicologo(ctx context.Context, psicologoID string) ([]*model.Encaminhamento, error) {
+	return m.ListarEncaminhamentosPorPsicologoFunc(ctx, psicologoID)
+}
+
+func (m *EncaminhamentoRepositoryMock) ListarEncaminhamentosPorAluno(ctx context.Context, alunoID string) ([]*model.Encaminhamento, error) {
+	return m.ListarEncaminhamentosPorAlunoFunc(ctx, alunoID)
+}
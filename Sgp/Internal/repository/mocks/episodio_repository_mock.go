@@ -0,0 +1,42 @@
+package mocks
+
+import (
+	"context"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+)
+
+var _ repository.EpisodioRepository = &EpisodioRepositoryMock{}
+
+type EpisodioRepositoryMock struct {
+	AbrirEpisodioFunc           func(ctx context.Context, episodio model.Episodio) (*model.Episodio, error)
+	AssumirEpisodioFunc         func(ctx context.Context, alunoID string, transferencia model.TransferenciaEpisodio) (*model.Episodio, error)
+	BuscarEpisodioPorIDFunc     func(ctx context.Context, id string) (*model.Episodio, error)
+	BuscarEpisodioAbertoFunc    func(ctx context.Context, alunoID string) (*model.Episodio, error)
+	ListarEpisodiosPorAlunoFunc func(ctx context.Context, alunoID string) ([]*model.Episodio, error)
+	AtualizarEpisodioFunc       func(ctx context.Context, id string, psicologoEsperado string, episodio model.Episodio) error
+}
+
+func (m *EpisodioRepositoryMock) AbrirEpisodio(ctx context.Context, episodio model.Episodio) (*model.Episodio, error) {
+	return m.AbrirEpisodioFunc(ctx, episodio)
+}
+
+func (m *EpisodioRepositoryMock) AssumirEpisodio(ctx context.Context, alunoID string, transferencia model.TransferenciaEpisodio) (*model.Episodio, error) {
+	return m.AssumirEpisodioFunc(ctx, alunoID, transferencia)
+}
+
+func (m *EpisodioRepositoryMock) BuscarEpisodioPorID(ctx context.Context, id string) (*model.Episodio, error) {
+	return m.BuscarEpisodioPorIDFunc(ctx, id)
+}
+
+func (m *EpisodioRepositoryMock) BuscarEpisodioAberto(ctx context.Context, alunoID string) (*model.Episodio, error) {
+	return m.BuscarEpisodioAbertoFunc(ctx, alunoID)
+}
+
+func (m *EpisodioRepositoryMock) ListarEpisodiosPorAluno(ctx context.Context, alunoID string) ([]*model.Episodio, error) {
+	return m.ListarEpisodiosPorAlunoFunc(ctx, alunoID)
+}
+
+func (m *EpisodioRepositoryMock) AtualizarEpisodio(ctx context.Context, id string, psicologoEsperado string, episodio model.Episodio) error {
+	return m.AtualizarEpisodioFunc(ctx, id, psicologoEsperado, episodio)
+}
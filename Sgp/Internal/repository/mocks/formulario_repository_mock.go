@@ -0,0 +1,37 @@
+package mocks
+
+import (
+	"context"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+)
+
+var _ repository.FormularioRepository = &FormularioRepositoryMock{}
+
+type FormularioRepositoryMock struct {
+	SalvarFormularioFunc             func(ctx context.Context, f model.FormularioInicial) (*model.FormularioInicial, error)
+	BuscarFormularioPorPsicologoFunc func(ctx context.Context, psicologoID string) (*model.FormularioInicial, error)
+	RegistrarRespostaFunc            func(ctx context.Context, r model.RespostaFormulario) (*model.RespostaFormulario, error)
+	BuscarRespostaPorConsultaFunc    func(ctx context.Context, consultaID string) (*model.RespostaFormulario, error)
+	ListarRespostasPorAlunoFunc      func(ctx context.Context, alunoID string) ([]*model.RespostaFormulario, error)
+}
+
+func (m *FormularioRepositoryMock) SalvarFormulario(ctx context.Context, f model.FormularioInicial) (*model.FormularioInicial, error) {
+	return m.SalvarFormularioFunc(ctx, f)
+}
+
+func (m *FormularioRepositoryMock) BuscarFormularioPorPsicologo(ctx context.Context, psicologoID string) (*model.FormularioInicial, error) {
+	return m.BuscarFormularioPorPsicologoFunc(ctx, psicologoID)
+}
+
+func (m *FormularioRepositoryMock) RegistrarResposta(ctx context.Context, r model.RespostaFormulario) (*model.RespostaFormulario, error) {
+	return m.RegistrarRespostaFunc(ctx, r)
+}
+
+func (m *FormularioRepositoryMock) BuscarRespostaPorConsulta(ctx context.Context, consultaID string) (*model.RespostaFormulario, error) {
+	return m.BuscarRespostaPorConsultaFunc(ctx, consultaID)
+}
+
+func (m *FormularioRepositoryMock) ListarRespostasPorAluno(ctx context.Context, alunoID string) ([]*model.RespostaFormulario, error) {
+	return m.ListarRespostasPorAlunoFunc(ctx, alunoID)
+}
@@ -0,0 +1,78 @@
+package mocks
+
+import (
+	"context"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"time"
+)
+
+var _ repository.HorarioDisponivelRepository = &HorarioDisponivelRepositoryMock{}
+
+type HorarioDisponivelRepositoryMock struct {
+	CriarHorarioFunc               func(ctx context.Context, horario model.HorarioDisponivel) (*model.HorarioDisponivel, error)
+	ListarHorariosPorPsicologoFunc func(ctx context.Context, psicologoID string, status string) ([]*model.HorarioDisponivel, error)
+	BuscarHorarioPorIDFunc         func(ctx context.Context, id string) (*model.HorarioDisponivel, error)
+	AtualizarStatusHorarioFunc     func(ctx context.Context, id string, novoStatus string) error
+	DeletarHorarioFunc             func(ctx context.Context, id string) error
+	ListarHorariosPorSalaFunc      func(ctx context.Context, salaID string, de, ate time.Time) ([]*model.HorarioDisponivel, error)
+	ListarHorariosNoPeriodoFunc    func(ctx context.Context, de, ate time.Time) ([]*model.HorarioDisponivel, error)
+	ReservarHorarioFunc            func(ctx context.Context, id string, alunoID string, ate time.Time) error
+	LiberarReservaFunc             func(ctx context.Context, id string, alunoID string) error
+	ExpirarReservaFunc             func(ctx context.Context, id string, agora time.Time) error
+	ListarHorariosPorStatusFunc    func(ctx context.Context, status string) ([]*model.HorarioDisponivel, error)
+	ReconciliarStatusHorarioFunc   func(ctx context.Context, id string, esperado, novoStatus string) error
+	RecontarVagasGrupoFunc         func(ctx context.Context, id string, inscritosVistos, esperaVistos int) error
+}
+
+func (m *HorarioDisponivelRepositoryMock) CriarHorario(ctx context.Context, h model.HorarioDisponivel) (*model.HorarioDisponivel, error) {
+	return m.CriarHorarioFunc(ctx, h)
+}
+
+func (m *HorarioDisponivelRepositoryMock) ListarHorariosPorPsicologo(ctx context.Context, pID string, s string) ([]*model.HorarioDisponivel, error) {
+	return m.ListarHorariosPorPsicologoFunc(ctx, pID, s)
+}
+
+func (m *HorarioDisponivelRepositoryMock) BuscarHorarioPorID(ctx context.Context, id string) (*model.HorarioDisponivel, error) {
+	return m.BuscarHorarioPorIDFunc(ctx, id)
+}
+
+func (m *HorarioDisponivelRepositoryMock) AtualizarStatusHorario(ctx context.Context, id string, s string) error {
+	return m.AtualizarStatusHorarioFunc(ctx, id, s)
+}
+
+func (m *HorarioDisponivelRepositoryMock) DeletarHorario(ctx context.Context, id string) error {
+	return m.DeletarHorarioFunc(ctx, id)
+}
+
+func (m *HorarioDisponivelRepositoryMock) ListarHorariosPorSala(ctx context.Context, salaID string, de, ate time.Time) ([]*model.HorarioDisponivel, error) {
+	return m.ListarHorariosPorSalaFunc(ctx, salaID, de, ate)
+}
+
+func (m *HorarioDisponivelRepositoryMock) ListarHorariosNoPeriodo(ctx context.Context, de, ate time.Time) ([]*model.HorarioDisponivel, error) {
+	return m.ListarHorariosNoPeriodoFunc(ctx, de, ate)
+}
+
+func (m *HorarioDisponivelRepositoryMock) ReservarHorario(ctx context.Context, id string, alunoID string, ate time.Time) error {
+	return m.ReservarHorarioFunc(ctx, id, alunoID, ate)
+}
+
+func (m *HorarioDisponivelRepositoryMock) LiberarReserva(ctx context.Context, id string, alunoID string) error {
+	return m.LiberarReservaFunc(ctx, id, alunoID)
+}
+
+func (m *HorarioDisponivelRepositoryMock) ExpirarReserva(ctx context.Context, id string, agora time.Time) error {
+	return m.ExpirarReservaFunc(ctx, id, agora)
+}
+
+func (m *HorarioDisponivelRepositoryMock) ListarHorariosPorStatus(ctx context.Context, status string) ([]*model.HorarioDisponivel, error) {
+	return m.ListarHorariosPorStatusFunc(ctx, status)
+}
+
+func (m *HorarioDisponivelRepositoryMock) ReconciliarStatusHorario(ctx context.Context, id string, esperado, novoStatus string) error {
+	return m.ReconciliarStatusHorarioFunc(ctx, id, esperado, novoStatus)
+}
+
+func (m *HorarioDisponivelRepositoryMock) RecontarVagasGrupo(ctx context.Context, id string, inscritosVistos, esperaVistos int) error {
+	return m.RecontarVagasGrupoFunc(ctx, id, inscritosVistos, esperaVistos)
+}
@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"context"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+)
+
+var _ repository.InstrumentoRepository = &InstrumentoRepositoryMock{}
+
+type InstrumentoRepositoryMock struct {
+	CriarAplicacaoFunc           func(ctx context.Context, a model.AplicacaoInstrumento) (*model.AplicacaoInstrumento, error)
+	BuscarAplicacaoPorIDFunc     func(ctx context.Context, id string) (*model.AplicacaoInstrumento, error)
+	ResponderAplicacaoFunc       func(ctx context.Context, id string, a model.AplicacaoInstrumento) error
+	ListarAplicacoesPorAlunoFunc func(ctx context.Context, alunoID string, instrumentoID string) ([]*model.AplicacaoInstrumento, error)
+}
+
+func (m *InstrumentoRepositoryMock) CriarAplicacao(ctx context.Context, a model.AplicacaoInstrumento) (*model.AplicacaoInstrumento, error) {
+	return m.CriarAplicacaoFunc(ctx, a)
+}
+
+func (m *InstrumentoRepositoryMock) BuscarAplicacaoPorID(ctx context.Context, id string) (*model.AplicacaoInstrumento, error) {
+	return m.BuscarAplicacaoPorIDFunc(ctx, id)
+}
+
+func (m *InstrumentoRepositoryMock) ResponderAplicacao(ctx context.Context, id string, a model.AplicacaoInstrumento) error {
+	return m.ResponderAplicacaoFunc(ctx, id, a)
+}
+
+func (m *InstrumentoRepositoryMock) ListarAplicacoesPorAluno(ctx context.Context, alunoID string, instrumentoID string) ([]*model.AplicacaoInstrumento, error) {
+	return m.ListarAplicacoesPorAlunoFunc(ctx, alunoID, instrumentoID)
+}
@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"context"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+)
+
+var _ repository.NotificacaoRepository = &NotificacaoRepositoryMock{}
+
+type NotificacaoRepositoryMock struct {
+	RegistrarNotificacaoFunc        func(ctx context.Context, n model.Notificacao) (*model.Notificacao, error)
+	AtualizarNotificacaoFunc        func(ctx context.Context, id string, n model.Notificacao) error
+	ListarNotificacoesPorAlunoFunc  func(ctx context.Context, alunoID string) ([]*model.Notificacao, error)
+	ListarNotificacoesPorStatusFunc func(ctx context.Context, status string) ([]*model.Notificacao, error)
+}
+
+func (m *NotificacaoRepositoryMock) RegistrarNotificacao(ctx context.Context, n model.Notificacao) (*model.Notificacao, error) {
+	return m.RegistrarNotificacaoFunc(ctx, n)
+}
+
+func (m *NotificacaoRepositoryMock) AtualizarNotificacao(ctx context.Context, id string, n model.Notificacao) error {
+	return m.AtualizarNotificacaoFunc(ctx, id, n)
+}
+
+func (m *NotificacaoRepositoryMock) ListarNotificacoesPorAluno(ctx context.Context, alunoID string) ([]*model.Notificacao, error) {
+	return m.ListarNotificacoesPorAlunoFunc(ctx, alunoID)
+}
+
+func (m *NotificacaoRepositoryMock) ListarNotificacoesPorStatus(ctx context.Context, status string) ([]*model.Notificacao, error) {
+	return m.ListarNotificacoesPorStatusFunc(ctx, status)
+}
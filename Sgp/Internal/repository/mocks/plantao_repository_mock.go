@@ -0,0 +1,53 @@
+package mocks
+
+import (
+	"context"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"time"
+)
+
+var _ repository.PlantaoRepository = &PlantaoRepositoryMock{}
+
+type PlantaoRepositoryMock struct {
+	CriarTurnoFunc             func(ctx context.Context, t model.TurnoPlantao) (*model.TurnoPlantao, error)
+	ListarTurnosFunc           func(ctx context.Context, de, ate time.Time) ([]*model.TurnoPlantao, error)
+	DeletarTurnoFunc           func(ctx context.Context, id string) error
+	CriarAlertaFunc            func(ctx context.Context, a model.AlertaPlantao) (*model.AlertaPlantao, error)
+	BuscarAlertaPorIDFunc      func(ctx context.Context, id string) (*model.AlertaPlantao, error)
+	RepassarAlertaFunc         func(ctx context.Context, id string, acionadoEm time.Time, a model.AlertaPlantao) error
+	ReconhecerAlertaFunc       func(ctx context.Context, id, psicologoID string, em time.Time) (*model.AlertaPlantao, error)
+	ListarAlertasPorStatusFunc func(ctx context.Context, status string) ([]*model.AlertaPlantao, error)
+}
+
+func (m *PlantaoRepositoryMock) CriarTurno(ctx context.Context, t model.TurnoPlantao) (*model.TurnoPlantao, error) {
+	return m.CriarTurnoFunc(ctx, t)
+}
+
+func (m *PlantaoRepositoryMock) ListarTurnos(ctx context.Context, de, ate time.Time) ([]*model.TurnoPlantao, error) {
+	return m.ListarTurnosFunc(ctx, de, ate)
+}
+
+func (m *PlantaoRepositoryMock) DeletarTurno(ctx context.Context, id string) error {
+	return m.DeletarTurnoFunc(ctx, id)
+}
+
+func (m *PlantaoRepositoryMock) CriarAlerta(ctx context.Context, a model.AlertaPlantao) (*model.AlertaPlantao, error) {
+	return m.CriarAlertaFunc(ctx, a)
+}
+
+func (m *PlantaoRepositoryMock) BuscarAlertaPorID(ctx context.Context, id string) (*model.AlertaPlantao, error) {
+	return m.BuscarAlertaPorIDFunc(ctx, id)
+}
+
+func (m *PlantaoRepositoryMock) RepassarAlerta(ctx context.Context, id string, acionadoEm time.Time, a model.AlertaPlantao) error {
+	return m.RepassarAlertaFunc(ctx, id, acionadoEm, a)
+}
+
+func (m *PlantaoRepositoryMock) ReconhecerAlerta(ctx context.Context, id, psicologoID string, em time.Time) (*model.AlertaPlantao, error) {
+	return m.ReconhecerAlertaFunc(ctx, id, psicologoID, em)
+}
+
+func (m *PlantaoRepositoryMock) ListarAlertasPorStatus(ctx context.Context, status string) ([]*model.AlertaPlantao, error) {
+	return m.ListarAlertasPorStatusFunc(ctx, status)
+}
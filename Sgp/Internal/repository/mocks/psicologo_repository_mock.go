@@ -16,6 +16,7 @@ type PsicologoRepositoryMock struct {
 	AtualizarPsicologoFunc    func(ctx context.Context, id string, psicologo model.Psicologo) error
 	DeletarPsicologoFunc      func(ctx context.Context, id string) error
 	GetPsicologoIDPorNomeFunc func(ctx context.Context, nome string) (string, error)
+	CriarPsicologosEmLoteFunc func(ctx context.Context, psicologos []model.Psicologo) ([]*model.Psicologo, error)
 }
 
 func (m *PsicologoRepositoryMock) CriarPsicologo(ctx context.Context, p model.Psicologo) (*model.Psicologo, error) {
@@ -40,4 +41,8 @@ func (m *PsicologoRepositoryMock) DeletarPsicologo(ctx context.Context, id strin
 
 func (m *PsicologoRepositoryMock) GetPsicologoIDPorNome(ctx context.Context, nome string) (string, error) {
 	return m.GetPsicologoIDPorNomeFunc(ctx, nome)
-}
\ No newline at end of file
+}
+
+func (m *PsicologoRepositoryMock) CriarPsicologosEmLote(ctx context.Context, psicologos []model.Psicologo) ([]*model.Psicologo, error) {
+	return m.CriarPsicologosEmLoteFunc(ctx, psicologos)
+}
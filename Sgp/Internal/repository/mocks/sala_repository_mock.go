@@ -0,0 +1,37 @@
+package mocks
+
+import (
+	"context"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+)
+
+var _ repository.SalaRepository = &SalaRepositoryMock{}
+
+type SalaRepositoryMock struct {
+	CriarSalaFunc       func(ctx context.Context, sala model.Sala) (*model.Sala, error)
+	ListarSalasFunc     func(ctx context.Context) ([]*model.Sala, error)
+	BuscarSalaPorIDFunc func(ctx context.Context, id string) (*model.Sala, error)
+	AtualizarSalaFunc   func(ctx context.Context, id string, sala model.Sala) error
+	DeletarSalaFunc     func(ctx context.Context, id string) error
+}
+
+func (m *SalaRepositoryMock) CriarSala(ctx context.Context, sala model.Sala) (*model.Sala, error) {
+	return m.CriarSalaFunc(ctx, sala)
+}
+
+func (m *SalaRepositoryMock) ListarSalas(ctx context.Context) ([]*model.Sala, error) {
+	return m.ListarSalasFunc(ctx)
+}
+
+func (m *SalaRepositoryMock) BuscarSalaPorID(ctx context.Context, id string) (*model.Sala, error) {
+	return m.BuscarSalaPorIDFunc(ctx, id)
+}
+
+func (m *SalaRepositoryMock) AtualizarSala(ctx context.Context, id string, sala model.Sala) error {
+	return m.AtualizarSalaFunc(ctx, id, sala)
+}
+
+func (m *SalaRepositoryMock) DeletarSala(ctx context.Context, id string) error {
+	return m.DeletarSalaFunc(ctx, id)
+}
@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+)
+
+var _ repository.SessaoNotaRepository = &SessaoNotaRepositoryMock{}
+
+type SessaoNotaRepositoryMock struct {
+	SalvarNotaFunc        func(ctx context.Context, nota model.SessaoNota) (*model.SessaoNota, error)
+	BuscarNotaFunc        func(ctx context.Context, consultaID string) (*model.SessaoNota, error)
+	ListarVersoesNotaFunc func(ctx context.Context, consultaID string) ([]*model.SessaoNota, error)
+}
+
+func (m *SessaoNotaRepositoryMock) SalvarNota(ctx context.Context, nota model.SessaoNota) (*model.SessaoNota, error) {
+	return m.SalvarNotaFunc(ctx, nota)
+}
+
+func (m *SessaoNotaRepositoryMock) BuscarNota(ctx context.Context, consultaID string) (*model.SessaoNota, error) {
+	return m.BuscarNotaFunc(ctx, consultaID)
+}
+
+func (m *SessaoNotaRepositoryMock) ListarVersoesNota(ctx context.Context, consultaID string) ([]*model.SessaoNota, error) {
+	return m.ListarVersoesNotaFunc(ctx, consultaID)
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sgp/Internal/model"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+type NotificacaoRepositoryImpl struct {
+	Client *firestore.Client
+}
+
+func NewNotificacaoRepository(client *firestore.Client) *NotificacaoRepositoryImpl {
+	return &NotificacaoRepositoryImpl{Client: client}
+}
+
+func (r *NotificacaoRepositoryImpl) RegistrarNotificacao(ctx context.Context, notificacao model.Notificacao) (*model.Notificacao, error) {
+	docRef, _, err := r.Client.Collection("Notificacoes").Add(ctx, notificacao)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao registrar notificação: %w", err)
+	}
+	notificacao.ID = docRef.ID
+	return &notificacao, nil
+}
+
+func (r *NotificacaoRepositoryImpl) AtualizarNotificacao(ctx context.Context, id string, notificacao model.Notificacao) error {
+	_, err := r.Client.Collection("Notificacoes").Doc(id).Set(ctx, notificacao)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar notificação com ID '%s': %w", id, err)
+	}
+	return nil
+}
+
+func (r *NotificacaoRepositoryImpl) ListarNotificacoesPorAluno(ctx context.Context, alunoID string) ([]*model.Notificacao, error) {
+	return r.listar(ctx, r.Client.Collection("Notificacoes").Where("alunoId", "==", alunoID))
+}
+
+func (r *NotificacaoRepositoryImpl) ListarNotificacoesPorStatus(ctx context.Context, status string) ([]*model.Notificacao, error) {
+	return r.listar(ctx, r.Client.Collection("Notificacoes").Where("status", "==", status))
+}
+
+func (r *NotificacaoRepositoryImpl) listar(ctx context.Context, query firestore.Query) ([]*model.Notificacao, error) {
+	var notificacoes []*model.Notificacao
+
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao listar notificações: %w", err)
+		}
+
+		var n model.Notificacao
+		if err := doc.DataTo(&n); err != nil {
+			fmt.Printf("erro ao converter dados do doc '%s': %v", doc.Ref.ID, err)
+			continue
+		}
+		n.ID = doc.Ref.ID
+		notificacoes = append(notificacoes, &n)
+	}
+	return notificacoes, nil
+}
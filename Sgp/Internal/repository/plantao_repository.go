@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sgp/Internal/model"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+type PlantaoRepositoryImpl struct {
+	Client *firestore.Client
+}
+
+func NewPlantaoRepository(client *firestore.Client) *PlantaoRepositoryImpl {
+	return &PlantaoRepositoryImpl{Client: client}
+}
+
+func (r *PlantaoRepositoryImpl) CriarTurno(ctx context.Context, turno model.TurnoPlantao) (*model.TurnoPlantao, error) {
+	docRef, _, err := r.Client.Collection("TurnosPlantao").Add(ctx, turno)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar turno de plantão: %w", err)
+	}
+	turno.ID = docRef.ID
+	return &turno, nil
+}
+
+// ListarTurnos retorna os turnos que se sobrepõem ao intervalo [de, ate).
+// O Firestore filtra pelo fim; o início é conferido em memória.
+func (r *PlantaoRepositoryImpl) ListarTurnos(ctx context.Context, de, ate time.Time) ([]*model.TurnoPlantao, error) {
+	var turnos []*model.TurnoPlantao
+
+	iter := r.Client.Collection("TurnosPlantao").Where("fim", ">", de).Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao listar turnos de plantão: %w", err)
+		}
+
+		var turno model.TurnoPlantao
+		if err := doc.DataTo(&turno); err != nil {
+			fmt.Printf("erro ao converter dados do doc '%s': %v", doc.Ref.ID, err)
+			continue
+		}
+		if !turno.Inicio.Before(ate) {
+			continue
+		}
+		turno.ID = doc.Ref.ID
+		turnos = append(turnos, &turno)
+	}
+	return turnos, nil
+}
+
+func (r *PlantaoRepositoryImpl) DeletarTurno(ctx context.Context, id string) error {
+	if _, err := r.Client.Collection("TurnosPlantao").Doc(id).Delete(ctx); err != nil {
+		return fmt.Errorf("erro ao deletar turno com ID '%s': %w", id, err)
+	}
+	return nil
+}
+
+func (r *PlantaoRepositoryImpl) CriarAlerta(ctx context.Context, alerta model.AlertaPlantao) (*model.AlertaPlantao, error) {
+	docRef, _, err := r.Client.Collection("AlertasPlantao").Add(ctx, alerta)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar alerta de plantão: %w", err)
+	}
+	alerta.ID = docRef.ID
+	return &alerta, nil
+}
+
+func (r *PlantaoRepositoryImpl) BuscarAlertaPorID(ctx context.Context, id string) (*model.AlertaPlantao, error) {
+	doc, err := r.Client.Collection("AlertasPlantao").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("alerta não encontrado: %w", err)
+	}
+	var alerta model.AlertaPlantao
+	if err := doc.DataTo(&alerta); err != nil {
+		return nil, err
+	}
+	alerta.ID = doc.Ref.ID
+	return &alerta, nil
+}
+
+// RepassarAlerta grava o alerta escalonado numa transação que confere se ele
+// continua como foi lido: pendente e acionado em acionadoEm. Um
+// reconhecimento ou outro repasse no meio resulta em ErrTransicaoInvalida.
+func (r *PlantaoRepositoryImpl) RepassarAlerta(ctx context.Context, id string, acionadoEm time.Time, alerta model.AlertaPlantao) error {
+	ref := r.Client.Collection("AlertasPlantao").Doc(id)
+	return r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		atual, err := lerAlerta(tx, ref)
+		if err != nil {
+			return err
+		}
+		if atual.Status != model.AlertaPendente || !atual.AcionadoEm.Equal(acionadoEm) {
+			return ErrTransicaoInvalida
+		}
+		return tx.Set(ref, alerta)
+	})
+}
+
+// ReconhecerAlerta muda só os campos de reconhecimento, numa transação que
+// recusa um alerta já reconhecido com ErrTransicaoInvalida.
+func (r *PlantaoRepositoryImpl) ReconhecerAlerta(ctx context.Context, id, psicologoID string, em time.Time) (*model.AlertaPlantao, error) {
+	ref := r.Client.Collection("AlertasPlantao").Doc(id)
+	var alerta *model.AlertaPlantao
+	err := r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		atual, err := lerAlerta(tx, ref)
+		if err != nil {
+			return err
+		}
+		if atual.Status == model.AlertaReconhecido {
+			return ErrTransicaoInvalida
+		}
+		atual.Status = model.AlertaReconhecido
+		atual.ReconhecidoPor = psicologoID
+		atual.ReconhecidoEm = em
+		alerta = atual
+		return tx.Update(ref, []firestore.Update{
+			{Path: "status", Value: atual.Status},
+			{Path: "reconhecidoPor", Value: psicologoID},
+			{Path: "reconhecidoEm", Value: em},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return alerta, nil
+}
+
+func lerAlerta(tx *firestore.Transaction, ref *firestore.DocumentRef) (*model.AlertaPlantao, error) {
+	doc, err := tx.Get(ref)
+	if err != nil {
+		return nil, fmt.Errorf("alerta não encontrado: %w", err)
+	}
+	var alerta model.AlertaPlantao
+	if err := doc.DataTo(&alerta); err != nil {
+		return nil, err
+	}
+	alerta.ID = doc.Ref.ID
+	return &alerta, nil
+}
+
+func (r *PlantaoRepositoryImpl) ListarAlertasPorStatus(ctx context.Context, status string) ([]*model.AlertaPlantao, error) {
+	var alertas []*model.AlertaPlantao
+
+	iter := r.Client.Collection("AlertasPlantao").Where("status", "==", status).Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao listar alertas de plantão: %w", err)
+		}
+
+		var alerta model.AlertaPlantao
+		if err := doc.DataTo(&alerta); err != nil {
+			fmt.Printf("erro ao converter dados do doc '%s': %v", doc.Ref.ID, err)
+			continue
+		}
+		alerta.ID = doc.Ref.ID
+		alertas = append(alertas, &alerta)
+	}
+	return alertas, nil
+}
@@ -20,11 +20,7 @@ func (r *PsicologoRepositoryImpl) CriarPsicologo(
 	ctx context.Context, psicologo model.Psicologo) (*model.Psicologo, error) {
 
 	docRef, _, err := r.Client.Collection("Psicologos").
-		Add(ctx, map[string]interface{}{
-			"nome":  psicologo.Nome,
-			"email": psicologo.Email,
-			"crp":   psicologo.CRP,
-		})
+		Add(ctx, dadosPsicologo(psicologo))
 
 	if err != nil {
 		return nil, err
@@ -50,6 +46,21 @@ func (r *PsicologoRepositoryImpl) BuscarPsicologoPorID(ctx context.Context, id s
 	return &Psicologo, nil
 }
 
+func (r *PsicologoRepositoryImpl) CriarPsicologosEmLote(ctx context.Context, psicologos []model.Psicologo) ([]*model.Psicologo, error) {
+	batch := r.Client.Batch()
+	criados := make([]*model.Psicologo, 0, len(psicologos))
+	for _, psicologo := range psicologos {
+		docRef := r.Client.Collection("Psicologos").NewDoc()
+		batch.Create(docRef, dadosPsicologo(psicologo))
+		psicologo.ID = docRef.ID
+		criados = append(criados, &psicologo)
+	}
+	if _, err := batch.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("erro ao gravar lote de %d psicólogos: %w", len(psicologos), err)
+	}
+	return criados, nil
+}
+
 func (r *PsicologoRepositoryImpl) ListarPsicologos(ctx context.Context) ([]*model.Psicologo, error) {
 	var Psicologos []*model.Psicologo
 
@@ -87,11 +98,7 @@ func (r *PsicologoRepositoryImpl) AtualizarPsicologo(
 
 	_, err := r.Client.Collection("Psicologos").
 		Doc(id).
-		Set(ctx, map[string]interface{}{
-			"nome":  Psicologo.Nome,
-			"email": Psicologo.Email,
-			"crp":   Psicologo.CRP,
-		})
+		Set(ctx, dadosPsicologo(Psicologo))
 
 	if err != nil {
 		return fmt.Errorf("erro ao atualizar o psicologo com ID '%s': %v", id, err)
@@ -127,3 +134,41 @@ func (r *PsicologoRepositoryImpl) GetPsicologoIDPorNome(ctx context.Context, nom
 
 	return doc.Ref.ID, nil
 }
+
+// dadosPsicologo monta o documento gravado no Firestore, omitindo os campos
+// de perfil não preenchidos.
+func dadosPsicologo(psicologo model.Psicologo) map[string]interface{} {
+	dados := map[string]interface{}{
+		"nome":  psicologo.Nome,
+		"email": psicologo.Email,
+		"crp":   psicologo.CRP,
+	}
+	if len(psicologo.Especialidades) > 0 {
+		dados["especialidades"] = psicologo.Especialidades
+	}
+	if psicologo.Abordagem != "" {
+		dados["abordagem"] = psicologo.Abordagem
+	}
+	if len(psicologo.Idiomas) > 0 {
+		dados["idiomas"] = psicologo.Idiomas
+	}
+	if len(psicologo.Modalidades) > 0 {
+		dados["modalidades"] = psicologo.Modalidades
+	}
+	if psicologo.Bio != "" {
+		dados["bio"] = psicologo.Bio
+	}
+	if psicologo.Ativo != nil {
+		dados["ativo"] = *psicologo.Ativo
+	}
+	if psicologo.LimiteSessoesSemana > 0 {
+		dados["limiteSessoesSemana"] = psicologo.LimiteSessoesSemana
+	}
+	if psicologo.LimitePacientesAtivos > 0 {
+		dados["limitePacientesAtivos"] = psicologo.LimitePacientesAtivos
+	}
+	if psicologo.FusoHorario != "" {
+		dados["fusoHorario"] = psicologo.FusoHorario
+	}
+	return dados
+}
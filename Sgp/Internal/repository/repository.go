@@ -5,6 +5,7 @@ package repository
 import (
 	"context"
 	"sgp/Internal/model"
+	"time"
 )
 
 type AlunoRepository interface {
@@ -14,6 +15,10 @@ type AlunoRepository interface {
 	AtualizarAluno(ctx context.Context, id string, aluno model.Aluno) error
 	DeletarAluno(ctx context.Context, id string) error
 	GetAlunoIDPorNome(ctx context.Context, nome string) (string, error)
+	AnonimizarAluno(ctx context.Context, id string) error
+	// CriarAlunosEmLote grava todos os alunos numa única escrita em lote:
+	// ou todos são criados, ou nenhum.
+	CriarAlunosEmLote(ctx context.Context, alunos []model.Aluno) ([]*model.Aluno, error)
 }
 
 type PsicologoRepository interface {
@@ -23,6 +28,8 @@ type PsicologoRepository interface {
 	AtualizarPsicologo(ctx context.Context, id string, psicologo model.Psicologo) error
 	DeletarPsicologo(ctx context.Context, id string) error
 	GetPsicologoIDPorNome(ctx context.Context, nome string) (string, error)
+	// CriarPsicologosEmLote grava todos os psicólogos numa única escrita em lote.
+	CriarPsicologosEmLote(ctx context.Context, psicologos []model.Psicologo) ([]*model.Psicologo, error)
 }
 
 type HorarioDisponivelRepository interface {
@@ -31,13 +38,160 @@ type HorarioDisponivelRepository interface {
 	BuscarHorarioPorID(ctx context.Context, id string) (*model.HorarioDisponivel, error)
 	AtualizarStatusHorario(ctx context.Context, id string, novoStatus string) error
 	DeletarHorario(ctx context.Context, id string) error
+	// ListarHorariosPorSala retorna os horários da sala que se sobrepõem a [de, ate).
+	ListarHorariosPorSala(ctx context.Context, salaID string, de, ate time.Time) ([]*model.HorarioDisponivel, error)
+	// ListarHorariosNoPeriodo retorna os horários de todos os psicólogos que
+	// se sobrepõem a [de, ate).
+	ListarHorariosNoPeriodo(ctx context.Context, de, ate time.Time) ([]*model.HorarioDisponivel, error)
+	// ReservarHorario deixa um horário "disponivel" reservado ao aluno até
+	// ate; caso contrário retorna ErrHorarioIndisponivel. LiberarReserva o
+	// devolve se ele ainda estiver reservado ao aluno.
+	ReservarHorario(ctx context.Context, id string, alunoID string, ate time.Time) error
+	LiberarReserva(ctx context.Context, id string, alunoID string) error
+	// ExpirarReserva só libera a reserva se ela tiver vencido antes de agora;
+	// caso contrário retorna ErrTransicaoInvalida.
+	ExpirarReserva(ctx context.Context, id string, agora time.Time) error
+	// ListarHorariosPorStatus não filtra por psicólogo; é usado pela
+	// reconciliação, que precisa ver também horários de psicólogos removidos.
+	ListarHorariosPorStatus(ctx context.Context, status string) ([]*model.HorarioDisponivel, error)
+	// ReconciliarStatusHorario troca o status só se ele ainda for esperado e
+	// se as consultas do horário, relidas na mesma transação, confirmarem o
+	// novo status (ver ConfereOcupacao); caso contrário retorna
+	// ErrTransicaoInvalida. Ao sair de "reservado", a reserva se desfaz.
+	ReconciliarStatusHorario(ctx context.Context, id string, esperado, novoStatus string) error
+	// RecontarVagasGrupo recalcula inscritos e lista de espera a partir das
+	// consultas, se o horário de grupo ainda tiver as contagens vistas; caso
+	// contrário retorna ErrTransicaoInvalida.
+	RecontarVagasGrupo(ctx context.Context, id string, inscritosVistos, esperaVistos int) error
+}
+
+type SalaRepository interface {
+	CriarSala(ctx context.Context, sala model.Sala) (*model.Sala, error)
+	ListarSalas(ctx context.Context) ([]*model.Sala, error)
+	BuscarSalaPorID(ctx context.Context, id string) (*model.Sala, error)
+	AtualizarSala(ctx context.Context, id string, sala model.Sala) error
+	DeletarSala(ctx context.Context, id string) error
 }
 
 type ConsultaRepository interface {
 	AgendarConsulta(ctx context.Context, consulta model.Consulta) (*model.Consulta, error)
 	AtualizaStatusConsulta(ctx context.Context, id string, novoStatus string) error
+	// TrocarStatusConsulta é AtualizaStatusConsulta só para a consulta que
+	// ainda estiver em esperado; caso contrário retorna ErrTransicaoInvalida.
+	TrocarStatusConsulta(ctx context.Context, id string, esperado, novoStatus string) error
 	ListarConsultasPorPsicologo(ctx context.Context, psicologoID string, statusFiltro string) ([]*model.Consulta, error)
 	ListarConsultasPorAluno(ctx context.Context, alunoID string) ([]*model.Consulta, error)
 	DeletarConsulta(ctx context.Context, id string) error
 	BuscarConsultaPorID(ctx context.Context, id string) (*model.Consulta, error)
-}
\ No newline at end of file
+	ListarConsultasPorStatus(ctx context.Context, status string) ([]*model.Consulta, error)
+	// ListarConsultasNoPeriodo retorna as consultas que se sobrepõem a
+	// [de, ate); statusFiltro vazio traz todos os status.
+	ListarConsultasNoPeriodo(ctx context.Context, statusFiltro string, de, ate time.Time) ([]*model.Consulta, error)
+	// RegistrarPresenca só muda o status se a consulta ainda estiver em
+	// esperado; caso contrário retorna ErrTransicaoInvalida.
+	RegistrarPresenca(ctx context.Context, id string, esperado, novoStatus string, em time.Time) error
+	DefinirCodigoCheckin(ctx context.Context, id string, codigo string) error
+	DefinirLinkReuniao(ctx context.Context, id string, link string) error
+	DefinirEpisodio(ctx context.Context, id string, episodioID string) error
+}
+
+// AuditoriaRepository é append-only: não há operações de edição ou remoção.
+type AuditoriaRepository interface {
+	Registrar(ctx context.Context, registro model.RegistroAuditoria) error
+	ListarAuditoria(ctx context.Context, filtro model.FiltroAuditoria) ([]*model.RegistroAuditoria, string, error)
+	// OcultarDadosRegistros apaga Antes e Depois dos registros da entidade,
+	// mantendo quem fez o quê e quando.
+	OcultarDadosRegistros(ctx context.Context, entidade, entidadeID string) error
+}
+
+// SessaoNotaRepository guarda notas já cifradas; cada escrita gera uma nova versão.
+type SessaoNotaRepository interface {
+	SalvarNota(ctx context.Context, nota model.SessaoNota) (*model.SessaoNota, error)
+	BuscarNota(ctx context.Context, consultaID string) (*model.SessaoNota, error)
+	ListarVersoesNota(ctx context.Context, consultaID string) ([]*model.SessaoNota, error)
+}
+
+type NotificacaoRepository interface {
+	RegistrarNotificacao(ctx context.Context, notificacao model.Notificacao) (*model.Notificacao, error)
+	AtualizarNotificacao(ctx context.Context, id string, notificacao model.Notificacao) error
+	ListarNotificacoesPorAluno(ctx context.Context, alunoID string) ([]*model.Notificacao, error)
+	// ListarNotificacoesPorStatus serve ao reenvio das que "falhou".
+	ListarNotificacoesPorStatus(ctx context.Context, status string) ([]*model.Notificacao, error)
+}
+
+// ConsentimentoRepository retorna nil (sem erro) quando não há documento vigente ou aceite.
+type ConsentimentoRepository interface {
+	PublicarDocumento(ctx context.Context, documento model.DocumentoConsentimento) (*model.DocumentoConsentimento, error)
+	BuscarDocumentoVigente(ctx context.Context) (*model.DocumentoConsentimento, error)
+	RegistrarAceite(ctx context.Context, aceite model.AceiteConsentimento) (*model.AceiteConsentimento, error)
+	BuscarAceite(ctx context.Context, alunoID string, versao int) (*model.AceiteConsentimento, error)
+	ListarAceitesPorAluno(ctx context.Context, alunoID string) ([]*model.AceiteConsentimento, error)
+}
+
+// FormularioRepository retorna nil (sem erro) quando o formulário ou a resposta não existem.
+type FormularioRepository interface {
+	SalvarFormulario(ctx context.Context, formulario model.FormularioInicial) (*model.FormularioInicial, error)
+	BuscarFormularioPorPsicologo(ctx context.Context, psicologoID string) (*model.FormularioInicial, error)
+	RegistrarResposta(ctx context.Context, resposta model.RespostaFormulario) (*model.RespostaFormulario, error)
+	BuscarRespostaPorConsulta(ctx context.Context, consultaID string) (*model.RespostaFormulario, error)
+	ListarRespostasPorAluno(ctx context.Context, alunoID string) ([]*model.RespostaFormulario, error)
+}
+
+type InstrumentoRepository interface {
+	CriarAplicacao(ctx context.Context, aplicacao model.AplicacaoInstrumento) (*model.AplicacaoInstrumento, error)
+	BuscarAplicacaoPorID(ctx context.Context, id string) (*model.AplicacaoInstrumento, error)
+	// ResponderAplicacao só grava se a aplicação ainda estiver "pendente";
+	// caso contrário retorna ErrTransicaoInvalida.
+	ResponderAplicacao(ctx context.Context, id string, aplicacao model.AplicacaoInstrumento) error
+	ListarAplicacoesPorAluno(ctx context.Context, alunoID string, instrumentoID string) ([]*model.AplicacaoInstrumento, error)
+}
+
+// PlantaoRepository guarda a escala de plantão e os alertas encaminhados a ela.
+type PlantaoRepository interface {
+	CriarTurno(ctx context.Context, turno model.TurnoPlantao) (*model.TurnoPlantao, error)
+	ListarTurnos(ctx context.Context, de, ate time.Time) ([]*model.TurnoPlantao, error)
+	DeletarTurno(ctx context.Context, id string) error
+	CriarAlerta(ctx context.Context, alerta model.AlertaPlantao) (*model.AlertaPlantao, error)
+	BuscarAlertaPorID(ctx context.Context, id string) (*model.AlertaPlantao, error)
+	// RepassarAlerta só grava se o alerta ainda estiver pendente e acionado
+	// em acionadoEm; caso contrário retorna ErrTransicaoInvalida.
+	RepassarAlerta(ctx context.Context, id string, acionadoEm time.Time, alerta model.AlertaPlantao) error
+	// ReconhecerAlerta retorna ErrTransicaoInvalida se o alerta já foi reconhecido.
+	ReconhecerAlerta(ctx context.Context, id, psicologoID string, em time.Time) (*model.AlertaPlantao, error)
+	ListarAlertasPorStatus(ctx context.Context, status string) ([]*model.AlertaPlantao, error)
+}
+
+type CalendarioRepository interface {
+	CriarEvento(ctx context.Context, evento model.EventoCalendario) (*model.EventoCalendario, error)
+	// ListarEventos retorna os eventos que se sobrepõem a [de, ate).
+	ListarEventos(ctx context.Context, de, ate time.Time) ([]*model.EventoCalendario, error)
+	DeletarEvento(ctx context.Context, id string) error
+}
+
+// EpisodioRepository guarda os episódios de cuidado. BuscarEpisodioAberto
+// retorna nil (sem erro) quando o aluno não tem episódio aberto.
+type EpisodioRepository interface {
+	// AbrirEpisodio retorna ErrEpisodioAberto se o aluno já tiver um episódio aberto.
+	AbrirEpisodio(ctx context.Context, episodio model.Episodio) (*model.Episodio, error)
+	// AssumirEpisodio passa o episódio aberto do aluno a
+	// transferencia.ParaPsicologoID, registrando a transferência, ou abre um
+	// novo com ele se não houver. DePsicologoID é preenchido pelo repositório.
+	AssumirEpisodio(ctx context.Context, alunoID string, transferencia model.TransferenciaEpisodio) (*model.Episodio, error)
+	BuscarEpisodioPorID(ctx context.Context, id string) (*model.Episodio, error)
+	BuscarEpisodioAberto(ctx context.Context, alunoID string) (*model.Episodio, error)
+	ListarEpisodiosPorAluno(ctx context.Context, alunoID string) ([]*model.Episodio, error)
+	// AtualizarEpisodio só grava se o episódio ainda estiver aberto e com o
+	// psicólogo psicologoEsperado; caso contrário retorna ErrTransicaoInvalida.
+	AtualizarEpisodio(ctx context.Context, id string, psicologoEsperado string, episodio model.Episodio) error
+}
+
+type EncaminhamentoRepository interface {
+	CriarEncaminhamento(ctx context.Context, encaminhamento model.Encaminhamento) (*model.Encaminhamento, error)
+	BuscarEncaminhamentoPorID(ctx context.Context, id string) (*model.Encaminhamento, error)
+	// AtualizarEncaminhamento só grava se o status ainda for esperado; caso
+	// contrário retorna ErrTransicaoInvalida.
+	AtualizarEncaminhamento(ctx context.Context, id string, esperado string, encaminhamento model.Encaminhamento) error
+	// ListarEncaminhamentosPorPsicologo traz os enviados e os recebidos.
+	ListarEncaminhamentosPorPsicologo(ctx context.Context, psicologoID string) ([]*model.Encaminhamento, error)
+	ListarEncaminhamentosPorAluno(ctx context.Context, alunoID string) ([]*model.Encaminhamento, error)
+}
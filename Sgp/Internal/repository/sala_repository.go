@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sgp/Internal/model"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+type SalaRepositoryImpl struct {
+	Client *firestore.Client
+}
+
+func NewSalaRepository(client *firestore.Client) SalaRepository {
+	return &SalaRepositoryImpl{Client: client}
+}
+
+func (r *SalaRepositoryImpl) CriarSala(ctx context.Context, sala model.Sala) (*model.Sala, error) {
+	docRef, _, err := r.Client.Collection("Salas").Add(ctx, sala)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar sala: %w", err)
+	}
+	sala.ID = docRef.ID
+	return &sala, nil
+}
+
+func (r *SalaRepositoryImpl) ListarSalas(ctx context.Context) ([]*model.Sala, error) {
+	var salas []*model.Sala
+	iter := r.Client.Collection("Salas").OrderBy("nome", firestore.Asc).Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao listar salas: %w", err)
+		}
+		var sala model.Sala
+		if err := doc.DataTo(&sala); err != nil {
+			fmt.Printf("erro ao converter dados do doc '%s': %v", doc.Ref.ID, err)
+			continue
+		}
+		sala.ID = doc.Ref.ID
+		salas = append(salas, &sala)
+	}
+	return salas, nil
+}
+
+func (r *SalaRepositoryImpl) BuscarSalaPorID(ctx context.Context, id string) (*model.Sala, error) {
+	doc, err := r.Client.Collection("Salas").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sala não encontrada: %w", err)
+	}
+	var sala model.Sala
+	if err := doc.DataTo(&sala); err != nil {
+		return nil, err
+	}
+	sala.ID = doc.Ref.ID
+	return &sala, nil
+}
+
+func (r *SalaRepositoryImpl) AtualizarSala(ctx context.Context, id string, sala model.Sala) error {
+	_, err := r.Client.Collection("Salas").Doc(id).Set(ctx, sala)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar sala com ID '%s': %w", id, err)
+	}
+	return nil
+}
+
+func (r *SalaRepositoryImpl) DeletarSala(ctx context.Context, id string) error {
+	if _, err := r.Client.Collection("Salas").Doc(id).Delete(ctx); err != nil {
+		return fmt.Errorf("erro ao deletar sala com ID '%s': %w", id, err)
+	}
+	return nil
+}
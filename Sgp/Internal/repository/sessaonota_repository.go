@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sgp/Internal/model"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type SessaoNotaRepositoryImpl struct {
+	Client *firestore.Client
+}
+
+func NewSessaoNotaRepository(client *firestore.Client) *SessaoNotaRepositoryImpl {
+	return &SessaoNotaRepositoryImpl{Client: client}
+}
+
+// SalvarNota grava a versão atual em SessaoNotas/{consultaId} e uma cópia
+// imutável em SessaoNotas/{consultaId}/versoes/{versao}, na mesma transação.
+func (r *SessaoNotaRepositoryImpl) SalvarNota(ctx context.Context, nota model.SessaoNota) (*model.SessaoNota, error) {
+	notaRef := r.Client.Collection("SessaoNotas").Doc(nota.ConsultaID)
+
+	err := r.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		versao := 1
+		doc, err := tx.Get(notaRef)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return fmt.Errorf("erro ao buscar nota da consulta '%s': %w", nota.ConsultaID, err)
+		}
+		if err == nil {
+			var atual model.SessaoNota
+			if err := doc.DataTo(&atual); err != nil {
+				return err
+			}
+			versao = atual.Versao + 1
+		}
+
+		nota.Versao = versao
+		nota.AtualizadoEm = time.Now().UTC()
+
+		versaoRef := notaRef.Collection("versoes").Doc(fmt.Sprintf("%06d", versao))
+		if err := tx.Create(versaoRef, nota); err != nil {
+			return err
+		}
+		return tx.Set(notaRef, nota)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	nota.ID = nota.ConsultaID
+	return &nota, nil
+}
+
+func (r *SessaoNotaRepositoryImpl) BuscarNota(ctx context.Context, consultaID string) (*model.SessaoNota, error) {
+	doc, err := r.Client.Collection("SessaoNotas").Doc(consultaID).Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var nota model.SessaoNota
+	if err := doc.DataTo(&nota); err != nil {
+		return nil, err
+	}
+	nota.ID = doc.Ref.ID
+	return &nota, nil
+}
+
+func (r *SessaoNotaRepositoryImpl) ListarVersoesNota(ctx context.Context, consultaID string) ([]*model.SessaoNota, error) {
+	var versoes []*model.SessaoNota
+
+	iter := r.Client.Collection("SessaoNotas").Doc(consultaID).Collection("versoes").
+		OrderBy("versao", firestore.Asc).Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao listar versões da nota '%s': %w", consultaID, err)
+		}
+
+		var nota model.SessaoNota
+		if err := doc.DataTo(&nota); err != nil {
+			fmt.Printf("erro ao converter dados do doc '%s': %v", doc.Ref.ID, err)
+			continue
+		}
+		nota.ID = consultaID
+		versoes = append(versoes, &nota)
+	}
+	return versoes, nil
+}
@@ -0,0 +1,46 @@
+// Package reqctx guarda no contexto os metadados da requisição (ator e
+// request ID) para que camadas abaixo do handler, como a auditoria, possam
+// usá-los sem depender do pacote de middleware.
+package reqctx
+
+import "context"
+
+type chave int
+
+const (
+	chaveAtor chave = iota
+	chaveRequestID
+)
+
+// Papéis usados na custom claim "role" do Firebase, com os mesmos nomes
+// devolvidos por GET /users/{id}/role.
+const (
+	PapelAdmin     = "admin"
+	PapelPsicologo = "psychologist"
+	PapelAluno     = "student"
+)
+
+// Ator identifica quem está executando a requisição.
+type Ator struct {
+	ID    string
+	Papel string
+}
+
+func ComAtor(ctx context.Context, ator Ator) context.Context {
+	return context.WithValue(ctx, chaveAtor, ator)
+}
+
+// AtorDe retorna o ator da requisição ou um Ator vazio se não houver.
+func AtorDe(ctx context.Context) Ator {
+	ator, _ := ctx.Value(chaveAtor).(Ator)
+	return ator
+}
+
+func ComRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, chaveRequestID, id)
+}
+
+func RequestIDDe(ctx context.Context) string {
+	id, _ := ctx.Value(chaveRequestID).(string)
+	return id
+}
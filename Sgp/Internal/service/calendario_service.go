@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"time"
+)
+
+var ErrDataBloqueada = errors.New("data indisponível no calendário institucional")
+
+// CalendarioService aplica feriados, fechamentos e períodos letivos aos
+// horários e cancela as consultas atingidas por um fechamento novo.
+type CalendarioService struct {
+	Repo         repository.CalendarioRepository
+	ConsultaRepo repository.ConsultaRepository
+	HorarioRepo  repository.HorarioDisponivelRepository
+	AlunoRepo    repository.AlunoRepository
+	EmailService *EmailService
+
+	// ExigirPeriodoLetivo recusa horários fora de um período letivo cadastrado.
+	ExigirPeriodoLetivo bool
+}
+
+func NewCalendarioService(
+	repo repository.CalendarioRepository,
+	consultaRepo repository.ConsultaRepository,
+	horarioRepo repository.HorarioDisponivelRepository,
+	alunoRepo repository.AlunoRepository,
+	emailService *EmailService,
+) *CalendarioService {
+	return &CalendarioService{
+		Repo:         repo,
+		ConsultaRepo: consultaRepo,
+		HorarioRepo:  horarioRepo,
+		AlunoRepo:    alunoRepo,
+		EmailService: emailService,
+	}
+}
+
+// EventoBloqueante diz se o evento impede atendimentos no seu intervalo.
+func EventoBloqueante(e *model.EventoCalendario) bool {
+	return e.Tipo == model.EventoFeriado || e.Tipo == model.EventoFechamento
+}
+
+// VerificarIntervalo confere [inicio, fim) contra os eventos já carregados.
+// O erro retornado embrulha ErrDataBloqueada.
+func (s *CalendarioService) VerificarIntervalo(eventos []*model.EventoCalendario, inicio, fim time.Time) error {
+	dentroDoPeriodo := false
+	for _, e := range eventos {
+		switch {
+		case EventoBloqueante(e) && e.Inicio.Before(fim) && inicio.Before(e.Fim):
+			return fmt.Errorf("%w: %s '%s'", ErrDataBloqueada, e.Tipo, e.Nome)
+		case e.Tipo == model.EventoPeriodoLetivo && !inicio.Before(e.Inicio) && !fim.After(e.Fim):
+			dentroDoPeriodo = true
+		}
+	}
+	if s.ExigirPeriodoLetivo && !dentroDoPeriodo {
+		return fmt.Errorf("%w: fora do período letivo", ErrDataBloqueada)
+	}
+	return nil
+}
+
+// VerificarHorario busca os eventos do intervalo e aplica VerificarIntervalo.
+func (s *CalendarioService) VerificarHorario(ctx context.Context, inicio, fim time.Time) error {
+	eventos, err := s.Repo.ListarEventos(ctx, inicio, fim)
+	if err != nil {
+		return err
+	}
+	return s.VerificarIntervalo(eventos, inicio, fim)
+}
+
+// RegistrarEvento grava o evento e, se ele bloquear atendimentos, cancela as
+// consultas ativas no intervalo e bloqueia seus horários. Falhas em consultas
+// individuais são registradas e não interrompem o lote.
+func (s *CalendarioService) RegistrarEvento(ctx context.Context, evento model.EventoCalendario) (*model.EventoCalendario, []*model.Consulta, error) {
+	evento.CriadoEm = time.Now().UTC()
+	criado, err := s.Repo.CriarEvento(ctx, evento)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !EventoBloqueante(criado) {
+		return criado, nil, nil
+	}
+
+	// A lista de espera vem primeiro: cancelar antes quem tem vaga num grupo
+	// promoveria um pedido da fila que também cai no evento.
+	var canceladas []*model.Consulta
+	for _, status := range []string{"lista de espera", "aguardando aprovacao", "confirmada"} {
+		consultas, err := s.ConsultaRepo.ListarConsultasNoPeriodo(ctx, status, criado.Inicio, criado.Fim)
+		if err != nil {
+			return criado, canceladas, err
+		}
+		for _, c := range consultas {
+			if err := s.ConsultaRepo.AtualizaStatusConsulta(ctx, c.ID, "cancelada pela clinica"); err != nil {
+				log.Printf("ERRO ao cancelar consulta %s pelo evento %s: %v", c.ID, criado.ID, err)
+				continue
+			}
+			if c.HorarioID != "" {
+				if err := s.HorarioRepo.AtualizarStatusHorario(ctx, c.HorarioID, "bloqueado"); err != nil {
+					log.Printf("ERRO ao bloquear horário %s pelo evento %s: %v", c.HorarioID, criado.ID, err)
+				}
+			}
+			c.Status = "cancelada pela clinica"
+			canceladas = append(canceladas, c)
+		}
+	}
+	return criado, canceladas, nil
+}
+
+// NotificarCancelamentos avisa cada aluno atingido. Deve rodar fora da requisição.
+func (s *CalendarioService) NotificarCancelamentos(evento *model.EventoCalendario, consultas []*model.Consulta) {
+	if s.EmailService == nil {
+		return
+	}
+	bgCtx := context.Background()
+	for _, c := range consultas {
+		aluno, err := s.AlunoRepo.BuscarAlunoPorID(bgCtx, c.AlunoID)
+		if err != nil {
+			log.Printf("ERRO ao buscar aluno %s para aviso de cancelamento: %v", c.AlunoID, err)
+			continue
+		}
+		err = s.EmailService.EnviarCancelamentoCalendario(
+			aluno.ID,
+			aluno.Email,
+			NomeDeTratamento(aluno),
+			FormatarDataHora(c.Inicio, Fuso(aluno.FusoHorario)),
+			evento.Nome,
+		)
+		if err != nil {
+			log.Printf("ERRO ao enviar aviso de cancelamento (Resend): %v", err)
+		}
+	}
+}
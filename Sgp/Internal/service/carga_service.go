@@ -0,0 +1,94 @@
+package service
+
+import (
+	"fmt"
+	"sgp/Internal/model"
+	"sort"
+	"time"
+)
+
+// ConsultaAtiva indica se a consulta ainda ocupa agenda e vínculo com o psicólogo.
+func ConsultaAtiva(c *model.Consulta) bool {
+	return c.Status == "aguardando aprovacao" || c.Status == "confirmada"
+}
+
+// CargaPsicologo resume a ocupação de um psicólogo a partir das suas consultas ativas.
+type CargaPsicologo struct {
+	Pacientes        map[string]bool
+	SessoesPorSemana map[string]int
+}
+
+// CalcularCarga conta cada horário de grupo como uma única sessão, e seus
+// inscritos não entram como pacientes: a capacidade do grupo já os limita.
+func CalcularCarga(consultas []*model.Consulta, agora time.Time) CargaPsicologo {
+	carga := CargaPsicologo{Pacientes: map[string]bool{}, SessoesPorSemana: map[string]int{}}
+	grupos := map[string]bool{}
+	for _, c := range consultas {
+		if !ConsultaAtiva(c) {
+			continue
+		}
+		if c.Grupo {
+			if !grupos[c.HorarioID] {
+				grupos[c.HorarioID] = true
+				carga.SessoesPorSemana[chaveSemana(c.Inicio)]++
+			}
+			continue
+		}
+		carga.SessoesPorSemana[chaveSemana(c.Inicio)]++
+		if c.Fim.After(agora) {
+			carga.Pacientes[c.AlunoID] = true
+		}
+	}
+	return carga
+}
+
+func (c CargaPsicologo) SessoesNaSemana(t time.Time) int {
+	return c.SessoesPorSemana[chaveSemana(t)]
+}
+
+// VerificarLimites explica por que o psicólogo não pode receber mais esta
+// consulta, ou retorna nil. Limites zerados não restringem.
+func VerificarLimites(psicologo *model.Psicologo, carga CargaPsicologo, alunoID string, inicio time.Time) error {
+	if psicologo.LimiteSessoesSemana > 0 && carga.SessoesNaSemana(inicio) >= psicologo.LimiteSessoesSemana {
+		return fmt.Errorf("o psicologo ja atingiu o limite de %d sessoes na semana de %s",
+			psicologo.LimiteSessoesSemana, inicio.In(fusoPadrao).Format("02/01/2006"))
+	}
+	if psicologo.LimitePacientesAtivos > 0 && !carga.Pacientes[alunoID] && len(carga.Pacientes) >= psicologo.LimitePacientesAtivos {
+		return fmt.Errorf("o psicologo ja atingiu o limite de %d pacientes ativos", psicologo.LimitePacientesAtivos)
+	}
+	return nil
+}
+
+// CandidatoAtribuicao é um psicólogo elegível com o primeiro horário que ele pode receber.
+type CandidatoAtribuicao struct {
+	Psicologo *model.Psicologo
+	Horario   *model.HorarioDisponivel
+	Carga     CargaPsicologo
+}
+
+// EscolherCandidato fica com o psicólogo de menor carga: menos pacientes
+// ativos, depois menos sessões na semana do horário, depois o horário mais cedo.
+func EscolherCandidato(candidatos []CandidatoAtribuicao) *CandidatoAtribuicao {
+	if len(candidatos) == 0 {
+		return nil
+	}
+	sort.SliceStable(candidatos, func(i, j int) bool {
+		a, b := candidatos[i], candidatos[j]
+		if len(a.Carga.Pacientes) != len(b.Carga.Pacientes) {
+			return len(a.Carga.Pacientes) < len(b.Carga.Pacientes)
+		}
+		sa, sb := a.Carga.SessoesNaSemana(a.Horario.Inicio), b.Carga.SessoesNaSemana(b.Horario.Inicio)
+		if sa != sb {
+			return sa < sb
+		}
+		return a.Horario.Inicio.Before(b.Horario.Inicio)
+	})
+	return &candidatos[0]
+}
+
+// chaveSemana usa o fuso da clínica: uma sessão de domingo à noite não pode
+// cair na semana seguinte só porque o servidor roda em UTC.
+func chaveSemana(t time.Time) string {
+	ano, semana := t.In(fusoPadrao).ISOWeek()
+	return fmt.Sprintf("%d-W%02d", ano, semana)
+}
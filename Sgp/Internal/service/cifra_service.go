@@ -0,0 +1,101 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"sgp/Internal/model"
+)
+
+// CifraEnvelope implementa cifragem em envelope com AES-256-GCM: cada conteúdo
+// recebe uma chave de dados aleatória, que é guardada cifrada pela chave mestra.
+type CifraEnvelope struct {
+	mestra cipher.AEAD
+}
+
+// NewCifraEnvelope recebe a chave mestra em base64 (32 bytes).
+func NewCifraEnvelope(chaveMestraBase64 string) (*CifraEnvelope, error) {
+	if chaveMestraBase64 == "" {
+		return nil, fmt.Errorf("chave mestra não configurada")
+	}
+
+	chave, err := base64.StdEncoding.DecodeString(chaveMestraBase64)
+	if err != nil {
+		return nil, fmt.Errorf("chave mestra inválida: %w", err)
+	}
+	if len(chave) != 32 {
+		return nil, fmt.Errorf("chave mestra deve ter 32 bytes, tem %d", len(chave))
+	}
+
+	mestra, err := novoGCM(chave)
+	if err != nil {
+		return nil, err
+	}
+	return &CifraEnvelope{mestra: mestra}, nil
+}
+
+// Cifrar protege o texto amarrando-o ao contexto informado (ex: o ID da
+// consulta), de modo que o conteúdo não possa ser copiado para outro documento.
+func (c *CifraEnvelope) Cifrar(texto, contexto string) (model.ConteudoCifrado, error) {
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return model.ConteudoCifrado{}, fmt.Errorf("erro ao gerar chave de dados: %w", err)
+	}
+
+	dados, err := novoGCM(dek)
+	if err != nil {
+		return model.ConteudoCifrado{}, err
+	}
+
+	nonce, err := novoNonce(dados)
+	if err != nil {
+		return model.ConteudoCifrado{}, err
+	}
+	nonceChave, err := novoNonce(c.mestra)
+	if err != nil {
+		return model.ConteudoCifrado{}, err
+	}
+
+	return model.ConteudoCifrado{
+		ChaveCifrada: c.mestra.Seal(nil, nonceChave, dek, []byte(contexto)),
+		NonceChave:   nonceChave,
+		Nonce:        nonce,
+		Texto:        dados.Seal(nil, nonce, []byte(texto), []byte(contexto)),
+	}, nil
+}
+
+func (c *CifraEnvelope) Decifrar(cifrado model.ConteudoCifrado, contexto string) (string, error) {
+	dek, err := c.mestra.Open(nil, cifrado.NonceChave, cifrado.ChaveCifrada, []byte(contexto))
+	if err != nil {
+		return "", fmt.Errorf("erro ao decifrar chave de dados: %w", err)
+	}
+
+	dados, err := novoGCM(dek)
+	if err != nil {
+		return "", err
+	}
+
+	texto, err := dados.Open(nil, cifrado.Nonce, cifrado.Texto, []byte(contexto))
+	if err != nil {
+		return "", fmt.Errorf("erro ao decifrar conteúdo: %w", err)
+	}
+	return string(texto), nil
+}
+
+func novoGCM(chave []byte) (cipher.AEAD, error) {
+	bloco, err := aes.NewCipher(chave)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar cifra: %w", err)
+	}
+	return cipher.NewGCM(bloco)
+}
+
+func novoNonce(aead cipher.AEAD) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("erro ao gerar nonce: %w", err)
+	}
+	return nonce, nil
+}
@@ -1,12 +1,28 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/resend/resend-go/v2"
 )
 
 type EmailService struct {
 	Client *resend.Client
+	// Registro é opcional; quando presente, todo envio fica gravado em Notificacoes.
+	Registro repository.NotificacaoRepository
+
+	// envios guarda as notificações ainda em curso, para que o encerramento
+	// registre as que não terminarem (veja RegistrarInterrompidos).
+	mu      sync.Mutex
+	envios  map[int]model.Notificacao
+	proximo int
 }
 
 func NewEmailService(apiKey string) *EmailService {
@@ -14,8 +30,16 @@ func NewEmailService(apiKey string) *EmailService {
 	return &EmailService{Client: client}
 }
 
+// NomeDeTratamento prefere o nome social do aluno em toda comunicação.
+func NomeDeTratamento(aluno *model.Aluno) string {
+	if aluno.NomeSocial != "" {
+		return aluno.NomeSocial
+	}
+	return aluno.Nome
+}
+
 // EnviarNotificacaoAgendamento envia e-mail para o aluno e/ou psicólogo
-func (s *EmailService) EnviarNotificacaoAgendamento(emailDestino, nomeAluno, nomePsicologo, dataHora string) error {
+func (s *EmailService) EnviarNotificacaoAgendamento(alunoID, emailDestino, nomeAluno, nomePsicologo, dataHora string) error {
 	
 	htmlContent := fmt.Sprintf(`
 		<h1>Olá, %s!</h1>
@@ -33,16 +57,11 @@ func (s *EmailService) EnviarNotificacaoAgendamento(emailDestino, nomeAluno, nom
 		Html:    htmlContent,
 	}
 
-	_, err := s.Client.Emails.Send(params)
-	if err != nil {
-		return fmt.Errorf("erro ao enviar email pelo Resend: %v", err)
-	}
-
-	return nil
+	return s.enviar(alunoID, "agendamento", params)
 }
 
 // EnviarNotificacaoAtualizacaoStatus avisa sobre mudança de status (ex: confirmada, cancelada)
-func (s *EmailService) EnviarNotificacaoAtualizacaoStatus(emailDestino, nomeAluno, novoStatus string) error {
+func (s *EmailService) EnviarNotificacaoAtualizacaoStatus(alunoID, emailDestino, nomeAluno, novoStatus string) error {
 	
 	htmlContent := fmt.Sprintf(`
 		<h1>Olá, %s!</h1>
@@ -59,6 +78,238 @@ func (s *EmailService) EnviarNotificacaoAtualizacaoStatus(emailDestino, nomeAlun
 		Html:    htmlContent,
 	}
 
+	return s.enviar(alunoID, "atualizacao_status", params)
+}
+
+// EnviarConfirmacaoConsulta avisa o aluno que a consulta foi confirmada, com
+// o convite (.ics) anexo e, em sessões online, o link da sala.
+func (s *EmailService) EnviarConfirmacaoConsulta(alunoID, emailDestino, nomeAluno, nomePsicologo, dataHora, linkReuniao string, ics []byte) error {
+	local := "<p><strong>Modalidade:</strong> presencial</p>"
+	if linkReuniao != "" {
+		local = fmt.Sprintf(`<p><strong>Modalidade:</strong> online</p>
+		<p><strong>Link da sala:</strong> <a href="%s">%s</a></p>`, linkReuniao, linkReuniao)
+	}
+
+	htmlContent := fmt.Sprintf(`
+		<h1>Olá, %s!</h1>
+		<p>Sua consulta foi confirmada.</p>
+		<p><strong>Psicólogo:</strong> %s</p>
+		<p><strong>Data/Hora:</strong> %s</p>
+		%s
+		<p>O convite para o seu calendário segue em anexo.</p>
+		<br>
+		<p>Atenciosamente,<br>Equipe SGP</p>
+	`, nomeAluno, nomePsicologo, dataHora, local)
+
+	params := &resend.SendEmailRequest{
+		From:        "SGP <robot@sgp.codes>",
+		To:          []string{emailDestino},
+		Subject:     "Consulta confirmada - SGP",
+		Html:        htmlContent,
+		Attachments: []*resend.Attachment{{Content: ics, Filename: "consulta.ics", ContentType: "text/calendar"}},
+	}
+
+	return s.enviar(alunoID, "confirmacao", params)
+}
+
+// EnviarCancelamentoCalendario avisa que a consulta foi cancelada por um
+// feriado ou fechamento da clínica.
+func (s *EmailService) EnviarCancelamentoCalendario(alunoID, emailDestino, nomeAluno, dataHora, motivo string) error {
+	htmlContent := fmt.Sprintf(`
+		<h1>Olá, %s!</h1>
+		<p>Sua consulta de <strong>%s</strong> foi cancelada pela clínica.</p>
+		<p><strong>Motivo:</strong> %s</p>
+		<p>Acesse a plataforma para escolher um novo horário.</p>
+		<br>
+		<p>Atenciosamente,<br>Equipe SGP</p>
+	`, nomeAluno, dataHora, motivo)
+
+	params := &resend.SendEmailRequest{
+		From:    "SGP <robot@sgp.codes>",
+		To:      []string{emailDestino},
+		Subject: "Consulta cancelada pela clínica - SGP",
+		Html:    htmlContent,
+	}
+
+	return s.enviar(alunoID, "cancelamento_calendario", params)
+}
+
+// EnviarAlertaRisco avisa o psicólogo sobre uma resposta crítica de um aluno.
+// O e-mail traz apenas o necessário para a ação; as respostas ficam na plataforma.
+func (s *EmailService) EnviarAlertaRisco(alunoID, emailPsicologo, nomePsicologo, nomeAluno, motivo string) error {
+	htmlContent := fmt.Sprintf(`
+		<h1>Atenção, %s</h1>
+		<p>Uma resposta de <strong>%s</strong> indica possível risco e requer avaliação imediata.</p>
+		<p><strong>Motivo:</strong> %s</p>
+		<br>
+		<p>Acesse a plataforma para ver os detalhes.</p>
+	`, nomePsicologo, nomeAluno, motivo)
+
+	params := &resend.SendEmailRequest{
+		From:    "SGP <robot@sgp.codes>",
+		To:      []string{emailPsicologo},
+		Subject: "ALERTA: resposta crítica em instrumento - SGP",
+		Html:    htmlContent,
+	}
+
+	return s.enviar(alunoID, "alerta_risco", params)
+}
+
+// EnviarAlertaUrgencia avisa a equipe de plantão sobre um pedido urgente.
+func (s *EmailService) EnviarAlertaUrgencia(alunoID string, destinatarios []string, nomeAluno, dataHora, urgencia string) error {
+	htmlContent := fmt.Sprintf(`
+		<h1>Pedido de atendimento %s</h1>
+		<p><strong>%s</strong> solicitou atendimento e a triagem indicou urgência <strong>%s</strong>.</p>
+		<p><strong>Horário solicitado:</strong> %s</p>
+		<br>
+		<p>Acesse a plataforma para avaliar o pedido o quanto antes.</p>
+	`, urgencia, nomeAluno, urgencia, dataHora)
+
+	params := &resend.SendEmailRequest{
+		From:    "SGP <robot@sgp.codes>",
+		To:      destinatarios,
+		Subject: fmt.Sprintf("URGENTE: pedido de atendimento (%s) - SGP", urgencia),
+		Html:    htmlContent,
+	}
+
+	return s.enviar(alunoID, "alerta_urgencia", params)
+}
+
+// EnviarAlertaPlantao aciona o psicólogo de plantão (ou a equipe reserva) para
+// um alerta que precisa ser reconhecido na plataforma.
+func (s *EmailService) EnviarAlertaPlantao(alunoID string, destinatarios []string, nomeAluno, motivo, alertaID string) error {
+	htmlContent := fmt.Sprintf(`
+		<h1>Alerta de plantão</h1>
+		<p>Um caso de <strong>%s</strong> precisa de avaliação imediata.</p>
+		<p><strong>Motivo:</strong> %s</p>
+		<p><strong>Alerta:</strong> %s</p>
+		<br>
+		<p>Reconheça o alerta na plataforma; sem reconhecimento ele será repassado ao próximo plantonista.</p>
+	`, nomeAluno, motivo, alertaID)
+
+	params := &resend.SendEmailRequest{
+		From:    "SGP <robot@sgp.codes>",
+		To:      destinatarios,
+		Subject: "PLANTÃO: alerta aguardando reconhecimento - SGP",
+		Html:    htmlContent,
+	}
+
+	return s.enviar(alunoID, "alerta_plantao", params)
+}
+
+// enviar dispara o e-mail e registra o resultado, com sucesso ou falha, para
+// permitir reenvio e a exportação de dados do titular.
+func (s *EmailService) enviar(alunoID, tipo string, params *resend.SendEmailRequest) error {
+	agora := time.Now().UTC()
+	notificacao := model.Notificacao{
+		AlunoID:      alunoID,
+		Tipo:         tipo,
+		Destinatario: strings.Join(params.To, ","),
+		Assunto:      params.Subject,
+		Html:         params.Html,
+		Status:       "enviada",
+		Tentativas:   1,
+		CriadoEm:     agora,
+		EnviadoEm:    agora,
+	}
+	envio := s.iniciarEnvio(notificacao)
+
 	_, err := s.Client.Emails.Send(params)
+	if err != nil {
+		err = fmt.Errorf("erro ao enviar email pelo Resend: %v", err)
+	}
+
+	// Se o encerramento já registrou este envio como interrompido, não grava de novo.
+	if s.concluirEnvio(envio) && s.Registro != nil {
+		if err != nil {
+			notificacao.Status = "falhou"
+			notificacao.Erro = err.Error()
+			notificacao.EnviadoEm = time.Time{}
+		}
+		if _, errReg := s.Registro.RegistrarNotificacao(context.Background(), notificacao); errReg != nil {
+			log.Printf("ERRO ao registrar notificação para %s: %v", alunoID, errReg)
+		}
+	}
+
 	return err
-}
\ No newline at end of file
+}
+
+func (s *EmailService) iniciarEnvio(notificacao model.Notificacao) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.envios == nil {
+		s.envios = make(map[int]model.Notificacao)
+	}
+	s.proximo++
+	s.envios[s.proximo] = notificacao
+	return s.proximo
+}
+
+// concluirEnvio tira o envio da lista de envios em curso e diz se ele ainda
+// estava lá.
+func (s *EmailService) concluirEnvio(envio int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.envios[envio]
+	delete(s.envios, envio)
+	return ok
+}
+
+// RegistrarInterrompidos grava como "falhou" os envios que ainda não
+// terminaram, para que `sgpctl notificacoes reenviar` os retome. O
+// encerramento a chama quando o prazo das tarefas em segundo plano acaba,
+// antes de fechar o Firestore.
+func (s *EmailService) RegistrarInterrompidos(ctx context.Context) error {
+	s.mu.Lock()
+	interrompidos := s.envios
+	s.envios = nil
+	s.mu.Unlock()
+
+	if s.Registro == nil || len(interrompidos) == 0 {
+		return nil
+	}
+	falhas := 0
+	for _, notificacao := range interrompidos {
+		notificacao.Status = "falhou"
+		notificacao.Erro = "envio interrompido pelo encerramento da API"
+		notificacao.EnviadoEm = time.Time{}
+		if _, err := s.Registro.RegistrarNotificacao(ctx, notificacao); err != nil {
+			log.Printf("ERRO ao registrar envio interrompido para %s: %v", notificacao.AlunoID, err)
+			falhas++
+		}
+	}
+	if falhas > 0 {
+		return fmt.Errorf("%d de %d envio(s) interrompido(s) sem registro", falhas, len(interrompidos))
+	}
+	log.Printf("%d envio(s) interrompido(s) registrado(s) para reenvio", len(interrompidos))
+	return nil
+}
+
+// Reenviar tenta de novo uma notificação registrada, com o mesmo conteúdo, e
+// atualiza o registro. Anexos (como o .ics) não são guardados e não seguem.
+func (s *EmailService) Reenviar(ctx context.Context, notificacao model.Notificacao) (model.Notificacao, error) {
+	_, err := s.Client.Emails.Send(&resend.SendEmailRequest{
+		From:    "SGP <robot@sgp.codes>",
+		To:      strings.Split(notificacao.Destinatario, ","),
+		Subject: notificacao.Assunto,
+		Html:    notificacao.Html,
+	})
+
+	notificacao.Tentativas++
+	if err != nil {
+		err = fmt.Errorf("erro ao reenviar email pelo Resend: %v", err)
+		notificacao.Status = "falhou"
+		notificacao.Erro = err.Error()
+	} else {
+		notificacao.Status = "enviada"
+		notificacao.Erro = ""
+		notificacao.EnviadoEm = time.Now().UTC()
+	}
+
+	if s.Registro != nil {
+		if errReg := s.Registro.AtualizarNotificacao(ctx, notificacao.ID, notificacao); errReg != nil {
+			log.Printf("ERRO ao atualizar notificação %s: %v", notificacao.ID, errReg)
+		}
+	}
+	return notificacao, err
+}
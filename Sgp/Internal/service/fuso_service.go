@@ -0,0 +1,61 @@
+package service
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata" // imagens mínimas de contêiner não trazem /usr/share/zoneinfo
+)
+
+// FusoPadrao vale para quem não informou o próprio fuso e para as regras da clínica.
+const FusoPadrao = "America/Sao_Paulo"
+
+var fusoPadrao = carregarFusoPadrao()
+
+func carregarFusoPadrao() *time.Location {
+	loc, err := time.LoadLocation(FusoPadrao)
+	if err != nil {
+		panic(fmt.Sprintf("fuso padrão indisponível: %v", err))
+	}
+	return loc
+}
+
+// ValidarFuso aceita vazio (fuso padrão) ou um nome IANA, como "America/Manaus".
+func ValidarFuso(nome string) error {
+	if nome == "" {
+		return nil
+	}
+	if nome == "Local" {
+		return fmt.Errorf("fuso horário inválido '%s'", nome)
+	}
+	if _, err := time.LoadLocation(nome); err != nil {
+		return fmt.Errorf("fuso horário inválido '%s'", nome)
+	}
+	return nil
+}
+
+// Fuso devolve o fuso pelo nome IANA; vazio ou inválido cai no FusoPadrao.
+func Fuso(nome string) *time.Location {
+	if nome != "" && nome != "Local" {
+		if loc, err := time.LoadLocation(nome); err == nil {
+			return loc
+		}
+	}
+	return fusoPadrao
+}
+
+// FormatarDataHora escreve o instante no fuso de quem vai ler a mensagem.
+func FormatarDataHora(t time.Time, fuso *time.Location) string {
+	return t.In(fuso).Format("02/01/2006 às 15:04")
+}
+
+// InicioDoDia devolve a meia-noite local de uma data AAAA-MM-DD e a do dia
+// seguinte. Em dias com troca de horário o intervalo tem 23 ou 25 horas.
+func InicioDoDia(data string, fuso *time.Location) (time.Time, time.Time, error) {
+	d, err := time.Parse("2006-01-02", data)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	inicio := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, fuso)
+	fim := time.Date(d.Year(), d.Month(), d.Day()+1, 0, 0, 0, 0, fuso)
+	return inicio, fim, nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"fmt"
+	"sgp/Internal/model"
+	"time"
+)
+
+// MaxHorariosPorGrade limita quantos horários uma única grade pode gerar.
+const MaxHorariosPorGrade = 500
+
+// GradeHorarios descreve horários semanais repetidos entre duas datas.
+type GradeHorarios struct {
+	PsicologoID    string   `json:"psicologoId"`
+	De             string   `json:"de"`         // AAAA-MM-DD
+	Ate            string   `json:"ate"`        // AAAA-MM-DD, inclusive
+	DiasSemana     []int    `json:"diasSemana"` // 0 = domingo ... 6 = sábado
+	Horarios       []string `json:"horarios"`   // "HH:MM"
+	DuracaoMinutos int      `json:"duracaoMinutos"`
+	Modalidade     string   `json:"modalidade,omitempty"`
+	SalaID         string   `json:"salaId,omitempty"`
+	Tipo           string   `json:"tipo,omitempty"` // "grupo" para terapia em grupo e oficinas
+	Titulo         string   `json:"titulo,omitempty"`
+	Capacidade     int      `json:"capacidade,omitempty"`
+	FusoHorario    string   `json:"fusoHorario,omitempty"` // datas e horas são locais a este fuso
+}
+
+// GerarHorarios expande a grade em horários "disponivel", em ordem
+// cronológica e gravados em UTC. A hora local é preservada em trocas de
+// horário de verão; horas que não existem no dia da troca são adiantadas.
+func GerarHorarios(g GradeHorarios) ([]model.HorarioDisponivel, error) {
+	if g.PsicologoID == "" {
+		return nil, fmt.Errorf("o campo 'psicologoId' é obrigatório")
+	}
+	if err := ValidarFuso(g.FusoHorario); err != nil {
+		return nil, err
+	}
+	fuso := Fuso(g.FusoHorario)
+	de, err := time.Parse("2006-01-02", g.De)
+	if err != nil {
+		return nil, fmt.Errorf("data 'de' inválida, use AAAA-MM-DD")
+	}
+	ate, err := time.Parse("2006-01-02", g.Ate)
+	if err != nil {
+		return nil, fmt.Errorf("data 'ate' inválida, use AAAA-MM-DD")
+	}
+	if ate.Before(de) {
+		return nil, fmt.Errorf("a data 'ate' deve ser igual ou posterior a 'de'")
+	}
+	if g.DuracaoMinutos <= 0 {
+		return nil, fmt.Errorf("a duração deve ser positiva")
+	}
+
+	dias := map[time.Weekday]bool{}
+	for _, d := range g.DiasSemana {
+		if d < 0 || d > 6 {
+			return nil, fmt.Errorf("dia da semana inválido: %d", d)
+		}
+		dias[time.Weekday(d)] = true
+	}
+
+	var horarios []time.Time
+	for _, h := range g.Horarios {
+		t, err := time.Parse("15:04", h)
+		if err != nil {
+			return nil, fmt.Errorf("horário inválido '%s', use HH:MM", h)
+		}
+		horarios = append(horarios, t)
+	}
+	if len(dias) == 0 || len(horarios) == 0 {
+		return nil, fmt.Errorf("informe ao menos um dia da semana e um horário")
+	}
+
+	duracao := time.Duration(g.DuracaoMinutos) * time.Minute
+	var gerados []model.HorarioDisponivel
+	for dia := de; !dia.After(ate); dia = dia.AddDate(0, 0, 1) {
+		if !dias[dia.Weekday()] {
+			continue
+		}
+		for _, h := range horarios {
+			if len(gerados) == MaxHorariosPorGrade {
+				return nil, fmt.Errorf("a grade gera mais de %d horários; divida o período", MaxHorariosPorGrade)
+			}
+			inicio := time.Date(dia.Year(), dia.Month(), dia.Day(), h.Hour(), h.Minute(), 0, 0, fuso).UTC()
+			gerados = append(gerados, model.HorarioDisponivel{
+				PsicologoID: g.PsicologoID,
+				Inicio:      inicio,
+				Fim:         inicio.Add(duracao),
+				Status:      "disponivel",
+				Modalidade:  g.Modalidade,
+				SalaID:      g.SalaID,
+				Tipo:        g.Tipo,
+				Titulo:      g.Titulo,
+				Capacidade:  g.Capacidade,
+			})
+		}
+	}
+	return gerados, nil
+}
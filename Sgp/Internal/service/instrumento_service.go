@@ -0,0 +1,112 @@
+package service
+
+import (
+	"fmt"
+	"sgp/Internal/model"
+)
+
+var opcoesFrequencia = []model.OpcaoResposta{
+	{Valor: 0, Texto: "Nenhuma vez"},
+	{Valor: 1, Texto: "Vários dias"},
+	{Valor: 2, Texto: "Mais da metade dos dias"},
+	{Valor: 3, Texto: "Quase todos os dias"},
+}
+
+const instrucaoDuasSemanas = "Durante as últimas 2 semanas, com que frequência você foi incomodado(a) por qualquer um dos problemas abaixo?"
+
+// Instrumentos é o catálogo de instrumentos padronizados disponíveis, indexado por ID.
+var Instrumentos = map[string]model.Instrumento{
+	"phq-9": {
+		ID:        "phq-9",
+		Nome:      "PHQ-9 - Questionário sobre a Saúde do Paciente",
+		Instrucao: instrucaoDuasSemanas,
+		Itens: []string{
+			"Pouco interesse ou pouco prazer em fazer as coisas",
+			"Se sentir para baixo, deprimido(a) ou sem perspectiva",
+			"Dificuldade para pegar no sono ou permanecer dormindo, ou dormir mais do que de costume",
+			"Se sentir cansado(a) ou com pouca energia",
+			"Falta de apetite ou comendo demais",
+			"Se sentir mal consigo mesmo(a), ou achar que é um fracasso ou que decepcionou sua família ou você mesmo(a)",
+			"Dificuldade para se concentrar nas coisas, como ler o jornal ou ver televisão",
+			"Lentidão para se movimentar ou falar, a ponto de outras pessoas perceberem, ou o oposto: estar tão agitado(a) que fica andando de um lado para o outro mais do que de costume",
+			"Pensar em se ferir de alguma maneira ou que seria melhor estar morto(a)",
+		},
+		Opcoes: opcoesFrequencia,
+		Faixas: []model.FaixaGravidade{
+			{Min: 0, Max: 4, Gravidade: "minima"},
+			{Min: 5, Max: 9, Gravidade: "leve"},
+			{Min: 10, Max: 14, Gravidade: "moderada"},
+			{Min: 15, Max: 19, Gravidade: "moderadamente grave"},
+			{Min: 20, Max: 27, Gravidade: "grave"},
+		},
+		ItensCriticos: []model.ItemCritico{
+			{Indice: 8, ValorMinimo: 1, Motivo: "ideação suicida ou de autolesão (item 9)"},
+		},
+	},
+	"gad-7": {
+		ID:        "gad-7",
+		Nome:      "GAD-7 - Escala de Transtorno de Ansiedade Generalizada",
+		Instrucao: instrucaoDuasSemanas,
+		Itens: []string{
+			"Sentir-se nervoso(a), ansioso(a) ou muito tenso(a)",
+			"Não ser capaz de impedir ou de controlar as preocupações",
+			"Preocupar-se muito com diversas coisas",
+			"Dificuldade para relaxar",
+			"Ficar tão agitado(a) que se torna difícil permanecer sentado(a)",
+			"Ficar facilmente aborrecido(a) ou irritado(a)",
+			"Sentir medo como se algo horrível fosse acontecer",
+		},
+		Opcoes: opcoesFrequencia,
+		Faixas: []model.FaixaGravidade{
+			{Min: 0, Max: 4, Gravidade: "minima"},
+			{Min: 5, Max: 9, Gravidade: "leve"},
+			{Min: 10, Max: 14, Gravidade: "moderada"},
+			{Min: 15, Max: 21, Gravidade: "grave"},
+		},
+	},
+}
+
+// ResultadoInstrumento é o resultado da pontuação de uma aplicação.
+type ResultadoInstrumento struct {
+	Escore        int
+	Gravidade     string
+	ItensCriticos []int
+	Motivos       []string
+}
+
+// PontuarInstrumento soma as respostas, encontra a faixa de gravidade e
+// identifica itens críticos. Retorna erro se as respostas não casarem com o instrumento.
+func PontuarInstrumento(instrumento model.Instrumento, respostas []int) (ResultadoInstrumento, error) {
+	if len(respostas) != len(instrumento.Itens) {
+		return ResultadoInstrumento{}, fmt.Errorf("o instrumento %s tem %d itens, foram enviadas %d respostas",
+			instrumento.ID, len(instrumento.Itens), len(respostas))
+	}
+
+	validos := map[int]bool{}
+	for _, o := range instrumento.Opcoes {
+		validos[o.Valor] = true
+	}
+
+	var resultado ResultadoInstrumento
+	for i, r := range respostas {
+		if !validos[r] {
+			return ResultadoInstrumento{}, fmt.Errorf("resposta inválida no item %d: %d", i+1, r)
+		}
+		resultado.Escore += r
+	}
+
+	for _, f := range instrumento.Faixas {
+		if resultado.Escore >= f.Min && resultado.Escore <= f.Max {
+			resultado.Gravidade = f.Gravidade
+			break
+		}
+	}
+
+	for _, c := range instrumento.ItensCriticos {
+		if respostas[c.Indice] >= c.ValorMinimo {
+			resultado.ItensCriticos = append(resultado.ItensCriticos, c.Indice)
+			resultado.Motivos = append(resultado.Motivos, c.Motivo)
+		}
+	}
+	return resultado, nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"sgp/Internal/model"
+	"sort"
+	"strings"
+	"time"
+)
+
+// IdiomaPadrao é assumido para psicólogos sem idiomas cadastrados.
+const IdiomaPadrao = "portugues"
+
+// CriteriosMatching são as preferências informadas pelo aluno. Campos vazios
+// não restringem a busca.
+type CriteriosMatching struct {
+	Necessidades []string
+	Idioma       string
+	Modalidade   string
+}
+
+// SugestaoPsicologo é um psicólogo ranqueado para o aluno.
+type SugestaoPsicologo struct {
+	Psicologo             *model.Psicologo `json:"psicologo"`
+	Pontuacao             int              `json:"pontuacao"`
+	NecessidadesAtendidas []string         `json:"necessidadesAtendidas"`
+	HorariosDisponiveis   int              `json:"horariosDisponiveis"`
+	ProximoHorario        time.Time        `json:"proximoHorario"`
+}
+
+const (
+	pesoNecessidade      = 10
+	pesoHorarioProximo   = 5
+	limiteHorarioProximo = 7 * 24 * time.Hour
+	maxPontosHorarios    = 5
+)
+
+// PsicologoAtivo trata a ausência do campo como ativo.
+func PsicologoAtivo(p *model.Psicologo) bool {
+	return p.Ativo == nil || *p.Ativo
+}
+
+// RanquearPsicologos filtra os psicólogos ativos que atendem no idioma e na
+// modalidade pedidos e que têm horário livre no futuro, e os ordena por
+// necessidades atendidas e disponibilidade. horarios é indexado por psicólogo.
+func RanquearPsicologos(
+	psicologos []*model.Psicologo,
+	horarios map[string][]*model.HorarioDisponivel,
+	criterios CriteriosMatching,
+	agora time.Time,
+) []SugestaoPsicologo {
+	sugestoes := []SugestaoPsicologo{}
+
+	for _, p := range psicologos {
+		if !PsicologoAtivo(p) {
+			continue
+		}
+
+		idiomas := p.Idiomas
+		if len(idiomas) == 0 {
+			idiomas = []string{IdiomaPadrao}
+		}
+		if criterios.Idioma != "" && !contemNormalizado(idiomas, criterios.Idioma) {
+			continue
+		}
+		if criterios.Modalidade != "" && len(p.Modalidades) > 0 && !contemNormalizado(p.Modalidades, criterios.Modalidade) {
+			continue
+		}
+
+		sugestao := SugestaoPsicologo{Psicologo: p, NecessidadesAtendidas: []string{}}
+		for _, h := range horarios[p.ID] {
+			if h.Status != "disponivel" || h.Tipo == model.HorarioGrupo || !h.Inicio.After(agora) {
+				continue
+			}
+			sugestao.HorariosDisponiveis++
+			if sugestao.ProximoHorario.IsZero() || h.Inicio.Before(sugestao.ProximoHorario) {
+				sugestao.ProximoHorario = h.Inicio
+			}
+		}
+		if sugestao.HorariosDisponiveis == 0 {
+			continue
+		}
+
+		for _, n := range criterios.Necessidades {
+			if contemNormalizado(p.Especialidades, n) {
+				sugestao.NecessidadesAtendidas = append(sugestao.NecessidadesAtendidas, n)
+				sugestao.Pontuacao += pesoNecessidade
+			}
+		}
+		if sugestao.ProximoHorario.Sub(agora) <= limiteHorarioProximo {
+			sugestao.Pontuacao += pesoHorarioProximo
+		}
+		sugestao.Pontuacao += min(sugestao.HorariosDisponiveis, maxPontosHorarios)
+
+		sugestoes = append(sugestoes, sugestao)
+	}
+
+	sort.SliceStable(sugestoes, func(i, j int) bool {
+		if sugestoes[i].Pontuacao != sugestoes[j].Pontuacao {
+			return sugestoes[i].Pontuacao > sugestoes[j].Pontuacao
+		}
+		return sugestoes[i].ProximoHorario.Before(sugestoes[j].ProximoHorario)
+	})
+	return sugestoes
+}
+
+func contemNormalizado(lista []string, valor string) bool {
+	valor = normalizar(valor)
+	for _, v := range lista {
+		if normalizar(v) == valor {
+			return true
+		}
+	}
+	return false
+}
+
+var semAcento = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a",
+	"é", "e", "ê", "e",
+	"í", "i",
+	"ó", "o", "ô", "o", "õ", "o",
+	"ú", "u", "ü", "u",
+	"ç", "c",
+)
+
+func normalizar(s string) string {
+	return semAcento.Replace(strings.ToLower(strings.TrimSpace(s)))
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Limites do XLSX: o arquivo enviado é pequeno, mas descompactado ou pelas
+// referências de linha e coluna pode pedir memória sem fim.
+const (
+	TamanhoMaximoXMLPlanilha = 32 << 20
+	MaximoLinhasPlanilha     = 100000
+	MaximoColunasPlanilha    = 256
+)
+
+// LerPlanilha devolve as linhas de um CSV ou da primeira aba de um XLSX,
+// reconhecido pela assinatura zip. No CSV, o separador é ',' ou ';' (padrão
+// do Excel em português), escolhido pelo cabeçalho.
+func LerPlanilha(conteudo []byte) ([][]string, error) {
+	if bytes.HasPrefix(conteudo, []byte("PK\x03\x04")) {
+		return lerXLSX(conteudo)
+	}
+
+	conteudo = bytes.TrimPrefix(conteudo, []byte("\xef\xbb\xbf"))
+	cabecalho, _, _ := bytes.Cut(conteudo, []byte("\n"))
+	leitor := csv.NewReader(bytes.NewReader(conteudo))
+	if bytes.Count(cabecalho, []byte(";")) > bytes.Count(cabecalho, []byte(",")) {
+		leitor.Comma = ';'
+	}
+	leitor.FieldsPerRecord = -1
+	leitor.TrimLeadingSpace = true
+
+	// O leitor pula linhas em branco; elas voltam como linhas vazias para que
+	// o índice de cada registro continue sendo a sua linha no arquivo.
+	var linhas [][]string
+	for {
+		registro, err := leitor.Read()
+		if err == io.EOF {
+			return linhas, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("CSV inválido: %w", err)
+		}
+		numero, _ := leitor.FieldPos(0)
+		for len(linhas) < numero-1 {
+			linhas = append(linhas, nil)
+		}
+		linhas = append(linhas, registro)
+	}
+}
+
+type xlsxCelula struct {
+	Ref    string `xml:"r,attr"`
+	Tipo   string `xml:"t,attr"`
+	Valor  string `xml:"v"`
+	Inline string `xml:"is>t"`
+}
+
+type xlsxAba struct {
+	Linhas []struct {
+		Numero  int          `xml:"r,attr"`
+		Celulas []xlsxCelula `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+type xlsxTextos struct {
+	Itens []struct {
+		Texto   string   `xml:"t"`
+		Trechos []string `xml:"r>t"`
+	} `xml:"si"`
+}
+
+func lerXLSX(conteudo []byte) ([][]string, error) {
+	arquivo, err := zip.NewReader(bytes.NewReader(conteudo), int64(len(conteudo)))
+	if err != nil {
+		return nil, fmt.Errorf("XLSX inválido: %w", err)
+	}
+
+	var abas []*zip.File
+	var textos xlsxTextos
+	for _, f := range arquivo.File {
+		switch {
+		case f.Name == "xl/sharedStrings.xml":
+			if err := lerXML(f, &textos); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(f.Name, "xl/worksheets/sheet") && strings.HasSuffix(f.Name, ".xml"):
+			abas = append(abas, f)
+		}
+	}
+	if len(abas) == 0 {
+		return nil, fmt.Errorf("XLSX inválido: nenhuma aba encontrada")
+	}
+	// sheet1.xml é a primeira aba; a ordem numérica evita que sheet10 venha antes de sheet2.
+	sort.Slice(abas, func(i, j int) bool { return numeroAba(abas[i].Name) < numeroAba(abas[j].Name) })
+
+	compartilhados := make([]string, len(textos.Itens))
+	for i, item := range textos.Itens {
+		compartilhados[i] = item.Texto + strings.Join(item.Trechos, "")
+	}
+
+	var aba xlsxAba
+	if err := lerXML(abas[0], &aba); err != nil {
+		return nil, err
+	}
+
+	linhas := make([][]string, 0, len(aba.Linhas))
+	for _, l := range aba.Linhas {
+		if l.Numero > MaximoLinhasPlanilha || len(linhas) >= MaximoLinhasPlanilha {
+			return nil, fmt.Errorf("XLSX inválido: a planilha passa de %d linhas", MaximoLinhasPlanilha)
+		}
+		// Linhas sem células não aparecem no XML; o número da linha as repõe.
+		for len(linhas) < l.Numero-1 {
+			linhas = append(linhas, nil)
+		}
+		var linha []string
+		for i, c := range l.Celulas {
+			coluna := i
+			if c.Ref != "" {
+				coluna = indiceColuna(c.Ref)
+			}
+			if coluna < 0 || coluna >= MaximoColunasPlanilha {
+				return nil, fmt.Errorf("XLSX inválido: a célula %s está fora das %d colunas aceitas", c.Ref, MaximoColunasPlanilha)
+			}
+			for len(linha) <= coluna {
+				linha = append(linha, "")
+			}
+			switch c.Tipo {
+			case "s":
+				n, err := strconv.Atoi(c.Valor)
+				if err != nil || n < 0 || n >= len(compartilhados) {
+					return nil, fmt.Errorf("XLSX inválido: texto compartilhado %q na célula %s", c.Valor, c.Ref)
+				}
+				linha[coluna] = compartilhados[n]
+			case "inlineStr":
+				linha[coluna] = c.Inline
+			default:
+				linha[coluna] = c.Valor
+			}
+		}
+		linhas = append(linhas, linha)
+	}
+	return linhas, nil
+}
+
+func lerXML(f *zip.File, destino interface{}) error {
+	r, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("XLSX inválido: %w", err)
+	}
+	defer r.Close()
+	dados, err := io.ReadAll(io.LimitReader(r, TamanhoMaximoXMLPlanilha+1))
+	if err != nil {
+		return fmt.Errorf("XLSX inválido: %w", err)
+	}
+	if len(dados) > TamanhoMaximoXMLPlanilha {
+		return fmt.Errorf("XLSX inválido: %s passa de %d MB descompactado", f.Name, TamanhoMaximoXMLPlanilha>>20)
+	}
+	if err := xml.Unmarshal(dados, destino); err != nil {
+		return fmt.Errorf("XLSX inválido em %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+func numeroAba(nome string) int {
+	n, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(nome, "xl/worksheets/sheet"), ".xml"))
+	return n
+}
+
+// indiceColuna converte a referência "C7" no índice 2. Referências com mais
+// letras do que o Excel admite (XFD) viram -1.
+func indiceColuna(ref string) int {
+	n := 0
+	for i, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		if i == 3 {
+			return -1
+		}
+		n = n*26 + int(r-'A'+1)
+	}
+	return n - 1
+}
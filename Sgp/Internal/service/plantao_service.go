@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sort"
+	"time"
+)
+
+const PrazoReconhecimentoPadrao = 15 * time.Minute
+
+var ErrAlertaEncerrado = errors.New("o alerta já foi reconhecido ou encerrado")
+
+// PlantaoService encaminha alertas urgentes a quem está de plantão e repassa
+// ao próximo plantonista quando ninguém reconhece dentro do Prazo.
+type PlantaoService struct {
+	Repo          repository.PlantaoRepository
+	PsicologoRepo repository.PsicologoRepository
+	AlunoRepo     repository.AlunoRepository
+	EmailService  *EmailService
+	Prazo         time.Duration
+
+	// EmailsReserva é acionado quando não há (mais) plantonista disponível.
+	EmailsReserva []string
+
+	// Tarefas acompanha os e-mails de alerta; nil só os dispara.
+	Tarefas *Tarefas
+}
+
+func NewPlantaoService(
+	repo repository.PlantaoRepository,
+	psicologoRepo repository.PsicologoRepository,
+	alunoRepo repository.AlunoRepository,
+	emailService *EmailService,
+) *PlantaoService {
+	return &PlantaoService{
+		Repo:          repo,
+		PsicologoRepo: psicologoRepo,
+		AlunoRepo:     alunoRepo,
+		EmailService:  emailService,
+		Prazo:         PrazoReconhecimentoPadrao,
+	}
+}
+
+// Plantonistas retorna os turnos ativos no instante informado, na ordem de
+// acionamento e sem repetir psicólogo.
+func (s *PlantaoService) Plantonistas(ctx context.Context, em time.Time) ([]*model.TurnoPlantao, error) {
+	turnos, err := s.Repo.ListarTurnos(ctx, em, em.Add(time.Nanosecond))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(turnos, func(i, j int) bool {
+		if turnos[i].Ordem != turnos[j].Ordem {
+			return turnos[i].Ordem < turnos[j].Ordem
+		}
+		return turnos[i].Inicio.Before(turnos[j].Inicio)
+	})
+
+	vistos := map[string]bool{}
+	ativos := []*model.TurnoPlantao{}
+	for _, t := range turnos {
+		if !vistos[t.PsicologoID] {
+			vistos[t.PsicologoID] = true
+			ativos = append(ativos, t)
+		}
+	}
+	return ativos, nil
+}
+
+// AbrirAlerta registra o alerta e aciona o primeiro plantonista.
+func (s *PlantaoService) AbrirAlerta(ctx context.Context, alerta model.AlertaPlantao) (*model.AlertaPlantao, error) {
+	agora := time.Now().UTC()
+	alerta.Status = model.AlertaPendente
+	alerta.CriadoEm = agora
+	alerta.Acionados = []string{}
+
+	destinatarios, err := s.acionarProximo(ctx, &alerta, agora)
+	if err != nil {
+		return nil, err
+	}
+
+	criado, err := s.Repo.CriarAlerta(ctx, alerta)
+	if err != nil {
+		return nil, err
+	}
+	s.notificar(criado, destinatarios)
+	return criado, nil
+}
+
+// Escalonar repassa os alertas pendentes cujo prazo de reconhecimento venceu.
+// Cada repasse só é gravado se o alerta não mudou desde a leitura; um alerta
+// reconhecido no meio do caminho é deixado como está. Falhas num alerta são
+// registradas e não impedem os demais. Retorna quantos alertas foram repassados.
+func (s *PlantaoService) Escalonar(ctx context.Context, agora time.Time) (int, error) {
+	pendentes, err := s.Repo.ListarAlertasPorStatus(ctx, model.AlertaPendente)
+	if err != nil {
+		return 0, err
+	}
+
+	repassados := 0
+	for _, alerta := range pendentes {
+		if agora.Sub(alerta.AcionadoEm) < s.Prazo {
+			continue
+		}
+
+		lidoEm := alerta.AcionadoEm
+		destinatarios, err := s.acionarProximo(ctx, alerta, agora)
+		if err != nil {
+			log.Printf("ERRO ao escolher o próximo plantonista do alerta %s: %v", alerta.ID, err)
+			continue
+		}
+		if err := s.Repo.RepassarAlerta(ctx, alerta.ID, lidoEm, *alerta); err != nil {
+			if !errors.Is(err, repository.ErrTransicaoInvalida) {
+				log.Printf("ERRO ao repassar alerta de plantão %s: %v", alerta.ID, err)
+			}
+			continue
+		}
+		s.notificar(alerta, destinatarios)
+		repassados++
+	}
+	return repassados, nil
+}
+
+// Reconhecer encerra o alerta em nome do psicólogo que assumiu o caso.
+func (s *PlantaoService) Reconhecer(ctx context.Context, id, psicologoID string) (*model.AlertaPlantao, error) {
+	alerta, err := s.Repo.ReconhecerAlerta(ctx, id, psicologoID, time.Now().UTC())
+	if errors.Is(err, repository.ErrTransicaoInvalida) {
+		return nil, ErrAlertaEncerrado
+	}
+	if err != nil {
+		return nil, err
+	}
+	return alerta, nil
+}
+
+// IniciarEscalonamento roda Escalonar a cada intervalo até o contexto ser
+// cancelado, sem interromper um escalonamento em curso.
+func (s *PlantaoService) IniciarEscalonamento(ctx context.Context, intervalo time.Duration) {
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case agora := <-ticker.C:
+			if n, err := s.Escalonar(context.WithoutCancel(ctx), agora.UTC()); err != nil {
+				log.Printf("ERRO ao escalonar alertas de plantão: %v", err)
+			} else if n > 0 {
+				log.Printf("%d alerta(s) de plantão repassado(s)", n)
+			}
+		}
+	}
+}
+
+// acionarProximo escolhe o próximo plantonista ainda não acionado e devolve os
+// e-mails a avisar. Sem plantonista, o alerta sai da fila e vai para a reserva.
+func (s *PlantaoService) acionarProximo(ctx context.Context, alerta *model.AlertaPlantao, agora time.Time) ([]string, error) {
+	plantonistas, err := s.Plantonistas(ctx, agora)
+	if err != nil {
+		return nil, err
+	}
+
+	alerta.AcionadoEm = agora
+	for _, t := range plantonistas {
+		if contem(alerta.Acionados, t.PsicologoID) {
+			continue
+		}
+		psicologo, err := s.PsicologoRepo.BuscarPsicologoPorID(ctx, t.PsicologoID)
+		if err != nil {
+			log.Printf("ERRO ao buscar plantonista %s: %v", t.PsicologoID, err)
+			continue
+		}
+		alerta.Acionados = append(alerta.Acionados, t.PsicologoID)
+		return []string{psicologo.Email}, nil
+	}
+
+	alerta.Status = model.AlertaSemPlantao
+	return s.EmailsReserva, nil
+}
+
+func (s *PlantaoService) notificar(alerta *model.AlertaPlantao, destinatarios []string) {
+	if len(destinatarios) == 0 {
+		log.Printf("AVISO: alerta de plantão %s sem ninguém para acionar", alerta.ID)
+		return
+	}
+	if s.EmailService == nil {
+		return
+	}
+
+	nomeAluno := alerta.AlunoID
+	if aluno, err := s.AlunoRepo.BuscarAlunoPorID(context.Background(), alerta.AlunoID); err == nil {
+		nomeAluno = NomeDeTratamento(aluno)
+	}
+
+	alunoID, motivo, alertaID := alerta.AlunoID, alerta.Motivo, alerta.ID
+	s.Tarefas.Disparar(func() {
+		if err := s.EmailService.EnviarAlertaPlantao(alunoID, destinatarios, nomeAluno, motivo, alertaID); err != nil {
+			log.Printf("ERRO ao enviar alerta de plantão (Resend): %v", err)
+		}
+	})
+}
+
+func contem(lista []string, valor string) bool {
+	for _, v := range lista {
+		if v == valor {
+			return true
+		}
+	}
+	return false
+}
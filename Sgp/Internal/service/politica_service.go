@@ -0,0 +1,101 @@
+package service
+
+import (
+	"fmt"
+	"sgp/Internal/model"
+	"time"
+)
+
+// PoliticaAgendamento reúne os limites por aluno aplicados em AgendarConsulta
+// e no cancelamento. Valores zerados desligam a regra correspondente.
+type PoliticaAgendamento struct {
+	MaxPedidosAtivos    int           // consultas aguardando aprovação ou confirmadas ainda por acontecer
+	MaxSessoesSemana    int           // consultas ativas na semana do horário pedido
+	AntecedenciaMinima  time.Duration // entre o pedido e o Inicio
+	PrazoCancelamento   time.Duration // o aluno só cancela até este tempo antes do Inicio
+	FaltasParaSuspensao int           // faltas dentro de JanelaFaltas que suspendem o aluno
+	JanelaFaltas        time.Duration
+	DuracaoSuspensao    time.Duration // contada a partir da última falta
+}
+
+// PoliticaPadrao não limita nada: cada regra só vale quando configurada.
+var PoliticaPadrao = PoliticaAgendamento{}
+
+// VerificarAgendamento explica por que o aluno não pode agendar no horário
+// que começa em inicio, ou retorna nil. consultas são as do próprio aluno.
+func (p PoliticaAgendamento) VerificarAgendamento(consultas []*model.Consulta, inicio, agora time.Time) error {
+	if ate, suspenso := p.SuspensoAte(consultas, agora); suspenso {
+		return fmt.Errorf("agendamento suspenso ate %s por %d faltas nos ultimos %d dias",
+			ate.In(fusoPadrao).Format("02/01/2006"), p.FaltasParaSuspensao, int(p.JanelaFaltas.Hours()/24))
+	}
+
+	if p.AntecedenciaMinima > 0 && inicio.Sub(agora) < p.AntecedenciaMinima {
+		return fmt.Errorf("a consulta precisa ser agendada com pelo menos %s de antecedencia", formatarDuracao(p.AntecedenciaMinima))
+	}
+
+	ativos, naSemana := 0, 0
+	semana := chaveSemana(inicio)
+	for _, c := range consultas {
+		if !ConsultaAtiva(c) {
+			continue
+		}
+		if c.Fim.After(agora) {
+			ativos++
+		}
+		if chaveSemana(c.Inicio) == semana {
+			naSemana++
+		}
+	}
+
+	if p.MaxPedidosAtivos > 0 && ativos >= p.MaxPedidosAtivos {
+		return fmt.Errorf("o aluno ja tem %d pedidos ativos; o limite e %d", ativos, p.MaxPedidosAtivos)
+	}
+	if p.MaxSessoesSemana > 0 && naSemana >= p.MaxSessoesSemana {
+		return fmt.Errorf("o aluno ja tem %d sessao(oes) na semana de %s; o limite e %d",
+			naSemana, inicio.In(fusoPadrao).Format("02/01/2006"), p.MaxSessoesSemana)
+	}
+	return nil
+}
+
+// VerificarCancelamento aplica o prazo mínimo para o aluno cancelar.
+func (p PoliticaAgendamento) VerificarCancelamento(consulta *model.Consulta, agora time.Time) error {
+	if p.PrazoCancelamento > 0 && consulta.Inicio.Sub(agora) < p.PrazoCancelamento {
+		return fmt.Errorf("cancelamentos pelo aluno so sao aceitos ate %s antes da consulta", formatarDuracao(p.PrazoCancelamento))
+	}
+	return nil
+}
+
+// SuspensoAte indica se o aluno está suspenso por faltas e até quando.
+func (p PoliticaAgendamento) SuspensoAte(consultas []*model.Consulta, agora time.Time) (time.Time, bool) {
+	if p.FaltasParaSuspensao <= 0 {
+		return time.Time{}, false
+	}
+
+	faltas := 0
+	var ultima time.Time
+	for _, c := range consultas {
+		if c.Status != "falta" || (p.JanelaFaltas > 0 && agora.Sub(c.Inicio) > p.JanelaFaltas) {
+			continue
+		}
+		faltas++
+		if c.Inicio.After(ultima) {
+			ultima = c.Inicio
+		}
+	}
+
+	if faltas < p.FaltasParaSuspensao {
+		return time.Time{}, false
+	}
+	ate := ultima.Add(p.DuracaoSuspensao)
+	return ate, agora.Before(ate)
+}
+
+func formatarDuracao(d time.Duration) string {
+	if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%d dia(s)", int(d.Hours()/24))
+	}
+	if d >= time.Hour && d%time.Hour == 0 {
+		return fmt.Sprintf("%d hora(s)", int(d.Hours()))
+	}
+	return fmt.Sprintf("%d minuto(s)", int(d.Minutes()))
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"time"
+)
+
+const ToleranciaFaltaPadrao = 20 * time.Minute
+
+// PresencaService marca como "falta" as consultas confirmadas que passaram da
+// tolerância sem check-in.
+type PresencaService struct {
+	Repo       repository.ConsultaRepository
+	Tolerancia time.Duration
+}
+
+func NewPresencaService(repo repository.ConsultaRepository) *PresencaService {
+	return &PresencaService{Repo: repo, Tolerancia: ToleranciaFaltaPadrao}
+}
+
+// MarcarFaltas retorna quantas consultas foram marcadas.
+func (s *PresencaService) MarcarFaltas(ctx context.Context, agora time.Time) (int, error) {
+	confirmadas, err := s.Repo.ListarConsultasPorStatus(ctx, "confirmada")
+	if err != nil {
+		return 0, err
+	}
+
+	marcadas := 0
+	for _, c := range confirmadas {
+		if agora.Sub(c.Inicio) < s.Tolerancia {
+			continue
+		}
+		err := s.Repo.RegistrarPresenca(ctx, c.ID, "confirmada", "falta", agora)
+		if errors.Is(err, repository.ErrTransicaoInvalida) {
+			continue // check-in feito entre a listagem e a marcação
+		}
+		if err != nil {
+			return marcadas, err
+		}
+		marcadas++
+	}
+	return marcadas, nil
+}
+
+// IniciarMarcacaoFaltas roda MarcarFaltas a cada intervalo até o contexto ser
+// cancelado; uma rodada já iniciada vai até o fim.
+func (s *PresencaService) IniciarMarcacaoFaltas(ctx context.Context, intervalo time.Duration) {
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case agora := <-ticker.C:
+			if n, err := s.MarcarFaltas(context.WithoutCancel(ctx), agora); err != nil {
+				log.Printf("ERRO ao marcar faltas: %v", err)
+			} else if n > 0 {
+				log.Printf("%d consulta(s) marcada(s) como falta", n)
+			}
+		}
+	}
+}
+
+// ConsultaCancelada diz se a consulta não vai acontecer por cancelamento ou
+// recusa; é o conjunto usado por CalcularPresenca e CalcularRelatorio.
+func ConsultaCancelada(status string) bool {
+	switch status {
+	case "cancelada pelo aluno", "cancelada pela clinica", "cancelada", "recusada":
+		return true
+	}
+	return false
+}
+
+// CalcularPresenca considera apenas consultas cujo início já passou.
+func CalcularPresenca(consultas []*model.Consulta, agora time.Time) model.EstatisticasPresenca {
+	var e model.EstatisticasPresenca
+	for _, c := range consultas {
+		if c.Inicio.After(agora) {
+			continue
+		}
+		switch {
+		case c.Status == "concluida":
+			e.Concluidas++
+		case c.Status == "em andamento":
+			e.EmAndamento++
+		case c.Status == "falta":
+			e.Faltas++
+		case ConsultaCancelada(c.Status):
+			e.Canceladas++
+		default:
+			continue
+		}
+		e.Total++
+	}
+
+	presentes := e.Concluidas + e.EmAndamento
+	if presentes+e.Faltas > 0 {
+		e.TaxaComparecimento = float64(presentes) / float64(presentes+e.Faltas)
+	}
+	return e
+}
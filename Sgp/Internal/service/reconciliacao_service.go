@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/reqctx"
+	"sort"
+	"time"
+)
+
+// AtorReconciliador aparece na auditoria como autor das correções feitas
+// pela execução periódica.
+var AtorReconciliador = reqctx.Ator{ID: "reconciliador", Papel: reqctx.PapelAdmin}
+
+var (
+	statusHorarios = []string{"disponivel", "agendado", "reservado", "lotado", "bloqueado"}
+
+	// statusConsultaAtivos são os status em que uma consulta ainda vai
+	// acontecer; só eles são cancelados quando falta uma referência.
+	statusConsultaAtivos = []string{"aguardando aprovacao", "confirmada", "em andamento", "lista de espera"}
+	// statusConsultaRealizados também seguram o horário, mas não são alterados.
+	statusConsultaRealizados = []string{"concluida", "falta"}
+)
+
+// Reconciliador encontra o que apagamentos e trocas de status fora de
+// transação deixam para trás entre consultas, horários e cadastros, e
+// corrige quando chamado com aplicar. As verificações de horário olham só
+// para horários futuros, já que os passados não voltam a ser oferecidos.
+// Cada correção confere, na transação que grava, que o dado ainda está como
+// foi visto; se mudou, a correção é descartada.
+type Reconciliador struct {
+	AlunoRepo     repository.AlunoRepository
+	PsicologoRepo repository.PsicologoRepository
+	HorarioRepo   repository.HorarioDisponivelRepository
+	ConsultaRepo  repository.ConsultaRepository
+
+	// Tipos limita a execução a alguns tipos de inconsistência; vazio trata todos.
+	Tipos []string
+}
+
+func NewReconciliador(
+	alunoRepo repository.AlunoRepository,
+	psicologoRepo repository.PsicologoRepository,
+	horarioRepo repository.HorarioDisponivelRepository,
+	consultaRepo repository.ConsultaRepository,
+) *Reconciliador {
+	return &Reconciliador{
+		AlunoRepo:     alunoRepo,
+		PsicologoRepo: psicologoRepo,
+		HorarioRepo:   horarioRepo,
+		ConsultaRepo:  consultaRepo,
+	}
+}
+
+// Reconciliar verifica primeiro as consultas e depois os horários. O estado
+// carregado é atualizado a cada correção, inclusive na simulação, para que
+// um horário liberado pelo cancelamento de uma consulta órfã apareça no
+// mesmo relatório. Falhas ao corrigir ficam no item e não interrompem a execução.
+func (s *Reconciliador) Reconciliar(ctx context.Context, agora time.Time, aplicar bool) (*model.RelatorioReconciliacao, error) {
+	alunos, err := s.AlunoRepo.ListarAlunos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	psicologos, err := s.PsicologoRepo.ListarPsicologos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	alunoExiste := map[string]bool{}
+	for _, a := range alunos {
+		alunoExiste[a.ID] = true
+	}
+	psicologoExiste := map[string]bool{}
+	for _, p := range psicologos {
+		psicologoExiste[p.ID] = true
+	}
+
+	horarios := map[string]*model.HorarioDisponivel{}
+	for _, status := range statusHorarios {
+		lista, err := s.HorarioRepo.ListarHorariosPorStatus(ctx, status)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range lista {
+			horarios[h.ID] = h
+		}
+	}
+	var consultas []*model.Consulta
+	for _, status := range append(statusConsultaAtivos, statusConsultaRealizados...) {
+		lista, err := s.ConsultaRepo.ListarConsultasPorStatus(ctx, status)
+		if err != nil {
+			return nil, err
+		}
+		consultas = append(consultas, lista...)
+	}
+
+	relatorio := &model.RelatorioReconciliacao{
+		ExecutadoEm:     agora,
+		Aplicado:        aplicar,
+		PorTipo:         map[string]int{},
+		Inconsistencias: []*model.Inconsistencia{},
+	}
+
+	for _, c := range consultas {
+		if !consultaAtiva(c.Status) {
+			continue
+		}
+		inc := &model.Inconsistencia{Entidade: "consulta", EntidadeID: c.ID, Correcao: "cancelar pela clínica"}
+		switch {
+		case horarios[c.HorarioID] == nil:
+			inc.Tipo = model.InconsistenciaConsultaSemHorario
+			inc.Detalhe = fmt.Sprintf("horário %s não existe", c.HorarioID)
+		case !alunoExiste[c.AlunoID]:
+			inc.Tipo = model.InconsistenciaConsultaSemAluno
+			inc.Detalhe = fmt.Sprintf("aluno %s não existe", c.AlunoID)
+		case !psicologoExiste[c.PsicologoID]:
+			inc.Tipo = model.InconsistenciaConsultaSemPsicologo
+			inc.Detalhe = fmt.Sprintf("psicólogo %s não existe", c.PsicologoID)
+		default:
+			continue
+		}
+		if s.tratar(relatorio, inc, aplicar, func() error {
+			return s.ConsultaRepo.TrocarStatusConsulta(ctx, c.ID, c.Status, "cancelada pela clinica")
+		}) {
+			refletirCancelamento(horarios[c.HorarioID], c, consultas)
+		}
+	}
+
+	porHorario := map[string][]*model.Consulta{}
+	for _, c := range consultas {
+		porHorario[c.HorarioID] = append(porHorario[c.HorarioID], c)
+	}
+
+	futuros := make([]*model.HorarioDisponivel, 0, len(horarios))
+	for _, h := range horarios {
+		if h.Inicio.After(agora) {
+			futuros = append(futuros, h)
+		}
+	}
+	sort.Slice(futuros, func(i, j int) bool { return futuros[i].Inicio.Before(futuros[j].Inicio) })
+	for _, h := range futuros {
+		ocupantes, espera := repository.ContarVagas(porHorario[h.ID])
+		s.verificarHorario(ctx, relatorio, h, aplicar, psicologoExiste[h.PsicologoID], alunoExiste, ocupantes, espera)
+	}
+	return relatorio, nil
+}
+
+func (s *Reconciliador) verificarHorario(ctx context.Context, relatorio *model.RelatorioReconciliacao, h *model.HorarioDisponivel, aplicar, temPsicologo bool, alunoExiste map[string]bool, ocupantes, espera int) {
+	novo := func(tipo, detalhe, correcao string) *model.Inconsistencia {
+		return &model.Inconsistencia{Tipo: tipo, Entidade: "horario", EntidadeID: h.ID, Detalhe: detalhe, Correcao: correcao}
+	}
+
+	// Sem psicólogo ninguém atende o horário; bloqueá-lo basta, e as
+	// consultas dele já foram canceladas acima.
+	if !temPsicologo {
+		if h.Status == "bloqueado" {
+			return
+		}
+		inc := novo(model.InconsistenciaHorarioSemPsicologo, fmt.Sprintf("psicólogo %s não existe", h.PsicologoID), "bloquear o horário")
+		s.tratar(relatorio, inc, aplicar, func() error {
+			return s.HorarioRepo.ReconciliarStatusHorario(ctx, h.ID, h.Status, "bloqueado")
+		})
+		return
+	}
+
+	if h.Tipo == model.HorarioGrupo {
+		if h.Inscritos == ocupantes && h.ListaEspera == espera {
+			return
+		}
+		detalhe := fmt.Sprintf("registra %d inscrito(s) e %d na espera; as consultas somam %d e %d", h.Inscritos, h.ListaEspera, ocupantes, espera)
+		inc := novo(model.InconsistenciaContagemGrupo, detalhe, "recalcular as vagas")
+		s.tratar(relatorio, inc, aplicar, func() error {
+			return s.HorarioRepo.RecontarVagasGrupo(ctx, h.ID, h.Inscritos, h.ListaEspera)
+		})
+		return
+	}
+
+	switch {
+	case h.Status == "agendado" && ocupantes == 0:
+		inc := novo(model.InconsistenciaHorarioAgendadoLivre, "nenhuma consulta ativa usa o horário", "liberar o horário")
+		s.tratar(relatorio, inc, aplicar, func() error {
+			return s.HorarioRepo.ReconciliarStatusHorario(ctx, h.ID, h.Status, "disponivel")
+		})
+	case (h.Status == "disponivel" || h.Status == "reservado") && ocupantes > 0:
+		// Deixar como está permitiria um segundo agendamento no mesmo horário.
+		inc := novo(model.InconsistenciaHorarioLivreOcupado, fmt.Sprintf("%d consulta(s) ativa(s) no horário %s", ocupantes, h.Status), "marcar como agendado")
+		s.tratar(relatorio, inc, aplicar, func() error {
+			return s.HorarioRepo.ReconciliarStatusHorario(ctx, h.ID, h.Status, "agendado")
+		})
+	case h.Status == "reservado" && !alunoExiste[h.ReservadoPara]:
+		inc := novo(model.InconsistenciaReservaSemAluno, fmt.Sprintf("reservado para o aluno %s, que não existe", h.ReservadoPara), "liberar a reserva")
+		s.tratar(relatorio, inc, aplicar, func() error {
+			return s.HorarioRepo.LiberarReserva(ctx, h.ID, h.ReservadoPara)
+		})
+	}
+}
+
+// tratar registra a inconsistência e, com aplicar, executa a correção. Retorna
+// se o estado deve ser considerado corrigido: sempre na simulação, e na
+// aplicação só quando a correção deu certo. ErrTransicaoInvalida quer dizer
+// que o dado mudou desde a leitura; a correção é descartada, sem falha.
+func (s *Reconciliador) tratar(relatorio *model.RelatorioReconciliacao, inc *model.Inconsistencia, aplicar bool, corrigir func() error) bool {
+	if !s.trata(inc.Tipo) {
+		return false
+	}
+	relatorio.Total++
+	relatorio.PorTipo[inc.Tipo]++
+	relatorio.Inconsistencias = append(relatorio.Inconsistencias, inc)
+	if !aplicar {
+		return true
+	}
+	err := corrigir()
+	if errors.Is(err, repository.ErrTransicaoInvalida) {
+		inc.Descartada = true
+		relatorio.Descartadas++
+		return false
+	}
+	if err != nil {
+		inc.Erro = err.Error()
+		relatorio.Falhas++
+		return false
+	}
+	inc.Corrigida = true
+	relatorio.Corrigidas++
+	return true
+}
+
+func (s *Reconciliador) trata(tipo string) bool {
+	if len(s.Tipos) == 0 {
+		return true
+	}
+	for _, t := range s.Tipos {
+		if t == tipo {
+			return true
+		}
+	}
+	return false
+}
+
+// IniciarReconciliacao roda Reconciliar a cada intervalo até o contexto ser
+// cancelado; a execução em curso termina antes. Sem aplicar, as
+// inconsistências só aparecem no log.
+func (s *Reconciliador) IniciarReconciliacao(ctx context.Context, intervalo time.Duration, aplicar bool) {
+	ctx = reqctx.ComAtor(ctx, AtorReconciliador)
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case agora := <-ticker.C:
+			relatorio, err := s.Reconciliar(context.WithoutCancel(ctx), agora, aplicar)
+			if err != nil {
+				log.Printf("ERRO na reconciliação: %v", err)
+				continue
+			}
+			for _, inc := range relatorio.Inconsistencias {
+				if inc.Erro != "" {
+					log.Printf("ERRO ao corrigir %s %s (%s): %s", inc.Entidade, inc.EntidadeID, inc.Tipo, inc.Erro)
+				} else if !aplicar {
+					log.Printf("reconciliação: %s %s (%s): %s", inc.Entidade, inc.EntidadeID, inc.Tipo, inc.Detalhe)
+				}
+			}
+			if relatorio.Total > 0 {
+				log.Printf("reconciliação: %d inconsistência(s), %d corrigida(s), %d descartada(s)", relatorio.Total, relatorio.Corrigidas, relatorio.Descartadas)
+			}
+		}
+	}
+}
+
+// refletirCancelamento faz no estado carregado o que o cancelamento de c faz
+// no repositório: devolve o horário individual ou a vaga do grupo, que passa
+// ao primeiro da lista de espera.
+func refletirCancelamento(h *model.HorarioDisponivel, c *model.Consulta, consultas []*model.Consulta) {
+	anterior := c.Status
+	c.Status = "cancelada pela clinica"
+	if h == nil || !repository.LiberaVaga(anterior, c.Status) {
+		return
+	}
+	if !c.Grupo {
+		repository.LiberarHorario(h)
+		return
+	}
+
+	var proximo *model.Consulta
+	if repository.OcupaVaga(anterior) {
+		for _, e := range consultas {
+			if e.HorarioID == h.ID && e.Status == "lista de espera" && (proximo == nil || e.DataAgendamento.Before(proximo.DataAgendamento)) {
+				proximo = e
+			}
+		}
+	}
+	if repository.LiberarVagaNoGrupo(h, anterior, proximo != nil) {
+		proximo.Status = "aguardando aprovacao"
+	}
+}
+
+func consultaAtiva(status string) bool {
+	for _, s := range statusConsultaAtivos {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,297 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"sgp/Internal/model"
+	"sgp/Internal/repository"
+	"sgp/Internal/repository/memoria"
+	"sgp/Internal/service"
+	"testing"
+	"time"
+)
+
+// cenario é um banco em memória com um psicólogo e um aluno cadastrados.
+type cenario struct {
+	ctx         context.Context
+	banco       *memoria.Banco
+	alunos      *memoria.AlunoRepository
+	psicologos  *memoria.PsicologoRepository
+	horarios    *memoria.HorarioDisponivelRepository
+	consultas   *memoria.ConsultaRepository
+	psicologoID string
+	alunoID     string
+	agora       time.Time
+}
+
+func novoCenario(t *testing.T) *cenario {
+	t.Helper()
+	banco := memoria.NovoBanco()
+	c := &cenario{
+		ctx:        context.Background(),
+		banco:      banco,
+		alunos:     memoria.NewAlunoRepository(banco),
+		psicologos: memoria.NewPsicologoRepository(banco),
+		horarios:   memoria.NewHorarioDisponivelRepository(banco),
+		consultas:  memoria.NewConsultaRepository(banco),
+		agora:      time.Now(),
+	}
+	p, err := c.psicologos.CriarPsicologo(c.ctx, model.Psicologo{Nome: "Helena"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := c.alunos.CriarAluno(c.ctx, model.Aluno{Nome: "Ana"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.psicologoID, c.alunoID = p.ID, a.ID
+	return c
+}
+
+func (c *cenario) horario(t *testing.T, h model.HorarioDisponivel) string {
+	t.Helper()
+	h.PsicologoID = c.psicologoID
+	h.Inicio = c.agora.Add(48 * time.Hour)
+	h.Fim = h.Inicio.Add(time.Hour)
+	criado, err := c.horarios.CriarHorario(c.ctx, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return criado.ID
+}
+
+func (c *cenario) agendar(t *testing.T, horarioID, alunoID string) string {
+	t.Helper()
+	consulta, err := c.consultas.AgendarConsulta(c.ctx, model.Consulta{AlunoID: alunoID, HorarioID: horarioID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return consulta.ID
+}
+
+func (c *cenario) reconciliar(t *testing.T, horarios repository.HorarioDisponivelRepository, aplicar bool) *model.RelatorioReconciliacao {
+	t.Helper()
+	relatorio, err := service.NewReconciliador(c.alunos, c.psicologos, horarios, c.consultas).Reconciliar(c.ctx, c.agora, aplicar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return relatorio
+}
+
+func (c *cenario) statusHorario(t *testing.T, id string) *model.HorarioDisponivel {
+	t.Helper()
+	h, err := c.horarios.BuscarHorarioPorID(c.ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func (c *cenario) statusConsulta(t *testing.T, id string) string {
+	t.Helper()
+	consulta, err := c.consultas.BuscarConsultaPorID(c.ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return consulta.Status
+}
+
+func TestReconciliarCadaInconsistencia(t *testing.T) {
+	casos := []struct {
+		nome  string
+		tipos []string
+		// preparar cria a inconsistência e devolve a conferência do estado corrigido.
+		preparar func(t *testing.T, c *cenario) func(t *testing.T)
+	}{
+		{"consulta sem horario", []string{model.InconsistenciaConsultaSemHorario}, func(t *testing.T, c *cenario) func(t *testing.T) {
+			h := c.horario(t, model.HorarioDisponivel{Status: "disponivel"})
+			consulta := c.agendar(t, h, c.alunoID)
+			c.horarios.DeletarHorario(c.ctx, h)
+			return func(t *testing.T) {
+				if s := c.statusConsulta(t, consulta); s != "cancelada pela clinica" {
+					t.Errorf("consulta deveria ser cancelada, está %q", s)
+				}
+			}
+		}},
+		{"consulta sem aluno", []string{model.InconsistenciaConsultaSemAluno}, func(t *testing.T, c *cenario) func(t *testing.T) {
+			h := c.horario(t, model.HorarioDisponivel{Status: "disponivel"})
+			consulta := c.agendar(t, h, c.alunoID)
+			c.alunos.DeletarAluno(c.ctx, c.alunoID)
+			return func(t *testing.T) {
+				if s := c.statusConsulta(t, consulta); s != "cancelada pela clinica" {
+					t.Errorf("consulta deveria ser cancelada, está %q", s)
+				}
+				if s := c.statusHorario(t, h).Status; s != "disponivel" {
+					t.Errorf("o cancelamento deveria devolver o horário, está %q", s)
+				}
+			}
+		}},
+		{"consulta sem aluno no grupo com espera", []string{model.InconsistenciaConsultaSemAluno}, func(t *testing.T, c *cenario) func(t *testing.T) {
+			h := c.horario(t, model.HorarioDisponivel{Tipo: model.HorarioGrupo, Capacidade: 1, Status: "disponivel"})
+			c.agendar(t, h, c.alunoID)
+			outro, err := c.alunos.CriarAluno(c.ctx, model.Aluno{Nome: "Bia"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			espera := c.agendar(t, h, outro.ID)
+			c.alunos.DeletarAluno(c.ctx, c.alunoID)
+			return func(t *testing.T) {
+				if s := c.statusConsulta(t, espera); s != "aguardando aprovacao" {
+					t.Errorf("a vaga deveria passar à lista de espera, status %q", s)
+				}
+				if g := c.statusHorario(t, h); g.Inscritos != 1 || g.ListaEspera != 0 || g.Status != "lotado" {
+					t.Errorf("contagens do grupo incorretas: %+v", g)
+				}
+			}
+		}},
+		{"consulta e horario sem psicologo", []string{model.InconsistenciaConsultaSemPsicologo, model.InconsistenciaHorarioSemPsicologo}, func(t *testing.T, c *cenario) func(t *testing.T) {
+			h := c.horario(t, model.HorarioDisponivel{Status: "disponivel"})
+			consulta := c.agendar(t, h, c.alunoID)
+			c.psicologos.DeletarPsicologo(c.ctx, c.psicologoID)
+			return func(t *testing.T) {
+				if s := c.statusConsulta(t, consulta); s != "cancelada pela clinica" {
+					t.Errorf("consulta deveria ser cancelada, está %q", s)
+				}
+				if s := c.statusHorario(t, h).Status; s != "bloqueado" {
+					t.Errorf("horário sem psicólogo deveria ser bloqueado, está %q", s)
+				}
+			}
+		}},
+		{"horario agendado sem consulta", []string{model.InconsistenciaHorarioAgendadoLivre}, func(t *testing.T, c *cenario) func(t *testing.T) {
+			h := c.horario(t, model.HorarioDisponivel{Status: "agendado"})
+			return func(t *testing.T) {
+				if s := c.statusHorario(t, h).Status; s != "disponivel" {
+					t.Errorf("horário deveria ser liberado, está %q", s)
+				}
+			}
+		}},
+		{"horario livre com consulta", []string{model.InconsistenciaHorarioLivreOcupado}, func(t *testing.T, c *cenario) func(t *testing.T) {
+			h := c.horario(t, model.HorarioDisponivel{Status: "disponivel"})
+			c.agendar(t, h, c.alunoID)
+			c.horarios.AtualizarStatusHorario(c.ctx, h, "disponivel")
+			return func(t *testing.T) {
+				if s := c.statusHorario(t, h).Status; s != "agendado" {
+					t.Errorf("horário deveria voltar a agendado, está %q", s)
+				}
+			}
+		}},
+		{"reserva sem aluno", []string{model.InconsistenciaReservaSemAluno}, func(t *testing.T, c *cenario) func(t *testing.T) {
+			h := c.horario(t, model.HorarioDisponivel{Status: "disponivel"})
+			if err := c.horarios.ReservarHorario(c.ctx, h, "aluno-removido", c.agora.Add(time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+			return func(t *testing.T) {
+				if liberado := c.statusHorario(t, h); liberado.Status != "disponivel" || liberado.ReservadoPara != "" {
+					t.Errorf("reserva deveria ser liberada: %+v", liberado)
+				}
+			}
+		}},
+		{"contagem do grupo", []string{model.InconsistenciaContagemGrupo}, func(t *testing.T, c *cenario) func(t *testing.T) {
+			h := c.horario(t, model.HorarioDisponivel{Tipo: model.HorarioGrupo, Capacidade: 2, Status: "disponivel"})
+			c.agendar(t, h, c.alunoID)
+			c.banco.Horarios[h].Inscritos = 2
+			c.banco.Horarios[h].Status = "lotado"
+			return func(t *testing.T) {
+				if g := c.statusHorario(t, h); g.Inscritos != 1 || g.ListaEspera != 0 || g.Status != "disponivel" {
+					t.Errorf("contagens deveriam ser recalculadas: %+v", g)
+				}
+			}
+		}},
+	}
+
+	for _, tc := range casos {
+		t.Run(tc.nome, func(t *testing.T) {
+			c := novoCenario(t)
+			conferir := tc.preparar(t, c)
+
+			antes, _ := json.Marshal(c.banco)
+			simulado := c.reconciliar(t, c.horarios, false)
+			depois, _ := json.Marshal(c.banco)
+			if string(antes) != string(depois) {
+				t.Error("a simulação não deveria alterar nada")
+			}
+			if simulado.Total != len(tc.tipos) || simulado.Corrigidas != 0 {
+				t.Errorf("simulação: %d inconsistência(s) e %d corrigida(s), esperado %d e 0", simulado.Total, simulado.Corrigidas, len(tc.tipos))
+			}
+			for _, tipo := range tc.tipos {
+				if simulado.PorTipo[tipo] != 1 {
+					t.Errorf("simulação deveria apontar %s: %+v", tipo, simulado.PorTipo)
+				}
+			}
+
+			aplicado := c.reconciliar(t, c.horarios, true)
+			if aplicado.Total != len(tc.tipos) || aplicado.Corrigidas != aplicado.Total || aplicado.Falhas != 0 {
+				t.Fatalf("aplicação incompleta: %+v", aplicado)
+			}
+			conferir(t)
+
+			if novamente := c.reconciliar(t, c.horarios, false); novamente.Total != 0 {
+				t.Errorf("depois de corrigir não deveria sobrar nada: %+v", novamente.PorTipo)
+			}
+		})
+	}
+}
+
+// horariosComCorrida roda antes logo antes de cada correção de horário,
+// simulando uma escrita entre a leitura do reconciliador e a gravação.
+type horariosComCorrida struct {
+	repository.HorarioDisponivelRepository
+	antes func()
+}
+
+func (h horariosComCorrida) ReconciliarStatusHorario(ctx context.Context, id string, esperado, novoStatus string) error {
+	h.antes()
+	return h.HorarioDisponivelRepository.ReconciliarStatusHorario(ctx, id, esperado, novoStatus)
+}
+
+func (h horariosComCorrida) RecontarVagasGrupo(ctx context.Context, id string, inscritosVistos, esperaVistos int) error {
+	h.antes()
+	return h.HorarioDisponivelRepository.RecontarVagasGrupo(ctx, id, inscritosVistos, esperaVistos)
+}
+
+func TestReconciliarDescartaOQueMudouDepoisDaLeitura(t *testing.T) {
+	t.Run("agendamento chega antes de liberar o horario", func(t *testing.T) {
+		c := novoCenario(t)
+		h := c.horario(t, model.HorarioDisponivel{Status: "agendado"})
+		horarios := horariosComCorrida{c.horarios, func() {
+			// A consulta que segura o horário é gravada depois da leitura.
+			c.banco.Consultas["consulta-atrasada"] = &model.Consulta{ID: "consulta-atrasada", HorarioID: h, AlunoID: c.alunoID, PsicologoID: c.psicologoID, Status: "aguardando aprovacao"}
+		}}
+
+		relatorio := c.reconciliar(t, horarios, true)
+		if relatorio.Descartadas != 1 || relatorio.Corrigidas != 0 || relatorio.Falhas != 0 || !relatorio.Inconsistencias[0].Descartada {
+			t.Fatalf("a correção deveria ser descartada: %+v", relatorio)
+		}
+		if s := c.statusHorario(t, h).Status; s != "agendado" {
+			t.Errorf("o horário agendado não pode ser liberado: %q", s)
+		}
+	})
+
+	t.Run("inscricao chega antes de recontar o grupo", func(t *testing.T) {
+		c := novoCenario(t)
+		h := c.horario(t, model.HorarioDisponivel{Tipo: model.HorarioGrupo, Capacidade: 2, Status: "disponivel"})
+		c.agendar(t, h, c.alunoID)
+		c.banco.Horarios[h].Inscritos = 0
+		outro, err := c.alunos.CriarAluno(c.ctx, model.Aluno{Nome: "Bia"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		horarios := horariosComCorrida{c.horarios, func() { c.agendar(t, h, outro.ID) }}
+
+		relatorio := c.reconciliar(t, horarios, true)
+		if relatorio.Descartadas != 1 || relatorio.Corrigidas != 0 {
+			t.Fatalf("a recontagem com dados velhos deveria ser descartada: %+v", relatorio)
+		}
+		if g := c.statusHorario(t, h); g.Inscritos != 1 {
+			t.Errorf("a inscrição concorrente deveria ser mantida: %+v", g)
+		}
+
+		// Na execução seguinte o reconciliador vê as duas inscrições.
+		if relatorio := c.reconciliar(t, c.horarios, true); relatorio.Corrigidas != 1 {
+			t.Fatalf("a próxima execução deveria corrigir: %+v", relatorio)
+		}
+		if g := c.statusHorario(t, h); g.Inscritos != 2 || g.Status != "lotado" {
+			t.Errorf("contagens deveriam refletir as duas inscrições: %+v", g)
+		}
+	})
+}
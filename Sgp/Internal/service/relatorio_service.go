@@ -0,0 +1,123 @@
+package service
+
+import (
+	"sgp/Internal/model"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// CalcularRelatorio considera os horários e as consultas com início em
+// [de, ate). Um horário de grupo conta uma vez, ocupado se tiver inscritos.
+func CalcularRelatorio(horarios []*model.HorarioDisponivel, consultas []*model.Consulta, de, ate time.Time) model.RelatorioAtendimento {
+	rel := model.RelatorioAtendimento{De: de, Ate: ate, PorStatus: map[string]int{}}
+	noPeriodo := func(t time.Time) bool { return !t.Before(de) && t.Before(ate) }
+
+	for _, h := range horarios {
+		if !noPeriodo(h.Inicio) || h.Status == "bloqueado" {
+			continue
+		}
+		rel.HorariosOfertados++
+		if h.Status == "agendado" || h.Status == "reservado" || h.Status == "lotado" || h.Inscritos > 0 {
+			rel.HorariosOcupados++
+		}
+	}
+	if rel.HorariosOfertados > 0 {
+		rel.TaxaOcupacao = float64(rel.HorariosOcupados) / float64(rel.HorariosOfertados)
+	}
+
+	var confirmacoes []float64
+	primeiras := map[string]*model.Consulta{}
+	atendidos := map[string]bool{}
+	canceladas, faltas, presentes := 0, 0, 0
+	for _, c := range consultas {
+		if !noPeriodo(c.Inicio) {
+			continue
+		}
+		rel.TotalConsultas++
+		rel.PorStatus[c.Status]++
+
+		switch {
+		case ConsultaCancelada(c.Status):
+			canceladas++
+		case c.Status == "falta":
+			faltas++
+		case c.Status == "em andamento" || c.Status == "concluida":
+			presentes++
+			atendidos[c.AlunoID] = true
+		}
+		if !c.ConfirmadaEm.IsZero() && !c.DataAgendamento.IsZero() {
+			confirmacoes = append(confirmacoes, c.ConfirmadaEm.Sub(c.DataAgendamento).Hours())
+		}
+		// A primeira sessão do aluno no período é a primeira que chegou a ser
+		// marcada, mesmo que ele tenha faltado.
+		if c.DataAgendamento.IsZero() || (c.Status != "confirmada" && c.Status != "em andamento" && c.Status != "concluida" && c.Status != "falta") {
+			continue
+		}
+		if p, ok := primeiras[c.AlunoID]; !ok || c.Inicio.Before(p.Inicio) {
+			primeiras[c.AlunoID] = c
+		}
+	}
+
+	var esperas []float64
+	for _, c := range primeiras {
+		esperas = append(esperas, c.Inicio.Sub(c.DataAgendamento).Hours())
+	}
+	rel.MedianaEsperaNoPeriodoHoras, rel.AmostrasEsperaNoPeriodo = mediana(esperas), len(esperas)
+	rel.MedianaConfirmacaoHoras, rel.AmostrasConfirmacao = mediana(confirmacoes), len(confirmacoes)
+
+	if rel.TotalConsultas > 0 {
+		rel.TaxaCancelamento = float64(canceladas) / float64(rel.TotalConsultas)
+	}
+	if faltas+presentes > 0 {
+		rel.TaxaFalta = float64(faltas) / float64(faltas+presentes)
+	}
+	rel.AlunosAtendidos = len(atendidos)
+	return rel
+}
+
+// LinhasRelatorio achata o relatório em pares métrica/valor, na ordem do CSV.
+func LinhasRelatorio(rel model.RelatorioAtendimento) [][]string {
+	inteiro := strconv.Itoa
+	decimal := func(v float64) string { return strconv.FormatFloat(v, 'f', 4, 64) }
+
+	linhas := [][]string{
+		{"metrica", "valor"},
+		{"de", rel.De.UTC().Format(time.RFC3339)},
+		{"ate", rel.Ate.UTC().Format(time.RFC3339)},
+		{"psicologoId", rel.PsicologoID},
+		{"horariosOfertados", inteiro(rel.HorariosOfertados)},
+		{"horariosOcupados", inteiro(rel.HorariosOcupados)},
+		{"taxaOcupacao", decimal(rel.TaxaOcupacao)},
+		{"medianaEsperaNoPeriodoHoras", decimal(rel.MedianaEsperaNoPeriodoHoras)},
+		{"amostrasEsperaNoPeriodo", inteiro(rel.AmostrasEsperaNoPeriodo)},
+		{"medianaConfirmacaoHoras", decimal(rel.MedianaConfirmacaoHoras)},
+		{"amostrasConfirmacao", inteiro(rel.AmostrasConfirmacao)},
+		{"totalConsultas", inteiro(rel.TotalConsultas)},
+		{"taxaCancelamento", decimal(rel.TaxaCancelamento)},
+		{"taxaFalta", decimal(rel.TaxaFalta)},
+		{"alunosAtendidos", inteiro(rel.AlunosAtendidos)},
+	}
+
+	status := make([]string, 0, len(rel.PorStatus))
+	for s := range rel.PorStatus {
+		status = append(status, s)
+	}
+	sort.Strings(status)
+	for _, s := range status {
+		linhas = append(linhas, []string{"status:" + s, inteiro(rel.PorStatus[s])})
+	}
+	return linhas
+}
+
+func mediana(valores []float64) float64 {
+	if len(valores) == 0 {
+		return 0
+	}
+	sort.Float64s(valores)
+	meio := len(valores) / 2
+	if len(valores)%2 == 1 {
+		return valores[meio]
+	}
+	return (valores[meio-1] + valores[meio]) / 2
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sgp/Internal/repository"
+	"time"
+)
+
+// ReservaService devolve à agenda os horários reservados por encaminhamentos
+// cujo prazo venceu sem agendamento.
+type ReservaService struct {
+	HorarioRepo repository.HorarioDisponivelRepository
+}
+
+func NewReservaService(horarioRepo repository.HorarioDisponivelRepository) *ReservaService {
+	return &ReservaService{HorarioRepo: horarioRepo}
+}
+
+// LiberarReservasVencidas retorna quantos horários foram liberados. Uma falha
+// num horário é registrada e não impede os demais.
+func (s *ReservaService) LiberarReservasVencidas(ctx context.Context, agora time.Time) (int, error) {
+	reservados, err := s.HorarioRepo.ListarHorariosPorStatus(ctx, "reservado")
+	if err != nil {
+		return 0, err
+	}
+
+	liberados := 0
+	for _, h := range reservados {
+		if h.ReservadoAte.IsZero() || !h.ReservadoAte.Before(agora) {
+			continue
+		}
+		err := s.HorarioRepo.ExpirarReserva(ctx, h.ID, agora)
+		if errors.Is(err, repository.ErrTransicaoInvalida) {
+			continue // agendado ou liberado entre a listagem e a liberação
+		}
+		if err != nil {
+			log.Printf("ERRO ao liberar a reserva vencida do horário %s: %v", h.ID, err)
+			continue
+		}
+		liberados++
+	}
+	return liberados, nil
+}
+
+// IniciarLiberacaoReservas roda LiberarReservasVencidas a cada intervalo até o
+// contexto ser cancelado; uma rodada já iniciada vai até o fim.
+func (s *ReservaService) IniciarLiberacaoReservas(ctx context.Context, intervalo time.Duration) {
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case agora := <-ticker.C:
+			if n, err := s.LiberarReservasVencidas(context.WithoutCancel(ctx), agora); err != nil {
+				log.Printf("ERRO ao liberar reservas vencidas: %v", err)
+			} else if n > 0 {
+				log.Printf("%d reserva(s) vencida(s) liberada(s)", n)
+			}
+		}
+	}
+}
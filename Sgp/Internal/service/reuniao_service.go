@@ -0,0 +1,90 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sgp/Internal/model"
+	"strings"
+	"time"
+)
+
+// MeetingProvider gera o link da sala virtual de uma consulta online.
+// Implementações devem ser idempotentes: a mesma consulta gera o mesmo link.
+type MeetingProvider interface {
+	GerarLink(consulta model.Consulta) (string, error)
+}
+
+// JitsiPadrao é o servidor usado quando nenhum outro é configurado.
+const JitsiPadrao = "https://meet.jit.si"
+
+// JitsiProvider monta URLs de sala no formato do Jitsi Meet sem chamar
+// nenhuma API: o nome da sala é um HMAC do ID da consulta (ou do horário, em
+// grupos), o que o torna determinístico e impossível de adivinhar sem o segredo.
+type JitsiProvider struct {
+	BaseURL string
+	segredo []byte
+}
+
+func NewJitsiProvider(baseURL, segredo string) (*JitsiProvider, error) {
+	if segredo == "" {
+		return nil, fmt.Errorf("segredo das salas de reunião não configurado")
+	}
+	if baseURL == "" {
+		baseURL = JitsiPadrao
+	}
+	return &JitsiProvider{BaseURL: strings.TrimRight(baseURL, "/"), segredo: []byte(segredo)}, nil
+}
+
+func (p *JitsiProvider) GerarLink(consulta model.Consulta) (string, error) {
+	if consulta.ID == "" {
+		return "", fmt.Errorf("consulta sem ID")
+	}
+	// Todos os inscritos de um grupo entram na mesma sala.
+	chave := consulta.ID
+	if consulta.Grupo && consulta.HorarioID != "" {
+		chave = "horario:" + consulta.HorarioID
+	}
+	mac := hmac.New(sha256.New, p.segredo)
+	mac.Write([]byte(chave))
+	return fmt.Sprintf("%s/sgp-%s", p.BaseURL, hex.EncodeToString(mac.Sum(nil))[:24]), nil
+}
+
+// GerarICS monta o convite (.ics) da consulta. Em sessões online o link vai
+// em LOCATION e URL para aparecer direto no calendário.
+func GerarICS(consulta model.Consulta, nomePsicologo string) []byte {
+	const formato = "20060102T150405Z"
+
+	local := "Presencial"
+	if consulta.Modalidade == model.ModalidadeOnline {
+		local = consulta.LinkReuniao
+	}
+
+	linhas := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//SGP//Consultas//PT",
+		"METHOD:PUBLISH",
+		"BEGIN:VEVENT",
+		fmt.Sprintf("UID:%s@sgp.codes", consulta.ID),
+		"DTSTAMP:" + time.Now().UTC().Format(formato),
+		"DTSTART:" + consulta.Inicio.UTC().Format(formato),
+		"DTEND:" + consulta.Fim.UTC().Format(formato),
+		"SUMMARY:" + escaparICS("Consulta com "+nomePsicologo),
+		"LOCATION:" + escaparICS(local),
+	}
+	if consulta.LinkReuniao != "" {
+		linhas = append(linhas,
+			"URL:"+consulta.LinkReuniao,
+			"DESCRIPTION:"+escaparICS("Entre na sala pelo link: "+consulta.LinkReuniao),
+		)
+	}
+	linhas = append(linhas, "END:VEVENT", "END:VCALENDAR")
+
+	return []byte(strings.Join(linhas, "\r\n") + "\r\n")
+}
+
+func escaparICS(texto string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(texto)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// Tarefas acompanha goroutines de segundo plano, como os e-mails disparados
+// pelos handlers e os agendadores, para que o encerramento espere por elas.
+// Um *Tarefas nil só dispara a goroutine, sem acompanhar.
+type Tarefas struct {
+	mu          sync.Mutex
+	encerrando  bool
+	wg          sync.WaitGroup
+	emAndamento atomic.Int64
+}
+
+// Disparar recusa, com um aviso no log, tarefas que chegam depois de Aguardar
+// ter começado: um handler que estourou o prazo do servidor HTTP ainda pode
+// tentar disparar um e-mail, e o WaitGroup não aceita Add durante o Wait.
+// Retorna se a tarefa foi aceita.
+func (t *Tarefas) Disparar(f func()) bool {
+	if t == nil {
+		go f()
+		return true
+	}
+	t.mu.Lock()
+	if t.encerrando {
+		t.mu.Unlock()
+		log.Printf("AVISO: tarefa em segundo plano recusada: o encerramento já começou")
+		return false
+	}
+	t.wg.Add(1)
+	t.emAndamento.Add(1)
+	t.mu.Unlock()
+	go func() {
+		defer t.wg.Done()
+		defer t.emAndamento.Add(-1)
+		f()
+	}()
+	return true
+}
+
+// Aguardar espera as tarefas em andamento ou o fim do contexto; no segundo
+// caso, o erro diz quantas ficaram sem terminar. A partir da chamada, novas
+// tarefas são recusadas.
+func (t *Tarefas) Aguardar(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	t.encerrando = true
+	t.mu.Unlock()
+	terminou := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(terminou)
+	}()
+	select {
+	case <-terminou:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%d tarefa(s) sem terminar: %w", t.emAndamento.Load(), ctx.Err())
+	}
+}
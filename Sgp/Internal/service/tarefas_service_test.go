@@ -0,0 +1,119 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sgp/Internal/repository/memoria"
+	"sgp/Internal/service"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTarefasAguardar(t *testing.T) {
+	tarefas := &service.Tarefas{}
+	liberar := make(chan struct{})
+	rapida := make(chan struct{})
+	tarefas.Disparar(func() { close(rapida) })
+	tarefas.Disparar(func() { <-liberar })
+	<-rapida
+
+	ctx, cancelar := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelar()
+	err := tarefas.Aguardar(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "1 tarefa(s)") {
+		t.Fatalf("deveria estourar o prazo com 1 tarefa pendente, obteve %v", err)
+	}
+
+	close(liberar)
+	if err := tarefas.Aguardar(context.Background()); err != nil {
+		t.Errorf("todas as tarefas terminaram: %v", err)
+	}
+
+	var nula *service.Tarefas
+	feita := make(chan struct{})
+	nula.Disparar(func() { close(feita) })
+	<-feita
+	if err := nula.Aguardar(context.Background()); err != nil {
+		t.Errorf("Tarefas nil não acompanha nada: %v", err)
+	}
+}
+
+func TestTarefasRecusaDisparoDepoisDoEncerramento(t *testing.T) {
+	tarefas := &service.Tarefas{}
+	liberar := make(chan struct{})
+	tarefas.Disparar(func() { <-liberar })
+
+	aguardou := make(chan error, 1)
+	go func() { aguardou <- tarefas.Aguardar(context.Background()) }()
+
+	// Até o Aguardar começar o disparo é aceito; depois, recusado.
+	var rodou atomic.Bool
+	for prazo := time.Now().Add(time.Second); tarefas.Disparar(func() {}); {
+		if time.Now().After(prazo) {
+			t.Fatal("o disparo continuou aceito depois de Aguardar começar")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if tarefas.Disparar(func() { rodou.Store(true) }) {
+		t.Error("depois de recusar, o disparo não deveria voltar a ser aceito")
+	}
+
+	close(liberar)
+	if err := <-aguardou; err != nil {
+		t.Fatalf("Aguardar deveria terminar com a tarefa acompanhada: %v", err)
+	}
+	if rodou.Load() {
+		t.Error("a tarefa recusada não deveria rodar")
+	}
+}
+
+func TestEmailInterrompidoFicaParaReenvio(t *testing.T) {
+	liberar := make(chan struct{})
+	resend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-liberar
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"e1"}`))
+	}))
+	defer resend.Close()
+	defer close(liberar)
+
+	banco := memoria.NovoBanco()
+	registro := memoria.NewNotificacaoRepository(banco)
+	email := service.NewEmailService("re_teste")
+	email.Client.BaseURL, _ = url.Parse(resend.URL + "/")
+	email.Registro = registro
+
+	tarefas := &service.Tarefas{}
+	tarefas.Disparar(func() { email.EnviarNotificacaoAtualizacaoStatus("a1", "ana@uni.br", "Ana", "confirmada") })
+
+	ctx, cancelar := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelar()
+	if err := tarefas.Aguardar(ctx); err == nil {
+		t.Fatal("o envio bloqueado deveria estourar o prazo")
+	}
+	if err := email.RegistrarInterrompidos(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	falhas, err := registro.ListarNotificacoesPorStatus(context.Background(), "falhou")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(falhas) != 1 || falhas[0].AlunoID != "a1" || falhas[0].Html == "" {
+		t.Fatalf("o envio interrompido deveria ficar registrado para reenvio: %+v", falhas)
+	}
+
+	// Quando o envio termina depois, não grava um segundo registro.
+	liberar <- struct{}{}
+	if err := tarefas.Aguardar(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	todas, _ := registro.ListarNotificacoesPorAluno(context.Background(), "a1")
+	if len(todas) != 1 {
+		t.Errorf("esperava um único registro, obteve %d", len(todas))
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"fmt"
+	"sgp/Internal/model"
+)
+
+// PerguntaTriagem é uma pergunta de sim/não da triagem feita no agendamento.
+// Uma resposta "sim" eleva a urgência ao nível indicado.
+type PerguntaTriagem struct {
+	ID       string `json:"id"`
+	Texto    string `json:"texto"`
+	Urgencia string `json:"urgencia"`
+}
+
+var PerguntasTriagem = []PerguntaTriagem{
+	{ID: "risco", Texto: "Você tem pensado em se machucar ou em tirar a própria vida?", Urgencia: model.UrgenciaUrgente},
+	{ID: "crise", Texto: "Você está passando por uma situação de crise neste momento (ex: luto recente, violência, crises de pânico)?", Urgencia: model.UrgenciaPrioritaria},
+	{ID: "prejuizo", Texto: "O que você está sentindo está impedindo você de estudar ou de fazer as atividades do dia a dia?", Urgencia: model.UrgenciaPrioritaria},
+}
+
+// RecursoCrise é um canal de ajuda imediata, disponível fora do agendamento.
+type RecursoCrise struct {
+	Nome      string `json:"nome"`
+	Contato   string `json:"contato"`
+	Descricao string `json:"descricao"`
+}
+
+var RecursosCrise = []RecursoCrise{
+	{Nome: "CVV - Centro de Valorização da Vida", Contato: "188", Descricao: "Apoio emocional e prevenção do suicídio, 24 horas, gratuito."},
+	{Nome: "SAMU", Contato: "192", Descricao: "Emergências médicas, incluindo crises em saúde mental."},
+	{Nome: "Bombeiros", Contato: "193", Descricao: "Situações de risco iminente à vida."},
+}
+
+var pesoUrgencia = map[string]int{
+	model.UrgenciaRotina:      0,
+	model.UrgenciaPrioritaria: 1,
+	model.UrgenciaUrgente:     2,
+}
+
+// PesoUrgencia permite ordenar consultas: quanto maior, mais urgente.
+// Consultas sem urgência registrada contam como rotina.
+func PesoUrgencia(urgencia string) int {
+	return pesoUrgencia[urgencia]
+}
+
+// ClassificarUrgencia combina o auto-relato do aluno com as respostas da
+// triagem, ficando sempre com o nível mais alto.
+func ClassificarUrgencia(autoRelato string, respostas map[string]bool) (string, error) {
+	urgencia := model.UrgenciaRotina
+	if autoRelato != "" {
+		if _, ok := pesoUrgencia[autoRelato]; !ok {
+			return "", fmt.Errorf("urgência inválida: %s", autoRelato)
+		}
+		urgencia = autoRelato
+	}
+
+	perguntas := map[string]PerguntaTriagem{}
+	for _, p := range PerguntasTriagem {
+		perguntas[p.ID] = p
+	}
+
+	for id, sim := range respostas {
+		p, ok := perguntas[id]
+		if !ok {
+			return "", fmt.Errorf("pergunta de triagem desconhecida: %s", id)
+		}
+		if sim && pesoUrgencia[p.Urgencia] > pesoUrgencia[urgencia] {
+			urgencia = p.Urgencia
+		}
+	}
+	return urgencia, nil
+}